@@ -13,7 +13,9 @@ type AudioSystem struct {
 }
 
 func init() {
-	core.SetAudioSystem(&AudioSystem{})
+	if err := core.SetAudioSystem(&AudioSystem{}); err != nil {
+		glog.Error(err)
+	}
 }
 
 // Start implements the core.AudioSystem interface
@@ -16,10 +16,17 @@ func NewClientApplication() core.ClientApplication {
 	c := demoApp{}
 
 	// use the default inputcomponent do deal with ESC->quit.
+	inputMap := core.GetInputManager().InputMap()
+	inputMap.Bind("quit", core.InputBinding{Key: core.KeyEscape})
+	inputMap.Bind("toggle_debug_menu", core.InputBinding{Key: core.KeyE})
 	c.inputComponent = new(applicationInputComponent)
 
 	// push the main scene into the scenemanager
-	core.GetSceneManager().PushScene(makeDemoScene())
+	scene, err := makeDemoScene()
+	if err != nil {
+		glog.Fatal(err)
+	}
+	core.GetSceneManager().PushScene(scene)
 
 	// return
 	return &c
@@ -8,14 +8,14 @@ type applicationInputComponent struct{}
 // Run checks the InputSystem for actionable state and returns commands
 func (ic *applicationInputComponent) Run() (commands []core.ClientApplicationCommand) {
 	// check for quit key, append to command list
-	state := *core.GetInputManager().State()
+	im := core.GetInputManager()
 
-	if state.Keys.Active[core.KeyEscape] {
+	if im.Action("quit") {
 		commands = append(commands, new(clientApplicationQuitCommand))
 	}
 
 	// key-up, after down
-	if state.Keys.Released[core.KeyE] {
+	if im.ActionReleased("toggle_debug_menu") {
 		commands = append(commands, new(clientApplicationToggleDebugMenuCommand))
 	}
 
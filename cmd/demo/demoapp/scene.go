@@ -10,11 +10,9 @@ func getDemoSceneShadowTextures(s *core.Scene) []*core.Texture {
 }
 
 func getDemoSceneLight(s *core.Scene) *core.Light {
-	geoRoot := s.Root().Children()[0]
-	lightNode := geoRoot.Children()[len(geoRoot.Children())-2]
-	return lightNode.Light()
+	return s.Root().Find("GeometryRoot/Light1").Light()
 }
 
-func makeDemoScene() *core.Scene {
+func makeDemoScene() (*core.Scene, error) {
 	return core.GetResourceManager().Scene("demo.yaml")
 }
@@ -198,3 +198,104 @@ func (a *AABB) Intersection(b *AABB) *AABB {
 	}
 	return result
 }
+
+// PlaneSide is the side of a plane a volume is on. Planes are stored as
+// (normal, d) with points p in front where normal.p+d > 0, as frustum planes are.
+type PlaneSide int
+
+// Plane sides
+const (
+	PlaneBack PlaneSide = iota - 1
+	PlaneIntersecting
+	PlaneFront
+)
+
+// planeSide classifies a volume by the signed distance of its centre to the
+// plane and its radius projected on the plane's normal.
+func planeSide(distance, radius float64) PlaneSide {
+	switch {
+	case distance > radius:
+		return PlaneFront
+	case distance < -radius:
+		return PlaneBack
+	default:
+		return PlaneIntersecting
+	}
+}
+
+// ClassifyPlane returns which side of a plane the AABB is on
+func (a *AABB) ClassifyPlane(plane mgl64.Vec4) PlaneSide {
+	center, extents := a.Center(), a.Size().Mul(0.5)
+	n := plane.Vec3()
+	radius := extents[0]*math.Abs(n[0]) + extents[1]*math.Abs(n[1]) + extents[2]*math.Abs(n[2])
+	return planeSide(n.Dot(center)+plane[3], radius)
+}
+
+// ClosestPoint returns the point in the AABB closest to the given point
+func (a *AABB) ClosestPoint(p mgl64.Vec3) mgl64.Vec3 {
+	for i := 0; i < 3; i++ {
+		p[i] = math.Max(a.min[i], math.Min(p[i], a.max[i]))
+	}
+	return p
+}
+
+// IntersectsAABB returns whether two AABBs overlap
+func (a *AABB) IntersectsAABB(b *AABB) bool {
+	for i := 0; i < 3; i++ {
+		if a.max[i] < b.min[i] || a.min[i] > b.max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsSphere returns whether the AABB and the sphere overlap
+func (a *AABB) IntersectsSphere(s *Sphere) bool {
+	return s.IntersectsAABB(a)
+}
+
+// IntersectsOBB returns whether the AABB and the OBB overlap
+func (a *AABB) IntersectsOBB(o *OBB) bool {
+	return o.IntersectsAABB(a)
+}
+
+// IntersectsTriangle returns whether the AABB and the triangle overlap, by
+// separating axis tests on the box's axes, the triangle's normal and the
+// cross products of their edges.
+func (a *AABB) IntersectsTriangle(t Triangle) bool {
+	return boxIntersectsTriangle(a.Size().Mul(0.5), [3]mgl64.Vec3{
+		t[0].Sub(a.Center()),
+		t[1].Sub(a.Center()),
+		t[2].Sub(a.Center()),
+	})
+}
+
+// boxIntersectsTriangle tests a box centred at the origin on the coordinate
+// axes against a triangle.
+func boxIntersectsTriangle(extents mgl64.Vec3, v [3]mgl64.Vec3) bool {
+	edges := [3]mgl64.Vec3{v[1].Sub(v[0]), v[2].Sub(v[1]), v[0].Sub(v[2])}
+	separated := func(axis mgl64.Vec3) bool {
+		if axis.Dot(axis) < 1e-18 {
+			// parallel edges, covered by the other axes
+			return false
+		}
+		p0, p1, p2 := v[0].Dot(axis), v[1].Dot(axis), v[2].Dot(axis)
+		r := extents[0]*math.Abs(axis[0]) + extents[1]*math.Abs(axis[1]) + extents[2]*math.Abs(axis[2])
+		return math.Max(p0, math.Max(p1, p2)) < -r || math.Min(p0, math.Min(p1, p2)) > r
+	}
+
+	axes := [3]mgl64.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for _, u := range axes {
+		for _, e := range edges {
+			if separated(u.Cross(e)) {
+				return false
+			}
+		}
+	}
+	for _, u := range axes {
+		if separated(u) {
+			return false
+		}
+	}
+	return !separated(edges[0].Cross(edges[1]))
+}
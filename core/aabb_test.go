@@ -232,3 +232,90 @@ func TestAABB_InFrustum(t *testing.T) {
 		t.Error("AABB far beyond far plane should not be in frustum")
 	}
 }
+
+func TestAABB_ClosestPoint(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{0, 0, 0})
+	a.ExtendWithPoint(mgl64.Vec3{10, 10, 10})
+
+	tests := []struct {
+		point mgl64.Vec3
+		want  mgl64.Vec3
+	}{
+		{mgl64.Vec3{5, 5, 5}, mgl64.Vec3{5, 5, 5}},
+		{mgl64.Vec3{-5, 5, 5}, mgl64.Vec3{0, 5, 5}},
+		{mgl64.Vec3{20, -3, 12}, mgl64.Vec3{10, 0, 10}},
+	}
+
+	for _, tt := range tests {
+		if got := a.ClosestPoint(tt.point); got != tt.want {
+			t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestAABB_ClassifyPlane(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+
+	tests := []struct {
+		plane mgl64.Vec4
+		want  PlaneSide
+	}{
+		{mgl64.Vec4{0, 1, 0, 2}, PlaneFront},
+		{mgl64.Vec4{0, 1, 0, -2}, PlaneBack},
+		{mgl64.Vec4{0, 1, 0, 0.5}, PlaneIntersecting},
+		// the corner reaches sqrt(3) along the diagonal
+		{mgl64.Vec3{1, 1, 1}.Normalize().Vec4(-1.7), PlaneIntersecting},
+		{mgl64.Vec3{1, 1, 1}.Normalize().Vec4(-1.8), PlaneBack},
+	}
+
+	for _, tt := range tests {
+		if got := a.ClassifyPlane(tt.plane); got != tt.want {
+			t.Errorf("ClassifyPlane(%v) = %v, want %v", tt.plane, got, tt.want)
+		}
+	}
+}
+
+func TestAABB_IntersectsAABB(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{0, 0, 0})
+	a.ExtendWithPoint(mgl64.Vec3{2, 2, 2})
+
+	b := a.Transformed(mgl64.Translate3D(1, 1, 1))
+	if !a.IntersectsAABB(b) || !b.IntersectsAABB(a) {
+		t.Error("overlapping boxes should intersect")
+	}
+
+	c := a.Transformed(mgl64.Translate3D(3, 0, 0))
+	if a.IntersectsAABB(c) {
+		t.Error("separated boxes should not intersect")
+	}
+}
+
+func TestAABB_IntersectsTriangle(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+
+	tests := []struct {
+		name string
+		tri  Triangle
+		want bool
+	}{
+		{"through", Triangle{{-5, -5, 0}, {5, -5, 0}, {0, 5, 0}}, true},
+		{"inside", Triangle{{-0.5, 0, 0}, {0.5, 0, 0}, {0, 0.5, 0}}, true},
+		{"above", Triangle{{-5, -5, 2}, {5, -5, 2}, {0, 5, 2}}, false},
+		// overlapping on every axis of the box, past its corner
+		{"corner", Triangle{{3.5, 0, 0}, {0, 3.5, 0}, {0, 0, 3.5}}, false},
+		{"touching", Triangle{{3, 0, 0}, {0, 3, 0}, {0, 0, 3}}, true},
+		{"beside", Triangle{{2.5, 0, -5}, {0, 2.5, -5}, {1.25, 1.25, 5}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := a.IntersectsTriangle(tt.tri); got != tt.want {
+			t.Errorf("%s: IntersectsTriangle = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -66,3 +66,39 @@ func SmoothStep(from, to, t float64) float64 {
 	t = Clamp((t-from)/(to-from), 0.0, 1.0)
 	return (t * t) * (3.0 - 2.0*t)
 }
+
+// InterpolateTransform blends two affine transforms, interpolating their
+// translations and scales linearly and their rotations spherically. Shears are
+// not preserved.
+func InterpolateTransform(from, to mgl64.Mat4, t float64) mgl64.Mat4 {
+	fromTranslation, fromRotation, fromScale := splitTransform(from)
+	toTranslation, toRotation, toScale := splitTransform(to)
+
+	translation := fromTranslation.Add(toTranslation.Sub(fromTranslation).Mul(t))
+	rotation := mgl64.QuatSlerp(fromRotation, toRotation, t)
+	scale := fromScale.Add(toScale.Sub(fromScale).Mul(t))
+
+	return mgl64.Translate3D(translation[0], translation[1], translation[2]).
+		Mul4(rotation.Mat4()).
+		Mul4(mgl64.Scale3D(scale[0], scale[1], scale[2]))
+}
+
+// splitTransform splits an affine transform into its translation,
+// rotation and scale. Mirroring transforms get a negative x scale.
+func splitTransform(m mgl64.Mat4) (mgl64.Vec3, mgl64.Quat, mgl64.Vec3) {
+	var scale mgl64.Vec3
+	var rotation mgl64.Mat3
+	for i := 0; i < 3; i++ {
+		axis := m.Col(i).Vec3()
+		scale[i] = axis.Len()
+		if scale[i] > 0 {
+			axis = axis.Mul(1 / scale[i])
+		}
+		rotation.SetCol(i, axis)
+	}
+	if rotation.Det() < 0 {
+		scale[0] = -scale[0]
+		rotation.SetCol(0, rotation.Col(0).Mul(-1))
+	}
+	return m.Col(3).Vec3(), mgl64.Mat4ToQuat(rotation.Mat4()), scale
+}
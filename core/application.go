@@ -13,6 +13,24 @@ type ClientApplication interface {
 	Done() bool
 }
 
+// ClientApplicationResizeHandler is implemented by client applications which
+// want to know when the window is resized. Width and height are in pixels.
+type ClientApplicationResizeHandler interface {
+	OnResize(width, height int)
+}
+
+// ClientApplicationFocusHandler is implemented by client applications which
+// want to know when the window gains or loses focus, eg: to pause.
+type ClientApplicationFocusHandler interface {
+	OnFocusChanged(focused bool)
+}
+
+// ClientApplicationFileDropHandler is implemented by client applications which
+// accept files dropped on the window.
+type ClientApplicationFileDropHandler interface {
+	OnFileDropped(path string)
+}
+
 // ClientApplicationCommand is a single runnable command
 type ClientApplicationCommand interface {
 	Run(ac ClientApplication)
@@ -24,11 +42,26 @@ type ClientApplicationInputComponent interface {
 	Run() []ClientApplicationCommand
 }
 
-// Application is the top-level runnable gosg App
+// Application is the top-level runnable gosg App. The zero value runs on the
+// default engine.
 type Application struct {
+	engine *Engine
 	client ClientApplication
 }
 
+// NewApplication returns an application which runs on the given engine.
+func NewApplication(e *Engine) *Application {
+	return &Application{engine: e}
+}
+
+// Engine returns the engine the application runs on.
+func (app *Application) Engine() *Engine {
+	if app.engine == nil {
+		app.engine = defaultEngine
+	}
+	return app.engine
+}
+
 // Start starts the application runloop by calling all systems/managers Start methods,
 // and calling the ClientApp constructor. On runloop termination, the Stop methods are
 // called in reverse order.
@@ -36,42 +69,68 @@ func (app *Application) Start(acConstructor func() ClientApplication) {
 	// we always handle input, window creating and drawing on main thread, keeps most OS happy
 	runtime.LockOSThread()
 
+	e := app.Engine()
+
 	// make a window
-	windowManager.MakeWindow()
+	if err := e.windowManager.MakeWindow(); err != nil {
+		glog.Errorf("Cannot create window: %v", err)
+		return
+	}
 
-	// start subsystems, all on main goroutine, main OS thread
-	audioSystem.Start()
-	physicsSystem.Start()
-	imguiSystem.Start()
+	// start subsystems, all on main goroutine, main OS thread. Headless
+	// applications may run without some of them registered.
+	if e.audioSystem != nil {
+		e.audioSystem.Start()
+	}
+	if e.physicsSystem != nil {
+		e.physicsSystem.Start()
+	}
+	if e.imguiSystem != nil {
+		e.imguiSystem.Start()
+	}
 
 	// create the client app, same here
 	app.client = acConstructor()
+	e.windowManager.AddEventHandler(app.windowEvent)
 
 	// start main loop, all systems go
 	app.runLoop()
 
 	// done, stop subsystems
-	imguiSystem.Stop()
-	physicsSystem.Stop()
-	audioSystem.Stop()
+	if e.imguiSystem != nil {
+		e.imguiSystem.Stop()
+	}
+	if e.physicsSystem != nil {
+		e.physicsSystem.Stop()
+	}
+	if e.audioSystem != nil {
+		e.audioSystem.Stop()
+	}
 
 	// stop managers
-	windowManager.closeWindow()
+	e.windowManager.closeWindow()
 }
 
 func (app *Application) runLoop() {
 	glog.Info("Starting runloop...")
 
+	timerManager := app.engine.timerManager
+
 	var dt = 1.0 / 60.0
-	var start = timerManager.Time()
+	var start = timerManager.clock()
 	var end float64
 
-	for !app.client.Done() && !windowManager.ShouldClose() {
+	// each frame starts a time step after the previous one, which replays
+	// depend on
+	timerManager.SetDt(dt)
+	timerManager.setFrameStartTime(start)
+
+	for !app.client.Done() && !app.engine.windowManager.ShouldClose() {
 		// run subsystem updates if not paused
 		app.update(dt)
 
 		// compute time delta
-		end = timerManager.Time()
+		end = timerManager.clock()
 		dt = end - start
 
 		// safeguard for extreme deltas (breakpoints, suspends)
@@ -79,8 +138,11 @@ func (app *Application) runLoop() {
 			dt = 1.0 / 60.0
 		}
 
-		timerManager.SetDt(dt)
-		timerManager.setFrameStartTime(end)
+		// replayed frames get their time from the recording
+		if !app.engine.inputManager.Replaying() {
+			timerManager.SetDt(dt)
+			timerManager.setFrameStartTime(end)
+		}
 
 		// rotate time
 		start = end
@@ -89,12 +151,34 @@ func (app *Application) runLoop() {
 	glog.Info("Runloop aborted...")
 }
 
+// windowEvent passes window events to the client's optional handlers.
+func (app *Application) windowEvent(ev WindowEvent) {
+	switch ev.Type {
+	case WindowEventResized:
+		if h, ok := app.client.(ClientApplicationResizeHandler); ok {
+			h.OnResize(ev.Width, ev.Height)
+		}
+	case WindowEventFocusGained, WindowEventFocusLost:
+		if h, ok := app.client.(ClientApplicationFocusHandler); ok {
+			h.OnFocusChanged(ev.Type == WindowEventFocusGained)
+		}
+	case WindowEventFileDropped:
+		if h, ok := app.client.(ClientApplicationFileDropHandler); ok {
+			h.OnFileDropped(ev.Path)
+		}
+	}
+}
+
 func (app *Application) update(dt float64) {
+	e := app.engine
+
 	// reset input
-	inputManager.reset()
+	e.inputManager.reset()
 
-	// poll for events
-	windowManager.PollEvents()
+	// poll for events, then record them or play back recorded ones, which
+	// also sets the frame's time step
+	e.windowManager.PollEvents()
+	dt = e.inputManager.frame(dt)
 
 	// update client app
 	acCommands := app.client.InputComponent().Run()
@@ -103,20 +187,27 @@ func (app *Application) update(dt float64) {
 	}
 
 	// play audio
-	audioSystem.Step()
+	if e.audioSystem != nil {
+		e.audioSystem.Step()
+	}
 
 	// call game object updates
-	sceneManager.update(dt)
+	e.sceneManager.advance(dt)
+
+	// there's nothing to draw into while minimized
+	if e.windowManager.Minimized() {
+		return
+	}
 
 	// run the culler
-	sceneManager.cull()
+	e.sceneManager.cull()
 
 	// begin GPU frame
-	renderer.BeginFrame()
+	e.renderer.BeginFrame()
 
 	// draw
-	sceneManager.draw()
+	e.sceneManager.draw()
 
 	// end GPU frame (submit + present)
-	renderer.EndFrame()
+	e.renderer.EndFrame()
 }
@@ -1,7 +1,5 @@
 package core
 
-import "log"
-
 // AudioSystem is the interface that wraps all audio processing logic.
 type AudioSystem interface {
 	// Start starts the audio system. This is where implementations should detect
@@ -16,20 +14,14 @@ type AudioSystem interface {
 	Stop()
 }
 
-var (
-	audioSystem AudioSystem
-)
-
 // SetAudioSystem should be called by implementations on their init function. It registers
-// the implementation as the active audio system.
-func SetAudioSystem(a AudioSystem) {
-	if audioSystem != nil {
-		log.Fatal("Can't replace previously registered audio system. Please make sure you're not importing twice.")
-	}
-	audioSystem = a
+// the implementation as the default engine's audio system and fails if one was already
+// registered.
+func SetAudioSystem(a AudioSystem) error {
+	return defaultEngine.SetAudioSystem(a)
 }
 
-// GetAudioSystem returns the registered window system
+// GetAudioSystem returns the default engine's audio system
 func GetAudioSystem() AudioSystem {
-	return audioSystem
+	return defaultEngine.audioSystem
 }
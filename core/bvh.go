@@ -0,0 +1,384 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	bvhNull = -1
+
+	// leaves are stored with their bounds grown by this fraction of their
+	// largest extent so small movements don't restructure the tree
+	bvhMargin = 0.1
+
+	// all six frustum planes
+	bvhAllPlanes = 0x3f
+)
+
+// bvhNode is an entry in the tree's node pool. Leaves reference a scenegraph
+// node, free entries are chained through parent.
+type bvhNode struct {
+	box    AABB
+	parent int
+	left   int
+	right  int
+	height int
+	node   *Node
+	seen   uint64
+}
+
+func (b *bvhNode) leaf() bool {
+	return b.left == bvhNull
+}
+
+// bvh is a dynamic bounding volume hierarchy of scenegraph nodes. Leaves are
+// inserted along the cheapest surface area path and the tree is kept balanced
+// with rotations as leaves come and go.
+type bvh struct {
+	nodes []bvhNode
+	root  int
+	free  int
+}
+
+func newBVH() *bvh {
+	return &bvh{root: bvhNull, free: bvhNull}
+}
+
+func (t *bvh) allocate() int {
+	if t.free == bvhNull {
+		t.nodes = append(t.nodes, bvhNode{})
+		t.free = len(t.nodes) - 1
+		t.nodes[t.free].parent = bvhNull
+	}
+	id := t.free
+	t.free = t.nodes[id].parent
+	t.nodes[id] = bvhNode{parent: bvhNull, left: bvhNull, right: bvhNull}
+	return id
+}
+
+func (t *bvh) release(id int) {
+	t.nodes[id] = bvhNode{parent: t.free, height: -1}
+	t.free = id
+}
+
+// insert adds a node to the tree using its world bounds. Nodes without
+// bounds are left out until they have some.
+func (t *bvh) insert(n *Node) {
+	if !validBounds(n.worldBounds) {
+		return
+	}
+	id := t.allocate()
+	t.nodes[id].box = fatBounds(n.worldBounds)
+	t.nodes[id].node = n
+	t.insertLeaf(id)
+	n.bvh, n.bvhLeaf = t, id
+}
+
+// remove removes a node from the tree.
+func (t *bvh) remove(n *Node) {
+	if n.bvh != t {
+		return
+	}
+	t.removeLeaf(n.bvhLeaf)
+	t.release(n.bvhLeaf)
+	n.bvh, n.bvhLeaf = nil, bvhNull
+}
+
+// move updates a node's leaf after its world bounds changed. The tree is only
+// touched when the bounds leave the leaf's fattened box.
+func (t *bvh) move(n *Node) {
+	if !validBounds(n.worldBounds) {
+		t.remove(n)
+		return
+	}
+	if n.bvh != t {
+		t.insert(n)
+		return
+	}
+	if t.nodes[n.bvhLeaf].box.ContainsBox(n.worldBounds) {
+		return
+	}
+	t.removeLeaf(n.bvhLeaf)
+	t.nodes[n.bvhLeaf].box = fatBounds(n.worldBounds)
+	t.insertLeaf(n.bvhLeaf)
+}
+
+func (t *bvh) insertLeaf(leaf int) {
+	if t.root == bvhNull {
+		t.root = leaf
+		t.nodes[leaf].parent = bvhNull
+		return
+	}
+
+	// find the cheapest sibling
+	box := t.nodes[leaf].box
+	index := t.root
+	for !t.nodes[index].leaf() {
+		n := &t.nodes[index]
+		area := n.box.area()
+		combinedArea := boundsUnion(n.box, box).area()
+
+		// cost of a new parent for this node and the leaf, and the cost of
+		// pushing the leaf further down the tree
+		cost := 2 * combinedArea
+		inheritance := 2 * (combinedArea - area)
+		leftCost := t.descendCost(n.left, box) + inheritance
+		rightCost := t.descendCost(n.right, box) + inheritance
+
+		if cost < leftCost && cost < rightCost {
+			break
+		}
+		if leftCost < rightCost {
+			index = n.left
+		} else {
+			index = n.right
+		}
+	}
+
+	// create a new parent for the sibling and the leaf
+	sibling := index
+	oldParent := t.nodes[sibling].parent
+	parent := t.allocate()
+	t.nodes[parent].parent = oldParent
+	t.nodes[parent].box = boundsUnion(box, t.nodes[sibling].box)
+	t.nodes[parent].height = t.nodes[sibling].height + 1
+	t.nodes[parent].left = sibling
+	t.nodes[parent].right = leaf
+	t.nodes[sibling].parent = parent
+	t.nodes[leaf].parent = parent
+	t.replaceChild(oldParent, sibling, parent)
+
+	t.refit(t.nodes[leaf].parent)
+}
+
+func (t *bvh) descendCost(child int, box AABB) float64 {
+	c := &t.nodes[child]
+	if c.leaf() {
+		return boundsUnion(box, c.box).area()
+	}
+	return boundsUnion(box, c.box).area() - c.box.area()
+}
+
+func (t *bvh) removeLeaf(leaf int) {
+	if leaf == t.root {
+		t.root = bvhNull
+		return
+	}
+
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	// the sibling takes the parent's place
+	t.nodes[sibling].parent = grandParent
+	t.replaceChild(grandParent, parent, sibling)
+	t.release(parent)
+	t.refit(grandParent)
+}
+
+// replaceChild points parent at child instead of old, or makes child the root.
+func (t *bvh) replaceChild(parent, old, child int) {
+	switch {
+	case parent == bvhNull:
+		t.root = child
+	case t.nodes[parent].left == old:
+		t.nodes[parent].left = child
+	default:
+		t.nodes[parent].right = child
+	}
+}
+
+// refit balances and recomputes boxes and heights from index up to the root.
+func (t *bvh) refit(index int) {
+	for index != bvhNull {
+		index = t.balance(index)
+		n := &t.nodes[index]
+		l, r := &t.nodes[n.left], &t.nodes[n.right]
+		n.height = 1 + max(l.height, r.height)
+		n.box = boundsUnion(l.box, r.box)
+		index = n.parent
+	}
+}
+
+// balance rotates the subtree at ia if its children's heights differ by more
+// than one and returns the subtree's new root.
+func (t *bvh) balance(ia int) int {
+	a := &t.nodes[ia]
+	if a.leaf() || a.height < 2 {
+		return ia
+	}
+
+	ib, ic := a.left, a.right
+	b, c := &t.nodes[ib], &t.nodes[ic]
+	balance := c.height - b.height
+
+	// rotate c up
+	if balance > 1 {
+		ifn, ig := c.left, c.right
+		f, g := &t.nodes[ifn], &t.nodes[ig]
+
+		c.left = ia
+		c.parent = a.parent
+		a.parent = ic
+		t.replaceChild(c.parent, ia, ic)
+
+		if f.height > g.height {
+			c.right = ifn
+			a.right = ig
+			g.parent = ia
+			a.box = boundsUnion(b.box, g.box)
+			c.box = boundsUnion(a.box, f.box)
+			a.height = 1 + max(b.height, g.height)
+			c.height = 1 + max(a.height, f.height)
+		} else {
+			c.right = ig
+			a.right = ifn
+			f.parent = ia
+			a.box = boundsUnion(b.box, f.box)
+			c.box = boundsUnion(a.box, g.box)
+			a.height = 1 + max(b.height, f.height)
+			c.height = 1 + max(a.height, g.height)
+		}
+		return ic
+	}
+
+	// rotate b up
+	if balance < -1 {
+		id, ie := b.left, b.right
+		d, e := &t.nodes[id], &t.nodes[ie]
+
+		b.left = ia
+		b.parent = a.parent
+		a.parent = ib
+		t.replaceChild(b.parent, ia, ib)
+
+		if d.height > e.height {
+			b.right = id
+			a.left = ie
+			e.parent = ia
+			a.box = boundsUnion(c.box, e.box)
+			b.box = boundsUnion(a.box, d.box)
+			a.height = 1 + max(c.height, e.height)
+			b.height = 1 + max(a.height, d.height)
+		} else {
+			b.right = ie
+			a.left = id
+			d.parent = ia
+			a.box = boundsUnion(c.box, d.box)
+			b.box = boundsUnion(a.box, e.box)
+			a.height = 1 + max(c.height, d.height)
+			b.height = 1 + max(a.height, e.height)
+		}
+		return ib
+	}
+
+	return ia
+}
+
+type bvhQueryEntry struct {
+	index int
+	mask  uint8
+}
+
+// query calls visit for every node whose world bounds intersect the frustum.
+// Planes a box is fully inside of are dropped from the mask its children are
+// tested with, and subtrees inside all planes are visited without tests.
+func (t *bvh) query(planes *[6]mgl64.Vec4, visit func(*Node)) {
+	if t.root == bvhNull {
+		return
+	}
+
+	stack := make([]bvhQueryEntry, 1, 64)
+	stack[0] = bvhQueryEntry{t.root, bvhAllPlanes}
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := &t.nodes[entry.index]
+		mask, inside := entry.mask, true
+		if mask != 0 {
+			mask, inside = n.box.clipFrustum(planes, mask)
+			if !inside {
+				continue
+			}
+		}
+
+		if n.leaf() {
+			// leaves are fattened, test the real bounds with what's left
+			if mask != 0 {
+				if _, inside = n.node.worldBounds.clipFrustum(planes, mask); !inside {
+					continue
+				}
+			}
+			visit(n.node)
+			continue
+		}
+
+		stack = append(stack, bvhQueryEntry{n.right, mask}, bvhQueryEntry{n.left, mask})
+	}
+}
+
+// clipFrustum tests the box against the planes in mask. It returns false if
+// the box is outside any of them, and otherwise the mask without the planes
+// the box is fully inside of.
+func (a *AABB) clipFrustum(planes *[6]mgl64.Vec4, mask uint8) (uint8, bool) {
+	for i := 0; i < 6; i++ {
+		bit := uint8(1) << i
+		if mask&bit == 0 {
+			continue
+		}
+
+		// farthest and nearest corners along the plane normal
+		p := &planes[i]
+		var far, near mgl64.Vec3
+		for j := 0; j < 3; j++ {
+			if p[j] > 0 {
+				far[j], near[j] = a.max[j], a.min[j]
+			} else {
+				far[j], near[j] = a.min[j], a.max[j]
+			}
+		}
+
+		if p.Vec3().Dot(far)+p[3] < 0 {
+			return mask, false
+		}
+		if p.Vec3().Dot(near)+p[3] >= 0 {
+			mask &^= bit
+		}
+	}
+	return mask, true
+}
+
+func (a AABB) area() float64 {
+	s := a.max.Sub(a.min)
+	return 2 * (s[0]*s[1] + s[1]*s[2] + s[2]*s[0])
+}
+
+func boundsUnion(a, b AABB) AABB {
+	for i := 0; i < 3; i++ {
+		a.min[i] = min(a.min[i], b.min[i])
+		a.max[i] = max(a.max[i], b.max[i])
+	}
+	return a
+}
+
+func fatBounds(b *AABB) AABB {
+	s := b.Size()
+	m := bvhMargin * max(s[0], s[1], s[2])
+	margin := mgl64.Vec3{m, m, m}
+	return AABB{min: b.min.Sub(margin), max: b.max.Add(margin)}
+}
+
+func validBounds(b *AABB) bool {
+	for i := 0; i < 3; i++ {
+		if !(b.min[i] <= b.max[i]) || math.IsInf(b.min[i], 0) || math.IsInf(b.max[i], 0) {
+			return false
+		}
+	}
+	return true
+}
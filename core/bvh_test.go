@@ -0,0 +1,275 @@
+package core
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// newCullScene builds a scene with count mesh nodes scattered around a camera
+// at the origin looking down -z, in groups of groupSize below the root.
+func newCullScene(tb testing.TB, count, groupSize int) (*Engine, *Scene, *Camera) {
+	tb.Helper()
+	e := newTestEngine(tb)
+	useRecordingRenderer(tb, e)
+	pipeline, err := e.ResourceManager().Pipeline("unlit")
+	if err != nil {
+		tb.Fatalf("Pipeline failed: %v", err)
+	}
+	mesh := newTriangleMesh(e)
+
+	r := rand.New(rand.NewSource(1))
+	root := NewNode("ROOT")
+	var group *Node
+	for i := 0; i < count; i++ {
+		if i%groupSize == 0 {
+			group = NewNode("Group")
+			root.AddChild(group)
+		}
+		n := NewNode("Mesh")
+		n.SetMesh(mesh)
+		n.SetPipeline(pipeline)
+		n.Translate(mgl64.Vec3{r.Float64()*2000 - 1000, r.Float64()*2000 - 1000, r.Float64()*2000 - 1000})
+		group.AddChild(n)
+	}
+
+	camera := NewCamera("Camera", PerspectiveProjection)
+	camera.SetViewport(mgl32.Vec4{0, 0, 64, 64})
+	camera.SetVerticalFieldOfView(60)
+	camera.SetClipDistance(mgl64.Vec2{0.1, 1000})
+	camera.SetScene(root)
+
+	scene := NewScene("cull")
+	scene.SetRoot(root)
+	scene.AddCamera(root, camera)
+	scene.update(e, 0)
+	camera.Reshape(mgl32.Vec2{64, 64})
+	return e, scene, camera
+}
+
+// cullNodes runs the root's cull component and returns the culled nodes.
+func cullNodes(scene *Scene, camera *Camera) []*Node {
+	for bk := range camera.pipelineBuckets {
+		camera.pipelineBuckets[bk] = camera.pipelineBuckets[bk][:0]
+	}
+	camera.visibleOpaqueNodes = camera.visibleOpaqueNodes[:0]
+	scene.root.CullComponent().Run(scene, camera, scene.root)
+
+	var nodes []*Node
+	for _, bucket := range camera.pipelineBuckets {
+		nodes = append(nodes, bucket...)
+	}
+	return nodes
+}
+
+func sameNodes(a, b []*Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[*Node]int)
+	for _, n := range a {
+		set[n]++
+	}
+	for _, n := range b {
+		if set[n] == 0 {
+			return false
+		}
+		set[n]--
+	}
+	return true
+}
+
+// checkBVH verifies the tree's links, heights and boxes and returns its height.
+func checkBVH(t *testing.T, tree *bvh) int {
+	t.Helper()
+	if tree.root == bvhNull {
+		return 0
+	}
+	var check func(i, parent int) int
+	check = func(i, parent int) int {
+		n := &tree.nodes[i]
+		if n.parent != parent {
+			t.Fatalf("node %d parent = %d, want %d", i, n.parent, parent)
+		}
+		if n.leaf() {
+			if !n.box.ContainsBox(n.node.worldBounds) {
+				t.Fatalf("leaf %d box %v does not contain %v", i, n.box, n.node.worldBounds)
+			}
+			return 0
+		}
+		l, r := &tree.nodes[n.left], &tree.nodes[n.right]
+		if !n.box.ContainsBox(&l.box) || !n.box.ContainsBox(&r.box) {
+			t.Fatalf("node %d box does not contain its children", i)
+		}
+		if d := l.height - r.height; d > 1 || d < -1 {
+			t.Fatalf("node %d is unbalanced: %d vs %d", i, l.height, r.height)
+		}
+		h := 1 + max(check(n.left, i), check(n.right, i))
+		if h != n.height {
+			t.Fatalf("node %d height = %d, want %d", i, n.height, h)
+		}
+		return h
+	}
+	return check(tree.root, bvhNull)
+}
+
+func TestBVHCullerMatchesDefault(t *testing.T) {
+	e, scene, camera := newCullScene(t, 5000, 50)
+	root := scene.Root()
+	groups := append([]*Node(nil), root.Children()...)
+
+	// inactive and masked nodes, and a subtree with its own culler
+	groups[0].SetActive(false)
+	groups[1].Children()[0].SetActive(false)
+	groups[2].Children()[0].SetLayers(Layer(4))
+	groups[3].SetCullComponent(new(AlwaysPassCuller))
+
+	bc := NewBVHCuller()
+	compare := func(step string) {
+		t.Helper()
+		root.SetCullComponent(new(DefaultCuller))
+		want := cullNodes(scene, camera)
+		root.SetCullComponent(bc)
+		got := cullNodes(scene, camera)
+		if len(want) == 0 || !sameNodes(got, want) {
+			t.Errorf("%s: bvh culled %d nodes, default culled %d", step, len(got), len(want))
+		}
+		checkBVH(t, bc.tree)
+	}
+	compare("initial")
+
+	// moved, removed and added nodes
+	r := rand.New(rand.NewSource(2))
+	for _, g := range groups[4:20] {
+		for _, n := range g.Children() {
+			n.Translate(mgl64.Vec3{r.Float64()*100 - 50, r.Float64()*100 - 50, r.Float64()*100 - 50})
+		}
+	}
+	root.RemoveChild(groups[20])
+	moved := groups[21].Children()[0]
+	groups[21].RemoveChild(moved)
+	groups[22].AddChild(moved)
+	groups[23].Translate(mgl64.Vec3{0, 0, -300})
+	scene.update(e, 0)
+	compare("updated")
+
+	for i := range bc.tree.nodes {
+		if n := bc.tree.nodes[i].node; n != nil && n.parent == groups[20] {
+			t.Fatal("removed nodes are still in the hierarchy")
+		}
+	}
+}
+
+func TestBVHBalance(t *testing.T) {
+	root := NewNode("ROOT")
+	tree := newBVH()
+	var nodes []*Node
+	for i := 0; i < 1024; i++ {
+		n := NewNode("Leaf")
+		n.worldBounds = &AABB{min: mgl64.Vec3{float64(i), 0, 0}, max: mgl64.Vec3{float64(i) + 1, 1, 1}}
+		root.AddChild(n)
+		tree.insert(n)
+		nodes = append(nodes, n)
+	}
+	if h := checkBVH(t, tree); h > 20 {
+		t.Errorf("height of 1024 ordered leaves = %d", h)
+	}
+
+	for _, n := range nodes[:512] {
+		tree.remove(n)
+	}
+	for _, n := range nodes[512:] {
+		n.worldBounds.min[1] += 5
+		n.worldBounds.max[1] += 5
+		tree.move(n)
+	}
+	checkBVH(t, tree)
+
+	var found []*Node
+	frustum := [6]mgl64.Vec4{{1, 0, 0, -600}, {-1, 0, 0, 700}, {0, 1, 0, 0}, {0, -1, 0, 100}, {0, 0, 1, 100}, {0, 0, -1, 100}}
+	tree.query(&frustum, func(n *Node) { found = append(found, n) })
+	sort.Slice(found, func(i, j int) bool { return found[i].worldBounds.min[0] < found[j].worldBounds.min[0] })
+	if len(found) != 102 || found[0] != nodes[599] || found[101] != nodes[700] {
+		t.Errorf("query found %d leaves, want the 102 touching x in [600, 700]", len(found))
+	}
+}
+
+func TestShadowCastersOutsideView(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		culler func() Culler
+	}{
+		{"default", func() Culler { return new(DefaultCuller) }},
+		{"bvh", func() Culler { return NewBVHCuller() }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			backend := useRecordingRenderer(t, e)
+			scene, camera := newRecordingScene(t, e, "unlit", "glass")
+
+			// behind the camera but within the first cascade
+			pipeline, _ := e.ResourceManager().Pipeline("unlit")
+			behind := NewNode("Behind")
+			behind.SetMesh(newTriangleMesh(e))
+			behind.SetPipeline(pipeline)
+			behind.Translate(mgl64.Vec3{0, 0, 1})
+			scene.Root().AddChild(behind)
+			scene.Root().SetCullComponent(tc.culler())
+			scene.update(e, 0)
+			scene.cull(e)
+
+			if got := len(camera.pipelineBuckets[pipeline]); got != 1 {
+				t.Fatalf("visible unlit nodes = %d, want 1", got)
+			}
+
+			light := &Light{Shadower: newShadowMap(e, 256, 3)}
+			light.Block.Position = mgl32.Vec4{1, 1, 1, 0}
+			e.Renderer().BeginFrame()
+			light.Shadower.Render(light, camera)
+			e.Renderer().EndFrame()
+
+			draws := backend.LastFrame().Passes[0].Draws
+			if len(draws) != 1 || draws[0].Pipeline != "shadow" || draws[0].InstanceCount != 2 {
+				t.Errorf("first cascade draws = %+v, want both opaque nodes", draws)
+			}
+		})
+	}
+}
+
+func benchmarkCull(b *testing.B, culler Culler, moving int) {
+	e, scene, camera := newCullScene(b, 100000, 100)
+	scene.Root().SetCullComponent(culler)
+	cullNodes(scene, camera)
+	nodes := scene.Root().Children()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, g := range nodes[:moving] {
+			g.Translate(mgl64.Vec3{1, 0, 0})
+		}
+		if moving > 0 {
+			scene.update(e, 0)
+		}
+		cullNodes(scene, camera)
+	}
+}
+
+func BenchmarkDefaultCuller100k(b *testing.B) {
+	benchmarkCull(b, new(DefaultCuller), 0)
+}
+
+func BenchmarkBVHCuller100k(b *testing.B) {
+	benchmarkCull(b, NewBVHCuller(), 0)
+}
+
+// one percent of the nodes move every frame
+func BenchmarkDefaultCuller100kMoving(b *testing.B) {
+	benchmarkCull(b, new(DefaultCuller), 10)
+}
+
+func BenchmarkBVHCuller100kMoving(b *testing.B) {
+	benchmarkCull(b, NewBVHCuller(), 10)
+}
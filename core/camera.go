@@ -3,7 +3,6 @@ package core
 import (
 	"unsafe"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/go-gl/mathgl/mgl64"
 )
@@ -41,8 +40,11 @@ type CameraRenderFn func(*Camera, map[*Pipeline][]*Node)
 // the scenegraph, as well as clipping distances (near, far planes) and render
 // ordering, target and techniques.
 type Camera struct {
+	engine             *Engine
 	name               string
 	autoReshape        bool
+	fitWindow          bool
+	pointProjection    bool
 	autoFrustum        bool
 	projectionType     ProjectionType
 	clearColor         mgl32.Vec4
@@ -50,6 +52,7 @@ type Camera struct {
 	clearMode          ClearMode
 	node               *Node
 	scene              *Node
+	cullingMask        LayerMask
 	viewMatrix         mgl64.Mat4
 	projectionMatrix   mgl64.Mat4
 	viewport           mgl32.Vec4
@@ -58,11 +61,16 @@ type Camera struct {
 	dirty              bool
 	renderOrder        uint8
 	framebuffer        *Framebuffer
+	window             *Window
 	frustum            [6]mgl64.Vec4
 	constants          cameraUBO
 	renderTechnique    CameraRenderFn
 	pipelineBuckets    map[*Pipeline][]*Node
 	visibleOpaqueNodes []*Node
+	lights             []*Light
+	occlusion          *occlusionBuffer
+	occludedNodes      []*Node
+	lodFades           map[*Node]float32
 }
 
 // CamerasByRenderOrder is used to sort cameras by the render order field.
@@ -84,16 +92,23 @@ func (a CamerasByRenderOrder) Less(i, j int) bool {
 }
 
 // NewCamera creates and returns a Camera with the given name and projection type.
+// The camera is rebound to the engine of the scene it renders in.
 func NewCamera(name string, projType ProjectionType) *Camera {
+	return newCamera(defaultEngine, name, projType)
+}
+
+func newCamera(e *Engine, name string, projType ProjectionType) *Camera {
 	cam := Camera{}
+	cam.engine = e
 	cam.name = name
 	cam.clearColor = mgl32.Vec4{0.0, 0.0, 0.0, 0.0}
 	cam.clearDepth = 1.0
 	cam.clearMode = ClearColor | ClearDepth
+	cam.cullingMask = LayerAll
 	cam.SetProjectionType(projType)
 	cam.node = NewNode(name)
 	cam.node.bounds = nil
-	cam.constants.buffer = NewUniformBuffer()
+	cam.constants.buffer = &UniformBuffer{engine: e}
 	cam.renderTechnique = DefaultRenderTechnique
 	cam.pipelineBuckets = make(map[*Pipeline][]*Node)
 	cam.visibleOpaqueNodes = make([]*Node, 0)
@@ -101,6 +116,12 @@ func NewCamera(name string, projType ProjectionType) *Camera {
 	return &cam
 }
 
+// setEngine binds the camera and its constants buffer to an engine.
+func (c *Camera) setEngine(e *Engine) {
+	c.engine = e
+	c.constants.buffer.setEngine(e)
+}
+
 // Node returns the camera's scenegraph Node
 func (c *Camera) Node() *Node {
 	return c.node
@@ -130,6 +151,52 @@ func (c *Camera) SetScene(s *Node) {
 	c.scene = s
 }
 
+// CullingMask returns the layers the camera renders.
+func (c *Camera) CullingMask() LayerMask {
+	return c.cullingMask
+}
+
+// SetCullingMask sets the layers the camera renders. Nodes and lights on none
+// of these layers are skipped by the cull components and light extraction.
+func (c *Camera) SetCullingMask(mask LayerMask) {
+	c.cullingMask = mask
+}
+
+// Sees returns whether a node is on any of the layers the camera renders.
+func (c *Camera) Sees(n *Node) bool {
+	return c.cullingMask&n.layers != 0
+}
+
+// visibleLights returns the lights on any of the layers the camera renders.
+func (c *Camera) visibleLights(lights []*Light) []*Light {
+	c.lights = c.lights[:0]
+	for _, l := range lights {
+		if l.layers == 0 || c.cullingMask&l.layers != 0 {
+			c.lights = append(c.lights, l)
+		}
+	}
+	return c.lights
+}
+
+// OcclusionCulling returns whether the camera culls nodes hidden behind occluders.
+func (c *Camera) OcclusionCulling() bool {
+	return c.occlusion != nil
+}
+
+// SetOcclusionCulling sets whether the camera culls nodes hidden behind
+// occluders. After frustum culling the visible occluders are rasterised into a
+// low resolution depth buffer on the CPU and nodes whose bounds are behind it
+// are removed from the render buckets.
+func (c *Camera) SetOcclusionCulling(enabled bool) {
+	switch {
+	case enabled && c.occlusion == nil:
+		c.occlusion = &occlusionBuffer{}
+	case !enabled:
+		c.occlusion = nil
+		c.occludedNodes = nil
+	}
+}
+
 // Name returns the camera's name.
 func (c *Camera) Name() string {
 	return c.name
@@ -179,17 +246,51 @@ func (c *Camera) AddNodeToRenderBuckets(n *Node) {
 	}
 }
 
+// setLODFade sets the LOD fade the meshes of a level are drawn with by the
+// camera until its next cull.
+func (c *Camera) setLODFade(level *Node, fade float32) {
+	if c.lodFades == nil {
+		c.lodFades = make(map[*Node]float32)
+	}
+	level.Walk(func(n *Node) VisitAction {
+		if n.mesh != nil {
+			c.lodFades[n] = fade
+		}
+		return VisitContinue
+	}, nil)
+}
+
+// instanceData returns the material instance data the camera draws a node
+// with, with the node's LOD fade if it is cross-fading.
+func (c *Camera) instanceData(n *Node) [4]mgl32.Vec4 {
+	data := n.material.instanceData
+	if fade, ok := c.lodFades[n]; ok {
+		data[LODFadeSlot] = mgl32.Vec4{fade, 0, 0, 0}
+	}
+	return data
+}
+
 // Reshape reshapes the camera's viewport and transforms according to a given window size.
 func (c *Camera) Reshape(windowSize mgl32.Vec2) {
-	if c.autoReshape {
+	if c.autoReshape || c.fitWindow {
 		if windowSize[0] != c.viewport[2] || windowSize[1] != c.viewport[3] {
 			c.SetViewport(mgl32.Vec4{0.0, 0.0, windowSize[0], windowSize[1]})
 		}
 	}
 
-	c.viewMatrix = c.node.InverseWorldTransform()
+	if c.node.interpolated {
+		c.viewMatrix = c.node.renderTransform.Inv()
+	} else {
+		c.viewMatrix = c.node.InverseWorldTransform()
+	}
 
-	if c.dirty {
+	if c.pointProjection {
+		// the viewport is in pixels but UIs are laid out in points, and the
+		// pixel density may change without the viewport doing so
+		density := float64(c.pixelDensity())
+		c.projectionMatrix = OrthoWebGPU(0, float64(c.viewport[2])/density, float64(c.viewport[3])/density, 0, c.clipDistance[0], c.clipDistance[1])
+		c.dirty = false
+	} else if c.dirty {
 		if c.projectionType == PerspectiveProjection {
 			c.projectionMatrix = PerspectiveWebGPU(mgl64.DegToRad(c.vertFOV), float64(c.viewport[2]/c.viewport[3]), c.clipDistance[0], c.clipDistance[1])
 		}
@@ -217,6 +318,26 @@ func (c *Camera) VisibleOpaqueNodes() []*Node {
 	return c.visibleOpaqueNodes
 }
 
+// ScreenPointToRay returns the world space ray through a point in window
+// coordinates, in points with the origin at the top left corner as mouse
+// positions are. The ray starts on the camera's near plane.
+func (c *Camera) ScreenPointToRay(x, y float64) (origin, direction mgl64.Vec3) {
+	// the viewport is in pixels
+	density := float64(c.pixelDensity())
+	x, y = x*density, y*density
+	vp := c.viewport
+	ndc := mgl64.Vec3{
+		2*(x-float64(vp[0]))/float64(vp[2]) - 1,
+		1 - 2*(y-float64(vp[1]))/float64(vp[3]),
+		0,
+	}
+	inverseViewProjection := c.projectionMatrix.Mul4(c.viewMatrix).Inv()
+	origin = mgl64.TransformCoordinate(ndc, inverseViewProjection)
+	ndc[2] = 1
+	far := mgl64.TransformCoordinate(ndc, inverseViewProjection)
+	return origin, far.Sub(origin).Normalize()
+}
+
 // Aspect returns the camera's viewport aspect ratio.
 func (c *Camera) Aspect() float64 {
 	return float64(c.viewport[2] / c.viewport[3])
@@ -307,6 +428,38 @@ func (c *Camera) SetFramebuffer(rt *Framebuffer) {
 	c.framebuffer = rt
 }
 
+// Window returns the secondary window the camera draws into, nil for the main
+// window.
+func (c *Camera) Window() *Window {
+	return c.window
+}
+
+// SetWindow makes the camera draw into a secondary window's surface when it
+// has no framebuffer, and reshape to that window's size. Pass nil for the main
+// window. Cameras of a closed window are skipped.
+func (c *Camera) SetWindow(w *Window) {
+	if w != nil && w.isMain() {
+		w = nil
+	}
+	c.window = w
+}
+
+// pixelDensity returns the pixel density of the window the camera draws into.
+func (c *Camera) pixelDensity() float32 {
+	if c.window != nil {
+		return c.window.PixelDensity()
+	}
+	return c.engine.windowManager.PixelDensity()
+}
+
+// windowSize returns the size of the window the camera draws into.
+func (c *Camera) windowSize() mgl32.Vec2 {
+	if c.window != nil {
+		return c.window.WindowSize()
+	}
+	return c.engine.windowManager.WindowSize()
+}
+
 // RenderOrder order returns the camera's render order.
 func (c *Camera) RenderOrder() uint8 {
 	return c.renderOrder
@@ -376,16 +529,20 @@ func (sb *cameraUBO) UniformBuffer() *UniformBuffer {
 func (c *Camera) MakeRenderPassDescriptor(clearColor, clearDepth bool) RenderPassDescriptor {
 	desc := RenderPassDescriptor{
 		Viewport: c.viewport,
+		Label:    c.name,
 	}
 
 	// Color attachments
 	if c.framebuffer == nil {
 		// No framebuffer → single swap chain attachment
 		ca := RenderPassColorAttachment{
-			LoadOp: gpu.LoadOpLoad,
+			LoadOp: LoadOpLoad,
+		}
+		if c.window != nil {
+			ca.Surface = c.window.surface
 		}
 		if clearColor && c.clearMode&ClearColor != 0 {
-			ca.LoadOp = gpu.LoadOpClear
+			ca.LoadOp = LoadOpClear
 			ca.ClearColor = c.clearColor
 		}
 		desc.ColorAttachments = []RenderPassColorAttachment{ca}
@@ -398,11 +555,11 @@ func (c *Camera) MakeRenderPassDescriptor(clearColor, clearDepth bool) RenderPas
 			}
 			ca := RenderPassColorAttachment{
 				View:   tex.view,
-				Format: sizedFormatToGPU(tex.descriptor.SizedFormat),
-				LoadOp: gpu.LoadOpLoad,
+				Format: tex.format,
+				LoadOp: LoadOpLoad,
 			}
 			if clearColor && c.clearMode&ClearColor != 0 {
-				ca.LoadOp = gpu.LoadOpClear
+				ca.LoadOp = LoadOpClear
 				ca.ClearColor = c.clearColor
 			}
 			desc.ColorAttachments = append(desc.ColorAttachments, ca)
@@ -413,12 +570,12 @@ func (c *Camera) MakeRenderPassDescriptor(clearColor, clearDepth bool) RenderPas
 	if c.framebuffer != nil && c.framebuffer.depthAttachment != nil {
 		da := RenderPassDepthAttachment{
 			View:       c.framebuffer.depthAttachment.view,
-			Format:     sizedFormatToGPU(c.framebuffer.depthAttachment.descriptor.SizedFormat),
-			LoadOp:     gpu.LoadOpLoad,
+			Format:     c.framebuffer.depthAttachment.format,
+			LoadOp:     LoadOpLoad,
 			ClearDepth: float32(c.clearDepth),
 		}
 		if clearDepth && c.clearMode&ClearDepth != 0 {
-			da.LoadOp = gpu.LoadOpClear
+			da.LoadOp = LoadOpClear
 		}
 		desc.DepthAttachment = &da
 	}
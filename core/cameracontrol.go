@@ -29,6 +29,7 @@ func (rc MouseCameraRotateCommand) Run(node *Node) {
 
 // MouseCameraInputComponent is a utility inputcomponent for simple camera movement.
 type MouseCameraInputComponent struct {
+	engine           *Engine
 	velocityExponent float64
 	velocity         float64
 }
@@ -36,57 +37,54 @@ type MouseCameraInputComponent struct {
 // NewMouseCameraInputComponent returns a default inputcomponent for use with camera nodes which
 // uses the mouse wheel to set the camera's velocity on 10x increments (world units/second).
 func NewMouseCameraInputComponent() *MouseCameraInputComponent {
+	return DefaultEngine().NewMouseCameraInputComponent()
+}
+
+// NewMouseCameraInputComponent returns a mouse camera inputcomponent reading the engine's input.
+func (e *Engine) NewMouseCameraInputComponent() *MouseCameraInputComponent {
 	mic := new(MouseCameraInputComponent)
+	mic.engine = e
 	return mic
 }
 
-// Run implements the InputComponent interface.
+// Run implements the InputComponent interface. It moves the node with the
+// move_x, move_y and move_z axes, turns it with look_x and look_y and sets its
+// speed with move_speed or the move_faster and move_slower actions.
 func (ic *MouseCameraInputComponent) Run(node *Node) []NodeCommand {
-	state := *inputManager.State()
+	im := ic.engine.inputManager
+	dt := ic.engine.timerManager.Dt()
 
 	var commands []NodeCommand
 
 	// keyboard input
-	movementMap := make(map[Key]mgl64.Vec3)
-	movementMap[KeyW] = mgl64.Vec3{0.0, 0.0, -1.0}
-	movementMap[KeyS] = mgl64.Vec3{0.0, 0.0, +1.0}
-	movementMap[KeyA] = mgl64.Vec3{-1.0, 0.0, 0.0}
-	movementMap[KeyD] = mgl64.Vec3{+1.0, 0.0, 0.0}
-	movementMap[KeyQ] = mgl64.Vec3{0.0, +1.0, 0.0}
-	movementMap[KeyZ] = mgl64.Vec3{0.0, -1.0, 0.0}
-
-	var direction = mgl64.Vec3{0.0, 0.0, 0.0}
-	for k, v := range movementMap {
-		if state.Keys.Active[k] {
-			direction = direction.Add(v)
-		}
-	}
-
-	if state.Keys.Valid {
-		if direction.Len() > 0.0 {
-			dtfactor := (ic.velocity) * timerManager.Dt()
-			commands = append(commands, MouseCameraMoveCommand{direction.Mul(dtfactor)})
-		}
+	direction := mgl64.Vec3{im.Axis("move_x"), im.Axis("move_y"), im.Axis("move_z")}
+	if direction.Len() > 0.0 {
+		dtfactor := (ic.velocity) * dt
+		commands = append(commands, MouseCameraMoveCommand{direction.Mul(dtfactor)})
 	}
 
 	// mouse movement
-	if state.Mouse.Valid {
-		if state.Mouse.Position.Valid {
-			pitch, yaw := -state.Mouse.Position.DistY, -state.Mouse.Position.DistX
-			commands = append(commands, MouseCameraRotateCommand{5.0 * timerManager.Dt() * pitch, mgl64.Vec3{1.0, 0.0, 0.0}})
-			commands = append(commands, MouseCameraRotateCommand{5.0 * timerManager.Dt() * yaw, mgl64.Vec3{0.0, 1.0, 0.0}})
-		}
-
-		// speed from scroll: doesn't generate commands, affects internal state only
-		if state.Mouse.Scroll.Valid {
-			ic.velocityExponent += -state.Mouse.Scroll.Y
+	if pitch, yaw := -im.Axis("look_y"), -im.Axis("look_x"); pitch != 0 || yaw != 0 {
+		commands = append(commands, MouseCameraRotateCommand{5.0 * dt * pitch, mgl64.Vec3{1.0, 0.0, 0.0}})
+		commands = append(commands, MouseCameraRotateCommand{5.0 * dt * yaw, mgl64.Vec3{0.0, 1.0, 0.0}})
+	}
 
-			if ic.velocityExponent >= 0 {
-				ic.velocity = math.Pow(2.0, ic.velocityExponent)
-			} else {
-				ic.velocity = 0.0
-				ic.velocityExponent = -1.0
-			}
+	// speed from scroll or buttons: doesn't generate commands, affects internal state only
+	change := -im.Axis("move_speed")
+	if im.ActionPressed("move_faster") {
+		change++
+	}
+	if im.ActionPressed("move_slower") {
+		change--
+	}
+	if change != 0 {
+		ic.velocityExponent += change
+
+		if ic.velocityExponent >= 0 {
+			ic.velocity = math.Pow(2.0, ic.velocityExponent)
+		} else {
+			ic.velocity = 0.0
+			ic.velocityExponent = -1.0
 		}
 	}
 
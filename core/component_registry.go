@@ -1,19 +1,175 @@
 package core
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComponentParams holds the parameters a component is configured with in a
+// scene file, as decoded from YAML.
+type ComponentParams map[string]interface{}
+
+// Decode decodes the parameters into v, usually a pointer to a struct with
+// yaml tags. Fields without a parameter are left alone so defaults can be set
+// before decoding, parameters without a field are an error.
+func (p ComponentParams) Decode(v interface{}) error {
+	if len(p) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(map[string]interface{}(p))
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(v)
+
+	// the lines are those of the re-encoded parameters, the scene loader
+	// positions errors at the component instead
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		for i, msg := range te.Errors {
+			if _, rest, ok := strings.Cut(msg, ": "); ok && strings.HasPrefix(msg, "line ") {
+				te.Errors[i] = rest
+			}
+		}
+	}
+	return err
+}
+
+// ParameterizedComponent is implemented by components which are configured
+// with parameters. Params is used to write the component back to a scene file.
+type ParameterizedComponent interface {
+	Params() ComponentParams
+}
+
+type componentRegistry[T any] struct {
+	kind      string
+	factories map[string]func(ComponentParams) (T, error)
+	names     map[reflect.Type]string // component types to the names they're registered under
+}
+
+func newComponentRegistry[T any](kind string) *componentRegistry[T] {
+	return &componentRegistry[T]{
+		kind:      kind,
+		factories: make(map[string]func(ComponentParams) (T, error)),
+		names:     make(map[reflect.Type]string),
+	}
+}
+
+// registerFactory adds a factory making components of type C. If C is a
+// concrete type it is recorded so def can find its name, factories declared
+// to return an interface have the type of their components recorded when
+// they're first looked up. Factories aren't called until then.
+func registerFactory[C, T any](r *componentRegistry[T], name string, factory func(ComponentParams) (C, error)) {
+	r.factories[name] = func(p ComponentParams) (T, error) {
+		c, err := factory(p)
+		t, _ := any(c).(T) // a nil interface C isn't a T
+		return t, err
+	}
+	for t, n := range r.names {
+		if n == name {
+			delete(r.names, t)
+		}
+	}
+	if t := reflect.TypeFor[C](); t.Kind() != reflect.Interface {
+		r.recordName(name, t)
+	}
+}
+
+// recordName maps a component type to name. A type made by several
+// factories keeps the first of their names.
+func (r *componentRegistry[T]) recordName(name string, t reflect.Type) {
+	if prev, ok := r.names[t]; !ok || name < prev {
+		r.names[t] = name
+	}
+}
+
+func (r *componentRegistry[T]) lookup(name string, params ComponentParams) (T, error) {
+	f, ok := r.factories[name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("unknown %s %q", r.kind, name)
+	}
+	c, err := f(params)
+	if err != nil {
+		return c, fmt.Errorf("cannot configure %s %q: %w", r.kind, name, err)
+	}
+	if t := reflect.TypeOf(c); t != nil {
+		r.recordName(name, t)
+	}
+	return c, nil
+}
+
+// def returns the scene definition for a component, with an empty type if the
+// component's type isn't registered.
+func (r *componentRegistry[T]) def(c T) ComponentDef {
+	t := reflect.TypeOf(c)
+	if t == nil {
+		return ComponentDef{}
+	}
+	name, ok := r.names[t]
+	if !ok {
+		return ComponentDef{}
+	}
+	def := ComponentDef{Type: name}
+	if pc, ok := interface{}(c).(ParameterizedComponent); ok {
+		def.Params = pc.Params()
+	}
+	return def
+}
+
 var (
-	inputComponentFactories  = make(map[string]func() InputComponent)
-	cullComponentFactories   = make(map[string]func() Culler)
-	renderTechniqueRegistry  = make(map[string]CameraRenderFn)
+	inputComponents   = newComponentRegistry[InputComponent]("input component")
+	cullComponents    = newComponentRegistry[Culler]("cull component")
+	updateComponents  = newComponentRegistry[Updater]("update component")
+	physicsComponents = newComponentRegistry[PhysicsComponent]("physics component")
+	lightExtractors   = newComponentRegistry[LightExtractor]("light extractor")
+
+	renderTechniqueRegistry = make(map[string]CameraRenderFn)
 )
 
 // RegisterInputComponent registers a named input component factory.
 func RegisterInputComponent(name string, factory func() InputComponent) {
-	inputComponentFactories[name] = factory
+	RegisterInputComponentWithParams(name, func(ComponentParams) (InputComponent, error) { return factory(), nil })
+}
+
+// RegisterInputComponentWithParams registers a named input component factory
+// which is configured with the parameters given in scene files.
+func RegisterInputComponentWithParams[C InputComponent](name string, factory func(ComponentParams) (C, error)) {
+	registerFactory(inputComponents, name, factory)
 }
 
 // RegisterCullComponent registers a named cull component factory.
 func RegisterCullComponent(name string, factory func() Culler) {
-	cullComponentFactories[name] = factory
+	RegisterCullComponentWithParams(name, func(ComponentParams) (Culler, error) { return factory(), nil })
+}
+
+// RegisterCullComponentWithParams registers a named cull component factory
+// which is configured with the parameters given in scene files.
+func RegisterCullComponentWithParams[C Culler](name string, factory func(ComponentParams) (C, error)) {
+	registerFactory(cullComponents, name, factory)
+}
+
+// RegisterUpdater registers a named update component factory.
+func RegisterUpdater[C Updater](name string, factory func(ComponentParams) (C, error)) {
+	registerFactory(updateComponents, name, factory)
+}
+
+// RegisterPhysicsComponent registers a named physics component factory.
+func RegisterPhysicsComponent[C PhysicsComponent](name string, factory func(ComponentParams) (C, error)) {
+	registerFactory(physicsComponents, name, factory)
+}
+
+// RegisterLightExtractor registers a named light extractor factory.
+func RegisterLightExtractor[C LightExtractor](name string, factory func(ComponentParams) (C, error)) {
+	registerFactory(lightExtractors, name, factory)
 }
 
 // RegisterRenderTechnique registers a named render technique.
@@ -23,18 +179,44 @@ func RegisterRenderTechnique(name string, fn CameraRenderFn) {
 
 // LookupInputComponent returns a new instance of the named input component, or nil.
 func LookupInputComponent(name string) InputComponent {
-	if f, ok := inputComponentFactories[name]; ok {
-		return f()
-	}
-	return nil
+	ic, _ := LookupInputComponentWithParams(name, nil)
+	return ic
+}
+
+// LookupInputComponentWithParams returns a new instance of the named input
+// component configured with params.
+func LookupInputComponentWithParams(name string, params ComponentParams) (InputComponent, error) {
+	return inputComponents.lookup(name, params)
 }
 
 // LookupCullComponent returns a new instance of the named cull component, or nil.
 func LookupCullComponent(name string) Culler {
-	if f, ok := cullComponentFactories[name]; ok {
-		return f()
-	}
-	return nil
+	c, _ := LookupCullComponentWithParams(name, nil)
+	return c
+}
+
+// LookupCullComponentWithParams returns a new instance of the named cull
+// component configured with params.
+func LookupCullComponentWithParams(name string, params ComponentParams) (Culler, error) {
+	return cullComponents.lookup(name, params)
+}
+
+// LookupUpdater returns a new instance of the named update component
+// configured with params.
+func LookupUpdater(name string, params ComponentParams) (Updater, error) {
+	return updateComponents.lookup(name, params)
+}
+
+// LookupPhysicsComponent returns a new instance of the named physics component
+// configured with params.
+func LookupPhysicsComponent(name string, params ComponentParams) (PhysicsComponent, error) {
+	return physicsComponents.lookup(name, params)
+}
+
+// LookupLightExtractor returns a new instance of the named light extractor
+// configured with params.
+func LookupLightExtractor(name string, params ComponentParams) (LightExtractor, error) {
+	return lightExtractors.lookup(name, params)
 }
 
 // LookupRenderTechnique returns the named render technique, or nil.
@@ -45,14 +227,44 @@ func LookupRenderTechnique(name string) CameraRenderFn {
 	return nil
 }
 
+// renderTechniqueName returns the name a render technique is registered under, or "".
+func renderTechniqueName(fn CameraRenderFn) string {
+	for _, name := range sortedNames(renderTechniqueRegistry) {
+		if reflect.ValueOf(renderTechniqueRegistry[name]).Pointer() == reflect.ValueOf(fn).Pointer() {
+			return name
+		}
+	}
+	return ""
+}
+
+// sortedNames returns a registry's names in order so reverse lookups are stable.
+func sortedNames[T any](registry map[string]T) []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	RegisterRenderTechnique("default", DefaultRenderTechnique)
 	RegisterRenderTechnique("postprocess", PostProcessRenderTechnique)
 	RegisterRenderTechnique("debug", DebugRenderTechnique)
 	RegisterRenderTechnique("aabb", AABBRenderTechnique)
 
-	RegisterCullComponent("default", func() Culler { return new(DefaultCuller) })
-	RegisterCullComponent("alwaysPass", func() Culler { return new(AlwaysPassCuller) })
+	RegisterCullComponentWithParams("default", func(ComponentParams) (*DefaultCuller, error) { return new(DefaultCuller), nil })
+	RegisterCullComponentWithParams("alwaysPass", func(ComponentParams) (*AlwaysPassCuller, error) { return new(AlwaysPassCuller), nil })
+	RegisterCullComponentWithParams("bvh", func(ComponentParams) (*BVHCuller, error) { return NewBVHCuller(), nil })
+
+	RegisterInputComponentWithParams("mouseCameraInput", func(ComponentParams) (*MouseCameraInputComponent, error) { return NewMouseCameraInputComponent(), nil })
+
+	RegisterUpdater("rotator", func(p ComponentParams) (*Rotator, error) {
+		r := &Rotator{Speed: 90, Axis: [3]float64{0, 1, 0}}
+		return r, p.Decode(r)
+	})
+
+	RegisterPhysicsComponent("default", func(ComponentParams) (*DefaultPhysicsComponent, error) { return new(DefaultPhysicsComponent), nil })
 
-	RegisterInputComponent("mouseCameraInput", func() InputComponent { return NewMouseCameraInputComponent() })
+	RegisterLightExtractor("default", func(ComponentParams) (*DefaultLightExtractor, error) { return new(DefaultLightExtractor), nil })
 }
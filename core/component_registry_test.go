@@ -0,0 +1,179 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testLightExtractor struct {
+	Limit int `yaml:"limit"`
+}
+
+func (le *testLightExtractor) Run(node *Node, lightBucket *[]*Light) {}
+
+func (le *testLightExtractor) Params() ComponentParams {
+	return ComponentParams{"limit": le.Limit}
+}
+
+func TestComponentDefYAML(t *testing.T) {
+	var sn SceneNode
+	src := "name: n\ncull: alwaysPass\nupdate: {type: rotator, speed: 1.5}\n"
+	if err := yaml.Unmarshal([]byte(src), &sn); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if sn.Cull.Type != "alwaysPass" || sn.Cull.Params != nil {
+		t.Errorf("cull = %+v, want alwaysPass without params", sn.Cull)
+	}
+	if sn.Update.Type != "rotator" || sn.Update.Params["speed"] != 1.5 || len(sn.Update.Params) != 1 {
+		t.Errorf("update = %+v, want rotator with speed 1.5", sn.Update)
+	}
+
+	if err := yaml.Unmarshal([]byte("name: n\nupdate: {speed: 1}\n"), &sn); err == nil {
+		t.Error("component without a type decoded without error")
+	}
+}
+
+func TestLookupComponents(t *testing.T) {
+	u, err := LookupUpdater("rotator", ComponentParams{"speed": 2.5, "axis": []interface{}{1, 0, 0}})
+	if err != nil {
+		t.Fatalf("LookupUpdater failed: %v", err)
+	}
+	if r := u.(*Rotator); r.Speed != 2.5 || r.Axis != [3]float64{1, 0, 0} {
+		t.Errorf("rotator = %+v, want speed 2.5 around x", r)
+	}
+
+	// called as a plain Updater it turns by the default engine's frame time
+	tm := GetTimerManager()
+	defer func(dt float64) { tm.dt = dt }(tm.dt)
+	tm.dt = 36
+	n := NewNode("spun")
+	u.Run(n)
+	if y := n.Transform().Col(1); math.Abs(y.Y()) > 1e-9 || math.Abs(y.Z()-1) > 1e-9 {
+		t.Errorf("rotator y axis = %v, want +z after a quarter turn", y)
+	}
+
+	u, err = LookupUpdater("rotator", nil)
+	if err != nil || u.(*Rotator).Speed != 90 || u.(*Rotator).Axis != [3]float64{0, 1, 0} {
+		t.Errorf("default rotator = %+v, %v", u, err)
+	}
+
+	if _, err := LookupUpdater("rotator", ComponentParams{"speed": "fast"}); err == nil {
+		t.Error("rotator accepted a string speed")
+	}
+	if _, err := LookupUpdater("rotator", ComponentParams{"sped": 2}); err == nil || !strings.Contains(err.Error(), "field sped not found") || strings.Contains(err.Error(), "line ") {
+		t.Errorf("rotator with an unknown parameter: error = %v, want field sped not found", err)
+	}
+	if _, err := LookupCullComponentWithParams("nope", nil); err == nil {
+		t.Error("unknown cull component looked up without error")
+	}
+	if c := LookupCullComponent("nope"); c != nil {
+		t.Errorf("LookupCullComponent(nope) = %v, want nil", c)
+	}
+	if _, ok := LookupInputComponent("mouseCameraInput").(*MouseCameraInputComponent); !ok {
+		t.Error("LookupInputComponent(mouseCameraInput) is not a mouse camera input")
+	}
+}
+
+func TestSceneComponents(t *testing.T) {
+	RegisterLightExtractor("testLimit", func(p ComponentParams) (LightExtractor, error) {
+		le := &testLightExtractor{Limit: 1}
+		return le, p.Decode(le)
+	})
+	defer func() {
+		delete(lightExtractors.factories, "testLimit")
+		delete(lightExtractors.names, reflect.TypeOf(&testLightExtractor{}))
+	}()
+
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	src := `name: Components
+nodes:
+  - name: Spinner
+    update: {type: rotator, speed: 90, axis: [0, 0, 1]}
+    lightExtractor:
+      type: testLimit
+      limit: 4
+`
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	spinner := scene.Root().Children()[0]
+	if le, ok := spinner.LightExtractor().(*testLightExtractor); !ok || le.Limit != 4 {
+		t.Errorf("light extractor = %+v, want limit 4", spinner.LightExtractor())
+	}
+
+	scene.update(e, 1)
+	x := spinner.Transform().Col(0)
+	if math.Abs(x.X()) > 1e-9 || math.Abs(x.Y()-1) > 1e-9 {
+		t.Errorf("rotator x axis = %v, want +y after a quarter turn", x)
+	}
+
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	for _, want := range []string{"update:\n      type: rotator\n      axis: [0, 0, 1]\n      speed: 90\n", "lightExtractor:\n      type: testLimit\n      limit: 4\n"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("saved scene does not contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestComponentDefNames(t *testing.T) {
+	calls := 0
+	RegisterLightExtractor("testRequired", func(p ComponentParams) (LightExtractor, error) {
+		calls++
+		if p == nil {
+			return nil, errors.New("limit is required")
+		}
+		le := &testLightExtractor{}
+		return le, p.Decode(le)
+	})
+	defer func() {
+		delete(lightExtractors.factories, "testRequired")
+		delete(lightExtractors.names, reflect.TypeOf(&testLightExtractor{}))
+	}()
+
+	if calls != 0 {
+		t.Errorf("registering called the factory %d times, want 0", calls)
+	}
+	if def := lightExtractors.def(&testLightExtractor{Limit: 2}); def.Type != "" {
+		t.Errorf("def before lookup = %+v, want no type", def)
+	}
+	le, err := LookupLightExtractor("testRequired", ComponentParams{"limit": 2})
+	if err != nil {
+		t.Fatalf("LookupLightExtractor failed: %v", err)
+	}
+
+	calls = 0
+	def := lightExtractors.def(le)
+	if def.Type != "testRequired" || def.Params["limit"] != 2 {
+		t.Errorf("def = %+v, want testRequired with limit 2", def)
+	}
+	if calls != 0 {
+		t.Errorf("def called factories %d times, want 0", calls)
+	}
+
+	// factories of a concrete type name it when they're registered
+	RegisterCullComponentWithParams("testCuller", func(ComponentParams) (*testCuller, error) {
+		calls++
+		return new(testCuller), nil
+	})
+	defer func() {
+		delete(cullComponents.factories, "testCuller")
+		delete(cullComponents.names, reflect.TypeOf(&testCuller{}))
+	}()
+	if def := cullComponents.def(new(testCuller)); def.Type != "testCuller" || calls != 0 {
+		t.Errorf("def = %+v after %d factory calls, want testCuller without calling it", def, calls)
+	}
+}
+
+type testCuller struct{ DefaultCuller }
@@ -1,14 +1,22 @@
 package core
 
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
 // Culler is an interface that wraps culling of a scenegraph.
 type Culler interface {
 	// Run culls a scenegraph node. A scene and camera are provided for visibility/frustum checks.
 	// If the policy dictates the node is to be drawn, then it should be added to the nodeBucket.
+	// Cameras are culled in parallel, so Run may be called concurrently with different cameras.
 	Run(*Scene, *Camera, *Node)
 }
 
 // DefaultCuller implements a scenegraph culler. The policy for this culler is to
-// mark all nodes in frustum for drawing. The node's modelMatrix state uniform is also set
+// mark all nodes in frustum and on the camera's layers for drawing. The node's modelMatrix state uniform is also set
 // from the nodes worldtransform. This may change as we transition away from individual uniforms
 // for instanced/indirect drawing.
 type DefaultCuller struct{}
@@ -24,29 +32,148 @@ func (cc *DefaultCuller) Run(scene *Scene, camera *Camera, node *Node) {
 	}
 
 	// the default implementation is to add ourselves to the bucket
-	if node.mesh != nil {
-		camera.pipelineBuckets[node.pipeline] = append(camera.pipelineBuckets[node.pipeline], node)
-		if !node.pipeline.Blending {
-			camera.visibleOpaqueNodes = append(camera.visibleOpaqueNodes, node)
-		}
+	if node.mesh != nil && camera.Sees(node) {
+		camera.AddNodeToRenderBuckets(node)
 	}
 
+	if node.lodGroup != nil {
+		node.lodGroup.cull(scene, camera, node)
+		return
+	}
 	for _, c := range node.children {
 		c.cullComponent.Run(scene, camera, c)
 	}
 }
 
 // AlwaysPassCuller implements a scenegraph culler by always adding the node to the bucket
+// if it is on the camera's layers
 type AlwaysPassCuller struct{}
 
 // Run implements the Culler interface
 func (apcc *AlwaysPassCuller) Run(scene *Scene, camera *Camera, node *Node) {
 	// the default implementation is to add ourselves to the bucket
-	if node.mesh != nil {
-		camera.pipelineBuckets[node.pipeline] = append(camera.pipelineBuckets[node.pipeline], node)
+	if node.mesh != nil && camera.Sees(node) {
+		camera.AddNodeToRenderBuckets(node)
 	}
 
+	if node.lodGroup != nil {
+		node.lodGroup.cull(scene, camera, node)
+		return
+	}
 	for _, ch := range node.children {
 		ch.cullComponent.Run(scene, camera, ch)
 	}
 }
+
+// BVHCuller implements a scenegraph culler backed by a dynamic bounding volume
+// hierarchy of the meshes below its node, so cull cost grows with what is in
+// view rather than with the size of the graph. Leaves follow the nodes' world
+// bounds as they are updated and the hierarchy is resynchronised with the graph
+// when nodes are attached or detached. Subtrees with cull components other than
+// DefaultCuller, and level of detail groups, are culled by their own components.
+type BVHCuller struct {
+	mutex     sync.Mutex
+	tree      *bvh
+	root      *Node
+	version   uint64
+	epoch     uint64
+	delegates []*Node
+	stale     []*Node
+}
+
+// NewBVHCuller returns a new BVHCuller. Each instance indexes the subtree it
+// is attached to, so it should not be shared between nodes.
+func NewBVHCuller() *BVHCuller {
+	return &BVHCuller{tree: newBVH()}
+}
+
+// Run implements the Culler interface
+func (bc *BVHCuller) Run(scene *Scene, camera *Camera, node *Node) {
+	if !node.active {
+		return
+	}
+
+	bc.sync(node)
+	bc.Query(camera.frustum, func(n *Node) {
+		if camera.Sees(n) {
+			camera.AddNodeToRenderBuckets(n)
+		}
+	})
+
+	for _, d := range bc.delegates {
+		if d.parent.worldBounds.InFrustum(camera.frustum) && bc.active(d.parent) {
+			d.cullComponent.Run(scene, camera, d)
+		}
+	}
+}
+
+// Query calls visit for every active mesh node in the frustum, as indexed by
+// the last Run. Nodes in subtrees culled by other components are not visited.
+func (bc *BVHCuller) Query(frustum [6]mgl64.Vec4, visit func(*Node)) {
+	bc.tree.query(&frustum, func(n *Node) {
+		if n.mesh != nil && bc.active(n) {
+			visit(n)
+		}
+	})
+}
+
+// active returns whether a node and its ancestors up to the culler's node are active.
+func (bc *BVHCuller) active(n *Node) bool {
+	for ; n != nil; n = n.parent {
+		if !n.active {
+			return false
+		}
+		if n == bc.root {
+			return true
+		}
+	}
+	return false
+}
+
+// sync brings the hierarchy in line with the graph below root if the graph
+// changed since the last sync. Cameras culled in parallel wait for the first
+// to sync, the graph doesn't change while they query it.
+func (bc *BVHCuller) sync(root *Node) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	version := atomic.LoadUint64(&graphVersion)
+	if bc.root == root && bc.version == version {
+		return
+	}
+	bc.root, bc.version = root, version
+	bc.epoch++
+	bc.delegates = bc.delegates[:0]
+
+	t := bc.tree
+	root.Walk(func(n *Node) VisitAction {
+		if _, ok := n.cullComponent.(*DefaultCuller); (!ok || n.lodGroup != nil) && n != root {
+			bc.delegates = append(bc.delegates, n)
+			return VisitSkipChildren
+		}
+		if n.mesh == nil {
+			return VisitContinue
+		}
+		if n.bvh != t {
+			if n.bvh != nil {
+				n.bvh.remove(n)
+			}
+			t.insert(n)
+		}
+		if n.bvh == t {
+			t.nodes[n.bvhLeaf].seen = bc.epoch
+		}
+		return VisitContinue
+	}, nil)
+
+	// drop the nodes which are no longer below root
+	bc.stale = bc.stale[:0]
+	for i := range t.nodes {
+		if l := &t.nodes[i]; l.node != nil && l.seen != bc.epoch {
+			bc.stale = append(bc.stale, l.node)
+		}
+	}
+	for _, n := range bc.stale {
+		t.remove(n)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func bucketSize(c *Camera) int {
+	n := 0
+	for _, nodes := range c.pipelineBuckets {
+		n += len(nodes)
+	}
+	return n
+}
+
+func TestCameraCullingMask(t *testing.T) {
+	e := newTestEngine(t)
+	backend := useRecordingRenderer(t, e)
+	scene, world := newRecordingScene(t, e, "unlit", "unlit")
+	weapon := scene.Root().Children()[1]
+	weapon.SetLayers(Layer(3))
+
+	ui := NewCamera("UI", PerspectiveProjection)
+	ui.SetViewport(mgl32.Vec4{0, 0, 64, 64})
+	ui.SetVerticalFieldOfView(60)
+	ui.SetClipDistance(mgl64.Vec2{0.1, 100})
+	ui.SetScene(scene.Root())
+	ui.SetCullingMask(Layer(3))
+	scene.AddCamera(scene.Root(), ui)
+
+	for _, culler := range []Culler{new(DefaultCuller), new(AlwaysPassCuller)} {
+		scene.Root().SetCullComponent(culler)
+		scene.cull(e)
+		if got := bucketSize(world); got != 2 {
+			t.Errorf("%T: world camera nodes = %d, want 2", culler, got)
+		}
+		if got := bucketSize(ui); got != 1 || ui.pipelineBuckets[weapon.pipeline][0] != weapon {
+			t.Errorf("%T: layer 3 camera nodes = %d, want the weapon", culler, got)
+		}
+	}
+
+	sun := &Light{Shadower: newShadowMap(e, 256, 1)}
+	sun.Block.Position = mgl32.Vec4{1, 1, 1, 0}
+	muzzle := &Light{layers: Layer(3)}
+	lights := []*Light{sun, muzzle, {layers: LayerDefault}}
+	if got := world.visibleLights(lights); len(got) != 3 {
+		t.Errorf("world camera lights = %d, want 3", len(got))
+	}
+	if got := ui.visibleLights(lights); len(got) != 2 || got[1] != muzzle {
+		t.Errorf("layer 3 camera lights = %d, want the unmasked and layer 3 lights", len(got))
+	}
+
+	// only nodes on the light's layers cast shadows
+	for _, tt := range []struct {
+		layers    LayerMask
+		instances uint32
+	}{{0, 2}, {Layer(3), 1}} {
+		sun.layers = tt.layers
+		e.Renderer().BeginFrame()
+		sun.Shadower.Render(sun, world)
+		e.Renderer().EndFrame()
+		draws := backend.LastFrame().DrawCalls()
+		if len(draws) != 1 || draws[0].InstanceCount != tt.instances {
+			t.Errorf("light layers %#x shadow draws = %+v, want %d instances", tt.layers, draws, tt.instances)
+		}
+	}
+}
+
+func TestSceneLayers(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	src := `name: Layers
+nodes:
+  - name: Gizmo
+    layers: 0x6
+    camera:
+      name: Editor
+      projection: perspective
+      cullingMask: 0x4
+`
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	gizmo := scene.Root().Children()[0]
+	if gizmo.Layers() != Layer(1)|Layer(2) {
+		t.Errorf("layers = %#x, want 0x6", gizmo.Layers())
+	}
+	if scene.Camera("Editor").CullingMask() != Layer(2) {
+		t.Errorf("culling mask = %#x, want 0x4", scene.Camera("Editor").CullingMask())
+	}
+	if scene.Root().Layers() != LayerDefault || NewCamera("c", PerspectiveProjection).CullingMask() != LayerAll {
+		t.Error("defaults are not layer 0 for nodes and all layers for cameras")
+	}
+
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("layers: 6\n")) || !bytes.Contains(data, []byte("cullingMask: 4\n")) {
+		t.Errorf("saved scene lost the layers:\n%s", data)
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import "errors"
+
+// Engine owns the renderer, managers and systems that make up a running gosg
+// instance. Several engines can coexist in one process, e.g. an editor preview
+// next to the game or independent engines in tests. The package-level Get*
+// and Set* functions operate on the default engine.
+type Engine struct {
+	renderer        *Renderer
+	resourceManager *ResourceManager
+	sceneManager    *SceneManager
+	inputManager    *InputManager
+	timerManager    *TimerManager
+	windowManager   *WindowManager
+	physicsSystem   PhysicsSystem
+	audioSystem     AudioSystem
+	imguiSystem     IMGUISystem
+	imgui           *imguiRenderer
+	workers         *workerPool
+	keepMeshData    bool
+}
+
+var defaultEngine = NewEngine()
+
+// NewEngine returns an engine with fresh managers. Systems are not registered and
+// the renderer is created when the engine's window is made.
+func NewEngine() *Engine {
+	e := &Engine{}
+	e.resourceManager = newResourceManager(e)
+	e.sceneManager = newSceneManager(e)
+	e.inputManager = newInputManager(e)
+	e.timerManager = newTimerManager(e)
+	e.windowManager = newWindowManager(e)
+	e.imgui = &imguiRenderer{engine: e}
+	e.workers = newWorkerPool()
+	return e
+}
+
+// DefaultEngine returns the engine used by the package-level functions.
+func DefaultEngine() *Engine {
+	return defaultEngine
+}
+
+// Renderer returns the engine's renderer, or nil before one was initialized.
+func (e *Engine) Renderer() *Renderer {
+	return e.renderer
+}
+
+// ResourceManager returns the engine's resource manager.
+func (e *Engine) ResourceManager() *ResourceManager {
+	return e.resourceManager
+}
+
+// SceneManager returns the engine's scene manager.
+func (e *Engine) SceneManager() *SceneManager {
+	return e.sceneManager
+}
+
+// InputManager returns the engine's input manager.
+func (e *Engine) InputManager() *InputManager {
+	return e.inputManager
+}
+
+// TimerManager returns the engine's timer manager.
+func (e *Engine) TimerManager() *TimerManager {
+	return e.timerManager
+}
+
+// WindowManager returns the engine's window manager.
+func (e *Engine) WindowManager() *WindowManager {
+	return e.windowManager
+}
+
+// PhysicsSystem returns the engine's physics system, if any.
+func (e *Engine) PhysicsSystem() PhysicsSystem {
+	return e.physicsSystem
+}
+
+// AudioSystem returns the engine's audio system, if any.
+func (e *Engine) AudioSystem() AudioSystem {
+	return e.audioSystem
+}
+
+// IMGUISystem returns the engine's IMGUI system, if any.
+func (e *Engine) IMGUISystem() IMGUISystem {
+	return e.imguiSystem
+}
+
+// SetPhysicsSystem registers the engine's physics system. It fails if one was
+// already registered.
+func (e *Engine) SetPhysicsSystem(ps PhysicsSystem) error {
+	if e.physicsSystem != nil {
+		return errors.New("can't replace previously registered physics system")
+	}
+	e.physicsSystem = ps
+	return nil
+}
+
+// SetAudioSystem registers the engine's audio system. It fails if one was
+// already registered.
+func (e *Engine) SetAudioSystem(a AudioSystem) error {
+	if e.audioSystem != nil {
+		return errors.New("can't replace previously registered audio system")
+	}
+	e.audioSystem = a
+	return nil
+}
+
+// SetIMGUISystem registers the engine's IMGUI system.
+func (e *Engine) SetIMGUISystem(is IMGUISystem) {
+	e.imguiSystem = is
+}
+
+// Workers returns the number of goroutines culling and sorting fan out to.
+func (e *Engine) Workers() int {
+	return e.workers.workers
+}
+
+// SetWorkers sets the number of goroutines culling and sorting fan out to.
+// Cameras, shadow cascades and render buckets are processed in parallel, with
+// the same output as a serial run. It defaults to GOMAXPROCS, and 1 keeps all
+// the work on the render goroutine.
+func (e *Engine) SetWorkers(n int) {
+	e.workers.workers = max(n, 1)
+}
+
+// KeepMeshData returns whether new meshes keep a CPU copy of their geometry.
+func (e *Engine) KeepMeshData() bool {
+	return e.keepMeshData
+}
+
+// SetKeepMeshData sets whether meshes created from now on keep a CPU copy of
+// their positions and indices. Ray casts hit the triangles of meshes which do
+// and only the bounds of those which don't.
+func (e *Engine) SetKeepMeshData(keep bool) {
+	e.keepMeshData = keep
+}
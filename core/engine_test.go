@@ -0,0 +1,80 @@
+package core
+
+import "testing"
+
+type nopAudioSystem struct{}
+
+func (a *nopAudioSystem) Start() {}
+func (a *nopAudioSystem) Step()  {}
+func (a *nopAudioSystem) Stop()  {}
+
+func TestEnginesAreIndependent(t *testing.T) {
+	a, b := newTestEngine(t), newTestEngine(t)
+	backendA := useRecordingRenderer(t, a)
+	backendB := useRecordingRenderer(t, b)
+
+	if a.Renderer() == b.Renderer() || a.ResourceManager() == b.ResourceManager() || a.SceneManager() == b.SceneManager() {
+		t.Fatal("engines share managers")
+	}
+
+	sceneA, _ := newRecordingScene(t, a, "unlit")
+	sceneB, _ := newRecordingScene(t, b, "unlit", "glass")
+	a.SceneManager().PushScene(sceneA)
+	b.SceneManager().PushScene(sceneB)
+
+	for _, e := range []*Engine{a, b} {
+		e.Renderer().BeginFrame()
+		e.SceneManager().draw()
+		e.Renderer().EndFrame()
+	}
+
+	if got := len(backendA.LastFrame().DrawCalls()); got != 2 {
+		t.Errorf("engine a draws = %d, want 2", got)
+	}
+	if got := len(backendB.LastFrame().DrawCalls()); got != 3 {
+		t.Errorf("engine b draws = %d, want 3", got)
+	}
+	if got := a.Renderer().Stats().InstancesDrawn; got != 2 {
+		t.Errorf("engine a instances = %d, want 2", got)
+	}
+	if got := b.Renderer().Stats().InstancesDrawn; got != 3 {
+		t.Errorf("engine b instances = %d, want 3", got)
+	}
+}
+
+func TestEngineCameraRebind(t *testing.T) {
+	a, b := newTestEngine(t), newTestEngine(t)
+	useRecordingRenderer(t, a)
+	backendB := useRecordingRenderer(t, b)
+
+	// a scene built on one engine and culled by another renders there
+	scene, camera := newRecordingScene(t, a, "unlit")
+	camera.constants.SetData(camera.ProjectionMatrix(), camera.ViewMatrix(), nil)
+	scene.cull(b)
+	if camera.engine != b || camera.constants.buffer.engine != b {
+		t.Fatal("camera was not rebound to the culling engine")
+	}
+	if camera.constants.buffer.buffer != nil {
+		t.Error("camera constants kept a buffer from the previous engine")
+	}
+
+	b.Renderer().BeginFrame()
+	scene.draw(b)
+	b.Renderer().EndFrame()
+	if got := len(backendB.LastFrame().Passes); got != 2 {
+		t.Errorf("passes = %d, want 2", got)
+	}
+}
+
+func TestEngineSystemRegistration(t *testing.T) {
+	e := NewEngine()
+	if err := e.SetAudioSystem(&nopAudioSystem{}); err != nil {
+		t.Fatalf("first SetAudioSystem failed: %v", err)
+	}
+	if err := e.SetAudioSystem(&nopAudioSystem{}); err == nil {
+		t.Error("second SetAudioSystem succeeded, want error")
+	}
+	if NewEngine().AudioSystem() != nil {
+		t.Error("new engine inherited an audio system")
+	}
+}
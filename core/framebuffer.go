@@ -4,6 +4,8 @@ package core
 type Framebuffer struct {
 	colorAttachments map[int]*Texture
 	depthAttachment  *Texture
+	// set on framebuffers resized along with the window
+	renderer *Renderer
 	// GPU handle will be added in Phase 2
 }
 
@@ -38,3 +40,29 @@ func (f *Framebuffer) SetDepthAttachment(attachment *Texture) {
 func (f *Framebuffer) DepthAttachment() *Texture {
 	return f.depthAttachment
 }
+
+// resizeToWindow resizes the attachments of framebuffers created with
+// Renderer.NewWindowFramebuffer.
+func (f *Framebuffer) resizeToWindow(width, height uint32) {
+	for _, tex := range f.colorAttachments {
+		tex.resize(width, height)
+	}
+	if f.depthAttachment != nil {
+		f.depthAttachment.resize(width, height)
+	}
+}
+
+// Dispose releases the framebuffer's attachments and stops resizing them
+// along with the window.
+func (f *Framebuffer) Dispose() {
+	if f.renderer != nil {
+		f.renderer.untrackWindowTarget(f)
+		f.renderer = nil
+	}
+	for _, tex := range f.colorAttachments {
+		tex.release()
+	}
+	if f.depthAttachment != nil {
+		f.depthAttachment.release()
+	}
+}
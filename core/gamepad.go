@@ -0,0 +1,270 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GamepadID identifies a connected gamepad for as long as it stays connected.
+// Reconnected gamepads get a new ID.
+type GamepadID uint32
+
+// GamepadButton represents a gamepad button, named after its position on the
+// gamepad: GamepadButtonSouth is A on Xbox and Cross on PlayStation gamepads.
+type GamepadButton int
+
+// Gamepad button constants, matching SDL3's plus one so the zero value binds
+// no button.
+const (
+	GamepadButtonNone GamepadButton = iota
+	GamepadButtonSouth
+	GamepadButtonEast
+	GamepadButtonWest
+	GamepadButtonNorth
+	GamepadButtonBack
+	GamepadButtonGuide
+	GamepadButtonStart
+	GamepadButtonLeftStick
+	GamepadButtonRightStick
+	GamepadButtonLeftShoulder
+	GamepadButtonRightShoulder
+	GamepadButtonDPadUp
+	GamepadButtonDPadDown
+	GamepadButtonDPadLeft
+	GamepadButtonDPadRight
+	GamepadButtonMisc1
+	GamepadButtonRightPaddle1
+	GamepadButtonLeftPaddle1
+	GamepadButtonRightPaddle2
+	GamepadButtonLeftPaddle2
+	GamepadButtonTouchpad
+	GamepadButtonMisc2
+	GamepadButtonMisc3
+	GamepadButtonMisc4
+	GamepadButtonMisc5
+	GamepadButtonMisc6
+
+	gamepadButtonCount = int(GamepadButtonMisc6)
+)
+
+// GamepadAxis represents a gamepad stick axis or trigger.
+type GamepadAxis int
+
+// Gamepad axis constants matching SDL3. Stick axes go from -1 to 1, positive
+// being right and down; triggers go from 0 to 1.
+const (
+	GamepadAxisLeftX GamepadAxis = iota
+	GamepadAxisLeftY
+	GamepadAxisRightX
+	GamepadAxisRightY
+	GamepadAxisLeftTrigger
+	GamepadAxisRightTrigger
+
+	gamepadAxisCount = int(GamepadAxisRightTrigger) + 1
+)
+
+// gamepadButtonNames are the names gamepad buttons have in input map files.
+var gamepadButtonNames = map[GamepadButton]string{
+	GamepadButtonSouth:         "south",
+	GamepadButtonEast:          "east",
+	GamepadButtonWest:          "west",
+	GamepadButtonNorth:         "north",
+	GamepadButtonBack:          "back",
+	GamepadButtonGuide:         "guide",
+	GamepadButtonStart:         "start",
+	GamepadButtonLeftStick:     "left_stick",
+	GamepadButtonRightStick:    "right_stick",
+	GamepadButtonLeftShoulder:  "left_shoulder",
+	GamepadButtonRightShoulder: "right_shoulder",
+	GamepadButtonDPadUp:        "dpad_up",
+	GamepadButtonDPadDown:      "dpad_down",
+	GamepadButtonDPadLeft:      "dpad_left",
+	GamepadButtonDPadRight:     "dpad_right",
+	GamepadButtonMisc1:         "misc1",
+	GamepadButtonRightPaddle1:  "right_paddle1",
+	GamepadButtonLeftPaddle1:   "left_paddle1",
+	GamepadButtonRightPaddle2:  "right_paddle2",
+	GamepadButtonLeftPaddle2:   "left_paddle2",
+	GamepadButtonTouchpad:      "touchpad",
+	GamepadButtonMisc2:         "misc2",
+	GamepadButtonMisc3:         "misc3",
+	GamepadButtonMisc4:         "misc4",
+	GamepadButtonMisc5:         "misc5",
+	GamepadButtonMisc6:         "misc6",
+}
+
+// String returns the gamepad button's name, or its number for unnamed buttons.
+func (b GamepadButton) String() string {
+	if name, ok := gamepadButtonNames[b]; ok {
+		return name
+	}
+	return strconv.Itoa(int(b))
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (b GamepadButton) MarshalYAML() (interface{}, error) {
+	return b.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (b *GamepadButton) UnmarshalYAML(value *yaml.Node) error {
+	for button, name := range gamepadButtonNames {
+		if name == value.Value {
+			*b = button
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: unknown gamepad button %q", value.Line, value.Value)
+}
+
+// GamepadInfo identifies a gamepad's model and, where the driver reports it,
+// the device itself.
+type GamepadInfo struct {
+	Name    string `json:"name,omitempty"`
+	Vendor  uint16 `json:"vendor,omitempty"`
+	Product uint16 `json:"product,omitempty"`
+	Serial  string `json:"serial,omitempty"`
+}
+
+// GamepadState holds a connected gamepad's input state.
+type GamepadState struct {
+	ID       GamepadID
+	Info     GamepadInfo
+	Active   map[GamepadButton]bool
+	Released map[GamepadButton]bool
+	Axes     [gamepadAxisCount]float64
+}
+
+// Axis returns an axis' value.
+func (g *GamepadState) Axis(axis GamepadAxis) float64 {
+	if axis < 0 || int(axis) >= gamepadAxisCount {
+		return 0
+	}
+	return g.Axes[axis]
+}
+
+// Gamepads returns the IDs of the connected gamepads, sorted.
+func (i *InputManager) Gamepads() []GamepadID {
+	gamepads := i.State().Gamepads
+	ids := make([]GamepadID, 0, len(gamepads))
+	for id := range gamepads {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+// Gamepad returns a connected gamepad's state, or nil.
+func (i *InputManager) Gamepad(id GamepadID) *GamepadState {
+	return i.State().Gamepads[id]
+}
+
+// RumbleGamepad starts a gamepad's rumble motors for a duration, with low and
+// high frequency intensities from 0 to 1, replacing any rumble in progress.
+// It returns false if the gamepad is gone or can't rumble.
+func (i *InputManager) RumbleGamepad(id GamepadID, low, high float64, duration time.Duration) bool {
+	if i.state.Gamepads[id] == nil {
+		return false
+	}
+	return i.engine.windowManager.backend.rumbleGamepad(id, clamp01(low), clamp01(high), duration)
+}
+
+// HandleGamepadAdded is called by the window system when a gamepad connects.
+func (i *InputManager) HandleGamepadAdded(id GamepadID, info GamepadInfo) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventGamepadAdded, Gamepad: id, GamepadInfo: &info})
+
+	i.state.Gamepads[id] = &GamepadState{
+		ID:       id,
+		Info:     info,
+		Active:   make(map[GamepadButton]bool),
+		Released: make(map[GamepadButton]bool),
+	}
+}
+
+// HandleGamepadRemoved is called by the window system when a gamepad
+// disconnects.
+func (i *InputManager) HandleGamepadRemoved(id GamepadID) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventGamepadRemoved, Gamepad: id})
+
+	delete(i.state.Gamepads, id)
+}
+
+// HandleGamepadButton is called by the window system to register gamepad
+// button events.
+func (i *InputManager) HandleGamepadButton(id GamepadID, button GamepadButton, pressed bool) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventGamepadButton, Gamepad: id, GamepadButton: button, Pressed: pressed})
+
+	g := i.state.Gamepads[id]
+	if g == nil {
+		return
+	}
+	g.Active[button] = pressed
+	g.Released[button] = !pressed
+}
+
+// HandleGamepadAxis is called by the window system to register gamepad axis
+// motion, with values normalised as documented on GamepadAxis.
+func (i *InputManager) HandleGamepadAxis(id GamepadID, axis GamepadAxis, value float64) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventGamepadAxis, Gamepad: id, GamepadAxis: axis, Value: value})
+
+	g := i.state.Gamepads[id]
+	if g == nil || axis < 0 || int(axis) >= gamepadAxisCount {
+		return
+	}
+	g.Axes[axis] = value
+}
+
+// gamepadAxisValue returns the value of an axis on the gamepad which has it
+// furthest from rest.
+func gamepadAxisValue(state *InputState, axis GamepadAxis) float64 {
+	var v float64
+	for _, g := range state.Gamepads {
+		if a := g.Axis(axis); math.Abs(a) > math.Abs(v) {
+			v = a
+		}
+	}
+	return v
+}
+
+// gamepadButtonActive returns whether a button is held on any gamepad, with
+// its press unconsumed.
+func (i *InputManager) gamepadButtonActive(button GamepadButton) bool {
+	for id := range i.State().Gamepads {
+		if i.active(inputButton{InputDeviceGamepad, id, int(button)}) {
+			return true
+		}
+	}
+	return false
+}
+
+// gamepadButtonReleased returns whether a button was released on any gamepad
+// this frame, unconsumed.
+func (i *InputManager) gamepadButtonReleased(button GamepadButton) bool {
+	for id := range i.State().Gamepads {
+		if i.released(inputButton{InputDeviceGamepad, id, int(button)}) {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp01(v float64) float64 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,150 @@
+package core
+
+/*
+#cgo pkg-config: sdl3
+#include <stdlib.h>
+#include <SDL3/SDL.h>
+
+static SDL_JoystickID gosg_attach_virtual_gamepad(const char *name, int naxes, int nbuttons) {
+	SDL_VirtualJoystickDesc desc;
+	SDL_INIT_INTERFACE(&desc);
+	desc.type = SDL_JOYSTICK_TYPE_GAMEPAD;
+	desc.naxes = naxes;
+	desc.nbuttons = nbuttons;
+	desc.name = name;
+	return SDL_AttachVirtualJoystick(&desc);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/golang/glog"
+)
+
+// gamepadButtonFromSDL returns the GamepadButton for an SDL_GamepadButton.
+func gamepadButtonFromSDL(button C.Uint8) GamepadButton {
+	return GamepadButton(button) + 1
+}
+
+// gamepadAxisFromSDL normalises an SDL axis value as documented on GamepadAxis.
+func gamepadAxisFromSDL(value int16) float64 {
+	return max(float64(value)/math.MaxInt16, -1)
+}
+
+// gamepadAxisToSDL is the inverse of gamepadAxisFromSDL.
+func gamepadAxisToSDL(value float64) C.Sint16 {
+	return C.Sint16(math.Round(min(max(value, -1), 1) * math.MaxInt16))
+}
+
+func (s *sdlWindow) gamepadAdded(w *WindowManager, which C.SDL_JoystickID) {
+	g := C.SDL_OpenGamepad(which)
+	if g == nil {
+		glog.Warningf("Cannot open gamepad %d: %s", which, C.GoString(C.SDL_GetError()))
+		return
+	}
+	if s.gamepads == nil {
+		s.gamepads = make(map[GamepadID]*C.SDL_Gamepad)
+	}
+	s.gamepads[GamepadID(which)] = g
+
+	info := GamepadInfo{
+		Name:    C.GoString(C.SDL_GetGamepadName(g)),
+		Vendor:  uint16(C.SDL_GetGamepadVendor(g)),
+		Product: uint16(C.SDL_GetGamepadProduct(g)),
+		Serial:  C.GoString(C.SDL_GetGamepadSerial(g)),
+	}
+	glog.Infof("Gamepad %d connected: %s", which, info.Name)
+	w.engine.inputManager.HandleGamepadAdded(GamepadID(which), info)
+}
+
+func (s *sdlWindow) gamepadRemoved(w *WindowManager, which C.SDL_JoystickID) {
+	if g, ok := s.gamepads[GamepadID(which)]; ok {
+		C.SDL_CloseGamepad(g)
+		delete(s.gamepads, GamepadID(which))
+	}
+	glog.Infof("Gamepad %d disconnected", which)
+	w.engine.inputManager.HandleGamepadRemoved(GamepadID(which))
+}
+
+func (s *sdlWindow) rumbleGamepad(id GamepadID, low, high float64, duration time.Duration) bool {
+	g, ok := s.gamepads[id]
+	if !ok {
+		return false
+	}
+	return bool(C.SDL_RumbleGamepad(g, C.Uint16(low*math.MaxUint16), C.Uint16(high*math.MaxUint16), C.Uint32(duration.Milliseconds())))
+}
+
+// VirtualGamepad is a gamepad emulated by SDL, used to test gamepad input
+// without a physical controller. Its connection, buttons and axes reach the
+// InputManager through WindowManager.PollEvents like a physical gamepad's.
+type VirtualGamepad struct {
+	id       C.SDL_JoystickID
+	joystick *C.SDL_Joystick
+	name     *C.char
+}
+
+// AttachVirtualGamepad connects a virtual gamepad. InitWindowManager must
+// have been called.
+func AttachVirtualGamepad(name string) (*VirtualGamepad, error) {
+	cName := C.CString(name)
+	id := C.gosg_attach_virtual_gamepad(cName, C.int(gamepadAxisCount), C.int(gamepadButtonCount))
+	if id == 0 {
+		C.free(unsafe.Pointer(cName))
+		return nil, fmt.Errorf("attaching virtual gamepad: %s", C.GoString(C.SDL_GetError()))
+	}
+
+	joystick := C.SDL_OpenJoystick(id)
+	if joystick == nil {
+		C.SDL_DetachVirtualJoystick(id)
+		C.free(unsafe.Pointer(cName))
+		return nil, fmt.Errorf("opening virtual gamepad: %s", C.GoString(C.SDL_GetError()))
+	}
+	return &VirtualGamepad{id: id, joystick: joystick, name: cName}, nil
+}
+
+// ID returns the gamepad's ID.
+func (v *VirtualGamepad) ID() GamepadID {
+	return GamepadID(v.id)
+}
+
+// SetButton presses or releases a button.
+func (v *VirtualGamepad) SetButton(button GamepadButton, pressed bool) error {
+	if v.joystick == nil {
+		return errors.New("virtual gamepad is detached")
+	}
+	if !C.SDL_SetJoystickVirtualButton(v.joystick, C.int(button-1), C.bool(pressed)) {
+		return fmt.Errorf("setting virtual gamepad button %s: %s", button, C.GoString(C.SDL_GetError()))
+	}
+	return nil
+}
+
+// SetAxis moves an axis, with values as documented on GamepadAxis.
+func (v *VirtualGamepad) SetAxis(axis GamepadAxis, value float64) error {
+	if v.joystick == nil {
+		return errors.New("virtual gamepad is detached")
+	}
+	if !C.SDL_SetJoystickVirtualAxis(v.joystick, C.int(axis), gamepadAxisToSDL(value)) {
+		return fmt.Errorf("setting virtual gamepad axis %d: %s", axis, C.GoString(C.SDL_GetError()))
+	}
+	return nil
+}
+
+// Detach disconnects the gamepad.
+func (v *VirtualGamepad) Detach() error {
+	if v.joystick == nil {
+		return nil
+	}
+	C.SDL_CloseJoystick(v.joystick)
+	v.joystick = nil
+	defer C.free(unsafe.Pointer(v.name))
+	if !C.SDL_DetachVirtualJoystick(v.id) {
+		return fmt.Errorf("detaching virtual gamepad: %s", C.GoString(C.SDL_GetError()))
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInputManagerGamepads(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+
+	im.HandleGamepadAdded(7, GamepadInfo{Name: "pad", Vendor: 0x45e, Product: 0x2ea})
+	im.HandleGamepadAdded(3, GamepadInfo{Name: "other pad"})
+	if ids := im.Gamepads(); len(ids) != 2 || ids[0] != 3 || ids[1] != 7 {
+		t.Fatalf("gamepads = %v, want [3 7]", ids)
+	}
+	if g := im.Gamepad(7); g.Info.Name != "pad" || g.Info.Vendor != 0x45e {
+		t.Errorf("gamepad info = %+v", g.Info)
+	}
+
+	im.HandleGamepadButton(7, GamepadButtonSouth, true)
+	im.HandleGamepadAxis(7, GamepadAxisLeftX, 0.1)
+	im.HandleGamepadAxis(3, GamepadAxisLeftX, -0.5)
+	im.HandleGamepadAxis(7, GamepadAxisRightTrigger, 1)
+	if !im.Gamepad(7).Active[GamepadButtonSouth] || im.Gamepad(3).Active[GamepadButtonSouth] {
+		t.Error("button state not kept per gamepad")
+	}
+	if got := im.Gamepad(7).Axis(GamepadAxisRightTrigger); got != 1 {
+		t.Errorf("right trigger = %v, want 1", got)
+	}
+
+	// the default map's axes follow the stick furthest from rest
+	if got := im.Axis("move_x"); got != -0.5 {
+		t.Errorf("move_x = %v, want -0.5", got)
+	}
+	im.HandleGamepadAxis(3, GamepadAxisLeftX, 0.1)
+	if got := im.Axis("move_x"); got != 0 {
+		t.Errorf("move_x = %v within the dead zone, want 0", got)
+	}
+
+	im.InputMap().Bind("jump", InputBinding{GamepadButton: GamepadButtonSouth})
+	im.reset()
+	im.HandleGamepadButton(7, GamepadButtonSouth, false)
+	if im.Action("jump") || !im.ActionReleased("jump") {
+		t.Error("jump not released with its gamepad button")
+	}
+	im.reset()
+	if im.ActionReleased("jump") {
+		t.Error("jump released again on the next frame")
+	}
+
+	// events for unknown gamepads are ignored
+	im.HandleGamepadButton(9, GamepadButtonSouth, true)
+	im.HandleGamepadRemoved(7)
+	if im.Gamepad(7) != nil || len(im.Gamepads()) != 1 {
+		t.Errorf("gamepads = %v after removing 7, want [3]", im.Gamepads())
+	}
+	if im.RumbleGamepad(3, 1, 1, time.Second) {
+		t.Error("headless gamepad rumbled")
+	}
+}
+
+func TestGamepadCamera(t *testing.T) {
+	script := map[int][]InputEvent{
+		0: {{Type: InputEventGamepadAdded, Gamepad: 1, GamepadInfo: &GamepadInfo{Name: "pad"}}},
+		1: {{Type: InputEventGamepadButton, Gamepad: 1, GamepadButton: GamepadButtonDPadUp, Pressed: true}},
+		2: {
+			{Type: InputEventGamepadButton, Gamepad: 1, GamepadButton: GamepadButtonDPadUp},
+			{Type: InputEventGamepadAxis, Gamepad: 1, GamepadAxis: GamepadAxisLeftY, Value: -1},
+		},
+	}
+
+	transform := runScriptedCamera(t, newTestEngine(t), &scriptedWindow{script: script}, 6)
+	if z := transform.Col(3).Z(); z >= 0 {
+		t.Errorf("camera z = %v, want it moved forward by the left stick", z)
+	}
+	if x := transform.Col(3).X(); x != 0 {
+		t.Errorf("camera x = %v, want no sideways movement", x)
+	}
+}
+
+func TestVirtualGamepad(t *testing.T) {
+	if err := InitWindowManager(); err != nil {
+		t.Skipf("SDL unavailable: %v", err)
+	}
+	vg, err := AttachVirtualGamepad("gosg test pad")
+	if err != nil {
+		t.Skipf("virtual gamepads unavailable: %v", err)
+	}
+	defer vg.Detach()
+
+	e := newTestEngine(t)
+	wm := e.WindowManager()
+	wm.backend = &sdlWindow{}
+	im := e.InputManager()
+
+	poll := func(done func() bool) bool {
+		for range 100 {
+			wm.PollEvents()
+			if done() {
+				return true
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return false
+	}
+
+	if !poll(func() bool { return im.Gamepad(vg.ID()) != nil }) {
+		t.Fatal("virtual gamepad never connected")
+	}
+	if name := im.Gamepad(vg.ID()).Info.Name; name != "gosg test pad" {
+		t.Errorf("gamepad name = %q", name)
+	}
+
+	if err := vg.SetButton(GamepadButtonEast, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.SetAxis(GamepadAxisLeftY, -1); err != nil {
+		t.Fatal(err)
+	}
+	if !poll(func() bool { return im.Gamepad(vg.ID()).Axis(GamepadAxisLeftY) == -1 }) {
+		t.Errorf("left y = %v, want -1", im.Gamepad(vg.ID()).Axis(GamepadAxisLeftY))
+	}
+	if !im.Gamepad(vg.ID()).Active[GamepadButtonEast] {
+		t.Error("east button not held")
+	}
+
+	if err := vg.Detach(); err != nil {
+		t.Fatal(err)
+	}
+	if !poll(func() bool { return im.Gamepad(vg.ID()) == nil }) {
+		t.Error("virtual gamepad never disconnected")
+	}
+}
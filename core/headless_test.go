@@ -0,0 +1,175 @@
+package core
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// memResourceSystem serves resources from in-memory maps.
+type memResourceSystem struct {
+	pipelines   map[string][]byte
+	programs    map[string][]byte
+	programData map[string][]byte
+	scenes      map[string][]byte
+}
+
+func (m *memResourceSystem) ModelPath(string) string { return "" }
+
+func (m *memResourceSystem) Model(name string) ([]byte, error) {
+	return m.get(ResourceModel, name, nil)
+}
+
+func (m *memResourceSystem) Texture(name string) ([]byte, error) {
+	return m.get(ResourceTexture, name, nil)
+}
+
+func (m *memResourceSystem) Program(name string) ([]byte, error) {
+	return m.get(ResourceProgram, name, m.programs)
+}
+
+func (m *memResourceSystem) Pipeline(name string) ([]byte, error) {
+	return m.get(ResourcePipeline, name, m.pipelines)
+}
+
+func (m *memResourceSystem) ProgramData(name string) ([]byte, error) {
+	return m.get(ResourceProgramData, name, m.programData)
+}
+
+func (m *memResourceSystem) Scene(name string) ([]byte, error) {
+	return m.get(ResourceScene, name, m.scenes)
+}
+
+func (m *memResourceSystem) get(kind ResourceKind, name string, from map[string][]byte) ([]byte, error) {
+	data, ok := from[name]
+	if !ok {
+		return nil, &ResourceError{Kind: kind, Name: name, Err: fs.ErrNotExist}
+	}
+	return data, nil
+}
+
+func newTestResourceSystem() *memResourceSystem {
+	return &memResourceSystem{
+		pipelines: map[string][]byte{
+			"unlit":   []byte(`{"programName": "unlit", "depthTest": true, "depthWrite": true, "colorWrite": true}`),
+			"unlit-z": []byte(`{"programName": "unlit", "depthTest": true, "depthWrite": true, "colorWrite": false}`),
+			"glass":   []byte(`{"programName": "unlit", "blending": true, "depthTest": true, "colorWrite": true}`),
+			"shadow":  []byte(`{"programName": "unlit", "depthTest": true, "depthWrite": true, "colorWrite": false}`),
+		},
+		programs: map[string][]byte{
+			"unlit": []byte(`{"shaders": {}}`),
+		},
+		scenes: map[string][]byte{},
+	}
+}
+
+// newTestEngine returns a fresh engine serving resources from memory.
+func newTestEngine(t testing.TB) *Engine {
+	t.Helper()
+	e := NewEngine()
+	if err := e.ResourceManager().SetSystem(newTestResourceSystem()); err != nil {
+		t.Fatalf("SetSystem failed: %v", err)
+	}
+	return e
+}
+
+type headlessTestApp struct {
+	frames, maxFrames int
+}
+
+func (a *headlessTestApp) InputComponent() ClientApplicationInputComponent { return a }
+func (a *headlessTestApp) Stop()                                           {}
+func (a *headlessTestApp) Done() bool                                      { return a.frames >= a.maxFrames }
+
+func (a *headlessTestApp) Run() []ClientApplicationCommand {
+	a.frames++
+	return nil
+}
+
+func newTriangleMesh(e *Engine) *Mesh {
+	m := e.Renderer().NewMesh()
+	m.SetPositions([]float32{-1, -1, 0, 1, -1, 0, 0, 1, 0})
+	m.SetNormals([]float32{0, 0, 1, 0, 0, 1, 0, 0, 1})
+	m.SetTextureCoordinates([]float32{0, 0, 1, 0, 0.5, 1})
+	m.SetIndices([]uint16{0, 1, 2})
+	return m
+}
+
+func TestHeadlessApplication(t *testing.T) {
+	e := newTestEngine(t)
+	e.WindowManager().SetWindowConfig(WindowConfig{Name: "test", Width: 320, Height: 240, Headless: true})
+
+	client := &headlessTestApp{maxFrames: 3}
+	app := NewApplication(e)
+	app.Start(func() ClientApplication {
+		pipeline, err := e.ResourceManager().Pipeline("unlit")
+		if err != nil {
+			t.Fatalf("Pipeline failed: %v", err)
+		}
+
+		root := NewNode("ROOT")
+		for _, name := range []string{"A", "B", "C"} {
+			n := NewNode(name)
+			n.SetMesh(newTriangleMesh(e))
+			n.SetPipeline(pipeline)
+			n.Translate(mgl64.Vec3{0, 0, -10})
+			root.AddChild(n)
+		}
+
+		camera := NewCamera("MainCamera", PerspectiveProjection)
+		camera.SetAutoReshape(true)
+		camera.SetVerticalFieldOfView(60)
+		camera.SetClipDistance(mgl64.Vec2{0.1, 100})
+		camera.SetScene(root)
+
+		scene := NewScene("headless")
+		scene.SetRoot(root)
+		scene.AddCamera(root, camera)
+		e.SceneManager().PushScene(scene)
+
+		return client
+	})
+
+	if client.frames != 3 {
+		t.Fatalf("frames = %d, want 3", client.frames)
+	}
+
+	frame := e.Renderer().LastFrame()
+	if len(frame.Passes) != 2 {
+		t.Fatalf("passes = %d, want 2", len(frame.Passes))
+	}
+	for _, p := range frame.Passes {
+		if p.Label != "MainCamera" {
+			t.Errorf("pass label = %q, want %q", p.Label, "MainCamera")
+		}
+		if p.Viewport != (mgl32.Vec4{0, 0, 320, 240}) {
+			t.Errorf("pass viewport = %v, want %v", p.Viewport, mgl32.Vec4{0, 0, 320, 240})
+		}
+	}
+	if got := frame.Passes[0].Pipelines; len(got) != 1 || got[0] != "unlit-z" {
+		t.Errorf("z-prepass pipelines = %v, want [unlit-z]", got)
+	}
+	if got := frame.Passes[1].Pipelines; len(got) != 1 || got[0] != "unlit" {
+		t.Errorf("opaque pipelines = %v, want [unlit]", got)
+	}
+
+	draws := frame.DrawCalls()
+	if len(draws) != 2 {
+		t.Fatalf("draws = %d, want 2", len(draws))
+	}
+	for _, d := range draws {
+		if d.IndexCount != 3 || d.InstanceCount != 3 {
+			t.Errorf("draw = %+v, want 3 indices and 3 instances", d)
+		}
+	}
+
+	want := FrameStats{RenderPasses: 2, PipelineSwitches: 2, DrawCalls: 2, Batches: 2, InstancesDrawn: 6, Flushes: 1}
+	if e.Renderer().Stats() != want {
+		t.Errorf("stats = %+v, want %+v", e.Renderer().Stats(), want)
+	}
+	if frame.Flushes != 1 {
+		t.Errorf("flushes = %d, want 1", frame.Flushes)
+	}
+}
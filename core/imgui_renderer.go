@@ -1,67 +1,63 @@
 package core
 
-import (
-	"unsafe"
-
-	"github.com/fcvarela/gosg/gpu"
-)
+import "unsafe"
 
 // imguiRenderer handles Dear ImGui rendering with its own pipeline and vertex format.
 type imguiRenderer struct {
-	vertexBuffer gpu.Buffer
-	indexBuffer  gpu.Buffer
+	engine       *Engine
+	vertexBuffer BufferHandle
+	indexBuffer  BufferHandle
 	vertexSize   uint64
 	indexSize    uint64
-	pipeline     gpu.RenderPipeline
+	pipeline     PipelineHandle
 	initialized  bool
 }
 
-var imgui imguiRenderer
-
-func (ir *imguiRenderer) ensurePipeline(program *Program, colorFormat gpu.TextureFormat) {
+func (ir *imguiRenderer) ensurePipeline(program *Program, colorFormat PixelFormat) {
 	if ir.initialized {
 		return
 	}
 
-	buffers := []gpu.VertexBufferLayout{{
+	buffers := []VertexBufferLayout{{
 		ArrayStride: 20, // ImDrawVert: pos(2xf32) + uv(2xf32) + col(u32) = 20 bytes
-		StepMode:    gpu.VertexStepModeVertex,
-		Attributes: []gpu.VertexAttribute{
-			{Format: gpu.VertexFormatFloat32x2, Offset: 0, ShaderLocation: 0},
-			{Format: gpu.VertexFormatFloat32x2, Offset: 8, ShaderLocation: 1},
-			{Format: gpu.VertexFormatUnorm8x4, Offset: 16, ShaderLocation: 2},
+		StepMode:    VertexStepModeVertex,
+		Attributes: []VertexAttribute{
+			{Format: VertexFormatFloat32x2, Offset: 0, ShaderLocation: 0},
+			{Format: VertexFormatFloat32x2, Offset: 8, ShaderLocation: 1},
+			{Format: VertexFormatUnorm8x4, Offset: 16, ShaderLocation: 2},
 		},
 	}}
 
-	desc := gpu.RenderPipelineDescriptor{
+	desc := PipelineDescriptor{
+		Label:          "imgui",
 		Layout:         program.pipelineLayout,
 		VertexModule:   program.vertexModule,
 		VertexEntry:    "main",
 		FragmentModule: program.fragmentModule,
 		FragmentEntry:  "main",
 		Buffers:        buffers,
-		Primitive:      gpu.PrimitiveTopologyTriangleList,
-		FrontFace:      gpu.FrontFaceCCW,
-		CullMode:       gpu.CullModeNone,
-		Targets: []gpu.ColorTargetState{{
+		Primitive:      PrimitiveTopologyTriangleList,
+		FrontFace:      FrontFaceCCW,
+		CullMode:       CullModeNone,
+		Targets: []ColorTargetState{{
 			Format:    colorFormat,
-			WriteMask: gpu.ColorWriteMaskAll,
-			Blend: &gpu.BlendState{
-				Color: gpu.BlendComponent{
-					SrcFactor: gpu.BlendFactorSrcAlpha,
-					DstFactor: gpu.BlendFactorOneMinusSrcAlpha,
-					Operation: gpu.BlendOperationAdd,
+			WriteMask: ColorWriteMaskAll,
+			Blend: &BlendState{
+				Color: BlendComponent{
+					SrcFactor: BlendFactorSrcAlpha,
+					DstFactor: BlendFactorOneMinusSrcAlpha,
+					Operation: BlendOperationAdd,
 				},
-				Alpha: gpu.BlendComponent{
-					SrcFactor: gpu.BlendFactorOne,
-					DstFactor: gpu.BlendFactorOneMinusSrcAlpha,
-					Operation: gpu.BlendOperationAdd,
+				Alpha: BlendComponent{
+					SrcFactor: BlendFactorOne,
+					DstFactor: BlendFactorOneMinusSrcAlpha,
+					Operation: BlendOperationAdd,
 				},
 			},
 		}},
 	}
 
-	ir.pipeline = renderer.device.CreateRenderPipeline(desc)
+	ir.pipeline = ir.engine.renderer.backend.CreatePipeline(desc)
 	ir.initialized = true
 }
 
@@ -70,23 +66,23 @@ func (ir *imguiRenderer) ensureBuffers(vertexBytes, indexBytes uint64) {
 	indexBytes = (indexBytes + 3) &^ 3
 
 	if ir.vertexSize < vertexBytes {
-		ir.vertexBuffer.Release()
-		ir.vertexBuffer = renderer.device.CreateBuffer(vertexBytes, gpu.BufferUsageVertex|gpu.BufferUsageCopyDst)
+		releaseHandle(ir.vertexBuffer)
+		ir.vertexBuffer = ir.engine.renderer.backend.CreateBuffer(vertexBytes, BufferUsageVertex|BufferUsageCopyDst)
 		ir.vertexSize = vertexBytes
 	}
 	if ir.indexSize < indexBytes {
-		ir.indexBuffer.Release()
-		ir.indexBuffer = renderer.device.CreateBuffer(indexBytes, gpu.BufferUsageIndex|gpu.BufferUsageCopyDst)
+		releaseHandle(ir.indexBuffer)
+		ir.indexBuffer = ir.engine.renderer.backend.CreateBuffer(indexBytes, BufferUsageIndex|BufferUsageCopyDst)
 		ir.indexSize = indexBytes
 	}
 }
 
 func (ir *imguiRenderer) draw(rp *RenderPass) {
-	if imguiSystem == nil {
+	if ir.engine.imguiSystem == nil {
 		return
 	}
 
-	drawData := imguiSystem.GetDrawData()
+	drawData := ir.engine.imguiSystem.GetDrawData()
 	if drawData == nil || drawData.CommandListCount() == 0 {
 		return
 	}
@@ -96,7 +92,7 @@ func (ir *imguiRenderer) draw(rp *RenderPass) {
 		return
 	}
 
-	ir.ensurePipeline(program, renderer.surfaceFormat)
+	ir.ensurePipeline(program, ir.engine.renderer.backend.SurfaceFormat())
 	rp.SetGPUPipeline(ir.pipeline)
 
 	listCount := drawData.CommandListCount()
@@ -124,25 +120,27 @@ func (ir *imguiRenderer) draw(rp *RenderPass) {
 		iBytesAligned := (iBytes + 3) &^ 3
 
 		// Upload vertex data at offset
-		renderer.queue.WriteBuffer(ir.vertexBuffer, vertexOffset, cmdList.VertexPointer, vBytes)
+		ir.engine.renderer.backend.WriteBuffer(ir.vertexBuffer, vertexOffset, cmdList.VertexPointer, vBytes)
 
 		// Upload index data at offset (with padding)
 		if iBytes > 0 {
 			padded := make([]byte, iBytesAligned)
 			copy(padded, unsafe.Slice((*byte)(cmdList.IndexPointer), iBytes))
-			renderer.queue.WriteBuffer(ir.indexBuffer, indexOffset, unsafe.Pointer(&padded[0]), iBytesAligned)
+			ir.engine.renderer.backend.WriteBuffer(ir.indexBuffer, indexOffset, unsafe.Pointer(&padded[0]), iBytesAligned)
 		}
 
 		// Bind this command list's region of the buffers
 		rp.SetVertexBuffer(0, ir.vertexBuffer, vertexOffset, vBytes)
-		rp.SetIndexBuffer(ir.indexBuffer, gpu.IndexFormatUint16, indexOffset, iBytesAligned)
+		rp.SetIndexBuffer(ir.indexBuffer, IndexFormatUint16, indexOffset, iBytesAligned)
+
+		surfaceWidth, surfaceHeight := ir.engine.renderer.backend.SurfaceSize()
 
 		var elemOffset uint32
 		for _, cmd := range cmdList.Commands {
 			// Scissor rect — ImGui clip rects are in point space, GPU needs pixels
-			scale := windowManager.PixelDensity()
-			vpW := int32(renderer.surfaceWidth)
-			vpH := int32(renderer.surfaceHeight)
+			scale := ir.engine.windowManager.PixelDensity()
+			vpW := int32(surfaceWidth)
+			vpH := int32(surfaceHeight)
 			cx := int32(cmd.ClipRect[0] * scale)
 			cy := int32(cmd.ClipRect[1] * scale)
 			cr := int32(cmd.ClipRect[2] * scale)
@@ -159,7 +157,7 @@ func (ir *imguiRenderer) draw(rp *RenderPass) {
 			// Bind texture
 			if cmd.TextureID != nil && len(program.bindGroupLayouts) >= 2 {
 				tex := (*Texture)(cmd.TextureID)
-				bg := renderer.device.CreateBindGroup(program.bindGroupLayouts[1], []gpu.BindGroupEntry{
+				bg := ir.engine.renderer.backend.CreateBindGroup(program.bindGroupLayouts[1], []BindGroupEntry{
 					{Binding: 0, TextureView: tex.view},
 					{Binding: 1, Sampler: tex.sampler},
 				})
@@ -132,39 +132,41 @@ type IMGUIDrawData interface {
 	GetCommandList(int) *IMGUICommandList
 }
 
-var (
-	imguiSystem IMGUISystem
-)
-
 // SetIMGUISystem is meant to be called from IMGUISystem implementations on their init method
 func SetIMGUISystem(is IMGUISystem) {
-	imguiSystem = is
+	defaultEngine.SetIMGUISystem(is)
 }
 
 // GetIMGUISystem returns the IMGUISystem, thereby exposing it to any package importing core.
 func GetIMGUISystem() IMGUISystem {
-	return imguiSystem
+	return defaultEngine.imguiSystem
 }
 
 // NewIMGUIScene returns a Scene which draws a UI. Users will want to use this to display a UI on top of
 // other scenes.
 func NewIMGUIScene(name string, inputComponent InputComponent) *Scene {
+	return defaultEngine.NewIMGUIScene(name, inputComponent)
+}
+
+// NewIMGUIScene returns a Scene which draws a UI sized to the engine's window.
+func (e *Engine) NewIMGUIScene(name string, inputComponent InputComponent) *Scene {
 	s := NewScene(name)
 	s.SetRoot(NewNode("root"))
 	s.Root().SetInputComponent(inputComponent)
 
-	size := windowManager.WindowSize()
-	sizePoints := windowManager.WindowSizePoints()
-	camera := NewCamera("MainMenuCamera", OrthographicProjection)
+	camera := newCamera(e, "MainMenuCamera", OrthographicProjection)
 	camera.SetRenderTechnique(IMGUIRenderTechnique)
 	camera.SetAutoReshape(false)
 	camera.SetClearMode(0)
-	camera.SetViewport(mgl32.Vec4{0, 0, size.X(), size.Y()})
 	camera.SetVerticalFieldOfView(60.0)
 	camera.SetClipDistance(mgl64.Vec2{0.0, 1.0})
 	camera.SetRenderOrder(0)
-	// ImGui works in point space; projection maps points to clip space
-	camera.SetProjectionMatrix(OrthoWebGPU(0, float64(sizePoints.X()), float64(sizePoints.Y()), 0, 0, 1))
+
+	// ImGui works in point space, so the camera covers the window's pixels
+	// with a projection from points, refit by the cull as the window changes
+	camera.fitWindow = true
+	camera.pointProjection = true
+	camera.Reshape(e.windowManager.WindowSize())
 
 	// set camera's scene
 	camera.SetScene(s.root)
@@ -181,11 +183,11 @@ func IMGUIRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Node)
 		camera.clearMode&ClearColor != 0,
 		camera.clearMode&ClearDepth != 0,
 	)
-	pass := renderer.BeginRenderPass(desc)
+	pass := camera.engine.renderer.BeginRenderPass(desc)
 	if pass == nil {
 		return
 	}
-	imguiPipeline, err := resourceManager.Pipeline("imgui")
+	imguiPipeline, err := camera.engine.resourceManager.Pipeline("imgui")
 	if err != nil {
 		glog.Warningf("failed to load imgui pipeline: %v", err)
 		pass.End()
@@ -193,6 +195,6 @@ func IMGUIRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Node)
 	}
 	pass.SetPipeline(imguiPipeline)
 	pass.SetCameraConstants(camera.constants.buffer)
-	imgui.draw(pass)
+	camera.engine.imgui.draw(pass)
 	pass.End()
 }
@@ -1,23 +1,269 @@
 package core
 
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Key represents a keyboard key.
 type Key int
 
 // MouseButton represents a mouse button.
 type MouseButton int
 
-// Key constants matching SDL3 scancodes — only the keys we use.
+// Key constants matching SDL3 scancodes. Scancodes are physical key positions,
+// named after the key in a US layout; use WindowManager.KeyLabel for the
+// key's label in the current layout.
 const (
-	KeyUnknown Key = -1
-	KeyA       Key = 4
-	KeyD       Key = 7
-	KeyE       Key = 8
-	KeyQ       Key = 20
-	KeyS       Key = 22
-	KeyW       Key = 26
-	KeyZ       Key = 29
-	KeyEscape  Key = 41
-	KeySpace   Key = 44
+	KeyUnknown            Key = -1
+	KeyA                  Key = 4
+	KeyB                  Key = 5
+	KeyC                  Key = 6
+	KeyD                  Key = 7
+	KeyE                  Key = 8
+	KeyF                  Key = 9
+	KeyG                  Key = 10
+	KeyH                  Key = 11
+	KeyI                  Key = 12
+	KeyJ                  Key = 13
+	KeyK                  Key = 14
+	KeyL                  Key = 15
+	KeyM                  Key = 16
+	KeyN                  Key = 17
+	KeyO                  Key = 18
+	KeyP                  Key = 19
+	KeyQ                  Key = 20
+	KeyR                  Key = 21
+	KeyS                  Key = 22
+	KeyT                  Key = 23
+	KeyU                  Key = 24
+	KeyV                  Key = 25
+	KeyW                  Key = 26
+	KeyX                  Key = 27
+	KeyY                  Key = 28
+	KeyZ                  Key = 29
+	Key1                  Key = 30
+	Key2                  Key = 31
+	Key3                  Key = 32
+	Key4                  Key = 33
+	Key5                  Key = 34
+	Key6                  Key = 35
+	Key7                  Key = 36
+	Key8                  Key = 37
+	Key9                  Key = 38
+	Key0                  Key = 39
+	KeyReturn             Key = 40
+	KeyEscape             Key = 41
+	KeyBackspace          Key = 42
+	KeyTab                Key = 43
+	KeySpace              Key = 44
+	KeyMinus              Key = 45
+	KeyEquals             Key = 46
+	KeyLeftBracket        Key = 47
+	KeyRightBracket       Key = 48
+	KeyBackslash          Key = 49
+	KeyNonUSHash          Key = 50
+	KeySemicolon          Key = 51
+	KeyApostrophe         Key = 52
+	KeyGrave              Key = 53
+	KeyComma              Key = 54
+	KeyPeriod             Key = 55
+	KeySlash              Key = 56
+	KeyCapsLock           Key = 57
+	KeyF1                 Key = 58
+	KeyF2                 Key = 59
+	KeyF3                 Key = 60
+	KeyF4                 Key = 61
+	KeyF5                 Key = 62
+	KeyF6                 Key = 63
+	KeyF7                 Key = 64
+	KeyF8                 Key = 65
+	KeyF9                 Key = 66
+	KeyF10                Key = 67
+	KeyF11                Key = 68
+	KeyF12                Key = 69
+	KeyPrintScreen        Key = 70
+	KeyScrollLock         Key = 71
+	KeyPause              Key = 72
+	KeyInsert             Key = 73
+	KeyHome               Key = 74
+	KeyPageUp             Key = 75
+	KeyDelete             Key = 76
+	KeyEnd                Key = 77
+	KeyPageDown           Key = 78
+	KeyRight              Key = 79
+	KeyLeft               Key = 80
+	KeyDown               Key = 81
+	KeyUp                 Key = 82
+	KeyNumLockClear       Key = 83
+	KeyKPDivide           Key = 84
+	KeyKPMultiply         Key = 85
+	KeyKPMinus            Key = 86
+	KeyKPPlus             Key = 87
+	KeyKPEnter            Key = 88
+	KeyKP1                Key = 89
+	KeyKP2                Key = 90
+	KeyKP3                Key = 91
+	KeyKP4                Key = 92
+	KeyKP5                Key = 93
+	KeyKP6                Key = 94
+	KeyKP7                Key = 95
+	KeyKP8                Key = 96
+	KeyKP9                Key = 97
+	KeyKP0                Key = 98
+	KeyKPPeriod           Key = 99
+	KeyNonUSBackslash     Key = 100
+	KeyApplication        Key = 101
+	KeyPower              Key = 102
+	KeyKPEquals           Key = 103
+	KeyF13                Key = 104
+	KeyF14                Key = 105
+	KeyF15                Key = 106
+	KeyF16                Key = 107
+	KeyF17                Key = 108
+	KeyF18                Key = 109
+	KeyF19                Key = 110
+	KeyF20                Key = 111
+	KeyF21                Key = 112
+	KeyF22                Key = 113
+	KeyF23                Key = 114
+	KeyF24                Key = 115
+	KeyExecute            Key = 116
+	KeyHelp               Key = 117
+	KeyMenu               Key = 118
+	KeySelect             Key = 119
+	KeyStop               Key = 120
+	KeyAgain              Key = 121
+	KeyUndo               Key = 122
+	KeyCut                Key = 123
+	KeyCopy               Key = 124
+	KeyPaste              Key = 125
+	KeyFind               Key = 126
+	KeyMute               Key = 127
+	KeyVolumeUp           Key = 128
+	KeyVolumeDown         Key = 129
+	KeyKPComma            Key = 133
+	KeyKPEqualsAS400      Key = 134
+	KeyInternational1     Key = 135
+	KeyInternational2     Key = 136
+	KeyInternational3     Key = 137
+	KeyInternational4     Key = 138
+	KeyInternational5     Key = 139
+	KeyInternational6     Key = 140
+	KeyInternational7     Key = 141
+	KeyInternational8     Key = 142
+	KeyInternational9     Key = 143
+	KeyLang1              Key = 144
+	KeyLang2              Key = 145
+	KeyLang3              Key = 146
+	KeyLang4              Key = 147
+	KeyLang5              Key = 148
+	KeyLang6              Key = 149
+	KeyLang7              Key = 150
+	KeyLang8              Key = 151
+	KeyLang9              Key = 152
+	KeyAltErase           Key = 153
+	KeySysReq             Key = 154
+	KeyCancel             Key = 155
+	KeyClear              Key = 156
+	KeyPrior              Key = 157
+	KeyReturn2            Key = 158
+	KeySeparator          Key = 159
+	KeyOut                Key = 160
+	KeyOper               Key = 161
+	KeyClearAgain         Key = 162
+	KeyCrSel              Key = 163
+	KeyExSel              Key = 164
+	KeyKP00               Key = 176
+	KeyKP000              Key = 177
+	KeyThousandsSeparator Key = 178
+	KeyDecimalSeparator   Key = 179
+	KeyCurrencyUnit       Key = 180
+	KeyCurrencySubunit    Key = 181
+	KeyKPLeftParen        Key = 182
+	KeyKPRightParen       Key = 183
+	KeyKPLeftBrace        Key = 184
+	KeyKPRightBrace       Key = 185
+	KeyKPTab              Key = 186
+	KeyKPBackspace        Key = 187
+	KeyKPA                Key = 188
+	KeyKPB                Key = 189
+	KeyKPC                Key = 190
+	KeyKPD                Key = 191
+	KeyKPE                Key = 192
+	KeyKPF                Key = 193
+	KeyKPXOR              Key = 194
+	KeyKPPower            Key = 195
+	KeyKPPercent          Key = 196
+	KeyKPLess             Key = 197
+	KeyKPGreater          Key = 198
+	KeyKPAmpersand        Key = 199
+	KeyKPDblAmpersand     Key = 200
+	KeyKPVerticalBar      Key = 201
+	KeyKPDblVerticalBar   Key = 202
+	KeyKPColon            Key = 203
+	KeyKPHash             Key = 204
+	KeyKPSpace            Key = 205
+	KeyKPAt               Key = 206
+	KeyKPExclam           Key = 207
+	KeyKPMemStore         Key = 208
+	KeyKPMemRecall        Key = 209
+	KeyKPMemClear         Key = 210
+	KeyKPMemAdd           Key = 211
+	KeyKPMemSubtract      Key = 212
+	KeyKPMemMultiply      Key = 213
+	KeyKPMemDivide        Key = 214
+	KeyKPPlusMinus        Key = 215
+	KeyKPClear            Key = 216
+	KeyKPClearEntry       Key = 217
+	KeyKPBinary           Key = 218
+	KeyKPOctal            Key = 219
+	KeyKPDecimal          Key = 220
+	KeyKPHexadecimal      Key = 221
+	KeyLeftControl        Key = 224
+	KeyLeftShift          Key = 225
+	KeyLeftAlt            Key = 226
+	KeyLeftSuper          Key = 227
+	KeyRightControl       Key = 228
+	KeyRightShift         Key = 229
+	KeyRightAlt           Key = 230
+	KeyRightSuper         Key = 231
+	KeyMode               Key = 257
+	KeySleep              Key = 258
+	KeyWake               Key = 259
+	KeyChannelIncrement   Key = 260
+	KeyChannelDecrement   Key = 261
+	KeyMediaPlay          Key = 262
+	KeyMediaPause         Key = 263
+	KeyMediaRecord        Key = 264
+	KeyMediaFastForward   Key = 265
+	KeyMediaRewind        Key = 266
+	KeyMediaNextTrack     Key = 267
+	KeyMediaPreviousTrack Key = 268
+	KeyMediaStop          Key = 269
+	KeyMediaEject         Key = 270
+	KeyMediaPlayPause     Key = 271
+	KeyMediaSelect        Key = 272
+	KeyACNew              Key = 273
+	KeyACOpen             Key = 274
+	KeyACClose            Key = 275
+	KeyACExit             Key = 276
+	KeyACSave             Key = 277
+	KeyACPrint            Key = 278
+	KeyACProperties       Key = 279
+	KeyACSearch           Key = 280
+	KeyACHome             Key = 281
+	KeyACBack             Key = 282
+	KeyACForward          Key = 283
+	KeyACStop             Key = 284
+	KeyACRefresh          Key = 285
+	KeyACBookmarks        Key = 286
+	KeySoftLeft           Key = 287
+	KeySoftRight          Key = 288
+	KeyCall               Key = 289
+	KeyEndCall            Key = 290
 )
 
 // Mouse button constants matching SDL3.
@@ -26,3 +272,372 @@ const (
 	MouseButton2 MouseButton = 2 // middle
 	MouseButton3 MouseButton = 3 // right
 )
+
+// KeyMod is a set of held modifier keys and enabled lock keys.
+type KeyMod uint16
+
+// Key modifier constants matching SDL3.
+const (
+	KeyModLeftShift    KeyMod = 0x0001
+	KeyModRightShift   KeyMod = 0x0002
+	KeyModLeftControl  KeyMod = 0x0040
+	KeyModRightControl KeyMod = 0x0080
+	KeyModLeftAlt      KeyMod = 0x0100
+	KeyModRightAlt     KeyMod = 0x0200
+	KeyModLeftSuper    KeyMod = 0x0400
+	KeyModRightSuper   KeyMod = 0x0800
+	KeyModNumLock      KeyMod = 0x1000
+	KeyModCapsLock     KeyMod = 0x2000
+	KeyModMode         KeyMod = 0x4000
+	KeyModScrollLock   KeyMod = 0x8000
+)
+
+// keyModKeys are the keys each modifier stands for in KeyState.Mods.
+var keyModKeys = []struct {
+	mod KeyMod
+	key Key
+}{
+	{KeyModLeftShift, KeyLeftShift},
+	{KeyModRightShift, KeyRightShift},
+	{KeyModLeftControl, KeyLeftControl},
+	{KeyModRightControl, KeyRightControl},
+	{KeyModLeftAlt, KeyLeftAlt},
+	{KeyModRightAlt, KeyRightAlt},
+	{KeyModLeftSuper, KeyLeftSuper},
+	{KeyModRightSuper, KeyRightSuper},
+	{KeyModNumLock, KeyNumLockClear},
+	{KeyModCapsLock, KeyCapsLock},
+	{KeyModMode, KeyMode},
+	{KeyModScrollLock, KeyScrollLock},
+}
+
+// keyNames are the names keys have in input map files.
+var keyNames = map[Key]string{
+	KeyA:                  "a",
+	KeyB:                  "b",
+	KeyC:                  "c",
+	KeyD:                  "d",
+	KeyE:                  "e",
+	KeyF:                  "f",
+	KeyG:                  "g",
+	KeyH:                  "h",
+	KeyI:                  "i",
+	KeyJ:                  "j",
+	KeyK:                  "k",
+	KeyL:                  "l",
+	KeyM:                  "m",
+	KeyN:                  "n",
+	KeyO:                  "o",
+	KeyP:                  "p",
+	KeyQ:                  "q",
+	KeyR:                  "r",
+	KeyS:                  "s",
+	KeyT:                  "t",
+	KeyU:                  "u",
+	KeyV:                  "v",
+	KeyW:                  "w",
+	KeyX:                  "x",
+	KeyY:                  "y",
+	KeyZ:                  "z",
+	Key1:                  "1",
+	Key2:                  "2",
+	Key3:                  "3",
+	Key4:                  "4",
+	Key5:                  "5",
+	Key6:                  "6",
+	Key7:                  "7",
+	Key8:                  "8",
+	Key9:                  "9",
+	Key0:                  "0",
+	KeyReturn:             "return",
+	KeyEscape:             "escape",
+	KeyBackspace:          "backspace",
+	KeyTab:                "tab",
+	KeySpace:              "space",
+	KeyMinus:              "minus",
+	KeyEquals:             "equals",
+	KeyLeftBracket:        "left_bracket",
+	KeyRightBracket:       "right_bracket",
+	KeyBackslash:          "backslash",
+	KeyNonUSHash:          "non_us_hash",
+	KeySemicolon:          "semicolon",
+	KeyApostrophe:         "apostrophe",
+	KeyGrave:              "grave",
+	KeyComma:              "comma",
+	KeyPeriod:             "period",
+	KeySlash:              "slash",
+	KeyCapsLock:           "caps_lock",
+	KeyF1:                 "f1",
+	KeyF2:                 "f2",
+	KeyF3:                 "f3",
+	KeyF4:                 "f4",
+	KeyF5:                 "f5",
+	KeyF6:                 "f6",
+	KeyF7:                 "f7",
+	KeyF8:                 "f8",
+	KeyF9:                 "f9",
+	KeyF10:                "f10",
+	KeyF11:                "f11",
+	KeyF12:                "f12",
+	KeyPrintScreen:        "print_screen",
+	KeyScrollLock:         "scroll_lock",
+	KeyPause:              "pause",
+	KeyInsert:             "insert",
+	KeyHome:               "home",
+	KeyPageUp:             "page_up",
+	KeyDelete:             "delete",
+	KeyEnd:                "end",
+	KeyPageDown:           "page_down",
+	KeyRight:              "right",
+	KeyLeft:               "left",
+	KeyDown:               "down",
+	KeyUp:                 "up",
+	KeyNumLockClear:       "num_lock",
+	KeyKPDivide:           "kp_divide",
+	KeyKPMultiply:         "kp_multiply",
+	KeyKPMinus:            "kp_minus",
+	KeyKPPlus:             "kp_plus",
+	KeyKPEnter:            "kp_enter",
+	KeyKP1:                "kp_1",
+	KeyKP2:                "kp_2",
+	KeyKP3:                "kp_3",
+	KeyKP4:                "kp_4",
+	KeyKP5:                "kp_5",
+	KeyKP6:                "kp_6",
+	KeyKP7:                "kp_7",
+	KeyKP8:                "kp_8",
+	KeyKP9:                "kp_9",
+	KeyKP0:                "kp_0",
+	KeyKPPeriod:           "kp_period",
+	KeyNonUSBackslash:     "non_us_backslash",
+	KeyApplication:        "application",
+	KeyPower:              "power",
+	KeyKPEquals:           "kp_equals",
+	KeyF13:                "f13",
+	KeyF14:                "f14",
+	KeyF15:                "f15",
+	KeyF16:                "f16",
+	KeyF17:                "f17",
+	KeyF18:                "f18",
+	KeyF19:                "f19",
+	KeyF20:                "f20",
+	KeyF21:                "f21",
+	KeyF22:                "f22",
+	KeyF23:                "f23",
+	KeyF24:                "f24",
+	KeyExecute:            "execute",
+	KeyHelp:               "help",
+	KeyMenu:               "menu",
+	KeySelect:             "select",
+	KeyStop:               "stop",
+	KeyAgain:              "again",
+	KeyUndo:               "undo",
+	KeyCut:                "cut",
+	KeyCopy:               "copy",
+	KeyPaste:              "paste",
+	KeyFind:               "find",
+	KeyMute:               "mute",
+	KeyVolumeUp:           "volume_up",
+	KeyVolumeDown:         "volume_down",
+	KeyKPComma:            "kp_comma",
+	KeyKPEqualsAS400:      "kp_equals_as400",
+	KeyInternational1:     "international1",
+	KeyInternational2:     "international2",
+	KeyInternational3:     "international3",
+	KeyInternational4:     "international4",
+	KeyInternational5:     "international5",
+	KeyInternational6:     "international6",
+	KeyInternational7:     "international7",
+	KeyInternational8:     "international8",
+	KeyInternational9:     "international9",
+	KeyLang1:              "lang1",
+	KeyLang2:              "lang2",
+	KeyLang3:              "lang3",
+	KeyLang4:              "lang4",
+	KeyLang5:              "lang5",
+	KeyLang6:              "lang6",
+	KeyLang7:              "lang7",
+	KeyLang8:              "lang8",
+	KeyLang9:              "lang9",
+	KeyAltErase:           "alt_erase",
+	KeySysReq:             "sys_req",
+	KeyCancel:             "cancel",
+	KeyClear:              "clear",
+	KeyPrior:              "prior",
+	KeyReturn2:            "return2",
+	KeySeparator:          "separator",
+	KeyOut:                "out",
+	KeyOper:               "oper",
+	KeyClearAgain:         "clear_again",
+	KeyCrSel:              "crsel",
+	KeyExSel:              "exsel",
+	KeyKP00:               "kp_00",
+	KeyKP000:              "kp_000",
+	KeyThousandsSeparator: "thousands_separator",
+	KeyDecimalSeparator:   "decimal_separator",
+	KeyCurrencyUnit:       "currency_unit",
+	KeyCurrencySubunit:    "currency_subunit",
+	KeyKPLeftParen:        "kp_left_paren",
+	KeyKPRightParen:       "kp_right_paren",
+	KeyKPLeftBrace:        "kp_left_brace",
+	KeyKPRightBrace:       "kp_right_brace",
+	KeyKPTab:              "kp_tab",
+	KeyKPBackspace:        "kp_backspace",
+	KeyKPA:                "kp_a",
+	KeyKPB:                "kp_b",
+	KeyKPC:                "kp_c",
+	KeyKPD:                "kp_d",
+	KeyKPE:                "kp_e",
+	KeyKPF:                "kp_f",
+	KeyKPXOR:              "kp_xor",
+	KeyKPPower:            "kp_power",
+	KeyKPPercent:          "kp_percent",
+	KeyKPLess:             "kp_less",
+	KeyKPGreater:          "kp_greater",
+	KeyKPAmpersand:        "kp_ampersand",
+	KeyKPDblAmpersand:     "kp_dbl_ampersand",
+	KeyKPVerticalBar:      "kp_vertical_bar",
+	KeyKPDblVerticalBar:   "kp_dbl_vertical_bar",
+	KeyKPColon:            "kp_colon",
+	KeyKPHash:             "kp_hash",
+	KeyKPSpace:            "kp_space",
+	KeyKPAt:               "kp_at",
+	KeyKPExclam:           "kp_exclam",
+	KeyKPMemStore:         "kp_mem_store",
+	KeyKPMemRecall:        "kp_mem_recall",
+	KeyKPMemClear:         "kp_mem_clear",
+	KeyKPMemAdd:           "kp_mem_add",
+	KeyKPMemSubtract:      "kp_mem_subtract",
+	KeyKPMemMultiply:      "kp_mem_multiply",
+	KeyKPMemDivide:        "kp_mem_divide",
+	KeyKPPlusMinus:        "kp_plus_minus",
+	KeyKPClear:            "kp_clear",
+	KeyKPClearEntry:       "kp_clear_entry",
+	KeyKPBinary:           "kp_binary",
+	KeyKPOctal:            "kp_octal",
+	KeyKPDecimal:          "kp_decimal",
+	KeyKPHexadecimal:      "kp_hexadecimal",
+	KeyLeftControl:        "left_ctrl",
+	KeyLeftShift:          "left_shift",
+	KeyLeftAlt:            "left_alt",
+	KeyLeftSuper:          "left_super",
+	KeyRightControl:       "right_ctrl",
+	KeyRightShift:         "right_shift",
+	KeyRightAlt:           "right_alt",
+	KeyRightSuper:         "right_super",
+	KeyMode:               "mode",
+	KeySleep:              "sleep",
+	KeyWake:               "wake",
+	KeyChannelIncrement:   "channel_up",
+	KeyChannelDecrement:   "channel_down",
+	KeyMediaPlay:          "media_play",
+	KeyMediaPause:         "media_pause",
+	KeyMediaRecord:        "media_record",
+	KeyMediaFastForward:   "media_fast_forward",
+	KeyMediaRewind:        "media_rewind",
+	KeyMediaNextTrack:     "media_next_track",
+	KeyMediaPreviousTrack: "media_previous_track",
+	KeyMediaStop:          "media_stop",
+	KeyMediaEject:         "media_eject",
+	KeyMediaPlayPause:     "media_play_pause",
+	KeyMediaSelect:        "media_select",
+	KeyACNew:              "ac_new",
+	KeyACOpen:             "ac_open",
+	KeyACClose:            "ac_close",
+	KeyACExit:             "ac_exit",
+	KeyACSave:             "ac_save",
+	KeyACPrint:            "ac_print",
+	KeyACProperties:       "ac_properties",
+	KeyACSearch:           "ac_search",
+	KeyACHome:             "ac_home",
+	KeyACBack:             "ac_back",
+	KeyACForward:          "ac_forward",
+	KeyACStop:             "ac_stop",
+	KeyACRefresh:          "ac_refresh",
+	KeyACBookmarks:        "ac_bookmarks",
+	KeySoftLeft:           "soft_left",
+	KeySoftRight:          "soft_right",
+	KeyCall:               "call",
+	KeyEndCall:            "end_call",
+}
+
+// mouseButtonNames are the names mouse buttons have in input map files.
+var mouseButtonNames = map[MouseButton]string{
+	MouseButton1: "left",
+	MouseButton2: "middle",
+	MouseButton3: "right",
+}
+
+// String returns the key's name, or its scancode for unnamed keys.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return strconv.Itoa(int(k))
+}
+
+// KeyFromName returns the key with the given name. Scancodes are accepted
+// for keys without a name.
+func KeyFromName(name string) (Key, error) {
+	for k, n := range keyNames {
+		if n == name {
+			return k, nil
+		}
+	}
+	if code, err := strconv.Atoi(name); err == nil && code >= 0 {
+		return Key(code), nil
+	}
+	return KeyUnknown, fmt.Errorf("unknown key %q", name)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (k Key) MarshalYAML() (interface{}, error) {
+	return k.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (k *Key) UnmarshalYAML(value *yaml.Node) error {
+	key, err := KeyFromName(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*k = key
+	return nil
+}
+
+// String returns the mouse button's name, or its number for unnamed buttons.
+func (b MouseButton) String() string {
+	if name, ok := mouseButtonNames[b]; ok {
+		return name
+	}
+	return strconv.Itoa(int(b))
+}
+
+// MouseButtonFromName returns the mouse button with the given name. Button
+// numbers are accepted for buttons without a name.
+func MouseButtonFromName(name string) (MouseButton, error) {
+	for b, n := range mouseButtonNames {
+		if n == name {
+			return b, nil
+		}
+	}
+	if code, err := strconv.Atoi(name); err == nil && code > 0 {
+		return MouseButton(code), nil
+	}
+	return 0, fmt.Errorf("unknown mouse button %q", name)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (b MouseButton) MarshalYAML() (interface{}, error) {
+	return b.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (b *MouseButton) UnmarshalYAML(value *yaml.Node) error {
+	button, err := MouseButtonFromName(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*b = button
+	return nil
+}
@@ -0,0 +1,101 @@
+package core
+
+// InputCapture is a set of input devices a scene captures from the scenes
+// below it on the stack.
+type InputCapture int
+
+// Input captures
+const (
+	InputCaptureKeyboard InputCapture = 1 << iota
+	InputCaptureMouse
+	InputCaptureGamepad
+
+	InputCaptureNone InputCapture = 0
+	InputCaptureAll               = InputCaptureKeyboard | InputCaptureMouse | InputCaptureGamepad
+)
+
+// has returns whether a device is in the set.
+func (c InputCapture) has(device InputDevice) bool {
+	return c&(1<<device) != 0
+}
+
+// SetInputCapture sets the devices the scene always captures: the scenes
+// below it see no input from them. Scenes can also capture devices for a
+// single frame with InputManager.Capture.
+func (s *Scene) SetInputCapture(c InputCapture) {
+	s.inputCapture = c
+}
+
+// InputCapture returns the devices the scene always captures.
+func (s *Scene) InputCapture() InputCapture {
+	return s.inputCapture
+}
+
+// SetInputModal sets whether the scene captures all devices, so the scenes
+// below it get no input at all.
+func (s *Scene) SetInputModal(modal bool) {
+	if modal {
+		s.inputCapture = InputCaptureAll
+	} else {
+		s.inputCapture = InputCaptureNone
+	}
+}
+
+// InputModal returns whether the scene captures all devices.
+func (s *Scene) InputModal() bool {
+	return s.inputCapture == InputCaptureAll
+}
+
+// Capture captures devices for the rest of the frame's input processing. The
+// devices' events are consumed, and State, actions and axes show no input from
+// them to the input components which run afterwards, eg: the scenes below the
+// current one. Input components of a UI call this while the UI wants the mouse
+// or keyboard.
+func (i *InputManager) Capture(c InputCapture) {
+	added := c &^ i.captured
+	if added == InputCaptureNone {
+		return
+	}
+	i.captured |= added
+
+	for _, e := range i.events {
+		if added.has(e.Type.Device()) {
+			e.Consume()
+		}
+	}
+	i.masked = i.maskedState()
+}
+
+// Captured returns the devices captured so far this frame.
+func (i *InputManager) Captured() InputCapture {
+	return i.captured
+}
+
+// releaseCapture ends the frame's captures once all scenes processed input.
+func (i *InputManager) releaseCapture() {
+	i.captured = InputCaptureNone
+	i.masked = InputState{}
+}
+
+// maskedState returns a copy of the input state without the captured devices.
+func (i *InputManager) maskedState() InputState {
+	s := i.state
+	if i.captured.has(InputDeviceKeyboard) {
+		s.Keys = KeyState{
+			Mods:     make(map[Key]bool),
+			Active:   make(map[Key]bool),
+			Released: make(map[Key]bool),
+		}
+		s.Text = ""
+	}
+	if i.captured.has(InputDeviceMouse) {
+		s.Mouse = MouseState{
+			Position: MousePositionState{X: s.Mouse.Position.X, Y: s.Mouse.Position.Y},
+			Buttons:  MouseButtonState{Active: make(map[MouseButton]bool)},
+		}
+	}
+	if i.captured.has(InputDeviceGamepad) {
+		s.Gamepads = make(map[GamepadID]*GamepadState)
+	}
+	return s
+}
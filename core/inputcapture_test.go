@@ -0,0 +1,100 @@
+package core
+
+import (
+	"testing"
+)
+
+// inputProbe is an input component which notes the input it sees and then
+// captures devices.
+type inputProbe struct {
+	im      *InputManager
+	capture InputCapture
+	jump    bool
+	lookX   float64
+	space   bool
+	text    string
+	pads    int
+}
+
+func (p *inputProbe) Run(node *Node) []NodeCommand {
+	im := p.im
+	p.jump = im.Action("jump")
+	p.lookX = im.Axis("look_x")
+	p.space = im.State().Keys.Active[KeySpace]
+	p.text = im.State().Text
+	p.pads = len(im.Gamepads())
+	im.Capture(p.capture)
+	return nil
+}
+
+// newProbeScene returns a scene whose root runs a probe.
+func newProbeScene(e *Engine, name string) (*Scene, *inputProbe) {
+	probe := &inputProbe{im: e.InputManager()}
+	scene := NewScene(name)
+	scene.SetRoot(NewNode(name))
+	scene.Root().SetInputComponent(probe)
+	return scene, probe
+}
+
+func TestSceneInputCapture(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+	sm := e.SceneManager()
+	im.InputMap().Bind("jump", InputBinding{Key: KeySpace})
+
+	back, backProbe := newProbeScene(e, "back")
+	front, frontProbe := newProbeScene(e, "front")
+	sm.PushScene(back)
+	sm.PushScene(front)
+
+	frame := func() {
+		im.reset()
+		im.HandleKeyEvent(KeySpace, true)
+		im.HandleTextInput(" ")
+		im.HandleMouseMove(10, 10, 3, 0)
+		sm.advance(1.0 / 60.0)
+	}
+
+	im.HandleGamepadAdded(1, GamepadInfo{})
+
+	// without captures the scenes below get the same input
+	frame()
+	if !backProbe.jump || backProbe.lookX != 3 || !backProbe.space || backProbe.text != " " || backProbe.pads != 1 {
+		t.Errorf("back scene saw %+v, want all input", backProbe)
+	}
+
+	// a UI capturing the mouse for a frame
+	frontProbe.capture = InputCaptureMouse
+	frame()
+	if !frontProbe.jump || frontProbe.lookX != 3 {
+		t.Errorf("front scene saw %+v, want all input", frontProbe)
+	}
+	if !backProbe.jump || backProbe.lookX != 0 || backProbe.pads != 1 {
+		t.Errorf("back scene saw %+v, want the keyboard and gamepads only", backProbe)
+	}
+	if im.Captured() != InputCaptureNone || im.State().Mouse.Position.DistX != 3 {
+		t.Error("capture outlived the scene updates")
+	}
+
+	// modal scenes capture everything
+	frontProbe.capture = InputCaptureNone
+	front.SetInputModal(true)
+	frame()
+	if backProbe.jump || backProbe.lookX != 0 || backProbe.space || backProbe.text != "" || backProbe.pads != 0 {
+		t.Errorf("back scene saw %+v under a modal scene, want nothing", backProbe)
+	}
+
+	// keys pressed under the modal scene stay hidden until released
+	front.SetInputModal(false)
+	im.reset()
+	sm.advance(1.0 / 60.0)
+	if backProbe.jump || !backProbe.space {
+		t.Errorf("back scene saw %+v, want space held but not jumping", backProbe)
+	}
+	im.reset()
+	im.HandleKeyEvent(KeySpace, false)
+	frame()
+	if !backProbe.jump {
+		t.Error("back scene doesn't jump once space is pressed again")
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/json"
 	"math"
 
 	"github.com/go-gl/mathgl/mgl64"
@@ -37,7 +38,8 @@ type MouseState struct {
 	Buttons  MouseButtonState
 }
 
-// KeyState holds key input state.
+// KeyState holds key input state. Mods holds the held modifier keys and the
+// enabled lock keys, eg: KeyCapsLock.
 type KeyState struct {
 	Valid    bool
 	Mods     map[Key]bool
@@ -45,10 +47,33 @@ type KeyState struct {
 	Released map[Key]bool
 }
 
-// InputState wraps mouse and keys input state.
+// Shift returns whether either shift key is held.
+func (k *KeyState) Shift() bool {
+	return k.Mods[KeyLeftShift] || k.Mods[KeyRightShift]
+}
+
+// Control returns whether either control key is held.
+func (k *KeyState) Control() bool {
+	return k.Mods[KeyLeftControl] || k.Mods[KeyRightControl]
+}
+
+// Alt returns whether either alt key is held.
+func (k *KeyState) Alt() bool {
+	return k.Mods[KeyLeftAlt] || k.Mods[KeyRightAlt]
+}
+
+// Super returns whether either super key is held.
+func (k *KeyState) Super() bool {
+	return k.Mods[KeyLeftSuper] || k.Mods[KeyRightSuper]
+}
+
+// InputState wraps mouse, keys and gamepads input state. Text holds the UTF-8
+// text typed this frame while text input is started on the WindowManager.
 type InputState struct {
-	Mouse MouseState
-	Keys  KeyState
+	Mouse    MouseState
+	Keys     KeyState
+	Text     string
+	Gamepads map[GamepadID]*GamepadState
 }
 
 // SetMouseValid sets the mouse state as valid. It will not be processed unless this is set.
@@ -63,7 +88,31 @@ func (i *InputState) SetKeysValid(valid bool) {
 
 // InputManager wraps global input state.
 type InputManager struct {
-	state InputState
+	engine *Engine
+	state  InputState
+	mods   KeyMod
+
+	// events handled this frame, the time stamped on them, and the keys and
+	// buttons held since a consumed press
+	events    []*QueuedInputEvent
+	eventTime float64
+	hidden    map[inputButton]bool
+
+	// devices captured by the scenes updated so far, and the state the
+	// scenes below them see
+	captured InputCapture
+	masked   InputState
+
+	// named actions and axes, and whether each action was held last frame
+	inputMap      *InputMap
+	actionsBefore map[string]bool
+
+	// recording being written, its frame count and the events handled this
+	// frame, and recording being played back
+	recorder       *json.Encoder
+	recordedFrames int
+	recorded       []InputEvent
+	replay         *inputReplay
 }
 
 // InputComponent is an interface which returns NodeCommands from nodes.
@@ -71,32 +120,83 @@ type InputComponent interface {
 	Run(node *Node) []NodeCommand
 }
 
-var (
-	inputManager *InputManager
-)
-
-func init() {
-	inputManager = &InputManager{}
-	inputManager.state.Keys.Active = make(map[Key]bool)
-	inputManager.state.Keys.Released = make(map[Key]bool)
-	inputManager.state.Mouse.Buttons.Active = make(map[MouseButton]bool)
+func newInputManager(e *Engine) *InputManager {
+	i := &InputManager{
+		engine:        e,
+		inputMap:      DefaultInputMap(),
+		actionsBefore: make(map[string]bool),
+		hidden:        make(map[inputButton]bool),
+	}
+	i.state.Keys.Mods = make(map[Key]bool)
+	i.state.Keys.Active = make(map[Key]bool)
+	i.state.Keys.Released = make(map[Key]bool)
+	i.state.Mouse.Buttons.Active = make(map[MouseButton]bool)
+	i.state.Gamepads = make(map[GamepadID]*GamepadState)
+	return i
 }
 
-// GetInputManager returns the manager.
+// GetInputManager returns the default engine's input manager.
 func GetInputManager() *InputManager {
-	return inputManager
+	return defaultEngine.inputManager
 }
 
-// State returns the manager's input state.
+// State returns the manager's input state, without the devices captured by
+// the scenes updated before the caller's.
 func (i *InputManager) State() *InputState {
+	if i.captured != InputCaptureNone {
+		return &i.masked
+	}
 	return &i.state
 }
 
+// InputMap returns the manager's action and axis bindings.
+func (i *InputManager) InputMap() *InputMap {
+	return i.inputMap
+}
+
+// SetInputMap replaces the manager's action and axis bindings.
+func (i *InputManager) SetInputMap(m *InputMap) {
+	i.inputMap = m
+	clear(i.actionsBefore)
+}
+
+// Action returns whether any input bound to an action is held.
+func (i *InputManager) Action(name string) bool {
+	return i.inputMap.active(name, i)
+}
+
+// ActionPressed returns whether an action started being held this frame.
+func (i *InputManager) ActionPressed(name string) bool {
+	return i.Action(name) && !i.actionsBefore[name]
+}
+
+// ActionReleased returns whether an action stopped being held this frame, or
+// a key bound to it was released.
+func (i *InputManager) ActionReleased(name string) bool {
+	return (!i.Action(name) && i.actionsBefore[name]) || i.inputMap.released(name, i)
+}
+
+// Axis returns the sum of the values of the inputs bound to an axis.
+func (i *InputManager) Axis(name string) float64 {
+	return i.inputMap.axis(name, i)
+}
+
 // reset resets all input state and marks substates as invalid.
 func (i *InputManager) reset() {
+	clear(i.actionsBefore)
+	for name := range i.inputMap.Actions {
+		i.actionsBefore[name] = i.Action(name)
+	}
+	i.releaseCapture()
+	i.clearEvents()
+	i.eventTime = i.engine.timerManager.FrameStartTime()
+
 	for j := range i.state.Keys.Released {
 		i.state.Keys.Released[j] = false
 	}
+	for _, g := range i.state.Gamepads {
+		clear(g.Released)
+	}
 
 	i.state.Mouse.Valid = false
 	i.state.Mouse.Position.Valid = false
@@ -104,10 +204,16 @@ func (i *InputManager) reset() {
 	i.state.Mouse.Position.DistX = 0.0
 	i.state.Mouse.Position.DistY = 0.0
 	i.state.Mouse.Scroll = MouseScrollState{false, 0.0, 0.0}
+	i.state.Text = ""
 }
 
 // HandleKeyEvent is called by the window system to register key events.
 func (i *InputManager) HandleKeyEvent(key Key, pressed bool) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventKey, Key: key, Pressed: pressed})
+
 	i.state.Keys.Valid = true
 
 	if pressed {
@@ -119,16 +225,54 @@ func (i *InputManager) HandleKeyEvent(key Key, pressed bool) {
 	}
 }
 
+// HandleKeyMods is called by the window system to register the modifier state
+// along with key events.
+func (i *InputManager) HandleKeyMods(mods KeyMod) {
+	if !i.live() || mods == i.mods {
+		return
+	}
+	i.record(InputEvent{Type: InputEventKeyMods, Mods: mods})
+
+	i.mods = mods
+	for _, m := range keyModKeys {
+		i.state.Keys.Mods[m.key] = mods&m.mod != 0
+	}
+}
+
+// HandleTextInput is called by the window system to register typed text.
+func (i *InputManager) HandleTextInput(text string) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventText, Text: text})
+
+	i.state.Keys.Valid = true
+	i.state.Text += text
+}
+
 // HandleMouseButton is called by the window system to register mouse button events.
 func (i *InputManager) HandleMouseButton(button MouseButton, pressed bool) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventMouseButton, Button: button, Pressed: pressed})
+
 	i.state.Mouse.Buttons.Valid = true
 	i.state.Mouse.Buttons.Active[button] = pressed
 }
 
 // HandleMouseScroll is called by the window system to register mouse scroll events.
 func (i *InputManager) HandleMouseScroll(x, y float64) {
-	if GetPlatform() == PlatformLinux || GetPlatform() == PlatformWindows {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventMouseScroll, X: x, Y: y})
+
+	if scrollFlipped(GetPlatform()) {
 		y = -y
+		// the queue has the scroll direction of the state, the recording the
+		// window system's
+		i.events[len(i.events)-1].Y = y
 	}
 
 	i.state.Mouse.Valid = true
@@ -139,16 +283,22 @@ func (i *InputManager) HandleMouseScroll(x, y float64) {
 
 // HandleMouseMove is called by the window system to register mouse move events.
 func (i *InputManager) HandleMouseMove(x, y, relX, relY float64) {
+	if !i.live() {
+		return
+	}
+	i.record(InputEvent{Type: InputEventMouseMove, X: x, Y: y, RelX: relX, RelY: relY})
+
 	i.state.Mouse.Valid = true
 	i.state.Mouse.Position.Valid = true
 
 	i.state.Mouse.Position.X = x
 	i.state.Mouse.Position.Y = y
-	i.state.Mouse.Position.DistX = relX
-	i.state.Mouse.Position.DistY = relY
+	i.state.Mouse.Position.DistX += relX
+	i.state.Mouse.Position.DistY += relY
 
-	windowManager.cursorPosition = mgl64.Vec2{
-		math.Min(math.Max(0.0, windowManager.cursorPosition.X()+relX), float64(windowManager.cfg.Width)),
-		math.Min(math.Max(0.0, windowManager.cursorPosition.Y()+relY), float64(windowManager.cfg.Height)),
+	w := i.engine.windowManager
+	w.cursorPosition = mgl64.Vec2{
+		math.Min(math.Max(0.0, w.cursorPosition.X()+relX), float64(w.cfg.Width)),
+		math.Min(math.Max(0.0, w.cursorPosition.Y()+relY), float64(w.cfg.Height)),
 	}
 }
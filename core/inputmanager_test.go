@@ -0,0 +1,173 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeyNames(t *testing.T) {
+	tests := map[Key]string{
+		KeyA:            "a",
+		Key0:            "0",
+		KeyReturn:       "return",
+		KeyF24:          "f24",
+		KeyKPEnter:      "kp_enter",
+		KeyRightControl: "right_ctrl",
+		KeyEndCall:      "end_call",
+	}
+	for key, name := range tests {
+		if got := key.String(); got != name {
+			t.Errorf("Key(%d).String() = %q, want %q", int(key), got, name)
+		}
+		if got, err := KeyFromName(name); err != nil || got != key {
+			t.Errorf("KeyFromName(%q) = %v, %v, want %v", name, got, err, key)
+		}
+	}
+	if got := Key(130).String(); got != "130" {
+		t.Errorf("unnamed key = %q, want its scancode", got)
+	}
+}
+
+func TestInputManagerKeyMods(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+	keys := &im.State().Keys
+
+	im.HandleKeyMods(KeyModLeftShift | KeyModRightControl | KeyModCapsLock)
+	if !keys.Shift() || !keys.Control() || keys.Alt() || keys.Super() {
+		t.Errorf("modifiers = %v, want shift and control", keys.Mods)
+	}
+	if !keys.Mods[KeyCapsLock] || keys.Mods[KeyNumLockClear] {
+		t.Errorf("lock keys = %v, want caps lock", keys.Mods)
+	}
+
+	// modifiers outlive the frame
+	im.reset()
+	im.HandleKeyMods(KeyModRightAlt | KeyModCapsLock)
+	if keys.Shift() || keys.Control() || !keys.Alt() || !keys.Mods[KeyCapsLock] {
+		t.Errorf("modifiers = %v, want alt and caps lock", keys.Mods)
+	}
+}
+
+func TestInputManagerTextInput(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+
+	im.HandleTextInput("h")
+	im.HandleTextInput("é€")
+	if got := im.State().Text; got != "hé€" {
+		t.Errorf("text = %q, want %q", got, "hé€")
+	}
+	if !im.State().Keys.Valid {
+		t.Error("text input didn't mark the keys valid")
+	}
+
+	im.reset()
+	if got := im.State().Text; got != "" {
+		t.Errorf("text = %q after reset, want none", got)
+	}
+
+	wm := e.WindowManager()
+	wm.backend = &headlessWindow{}
+	wm.StartTextInput()
+	if !wm.TextInputActive() {
+		t.Error("text input not active after StartTextInput")
+	}
+	wm.StopTextInput()
+	if wm.TextInputActive() {
+		t.Error("text input active after StopTextInput")
+	}
+	if got := wm.KeyLabel(KeyKPMinus); got != "kp_minus" {
+		t.Errorf("headless key label = %q, want the key's name", got)
+	}
+}
+
+func TestInputRecordingKeyModsAndText(t *testing.T) {
+	var recording bytes.Buffer
+	im := newTestEngine(t).InputManager()
+	im.HandleKeyMods(KeyModLeftShift)
+	if err := im.StartRecording(&recording); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+	im.HandleKeyMods(KeyModLeftShift)
+	im.HandleTextInput("Ab")
+	im.frame(0.1)
+	im.HandleKeyMods(0)
+	im.frame(0.1)
+	im.StopRecording()
+
+	im = newTestEngine(t).InputManager()
+	if err := im.Replay(&recording); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	im.frame(1)
+	if !im.State().Keys.Shift() || im.State().Text != "Ab" {
+		t.Errorf("replayed frame = shift %v, text %q, want shift held and %q", im.State().Keys.Shift(), im.State().Text, "Ab")
+	}
+	im.reset()
+	im.frame(1)
+	if im.State().Keys.Shift() {
+		t.Error("shift still held after the replayed release")
+	}
+}
+
+func TestInputEventQueue(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+	im.InputMap().Bind("jump", InputBinding{Key: KeySpace})
+
+	// a tap within a frame keeps both events, in order
+	im.reset()
+	im.eventTime = 1.25
+	im.HandleKeyEvent(KeySpace, true)
+	im.HandleMouseMove(5, 5, 2, 1)
+	im.HandleKeyEvent(KeySpace, false)
+	events := im.Events()
+	if len(events) != 3 || events[0].Type != InputEventKey || !events[0].Pressed ||
+		events[1].Type != InputEventMouseMove || events[2].Type != InputEventKey || events[2].Pressed {
+		t.Fatalf("events = %+v, want press, move and release", events)
+	}
+	if events[0].Time != 1.25 {
+		t.Errorf("event time = %v, want 1.25", events[0].Time)
+	}
+	if im.Action("jump") || !im.ActionReleased("jump") {
+		t.Error("tapped jump not released")
+	}
+
+	im.reset()
+	if len(im.Events()) != 0 {
+		t.Errorf("events = %+v after reset, want none", im.Events())
+	}
+
+	// consumed motion and presses don't reach actions and axes
+	im.HandleMouseMove(5, 5, 4, 0)
+	im.HandleMouseMove(9, 5, 3, 0)
+	im.HandleKeyEvent(KeySpace, true)
+	if got := im.Axis("look_x"); got != 7 {
+		t.Errorf("look_x = %v, want both moves", got)
+	}
+	im.ConsumeEvents(InputDeviceMouse)
+	im.Events()[2].Consume()
+	if got := im.Axis("look_x"); got != 0 {
+		t.Errorf("look_x = %v after consuming the moves, want 0", got)
+	}
+	if im.Action("jump") || !im.State().Keys.Active[KeySpace] {
+		t.Error("consumed press held jump, or was dropped from the state")
+	}
+
+	// the key stays hidden until it is released
+	im.reset()
+	if im.Action("jump") {
+		t.Error("jump held on the frame after its press was consumed")
+	}
+	im.reset()
+	im.HandleKeyEvent(KeySpace, false)
+	if im.ActionReleased("jump") {
+		t.Error("jump released although its press was consumed")
+	}
+	im.reset()
+	im.HandleKeyEvent(KeySpace, true)
+	if !im.Action("jump") || !im.ActionPressed("jump") {
+		t.Error("jump not pressed again after the hidden release")
+	}
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InputAxis is an analog input which can be bound to actions and axes.
+type InputAxis int
+
+// Input axes
+const (
+	InputAxisNone InputAxis = iota
+	InputAxisMouseX
+	InputAxisMouseY
+	InputAxisScrollX
+	InputAxisScrollY
+	InputAxisGamepadLeftX
+	InputAxisGamepadLeftY
+	InputAxisGamepadRightX
+	InputAxisGamepadRightY
+	InputAxisGamepadLeftTrigger
+	InputAxisGamepadRightTrigger
+)
+
+// inputAxisNames are the names axes have in input map files.
+var inputAxisNames = map[InputAxis]string{
+	InputAxisMouseX:  "mouse_x",
+	InputAxisMouseY:  "mouse_y",
+	InputAxisScrollX: "scroll_x",
+	InputAxisScrollY: "scroll_y",
+
+	InputAxisGamepadLeftX:        "left_x",
+	InputAxisGamepadLeftY:        "left_y",
+	InputAxisGamepadRightX:       "right_x",
+	InputAxisGamepadRightY:       "right_y",
+	InputAxisGamepadLeftTrigger:  "left_trigger",
+	InputAxisGamepadRightTrigger: "right_trigger",
+}
+
+// String returns the axis' name.
+func (a InputAxis) String() string {
+	if name, ok := inputAxisNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("axis %d", int(a))
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (a InputAxis) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (a *InputAxis) UnmarshalYAML(value *yaml.Node) error {
+	for axis, name := range inputAxisNames {
+		if name == value.Value {
+			*a = axis
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: unknown input axis %q", value.Line, value.Value)
+}
+
+// InputBinding binds a key, a mouse button, a gamepad button or an input axis
+// to an action or an axis. Keys and buttons give an axis Scale while held;
+// input axes give their value times Scale, or 0 while it is within the dead
+// zone. A zero Scale counts as 1. The binding only applies while all its
+// modifier keys are held. Gamepad bindings apply to all connected gamepads.
+type InputBinding struct {
+	Key           Key           `yaml:"key,omitempty"`
+	Button        MouseButton   `yaml:"button,omitempty"`
+	GamepadButton GamepadButton `yaml:"gamepadButton,omitempty"`
+	Axis          InputAxis     `yaml:"axis,omitempty"`
+	Scale         float64       `yaml:"scale,omitempty"`
+	DeadZone      float64       `yaml:"deadZone,omitempty"`
+	Modifiers     []Key         `yaml:"modifiers,omitempty"`
+}
+
+// value returns the binding's value for the input manager's unconsumed input.
+func (b InputBinding) value(i *InputManager) float64 {
+	for _, m := range b.Modifiers {
+		if !i.active(inputButton{InputDeviceKeyboard, 0, int(m)}) {
+			return 0
+		}
+	}
+
+	scale := b.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	var v float64
+	switch {
+	case b.Key != 0:
+		if i.active(inputButton{InputDeviceKeyboard, 0, int(b.Key)}) {
+			v = 1
+		}
+	case b.Button != 0:
+		if i.active(inputButton{InputDeviceMouse, 0, int(b.Button)}) {
+			v = 1
+		}
+	case b.GamepadButton != GamepadButtonNone:
+		if i.gamepadButtonActive(b.GamepadButton) {
+			v = 1
+		}
+	case b.Axis != InputAxisNone:
+		v = inputAxisValue(i, b.Axis)
+		if math.Abs(v) <= b.DeadZone {
+			v = 0
+		}
+	}
+	return v * scale
+}
+
+// released returns whether the binding's key or gamepad button was released
+// this frame.
+func (b InputBinding) released(i *InputManager) bool {
+	switch {
+	case b.Key != 0:
+		return i.released(inputButton{InputDeviceKeyboard, 0, int(b.Key)})
+	case b.GamepadButton != GamepadButtonNone:
+		return i.gamepadButtonReleased(b.GamepadButton)
+	}
+	return false
+}
+
+// inputAxisValue returns an input axis' value for the input manager's
+// unconsumed input.
+func inputAxisValue(i *InputManager, axis InputAxis) float64 {
+	switch axis {
+	case InputAxisMouseX, InputAxisMouseY, InputAxisScrollX, InputAxisScrollY:
+		return i.mouseAxis(axis)
+	case InputAxisGamepadLeftX, InputAxisGamepadLeftY, InputAxisGamepadRightX,
+		InputAxisGamepadRightY, InputAxisGamepadLeftTrigger, InputAxisGamepadRightTrigger:
+		return gamepadAxisValue(i.State(), GamepadAxis(axis-InputAxisGamepadLeftX))
+	}
+	return 0
+}
+
+// InputMap maps named actions, eg: "jump", and axes, eg: "move_x", to the
+// inputs bound to them. Input components query the InputManager for actions
+// and axes instead of keys, so the inputs can be rebound.
+type InputMap struct {
+	Actions map[string][]InputBinding `yaml:"actions,omitempty"`
+	Axes    map[string][]InputBinding `yaml:"axes,omitempty"`
+}
+
+// NewInputMap returns an empty input map.
+func NewInputMap() *InputMap {
+	return &InputMap{
+		Actions: make(map[string][]InputBinding),
+		Axes:    make(map[string][]InputBinding),
+	}
+}
+
+// DefaultInputMap returns the input map the InputManager starts with. It has
+// the axes and actions used by MouseCameraInputComponent, bound to the
+// keyboard and mouse and to gamepads.
+func DefaultInputMap() *InputMap {
+	const stickDeadZone = 0.15
+
+	m := NewInputMap()
+	m.BindAxis("move_x", InputBinding{Key: KeyD}, InputBinding{Key: KeyA, Scale: -1},
+		InputBinding{Axis: InputAxisGamepadLeftX, DeadZone: stickDeadZone})
+	m.BindAxis("move_y", InputBinding{Key: KeyQ}, InputBinding{Key: KeyZ, Scale: -1},
+		InputBinding{GamepadButton: GamepadButtonRightShoulder}, InputBinding{GamepadButton: GamepadButtonLeftShoulder, Scale: -1})
+	m.BindAxis("move_z", InputBinding{Key: KeyS}, InputBinding{Key: KeyW, Scale: -1},
+		InputBinding{Axis: InputAxisGamepadLeftY, DeadZone: stickDeadZone})
+	m.BindAxis("look_x", InputBinding{Axis: InputAxisMouseX},
+		InputBinding{Axis: InputAxisGamepadRightX, Scale: 20, DeadZone: stickDeadZone})
+	m.BindAxis("look_y", InputBinding{Axis: InputAxisMouseY},
+		InputBinding{Axis: InputAxisGamepadRightY, Scale: 20, DeadZone: stickDeadZone})
+	m.BindAxis("move_speed", InputBinding{Axis: InputAxisScrollY})
+	m.Bind("move_faster", InputBinding{GamepadButton: GamepadButtonDPadUp})
+	m.Bind("move_slower", InputBinding{GamepadButton: GamepadButtonDPadDown})
+	return m
+}
+
+// LoadInputMap returns the input map described by a YAML document with
+// actions and axes mappings, eg:
+//
+//	actions:
+//	  jump:
+//	    - key: space
+//	    - button: left
+//	      modifiers: [left_shift]
+//	axes:
+//	  move_x:
+//	    - key: d
+//	    - key: a
+//	      scale: -1
+//	    - axis: mouse_x
+//	      deadZone: 0.5
+func LoadInputMap(data []byte) (*InputMap, error) {
+	m := NewInputMap()
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parsing input map: %w", err)
+	}
+	if m.Actions == nil {
+		m.Actions = make(map[string][]InputBinding)
+	}
+	if m.Axes == nil {
+		m.Axes = make(map[string][]InputBinding)
+	}
+	return m, nil
+}
+
+// Marshal returns the input map as YAML, eg: to save rebound inputs.
+func (m *InputMap) Marshal() ([]byte, error) {
+	return yaml.Marshal(m)
+}
+
+// Bind adds bindings to an action.
+func (m *InputMap) Bind(action string, bindings ...InputBinding) {
+	m.Actions[action] = append(m.Actions[action], bindings...)
+}
+
+// BindAxis adds bindings to an axis.
+func (m *InputMap) BindAxis(axis string, bindings ...InputBinding) {
+	m.Axes[axis] = append(m.Axes[axis], bindings...)
+}
+
+// SetActionBindings replaces an action's bindings. Without bindings the
+// action is removed.
+func (m *InputMap) SetActionBindings(action string, bindings ...InputBinding) {
+	setBindings(m.Actions, action, bindings)
+}
+
+// SetAxisBindings replaces an axis' bindings. Without bindings the axis is
+// removed.
+func (m *InputMap) SetAxisBindings(axis string, bindings ...InputBinding) {
+	setBindings(m.Axes, axis, bindings)
+}
+
+func setBindings(to map[string][]InputBinding, name string, bindings []InputBinding) {
+	if len(bindings) == 0 {
+		delete(to, name)
+		return
+	}
+	to[name] = append([]InputBinding(nil), bindings...)
+}
+
+// ActionNames returns the names of the map's actions, sorted.
+func (m *InputMap) ActionNames() []string {
+	return sortedNames(m.Actions)
+}
+
+// AxisNames returns the names of the map's axes, sorted.
+func (m *InputMap) AxisNames() []string {
+	return sortedNames(m.Axes)
+}
+
+// active returns whether any of an action's bindings is held.
+func (m *InputMap) active(action string, i *InputManager) bool {
+	for _, b := range m.Actions[action] {
+		if b.value(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// released returns whether a key bound to an action was released this frame.
+func (m *InputMap) released(action string, i *InputManager) bool {
+	for _, b := range m.Actions[action] {
+		if b.released(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// axis returns the sum of an axis' bindings.
+func (m *InputMap) axis(axis string, i *InputManager) float64 {
+	var v float64
+	for _, b := range m.Axes[axis] {
+		v += b.value(i)
+	}
+	return v
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testInputMap = `
+actions:
+  jump:
+    - key: space
+    - button: left
+      modifiers: [left_shift]
+  fire:
+    - key: 13
+axes:
+  move_x:
+    - key: d
+    - key: a
+      scale: -1
+    - axis: mouse_x
+      scale: 0.5
+      deadZone: 2
+`
+
+func TestLoadInputMap(t *testing.T) {
+	m, err := LoadInputMap([]byte(testInputMap))
+	if err != nil {
+		t.Fatalf("LoadInputMap failed: %v", err)
+	}
+
+	if got := m.ActionNames(); !reflect.DeepEqual(got, []string{"fire", "jump"}) {
+		t.Errorf("actions = %v, want [fire jump]", got)
+	}
+	want := []InputBinding{{Key: KeySpace}, {Button: MouseButton1, Modifiers: []Key{KeyLeftShift}}}
+	if got := m.Actions["jump"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump = %+v, want %+v", got, want)
+	}
+	if got := m.Actions["fire"]; len(got) != 1 || got[0].Key != Key(13) {
+		t.Errorf("fire = %+v, want scancode 13", got)
+	}
+	wantAxis := []InputBinding{{Key: KeyD}, {Key: KeyA, Scale: -1}, {Axis: InputAxisMouseX, Scale: 0.5, DeadZone: 2}}
+	if got := m.Axes["move_x"]; !reflect.DeepEqual(got, wantAxis) {
+		t.Errorf("move_x = %+v, want %+v", got, wantAxis)
+	}
+
+	// saved maps load back the same
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), "key: space") || !strings.Contains(string(data), "axis: mouse_x") {
+		t.Errorf("marshalled map doesn't use input names:\n%s", data)
+	}
+	loaded, err := LoadInputMap(data)
+	if err != nil {
+		t.Fatalf("LoadInputMap of the marshalled map failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("reloaded map = %+v, want %+v", loaded, m)
+	}
+
+	for _, bad := range []string{
+		"actions: {jump: [{key: hyperspace}]}",
+		"actions: {jump: [{button: fourth}]}",
+		"axes: {move_x: [{axis: tilt}]}",
+		"actions: [jump]",
+	} {
+		if _, err := LoadInputMap([]byte(bad)); err == nil {
+			t.Errorf("LoadInputMap(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestInputManagerActions(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+	m, err := LoadInputMap([]byte(testInputMap))
+	if err != nil {
+		t.Fatalf("LoadInputMap failed: %v", err)
+	}
+	im.SetInputMap(m)
+
+	im.reset()
+	im.HandleKeyEvent(KeySpace, true)
+	if !im.Action("jump") || !im.ActionPressed("jump") || im.ActionReleased("jump") {
+		t.Error("jump not pressed on the frame space went down")
+	}
+
+	im.reset()
+	if !im.Action("jump") || im.ActionPressed("jump") {
+		t.Error("jump pressed again while space is held")
+	}
+
+	im.reset()
+	im.HandleKeyEvent(KeySpace, false)
+	if im.Action("jump") || !im.ActionReleased("jump") {
+		t.Error("jump not released on the frame space went up")
+	}
+
+	// the mouse button only jumps with shift held
+	im.reset()
+	im.HandleMouseButton(MouseButton1, true)
+	if im.Action("jump") {
+		t.Error("jump held by the mouse button without its modifier")
+	}
+	im.HandleKeyEvent(KeyLeftShift, true)
+	if !im.Action("jump") || !im.ActionPressed("jump") {
+		t.Error("jump not held by the mouse button with its modifier")
+	}
+	im.HandleMouseButton(MouseButton1, false)
+	im.HandleKeyEvent(KeyLeftShift, false)
+
+	// keys and the mouse add up, with small moves in the dead zone
+	im.reset()
+	im.HandleKeyEvent(KeyD, true)
+	im.HandleMouseMove(0, 0, 1.5, 0)
+	if got := im.Axis("move_x"); got != 1 {
+		t.Errorf("move_x = %v, want 1", got)
+	}
+	im.reset()
+	im.HandleMouseMove(0, 0, -6, 0)
+	im.HandleKeyEvent(KeyA, true)
+	if got := im.Axis("move_x"); got != -3 {
+		t.Errorf("move_x = %v, want -3", got)
+	}
+	if got := im.Axis("unbound"); got != 0 || im.Action("unbound") {
+		t.Errorf("unbound axis = %v", got)
+	}
+
+	// rebinding at runtime
+	im.reset()
+	m.SetActionBindings("jump", InputBinding{Key: KeyW})
+	if im.Action("jump") {
+		t.Error("jump held after space was unbound")
+	}
+	im.HandleKeyEvent(KeyW, true)
+	if !im.Action("jump") {
+		t.Error("jump not held by its new key")
+	}
+	m.SetActionBindings("jump")
+	if _, ok := m.Actions["jump"]; ok || im.Action("jump") {
+		t.Error("jump still bound after removing its bindings")
+	}
+}
@@ -0,0 +1,179 @@
+package core
+
+// InputDevice is the kind of device an input event comes from.
+type InputDevice int
+
+// Input devices
+const (
+	InputDeviceKeyboard InputDevice = iota
+	InputDeviceMouse
+	InputDeviceGamepad
+)
+
+// Device returns the kind of device events of this type come from.
+func (t InputEventType) Device() InputDevice {
+	switch t {
+	case InputEventMouseButton, InputEventMouseMove, InputEventMouseScroll:
+		return InputDeviceMouse
+	case InputEventGamepadAdded, InputEventGamepadRemoved, InputEventGamepadButton, InputEventGamepadAxis:
+		return InputDeviceGamepad
+	}
+	return InputDeviceKeyboard
+}
+
+// QueuedInputEvent is an input event handled this frame. Components which
+// act on an event can consume it to hide it from the components that run
+// after them, eg: a UI scene consumes the clicks on its widgets so the camera
+// of the scene underneath doesn't turn.
+type QueuedInputEvent struct {
+	InputEvent
+	consumed bool
+}
+
+// Consume marks the event as handled. Consumed events no longer count for
+// actions and axes; a consumed press hides its key or button from them until
+// it is released.
+func (e *QueuedInputEvent) Consume() {
+	e.consumed = true
+}
+
+// Consumed returns whether the event was consumed.
+func (e *QueuedInputEvent) Consumed() bool {
+	return e.consumed
+}
+
+// inputButton identifies a key or a button on a device.
+type inputButton struct {
+	device  InputDevice
+	gamepad GamepadID
+	code    int
+}
+
+// button returns the key or button pressed or released by the event.
+func (e *InputEvent) button() (inputButton, bool) {
+	switch e.Type {
+	case InputEventKey:
+		return inputButton{InputDeviceKeyboard, 0, int(e.Key)}, true
+	case InputEventMouseButton:
+		return inputButton{InputDeviceMouse, 0, int(e.Button)}, true
+	case InputEventGamepadButton:
+		return inputButton{InputDeviceGamepad, e.Gamepad, int(e.GamepadButton)}, true
+	}
+	return inputButton{}, false
+}
+
+// Events returns the input events handled this frame, in the order the
+// window system delivered them. The queue is cleared at the start of every
+// frame, along with the per frame state.
+func (i *InputManager) Events() []*QueuedInputEvent {
+	return i.events
+}
+
+// ConsumeEvents consumes this frame's events from a device.
+func (i *InputManager) ConsumeEvents(device InputDevice) {
+	for _, e := range i.events {
+		if e.Type.Device() == device {
+			e.Consume()
+		}
+	}
+}
+
+// queue adds a handled event to the frame's queue.
+func (i *InputManager) queue(e InputEvent) {
+	i.events = append(i.events, &QueuedInputEvent{InputEvent: e})
+}
+
+// active returns whether a key or button is held and its press wasn't
+// consumed.
+func (i *InputManager) active(b inputButton) bool {
+	if !i.buttonState(b) {
+		return false
+	}
+	visible := !i.hidden[b]
+	for _, e := range i.events {
+		if eb, ok := e.button(); ok && eb == b && e.Pressed {
+			visible = !e.consumed
+		}
+	}
+	return visible
+}
+
+// released returns whether a key or button was released this frame, with
+// neither its press nor its release consumed.
+func (i *InputManager) released(b inputButton) bool {
+	if i.captured.has(b.device) {
+		return false
+	}
+	visible := !i.hidden[b]
+	for _, e := range i.events {
+		eb, ok := e.button()
+		if !ok || eb != b {
+			continue
+		}
+		if e.Pressed {
+			visible = !e.consumed
+		} else if visible && !e.consumed {
+			return true
+		}
+	}
+	return false
+}
+
+// buttonState returns whether a key or button is held, and not captured.
+func (i *InputManager) buttonState(b inputButton) bool {
+	state := i.State()
+	switch b.device {
+	case InputDeviceKeyboard:
+		return state.Keys.Active[Key(b.code)]
+	case InputDeviceMouse:
+		return state.Mouse.Buttons.Active[MouseButton(b.code)]
+	case InputDeviceGamepad:
+		g := state.Gamepads[b.gamepad]
+		return g != nil && g.Active[GamepadButton(b.code)]
+	}
+	return false
+}
+
+// mouseAxis returns the sum of this frame's unconsumed motion or scroll on
+// a mouse axis.
+func (i *InputManager) mouseAxis(axis InputAxis) float64 {
+	var v float64
+	for _, e := range i.events {
+		if e.consumed {
+			continue
+		}
+		switch {
+		case axis == InputAxisMouseX && e.Type == InputEventMouseMove:
+			v += e.RelX
+		case axis == InputAxisMouseY && e.Type == InputEventMouseMove:
+			v += e.RelY
+		case axis == InputAxisScrollX && e.Type == InputEventMouseScroll:
+			v += e.X
+		case axis == InputAxisScrollY && e.Type == InputEventMouseScroll:
+			v += e.Y
+		}
+	}
+	return v
+}
+
+// clearEvents empties the queue for a new frame. Keys and buttons whose press
+// was consumed stay hidden until they are released.
+func (i *InputManager) clearEvents() {
+	for _, e := range i.events {
+		if b, ok := e.button(); ok && e.Pressed {
+			if e.consumed {
+				i.hidden[b] = true
+			} else {
+				delete(i.hidden, b)
+			}
+		}
+	}
+	for b := range i.hidden {
+		if !i.buttonState(b) {
+			delete(i.hidden, b)
+		}
+	}
+
+	clear(i.events)
+	i.events = i.events[:0]
+}
@@ -0,0 +1,274 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/glog"
+)
+
+// inputRecordingVersion is the version of the recording format written by
+// StartRecording.
+const inputRecordingVersion = 1
+
+// InputEventType is the InputManager entry point an input event went through.
+type InputEventType int
+
+// Input event types
+const (
+	InputEventKey InputEventType = iota
+	InputEventMouseButton
+	InputEventMouseMove
+	InputEventMouseScroll
+	InputEventKeyMods
+	InputEventText
+	InputEventGamepadAdded
+	InputEventGamepadRemoved
+	InputEventGamepadButton
+	InputEventGamepadAxis
+)
+
+// InputEvent is an input event as the window system passed it to the
+// InputManager. Time is when the window system received it, in seconds on
+// the TimerManager's clock.
+type InputEvent struct {
+	Type    InputEventType `json:"type"`
+	Key     Key            `json:"key,omitempty"`
+	Mods    KeyMod         `json:"mods,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Button  MouseButton    `json:"button,omitempty"`
+	Pressed bool           `json:"pressed,omitempty"`
+	X       float64        `json:"x,omitempty"`
+	Y       float64        `json:"y,omitempty"`
+	RelX    float64        `json:"relX,omitempty"`
+	RelY    float64        `json:"relY,omitempty"`
+	Time    float64        `json:"time,omitempty"`
+
+	Gamepad       GamepadID     `json:"gamepad,omitempty"`
+	GamepadInfo   *GamepadInfo  `json:"gamepadInfo,omitempty"`
+	GamepadButton GamepadButton `json:"gamepadButton,omitempty"`
+	GamepadAxis   GamepadAxis   `json:"gamepadAxis,omitempty"`
+	Value         float64       `json:"value,omitempty"`
+}
+
+// InputFrame holds the input events of a frame and the time step the frame
+// was updated with. Time is the frame's start time, only written for the
+// first frame as the others start a time step after the previous one.
+type InputFrame struct {
+	Dt     float64      `json:"dt"`
+	Time   float64      `json:"time,omitempty"`
+	Events []InputEvent `json:"events,omitempty"`
+}
+
+// inputRecordingHeader starts a recording. The platform is kept because the
+// window system's scroll direction depends on it.
+type inputRecordingHeader struct {
+	Version  int      `json:"version"`
+	Platform Platform `json:"platform"`
+}
+
+// inputReplay is a recording being played back, and the start time of the
+// frame being replayed.
+type inputReplay struct {
+	frames  []InputFrame
+	next    int
+	time    float64
+	flipY   bool
+	feeding bool
+}
+
+// StartRecording writes the input events handled from the next frame on to w,
+// with each frame's time step, until StopRecording is called. Recordings are
+// JSON lines, one per frame, so they are usable up to the last frame written
+// if the application dies.
+func (i *InputManager) StartRecording(w io.Writer) error {
+	if i.recorder != nil {
+		return errors.New("input is already being recorded")
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(inputRecordingHeader{Version: inputRecordingVersion, Platform: GetPlatform()}); err != nil {
+		return fmt.Errorf("writing input recording header: %w", err)
+	}
+	i.recorder = enc
+	i.recordedFrames = 0
+	i.recorded = i.recorded[:0]
+
+	// modifiers and gamepads are only passed on change, so a replay starts
+	// with the held modifiers and the connected gamepads
+	if i.mods != 0 {
+		i.recorded = append(i.recorded, InputEvent{Type: InputEventKeyMods, Mods: i.mods})
+	}
+	for _, id := range i.Gamepads() {
+		g := i.state.Gamepads[id]
+		i.recorded = append(i.recorded, InputEvent{Type: InputEventGamepadAdded, Gamepad: id, GamepadInfo: &g.Info})
+		for axis, v := range g.Axes {
+			if v != 0 {
+				i.recorded = append(i.recorded, InputEvent{Type: InputEventGamepadAxis, Gamepad: id, GamepadAxis: GamepadAxis(axis), Value: v})
+			}
+		}
+		for button, pressed := range g.Active {
+			if pressed {
+				i.recorded = append(i.recorded, InputEvent{Type: InputEventGamepadButton, Gamepad: id, GamepadButton: button, Pressed: true})
+			}
+		}
+	}
+	return nil
+}
+
+// StopRecording stops recording input.
+func (i *InputManager) StopRecording() {
+	i.recorder = nil
+}
+
+// Recording returns whether input is being recorded.
+func (i *InputManager) Recording() bool {
+	return i.recorder != nil
+}
+
+// Replay plays back a recording made with StartRecording. From the next frame
+// on, the window system's input is ignored and each frame gets the recorded
+// events, through the same Handle* methods, and the recorded time step. The
+// TimerManager's time follows the recorded time steps instead of the window
+// system's clock. Live input and time resume once the recording ends.
+func (i *InputManager) Replay(r io.Reader) error {
+	dec := json.NewDecoder(r)
+
+	var header inputRecordingHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("reading input recording header: %w", err)
+	}
+	if header.Version != inputRecordingVersion {
+		return fmt.Errorf("unsupported input recording version %d", header.Version)
+	}
+
+	replay := &inputReplay{flipY: scrollFlipped(header.Platform) != scrollFlipped(GetPlatform())}
+	for {
+		var f InputFrame
+		err := dec.Decode(&f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading input recording frame %d: %w", len(replay.frames), err)
+		}
+		replay.frames = append(replay.frames, f)
+	}
+
+	i.replay = replay
+	return nil
+}
+
+// Replaying returns whether a recording is being played back.
+func (i *InputManager) Replaying() bool {
+	return i.replay != nil
+}
+
+// StopReplay stops playing back a recording and resumes live input.
+func (i *InputManager) StopReplay() {
+	i.replay = nil
+}
+
+// live returns whether events from the window system are handled. They are
+// dropped while a recording is played back.
+func (i *InputManager) live() bool {
+	return i.replay == nil || i.replay.feeding
+}
+
+// record stamps an event handled this frame, queues it and keeps it for the
+// recording.
+func (i *InputManager) record(e InputEvent) {
+	e.Time = i.eventTime
+	i.queue(e)
+	if i.recorder != nil {
+		i.recorded = append(i.recorded, e)
+	}
+}
+
+// frame is called once the frame's window events were handled, with the time
+// step the frame is going to be updated with. It plays back the next recorded
+// frame or writes this one to the recording, and returns the time step to use.
+func (i *InputManager) frame(dt float64) float64 {
+	if r := i.replay; r != nil {
+		if r.next == len(r.frames) {
+			glog.Info("Input replay finished")
+			i.replay = nil
+		} else {
+			f := r.frames[r.next]
+			r.next++
+			r.feeding = true
+			for _, e := range f.Events {
+				i.handle(e, r.flipY)
+			}
+			r.feeding = false
+
+			dt = f.Dt
+			if r.next == 1 {
+				r.time = f.Time
+			} else {
+				r.time += dt
+			}
+			tm := i.engine.timerManager
+			tm.SetDt(dt)
+			tm.setFrameStartTime(r.time)
+		}
+	}
+
+	if i.recorder != nil {
+		f := InputFrame{Dt: dt, Events: i.recorded}
+		if i.recordedFrames == 0 {
+			f.Time = i.engine.timerManager.FrameStartTime()
+		}
+		if err := i.recorder.Encode(f); err != nil {
+			glog.Warningf("Stopping input recording: %v", err)
+			i.recorder = nil
+		}
+		i.recordedFrames++
+		i.recorded = i.recorded[:0]
+	}
+	return dt
+}
+
+// handle passes a recorded event to its entry point.
+func (i *InputManager) handle(e InputEvent, flipY bool) {
+	i.eventTime = e.Time
+	switch e.Type {
+	case InputEventKey:
+		i.HandleKeyEvent(e.Key, e.Pressed)
+	case InputEventKeyMods:
+		i.HandleKeyMods(e.Mods)
+	case InputEventText:
+		i.HandleTextInput(e.Text)
+	case InputEventMouseButton:
+		i.HandleMouseButton(e.Button, e.Pressed)
+	case InputEventMouseMove:
+		i.HandleMouseMove(e.X, e.Y, e.RelX, e.RelY)
+	case InputEventMouseScroll:
+		if flipY {
+			e.Y = -e.Y
+		}
+		i.HandleMouseScroll(e.X, e.Y)
+	case InputEventGamepadAdded:
+		var info GamepadInfo
+		if e.GamepadInfo != nil {
+			info = *e.GamepadInfo
+		}
+		i.HandleGamepadAdded(e.Gamepad, info)
+	case InputEventGamepadRemoved:
+		i.HandleGamepadRemoved(e.Gamepad)
+	case InputEventGamepadButton:
+		i.HandleGamepadButton(e.Gamepad, e.GamepadButton, e.Pressed)
+	case InputEventGamepadAxis:
+		i.HandleGamepadAxis(e.Gamepad, e.GamepadAxis, e.Value)
+	default:
+		glog.Warningf("Skipping recorded input event of unknown type %d", e.Type)
+	}
+}
+
+// scrollFlipped returns whether the window system's vertical scroll is
+// inverted on a platform.
+func scrollFlipped(p Platform) bool {
+	return p == PlatformLinux || p == PlatformWindows
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// scriptedWindow is a headless window backend which delivers scripted input
+// events and whose clock advances by irregular steps.
+type scriptedWindow struct {
+	headlessWindow
+	frame  int
+	clock  float64
+	script map[int][]InputEvent
+}
+
+func (s *scriptedWindow) pollEvents(w *WindowManager) {
+	for _, e := range s.script[s.frame] {
+		w.engine.inputManager.handle(e, false)
+	}
+	s.frame++
+}
+
+func (s *scriptedWindow) time() float64 {
+	s.clock += 0.01 + 0.005*float64(s.frame%3)
+	return s.clock
+}
+
+// runScriptedCamera runs a mouse controlled camera for a number of frames and
+// returns its final transform.
+func runScriptedCamera(t *testing.T, e *Engine, window *scriptedWindow, frames int) mgl64.Mat4 {
+	t.Helper()
+	e.WindowManager().SetWindowConfig(WindowConfig{Name: "test", Width: 320, Height: 240})
+	e.WindowManager().backend = window
+
+	camera := NewCamera("MainCamera", PerspectiveProjection)
+	client := &headlessTestApp{maxFrames: frames}
+	NewApplication(e).Start(func() ClientApplication {
+		root := NewNode("ROOT")
+		camera.SetAutoReshape(true)
+		camera.SetVerticalFieldOfView(60)
+		camera.SetClipDistance(mgl64.Vec2{0.1, 100})
+		camera.SetScene(root)
+		camera.Node().SetInputComponent(e.NewMouseCameraInputComponent())
+
+		scene := NewScene("scripted")
+		scene.SetRoot(root)
+		scene.AddCamera(root, camera)
+		e.SceneManager().PushScene(scene)
+		return client
+	})
+	return camera.Node().WorldTransform()
+}
+
+func TestInputRecordingReplay(t *testing.T) {
+	script := map[int][]InputEvent{
+		1: {{Type: InputEventMouseScroll, Y: 3}, {Type: InputEventMouseScroll, Y: -3}},
+		2: {{Type: InputEventKey, Key: KeyW, Pressed: true}, {Type: InputEventMouseMove, X: 10, Y: 5, RelX: 4, RelY: -2}},
+		4: {{Type: InputEventMouseMove, X: 12, Y: 3, RelX: 2, RelY: -2}, {Type: InputEventMouseButton, Button: MouseButton1, Pressed: true}},
+		5: {{Type: InputEventKey, Key: KeyW}, {Type: InputEventKey, Key: KeyD, Pressed: true}},
+	}
+
+	var recording bytes.Buffer
+	e := newTestEngine(t)
+	if err := e.InputManager().StartRecording(&recording); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+	if err := e.InputManager().StartRecording(&recording); err == nil {
+		t.Error("second StartRecording succeeded")
+	}
+	recorded := runScriptedCamera(t, e, &scriptedWindow{script: script}, 8)
+	e.InputManager().StopRecording()
+
+	if recorded == mgl64.Ident4() {
+		t.Fatal("scripted input didn't move the camera")
+	}
+	if lines := strings.Count(recording.String(), "\n"); lines != 9 {
+		t.Errorf("recording has %d lines, want a header and 8 frames", lines)
+	}
+
+	// live events are ignored and the clock runs differently during the replay
+	e = newTestEngine(t)
+	if err := e.InputManager().Replay(bytes.NewReader(recording.Bytes())); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	live := map[int][]InputEvent{3: {{Type: InputEventKey, Key: KeyS, Pressed: true}}}
+	replayed := runScriptedCamera(t, e, &scriptedWindow{script: live, clock: 100}, 8)
+	if replayed != recorded {
+		t.Errorf("replayed camera transform = %v, want %v", replayed, recorded)
+	}
+	if e.TimerManager().FrameStartTime() == 0 {
+		t.Error("replay didn't drive the timer")
+	}
+
+	// live input resumes after the recording's last frame
+	e.InputManager().frame(1.0 / 60.0)
+	if e.InputManager().Replaying() {
+		t.Error("replay didn't finish after its last frame")
+	}
+	e.InputManager().HandleKeyEvent(KeyS, true)
+	if !e.InputManager().State().Keys.Active[KeyS] {
+		t.Error("live input ignored after the replay")
+	}
+}
+
+// timedClient records the timer's values each frame.
+type timedClient struct {
+	headlessTestApp
+	tm                *TimerManager
+	times, starts, dt []float64
+}
+
+func (c *timedClient) InputComponent() ClientApplicationInputComponent { return c }
+
+func (c *timedClient) Run() []ClientApplicationCommand {
+	if c.tm.engine.inputManager.Replaying() {
+		c.times = append(c.times, c.tm.Time())
+	}
+	c.starts = append(c.starts, c.tm.FrameStartTime())
+	c.dt = append(c.dt, c.tm.Dt())
+	return c.headlessTestApp.Run()
+}
+
+func runTimedClient(e *Engine, clock float64) *timedClient {
+	e.WindowManager().SetWindowConfig(WindowConfig{Name: "test", Width: 320, Height: 240})
+	e.WindowManager().backend = &scriptedWindow{clock: clock}
+	client := &timedClient{headlessTestApp: headlessTestApp{maxFrames: 6}, tm: e.TimerManager()}
+	NewApplication(e).Start(func() ClientApplication { return client })
+	return client
+}
+
+func TestInputReplayTime(t *testing.T) {
+	var recording bytes.Buffer
+	e := newTestEngine(t)
+	if err := e.InputManager().StartRecording(&recording); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+	recorded := runTimedClient(e, 0)
+
+	// the replay's clock runs differently and is ignored
+	e = newTestEngine(t)
+	if err := e.InputManager().Replay(bytes.NewReader(recording.Bytes())); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	replayed := runTimedClient(e, 100)
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for i := range recorded.starts {
+		if !near(replayed.times[i], recorded.starts[i]) || !near(replayed.starts[i], recorded.starts[i]) || !near(replayed.dt[i], recorded.dt[i]) {
+			t.Errorf("frame %d: replayed time %v, start %v, dt %v, want %v, %v, %v", i,
+				replayed.times[i], replayed.starts[i], replayed.dt[i], recorded.starts[i], recorded.starts[i], recorded.dt[i])
+		}
+	}
+}
+
+func TestInputReplayErrors(t *testing.T) {
+	im := newTestEngine(t).InputManager()
+
+	tests := []string{
+		"",
+		`{"version": 2, "platform": 0}`,
+		`{"version": 1, "platform": 0}` + "\n" + `{"dt": "soon"}`,
+	}
+	for _, data := range tests {
+		if err := im.Replay(strings.NewReader(data)); err == nil {
+			t.Errorf("Replay(%q) succeeded", data)
+		}
+	}
+	if im.Replaying() {
+		t.Error("failed replays left a replay running")
+	}
+}
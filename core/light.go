@@ -16,6 +16,11 @@ type Light struct {
 	Block      LightBlock
 	Shadower   Shadower
 	ShadowBias float32 // tunable shadow bias, passed via Color.w
+
+	// layers of the node the light was extracted from. Cameras only receive
+	// lights on their layers and only nodes on these layers cast shadows. Zero
+	// means every layer.
+	layers LayerMask
 }
 
 // LightExtractor is an interface which extracts a light from a node and adds it to a bucket.
@@ -40,6 +45,7 @@ func (lc *DefaultLightExtractor) Run(node *Node, lightBucket *[]*Light) {
 		node.light.Block.Position = Vec4DoubleToFloat(lPos)
 		// pass shadow bias through color.w
 		node.light.Block.Color[3] = node.light.ShadowBias
+		node.light.layers = node.layers
 		*lightBucket = append(*lightBucket, node.light)
 	}
 
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"math"
+	"sync"
+)
+
+// LODFadeSlot is the instance data slot set for nodes drawn during LOD
+// cross-fades. Its x component is how far the node has faded out, from 0 for
+// fully visible to 1 for gone, so shaders which don't read it are unaffected.
+// The fade is kept per camera and overrides the slot of the node's Material
+// when drawing.
+const LODFadeSlot = 3
+
+// LODLevel is a level of detail of a LODGroup.
+type LODLevel struct {
+	// Node is the subtree drawn for the level. It must be a child of the
+	// group's node.
+	Node *Node
+
+	// ScreenSize is the smallest projected size of the group's world bounds,
+	// as a fraction of the viewport height, for which the level is drawn.
+	ScreenSize float64
+}
+
+// LODGroup selects one of a node's children to draw from the size of the
+// node's world bounds on screen. Levels are ordered from the most detailed,
+// with decreasing screen sizes, and nothing is drawn below the last level's
+// size. Children which are not levels are always culled normally.
+type LODGroup struct {
+	Levels []LODLevel
+
+	// Hysteresis is the fraction by which the screen size has to move past a
+	// level's threshold before the group switches, so objects hovering around
+	// a threshold don't pop back and forth.
+	Hysteresis float64
+
+	// FadeDuration is how long in seconds the outgoing and incoming levels
+	// are both drawn while cross-fading. Zero switches instantly.
+	FadeDuration float64
+
+	mutex  sync.Mutex
+	states map[*Camera]*lodState
+}
+
+// lodState is a group's selection for a camera. from is the level fading out
+// and fade how far the switch has progressed.
+type lodState struct {
+	level int
+	from  int
+	fade  float64
+}
+
+// NewLODGroup returns a new LODGroup with the given levels.
+func NewLODGroup(levels ...LODLevel) *LODGroup {
+	return &LODGroup{Levels: levels}
+}
+
+// forget drops the group's selection for a camera.
+func (g *LODGroup) forget(c *Camera) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.states, c)
+}
+
+// Level returns the level last selected for a camera, or -1 if the group was
+// culled or too small to draw.
+func (g *LODGroup) Level(c *Camera) int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if s, ok := g.states[c]; ok && s.level < len(g.Levels) {
+		return s.level
+	}
+	return -1
+}
+
+// ScreenSize returns the projected size of a node's world bounds as a fraction
+// of the camera's viewport height.
+func ScreenSize(c *Camera, n *Node) float64 {
+	center := n.worldBounds.Center()
+	radius := 0.5 * n.worldBounds.Size().Len()
+	w := c.projectionMatrix.Mul4(c.viewMatrix).Mul4x1(center.Vec4(1))[3]
+	if w <= 0 {
+		return math.Inf(1)
+	}
+	return radius * c.projectionMatrix[5] / w
+}
+
+// levelFor returns the first level drawn at a screen size, or len(g.Levels)
+// if it is too small for all of them.
+func (g *LODGroup) levelFor(size float64) int {
+	for i := range g.Levels {
+		if size >= g.Levels[i].ScreenSize {
+			return i
+		}
+	}
+	return len(g.Levels)
+}
+
+// selectLevel updates the camera's selection for the group's node.
+func (g *LODGroup) selectLevel(c *Camera, n *Node) lodState {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	size := ScreenSize(c, n)
+	s, ok := g.states[c]
+	if !ok {
+		if g.states == nil {
+			g.states = make(map[*Camera]*lodState)
+		}
+		s = &lodState{level: g.levelFor(size), from: -1}
+		g.states[c] = s
+		return *s
+	}
+
+	level := s.level
+	if finer := g.levelFor(size / (1 + g.Hysteresis)); finer < level {
+		level = finer
+	} else if coarser := g.levelFor(size / math.Max(1-g.Hysteresis, 1e-6)); coarser > level {
+		level = coarser
+	}
+	if level != s.level {
+		s.from, s.fade = -1, 0
+		if g.FadeDuration > 0 {
+			s.from = s.level
+		}
+		s.level = level
+	}
+	return *s
+}
+
+// advance moves the cross-fades along by dt seconds.
+func (g *LODGroup) advance(dt float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for _, s := range g.states {
+		if s.from < 0 {
+			continue
+		}
+		s.fade += dt / g.FadeDuration
+		if s.fade >= 1 {
+			s.from, s.fade = -1, 0
+		}
+	}
+}
+
+// cull runs the cull components of the node's children which are drawn at
+// the camera's current level, marking the levels being faded.
+func (g *LODGroup) cull(scene *Scene, camera *Camera, node *Node) {
+	s := g.selectLevel(camera, node)
+	for _, c := range node.children {
+		switch i := g.levelOf(c); {
+		case i < 0:
+		case i == s.level:
+			if s.from >= 0 {
+				camera.setLODFade(c, float32(1-s.fade))
+			}
+		case i == s.from:
+			camera.setLODFade(c, float32(s.fade))
+		default:
+			continue
+		}
+		c.cullComponent.Run(scene, camera, c)
+	}
+}
+
+func (g *LODGroup) levelOf(n *Node) int {
+	for i := range g.Levels {
+		if g.Levels[i].Node == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyFor returns a copy of the group for a copy of its node, with levels
+// pointing at the copied children.
+func (g *LODGroup) copyFor(from, to *Node) *LODGroup {
+	nc := &LODGroup{Hysteresis: g.Hysteresis, FadeDuration: g.FadeDuration}
+	for _, l := range g.Levels {
+		for i, c := range from.children {
+			if c == l.Node {
+				nc.Levels = append(nc.Levels, LODLevel{Node: to.children[i], ScreenSize: l.ScreenSize})
+				break
+			}
+		}
+	}
+	return nc
+}
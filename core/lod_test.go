@@ -0,0 +1,221 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/qmuntal/gltf"
+)
+
+// newLODScene adds a group of three triangle levels to an empty recording
+// scene. At a distance d the group's screen size is about 2.45/d.
+func newLODScene(t *testing.T) (*Engine, *Scene, *Camera, *Node) {
+	t.Helper()
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	scene, camera := newRecordingScene(t, e)
+	pipeline, _ := e.ResourceManager().Pipeline("unlit")
+
+	group := NewNode("Tree")
+	g := NewLODGroup()
+	g.Hysteresis = 0.1
+	for i, size := range []float64{0.4, 0.1, 0.05} {
+		level := NewNode("Level")
+		level.SetMesh(newTriangleMesh(e))
+		level.SetPipeline(pipeline)
+		group.AddChild(level)
+		g.Levels = append(g.Levels, LODLevel{Node: level, ScreenSize: size})
+		if i == 0 {
+			// not a level, always drawn
+			trunk := NewNode("Trunk")
+			trunk.SetMesh(newTriangleMesh(e))
+			trunk.SetPipeline(pipeline)
+			group.AddChild(trunk)
+		}
+	}
+	group.SetLODGroup(g)
+	scene.Root().AddChild(group)
+	return e, scene, camera, group
+}
+
+// cullAt moves the group d units in front of the camera and culls the scene.
+func cullAt(e *Engine, scene *Scene, group *Node, d, dt float64) {
+	group.SetWorldTransform(mgl64.Translate3D(0, 0, -d))
+	scene.update(e, dt)
+	scene.cull(e)
+}
+
+func TestLODGroup(t *testing.T) {
+	e, scene, camera, group := newLODScene(t)
+	g := group.LODGroup()
+
+	steps := []struct {
+		distance float64
+		level    int
+	}{
+		{10, 1},
+		{6, 1}, // past the threshold but within the hysteresis
+		{5, 0},
+		{6.5, 0},
+		{7, 1},
+		{60, -1},
+	}
+	for _, culler := range []Culler{new(DefaultCuller), new(AlwaysPassCuller), NewBVHCuller()} {
+		scene.Root().SetCullComponent(culler)
+		for _, s := range steps {
+			cullAt(e, scene, group, s.distance, 0)
+			if got := g.Level(camera); got != s.level {
+				t.Errorf("%T at %v: level = %d, want %d", culler, s.distance, got, s.level)
+			}
+
+			// the bucket holds the level's node and the trunk, unless the
+			// frustum culler dropped the whole group
+			bucket := camera.pipelineBuckets[group.children[0].pipeline]
+			want := []*Node{group.children[1]}
+			if s.level >= 0 {
+				want = append(want, g.Levels[s.level].Node)
+			}
+			if !sameNodes(bucket, want) {
+				t.Errorf("%T at %v: drew %d nodes, want %d", culler, s.distance, len(bucket), len(want))
+			}
+		}
+	}
+
+	c := group.Copy()
+	cg := c.LODGroup()
+	if len(cg.Levels) != 3 || cg.Levels[0].Node != c.children[0] || cg.Levels[2].Node != c.children[3] {
+		t.Error("copied group doesn't point at the copied levels")
+	}
+}
+
+func TestLODGroupCrossFade(t *testing.T) {
+	e, scene, camera, group := newLODScene(t)
+	g := group.LODGroup()
+	g.FadeDuration = 1
+	lod0, lod1 := g.Levels[0].Node, g.Levels[1].Node
+
+	// a narrow camera sees the group large enough for the first level from
+	// the start, so it never fades
+	zoomed := NewCamera("Zoomed", PerspectiveProjection)
+	zoomed.SetViewport(camera.Viewport())
+	zoomed.SetVerticalFieldOfView(10)
+	zoomed.SetClipDistance(camera.ClipDistance())
+	zoomed.SetScene(scene.Root())
+	scene.AddCamera(scene.Root(), zoomed)
+
+	fade := func(c *Camera, n *Node) float32 {
+		return c.instanceData(n)[LODFadeSlot][0]
+	}
+	drawn := func() int {
+		return len(camera.pipelineBuckets[lod0.pipeline])
+	}
+
+	cullAt(e, scene, group, 10, 0)
+	cullAt(e, scene, group, 5, 0)
+	if drawn() != 3 || fade(camera, lod0) != 1 || fade(camera, lod1) != 0 {
+		t.Errorf("fade start: drawn %d, fades %v %v, want both levels at 1 and 0", drawn(), fade(camera, lod0), fade(camera, lod1))
+	}
+	cullAt(e, scene, group, 5, 0.25)
+	if drawn() != 3 || fade(camera, lod0) != 0.75 || fade(camera, lod1) != 0.25 {
+		t.Errorf("fade: drawn %d, fades %v %v, want both levels at 0.75 and 0.25", drawn(), fade(camera, lod0), fade(camera, lod1))
+	}
+	if g.Level(zoomed) != 0 || fade(zoomed, lod0) != 0 || lod0.Material().InstanceData()[LODFadeSlot][0] != 0 {
+		t.Errorf("zoomed camera draws level %d at fade %v, want level 0 unfaded", g.Level(zoomed), fade(zoomed, lod0))
+	}
+	cullAt(e, scene, group, 5, 1)
+	if drawn() != 2 || fade(camera, lod0) != 0 {
+		t.Errorf("fade end: drawn %d, fade %v, want the first level alone", drawn(), fade(camera, lod0))
+	}
+
+	// removed cameras are forgotten
+	scene.RemoveCamera(zoomed)
+	if len(scene.Cameras()) != 1 || len(g.states) != 1 || g.Level(zoomed) != -1 {
+		t.Errorf("group keeps %d camera states after removing one, want 1", len(g.states))
+	}
+}
+
+const lodScene = `name: LOD
+nodes:
+  - name: Tree
+    lod:
+      hysteresis: 0.1
+      fadeDuration: 0.5
+      levels:
+        - {node: High, screenSize: 0.3}
+        - {node: Low, screenSize: 0.05}
+        - {node: Missing, screenSize: 0.01}
+    children:
+      - name: High
+      - name: Low
+`
+
+func TestSceneLOD(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	scene, err := loadSceneFromYAML(e, "", []byte(lodScene))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	tree := scene.FindNode("Tree")
+	g := tree.LODGroup()
+	if g == nil || len(g.Levels) != 2 || g.Levels[1].Node != tree.Find("Low") || g.Levels[1].ScreenSize != 0.05 || g.FadeDuration != 0.5 {
+		t.Fatalf("loaded group = %+v", g)
+	}
+
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("lod:\n      hysteresis: 0.1\n      fadeDuration: 0.5\n      levels:\n        - node: High\n          screenSize: 0.3\n")) {
+		t.Errorf("saved scene lost the group:\n%s", data)
+	}
+}
+
+func TestGLTFLOD(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	doc := &gltf.Document{
+		Nodes: []*gltf.Node{
+			{Name: "Tree_LOD1", Translation: [3]float64{1, 0, 0}},
+			{Name: "Tree_LOD0"},
+			{
+				Name:        "Rock",
+				Translation: [3]float64{0, 2, 0},
+				Extensions:  gltf.Extensions{"MSFT_lod": json.RawMessage(`{"ids": [3]}`)},
+				Extras:      map[string]any{"MSFT_screencoverage": []any{0.25, 0.0001}},
+			},
+			{Name: "Rock_low"},
+			{Name: "Bush"},
+		},
+		Scenes: []*gltf.Scene{{Nodes: []int{0, 1, 2, 4}}},
+	}
+	root := buildGLTF(e, doc, "trees.gltf")
+
+	tree := root.Find("Tree")
+	if tree == nil || tree.LODGroup() == nil {
+		t.Fatal("suffixed nodes were not grouped")
+	}
+	tg := tree.LODGroup()
+	if len(tg.Levels) != 2 || tg.Levels[0].Node.Name() != "Tree_LOD0" || tg.Levels[0].ScreenSize != 0.5 || tg.Levels[1].ScreenSize != 0 {
+		t.Errorf("suffix group levels = %+v", tg.Levels)
+	}
+
+	rock := root.Find("Rock")
+	if rock == nil || rock.LODGroup() == nil {
+		t.Fatal("MSFT_lod node has no group")
+	}
+	rg := rock.LODGroup()
+	if len(rg.Levels) != 2 || rg.Levels[0].Node.Name() != "Rock_LOD0" || rg.Levels[1].Node.Name() != "Rock_low" ||
+		rg.Levels[0].ScreenSize != 0.5 || rg.Levels[1].ScreenSize != 0.01 {
+		t.Errorf("MSFT_lod group levels = %+v", rg.Levels)
+	}
+	if rock.Transform().Col(3) != (mgl64.Vec4{0, 2, 0, 1}) || rg.Levels[0].Node.Transform() != mgl64.Ident4() {
+		t.Error("MSFT_lod group didn't take the node's transform")
+	}
+	if len(root.Children()) != 3 {
+		t.Errorf("root children = %d, want Bush, Rock and Tree", len(root.Children()))
+	}
+}
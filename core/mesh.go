@@ -5,7 +5,6 @@ import (
 	"sync/atomic"
 	"unsafe"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/go-gl/mathgl/mgl64"
 )
 
@@ -23,35 +22,50 @@ var nextMeshID uint32
 
 // Mesh holds geometry data backed by GPU buffers.
 type Mesh struct {
+	engine         *Engine
 	id             uint32
 	name           string
 	bounds         *AABB
 	primitiveType  PrimitiveType
 	indexCount     uint32
-	indexFormat    gpu.IndexFormat
-	positionBuffer gpu.Buffer
-	normalBuffer   gpu.Buffer
-	texCoordBuffer gpu.Buffer
-	indexBuffer    gpu.Buffer
-	instanceBuffer gpu.Buffer
+	indexFormat    IndexFormat
+	positionBuffer BufferHandle
+	normalBuffer   BufferHandle
+	texCoordBuffer BufferHandle
+	indexBuffer    BufferHandle
+	instanceBuffer BufferHandle
 	positionSize   uint64
 	normalSize     uint64
 	texCoordSize   uint64
 	indexSize      uint64
+
+	// cpu copies of the geometry, kept for ray casts if keepData is set
+	keepData  bool
+	positions []float32
+	indices   []uint32
+
+	// windowQuad screen quads follow the window size
+	windowQuad bool
 }
 
-// NewMesh creates a new empty mesh.
+// NewMesh creates a new empty mesh on the default engine.
 func NewMesh() *Mesh {
+	return newMesh(defaultEngine)
+}
+
+func newMesh(e *Engine) *Mesh {
 	m := &Mesh{
+		engine:      e,
 		id:          atomic.AddUint32(&nextMeshID, 1),
 		bounds:      NewAABB(),
-		indexFormat: gpu.IndexFormatUint16,
+		indexFormat: IndexFormatUint16,
+		keepData:    e.keepMeshData,
 	}
 	// Pre-allocate instance buffer for instanced drawing
-	if renderer != nil {
-		m.instanceBuffer = renderer.device.CreateBuffer(
+	if e.renderer != nil {
+		m.instanceBuffer = e.renderer.backend.CreateBuffer(
 			uint64(MaxInstances*InstanceDataLen),
-			gpu.BufferUsageVertex|gpu.BufferUsageCopyDst,
+			BufferUsageVertex|BufferUsageCopyDst,
 		)
 	}
 	return m
@@ -62,13 +76,32 @@ func (m *Mesh) SetName(name string)              { m.name = name }
 func (m *Mesh) Name() string                     { return m.name }
 func (m *Mesh) Bounds() *AABB                    { return m.bounds }
 
+// SetKeepData sets whether the mesh keeps a CPU copy of the positions and
+// indices it is given, so rays can be cast against its triangles. It only
+// applies to data set afterwards. Meshes start with the engine's setting.
+func (m *Mesh) SetKeepData(keep bool) {
+	m.keepData = keep
+	if !keep {
+		m.positions, m.indices = nil, nil
+	}
+}
+
+// Positions returns the CPU copy of the mesh's positions, or nil if it keeps none.
+func (m *Mesh) Positions() []float32 { return m.positions }
+
+// Indices returns the CPU copy of the mesh's indices, or nil if it keeps none.
+func (m *Mesh) Indices() []uint32 { return m.indices }
+
 func (m *Mesh) SetPositions(positions []float32) {
-	m.positionBuffer.Release()
+	releaseHandle(m.positionBuffer)
+	if m.keepData {
+		m.positions = append(m.positions[:0], positions...)
+	}
 	m.positionSize = uint64(len(positions) * 4)
-	m.positionBuffer = renderer.device.CreateBuffer(m.positionSize, gpu.BufferUsageVertex|gpu.BufferUsageCopyDst)
+	m.positionBuffer = m.engine.renderer.backend.CreateBuffer(m.positionSize, BufferUsageVertex|BufferUsageCopyDst)
 	var pinner runtime.Pinner
 	pinner.Pin(&positions[0])
-	renderer.queue.WriteBuffer(m.positionBuffer, 0, unsafe.Pointer(&positions[0]), m.positionSize)
+	m.engine.renderer.backend.WriteBuffer(m.positionBuffer, 0, unsafe.Pointer(&positions[0]), m.positionSize)
 	pinner.Unpin()
 
 	// grow bounds
@@ -82,53 +115,62 @@ func (m *Mesh) SetPositions(positions []float32) {
 }
 
 func (m *Mesh) SetNormals(normals []float32) {
-	m.normalBuffer.Release()
+	releaseHandle(m.normalBuffer)
 	m.normalSize = uint64(len(normals) * 4)
-	m.normalBuffer = renderer.device.CreateBuffer(m.normalSize, gpu.BufferUsageVertex|gpu.BufferUsageCopyDst)
+	m.normalBuffer = m.engine.renderer.backend.CreateBuffer(m.normalSize, BufferUsageVertex|BufferUsageCopyDst)
 	var pinner runtime.Pinner
 	pinner.Pin(&normals[0])
-	renderer.queue.WriteBuffer(m.normalBuffer, 0, unsafe.Pointer(&normals[0]), m.normalSize)
+	m.engine.renderer.backend.WriteBuffer(m.normalBuffer, 0, unsafe.Pointer(&normals[0]), m.normalSize)
 	pinner.Unpin()
 }
 
 func (m *Mesh) SetTextureCoordinates(texcoords []float32) {
-	m.texCoordBuffer.Release()
+	releaseHandle(m.texCoordBuffer)
 	m.texCoordSize = uint64(len(texcoords) * 4)
-	m.texCoordBuffer = renderer.device.CreateBuffer(m.texCoordSize, gpu.BufferUsageVertex|gpu.BufferUsageCopyDst)
+	m.texCoordBuffer = m.engine.renderer.backend.CreateBuffer(m.texCoordSize, BufferUsageVertex|BufferUsageCopyDst)
 	var pinner runtime.Pinner
 	pinner.Pin(&texcoords[0])
-	renderer.queue.WriteBuffer(m.texCoordBuffer, 0, unsafe.Pointer(&texcoords[0]), m.texCoordSize)
+	m.engine.renderer.backend.WriteBuffer(m.texCoordBuffer, 0, unsafe.Pointer(&texcoords[0]), m.texCoordSize)
 	pinner.Unpin()
 }
 
 func (m *Mesh) SetIndices(indices []uint16) {
-	m.indexBuffer.Release()
+	releaseHandle(m.indexBuffer)
+	if m.keepData {
+		m.indices = m.indices[:0]
+		for _, i := range indices {
+			m.indices = append(m.indices, uint32(i))
+		}
+	}
 	m.indexCount = uint32(len(indices))
-	m.indexFormat = gpu.IndexFormatUint16
+	m.indexFormat = IndexFormatUint16
 	rawSize := uint64(len(indices) * 2)
 	// wgpu requires buffer sizes and copy sizes aligned to 4 bytes
 	m.indexSize = (rawSize + 3) &^ 3
-	m.indexBuffer = renderer.device.CreateBuffer(m.indexSize, gpu.BufferUsageIndex|gpu.BufferUsageCopyDst)
+	m.indexBuffer = m.engine.renderer.backend.CreateBuffer(m.indexSize, BufferUsageIndex|BufferUsageCopyDst)
 	// Pad data to aligned size
 	padded := make([]byte, m.indexSize)
 	copy(padded, (*[1 << 30]byte)(unsafe.Pointer(&indices[0]))[:rawSize:rawSize])
 	var pinner runtime.Pinner
 	pinner.Pin(&padded[0])
-	renderer.queue.WriteBuffer(m.indexBuffer, 0, unsafe.Pointer(&padded[0]), m.indexSize)
+	m.engine.renderer.backend.WriteBuffer(m.indexBuffer, 0, unsafe.Pointer(&padded[0]), m.indexSize)
 	pinner.Unpin()
 }
 
 func (m *Mesh) SetIndices32(indices []uint32) {
-	m.indexBuffer.Release()
+	releaseHandle(m.indexBuffer)
+	if m.keepData {
+		m.indices = append(m.indices[:0], indices...)
+	}
 	m.indexCount = uint32(len(indices))
-	m.indexFormat = gpu.IndexFormatUint32
+	m.indexFormat = IndexFormatUint32
 	rawSize := uint64(len(indices) * 4)
 	// uint32 indices are already 4-byte aligned
 	m.indexSize = rawSize
-	m.indexBuffer = renderer.device.CreateBuffer(m.indexSize, gpu.BufferUsageIndex|gpu.BufferUsageCopyDst)
+	m.indexBuffer = m.engine.renderer.backend.CreateBuffer(m.indexSize, BufferUsageIndex|BufferUsageCopyDst)
 	var pinner runtime.Pinner
 	pinner.Pin(&indices[0])
-	renderer.queue.WriteBuffer(m.indexBuffer, 0, unsafe.Pointer(&indices[0]), m.indexSize)
+	m.engine.renderer.backend.WriteBuffer(m.indexBuffer, 0, unsafe.Pointer(&indices[0]), m.indexSize)
 	pinner.Unpin()
 }
 
@@ -144,7 +186,7 @@ func (m *Mesh) DrawInstanced(rp *RenderPass, instanceCount int, instanceData uns
 	dataSize := uint64(instanceCount * InstanceDataLen)
 	var pinner runtime.Pinner
 	pinner.Pin(instanceData)
-	renderer.queue.WriteBuffer(m.instanceBuffer, 0, instanceData, dataSize)
+	m.engine.renderer.backend.WriteBuffer(m.instanceBuffer, 0, instanceData, dataSize)
 	pinner.Unpin()
 	rp.SetVertexBuffer(0, m.positionBuffer, 0, m.positionSize)
 	rp.SetVertexBuffer(1, m.normalBuffer, 0, m.normalSize)
@@ -156,20 +198,25 @@ func (m *Mesh) DrawInstanced(rp *RenderPass, instanceCount int, instanceData uns
 
 // Dispose releases all GPU buffers held by the mesh.
 func (m *Mesh) Dispose() {
-	m.positionBuffer.Release()
-	m.normalBuffer.Release()
-	m.texCoordBuffer.Release()
-	m.indexBuffer.Release()
-	m.instanceBuffer.Release()
+	if m.windowQuad {
+		m.engine.renderer.untrackWindowTarget(m)
+	}
+	releaseHandle(m.positionBuffer)
+	releaseHandle(m.normalBuffer)
+	releaseHandle(m.texCoordBuffer)
+	releaseHandle(m.indexBuffer)
+	releaseHandle(m.instanceBuffer)
 }
 
 func (m *Mesh) ID() uint32 { return m.id }
 
-var aabbMesh *Mesh
-
 // NewScreenQuadMesh returns a mesh to be drawn by an orthographic projection camera.
 func NewScreenQuadMesh(width, height float32) *Mesh {
-	m := NewMesh()
+	return newScreenQuadMesh(defaultEngine, width, height)
+}
+
+func newScreenQuadMesh(e *Engine, width, height float32) *Mesh {
+	m := newMesh(e)
 	m.SetPrimitiveType(PrimitiveTypeTriangles)
 	m.SetPositions([]float32{
 		width * 0.0, height * 0.0, 0.0,
@@ -184,13 +231,45 @@ func NewScreenQuadMesh(width, height float32) *Mesh {
 	return m
 }
 
-// AABBMesh returns a normalized cube centered at the origin.
+// NewWindowQuadMesh returns a screen quad mesh covering the default engine's
+// window, which is recreated whenever the window is resized.
+func NewWindowQuadMesh() *Mesh {
+	return newWindowQuadMesh(defaultEngine)
+}
+
+func newWindowQuadMesh(e *Engine) *Mesh {
+	size := e.windowManager.WindowSize()
+	m := newScreenQuadMesh(e, size.X(), size.Y())
+	m.windowQuad = true
+	e.renderer.trackWindowTarget(m)
+	return m
+}
+
+// resizeToWindow rebuilds a window quad's positions for the new window size.
+func (m *Mesh) resizeToWindow(width, height uint32) {
+	w, h := float32(width), float32(height)
+	*m.bounds = *NewAABB()
+	m.SetPositions([]float32{
+		0, 0, 0,
+		w, 0, 0,
+		w, h, 0,
+		0, h, 0,
+	})
+}
+
+// AABBMesh returns a normalized cube centered at the origin, owned by the
+// default engine's renderer.
 func AABBMesh() *Mesh {
-	if aabbMesh != nil {
-		return aabbMesh
+	return defaultEngine.renderer.AABBMesh()
+}
+
+// AABBMesh returns the renderer's normalized cube centered at the origin.
+func (r *Renderer) AABBMesh() *Mesh {
+	if r.aabbMesh != nil {
+		return r.aabbMesh
 	}
 
-	aabbMesh = NewMesh()
+	aabbMesh := newMesh(r.engine)
 	aabbMesh.SetPrimitiveType(PrimitiveTypeLines)
 	aabbMesh.SetPositions([]float32{
 		-0.5, -0.5, -0.5, +0.5, -0.5, -0.5, +0.5, +0.5, -0.5, -0.5, +0.5, -0.5,
@@ -202,5 +281,6 @@ func AABBMesh() *Mesh {
 	aabbMesh.SetIndices([]uint16{0, 1, 1, 2, 2, 3, 3, 0, 4, 5, 5, 6, 6, 7, 7, 4, 0, 4, 1, 5, 2, 6, 3, 7})
 	aabbMesh.SetName("AABB")
 
+	r.aabbMesh = aabbMesh
 	return aabbMesh
 }
@@ -174,8 +174,13 @@ func skipField(data []byte, offset int, wireType uint64) int {
 	}
 }
 
-// LoadModel parses model data from a raw resource and returns a node.
+// LoadModel parses model data from a raw resource and returns a node. GPU
+// resources are created on the default engine.
 func LoadModel(name string, res []byte) (*Node, error) {
+	return loadModel(defaultEngine, name, res)
+}
+
+func loadModel(e *Engine, name string, res []byte) (*Node, error) {
 	m, err := parseModel(res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse model %s: %w", name, err)
@@ -185,7 +190,7 @@ func LoadModel(name string, res []byte) (*Node, error) {
 	parentNode := NewNode(basename)
 	for i := range m.Meshes {
 		node := NewNode(basename + fmt.Sprintf("-%d", i))
-		pipeline, err := resourceManager.Pipeline(m.Meshes[i].State)
+		pipeline, err := e.resourceManager.Pipeline(m.Meshes[i].State)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load pipeline for model %s: %w", name, err)
 		}
@@ -197,20 +202,26 @@ func LoadModel(name string, res []byte) (*Node, error) {
 			WrapMode: TextureWrapModeRepeat,
 		}
 
-		if len(m.Meshes[i].AlbedoMap) > 0 {
-			node.Material().SetTexture("albedoTex", renderer.NewTextureFromImageData(m.Meshes[i].AlbedoMap, textureDescriptor))
-		}
-		if len(m.Meshes[i].NormalMap) > 0 {
-			node.Material().SetTexture("normalTex", renderer.NewTextureFromImageData(m.Meshes[i].NormalMap, textureDescriptor))
-		}
-		if len(m.Meshes[i].RoughMap) > 0 {
-			node.Material().SetTexture("roughTex", renderer.NewTextureFromImageData(m.Meshes[i].RoughMap, textureDescriptor))
-		}
-		if len(m.Meshes[i].MetalMap) > 0 {
-			node.Material().SetTexture("metalTex", renderer.NewTextureFromImageData(m.Meshes[i].MetalMap, textureDescriptor))
+		for _, t := range []struct {
+			name string
+			data []byte
+		}{
+			{"albedoTex", m.Meshes[i].AlbedoMap},
+			{"normalTex", m.Meshes[i].NormalMap},
+			{"roughTex", m.Meshes[i].RoughMap},
+			{"metalTex", m.Meshes[i].MetalMap},
+		} {
+			if len(t.data) == 0 {
+				continue
+			}
+			tex, err := e.renderer.NewTextureFromImageData(t.data, textureDescriptor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s for model %s: %w", t.name, name, err)
+			}
+			node.Material().SetTexture(t.name, tex)
 		}
 
-		mesh := renderer.NewMesh()
+		mesh := e.renderer.NewMesh()
 		mesh.SetName(node.name)
 		mesh.SetPositions(bytesToFloat(m.Meshes[i].Positions))
 		mesh.SetNormals(bytesToFloat(m.Meshes[i].Normals))
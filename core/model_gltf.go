@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/draw"
@@ -10,25 +11,35 @@ import (
 	_ "image/png"
 	"math"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 
 	"github.com/go-gl/mathgl/mgl64"
 	"github.com/golang/glog"
 	"github.com/qmuntal/gltf"
 )
 
-// LoadGLTF loads a glTF/GLB file and returns a node tree.
+// LoadGLTF loads a glTF/GLB file and returns a node tree. GPU resources are
+// created on the default engine.
 func LoadGLTF(name string, resourceSystem ResourceSystem) (*Node, error) {
+	return loadGLTF(defaultEngine, name, resourceSystem)
+}
+
+func loadGLTF(e *Engine, name string, resourceSystem ResourceSystem) (*Node, error) {
 	path := resourceSystem.ModelPath(name)
 	doc, err := gltf.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open glTF %s: %w", name, err)
 	}
+	return buildGLTF(e, doc, filepath.Base(name)), nil
+}
 
-	basename := filepath.Base(name)
+func buildGLTF(e *Engine, doc *gltf.Document, basename string) *Node {
 	root := NewNode(basename)
 
 	if len(doc.Scenes) == 0 {
-		return root, nil
+		return root
 	}
 
 	sceneIdx := 0
@@ -38,14 +49,125 @@ func LoadGLTF(name string, resourceSystem ResourceSystem) (*Node, error) {
 	scene := doc.Scenes[sceneIdx]
 
 	for _, nodeIdx := range scene.Nodes {
-		child := loadGLTFNode(doc, nodeIdx, basename)
+		child := loadGLTFNode(e, doc, nodeIdx, basename)
 		root.AddChild(child)
 	}
+	groupLODSuffixes(root)
+
+	return root
+}
+
+// gltfLOD is the MSFT_lod node extension. The node itself is the most detailed
+// level and ids are the nodes of the following ones.
+type gltfLOD struct {
+	IDs []int `json:"ids"`
+}
+
+// lodSuffix matches the names of levels of detail exported as siblings, eg:
+// Tree_LOD0, Tree_LOD1.
+var lodSuffix = regexp.MustCompile(`^(.+)_LOD(\d+)$`)
+
+func loadGLTFNode(e *Engine, doc *gltf.Document, nodeIdx int, prefix string) *Node {
+	node := loadGLTFNodeContent(e, doc, nodeIdx, prefix)
+
+	raw, ok := doc.Nodes[nodeIdx].Extensions["MSFT_lod"].(json.RawMessage)
+	if !ok {
+		return node
+	}
+	var ext gltfLOD
+	if err := json.Unmarshal(raw, &ext); err != nil {
+		glog.Warningf("glTF: node %q has an invalid MSFT_lod extension: %v", node.name, err)
+		return node
+	}
+
+	// the group takes the first level's place and transform
+	group := NewNode(node.name)
+	group.transform, node.transform = node.transform, mgl64.Ident4()
+	node.name += "_LOD0"
+	levels := []*Node{node}
+	for _, id := range ext.IDs {
+		if id < 0 || id >= len(doc.Nodes) {
+			glog.Warningf("glTF: node %q has an invalid MSFT_lod id %d", group.name, id)
+			continue
+		}
+		levels = append(levels, loadGLTFNodeContent(e, doc, id, prefix))
+	}
+
+	// screen coverage is a fraction of the screen's area, the square root is
+	// close enough to the fraction of its height
+	sizes := defaultLODScreenSizes(len(levels))
+	if extras, ok := doc.Nodes[nodeIdx].Extras.(map[string]any); ok {
+		if coverage, ok := extras["MSFT_screencoverage"].([]any); ok {
+			for i := range min(len(coverage), len(levels)) {
+				if c, ok := coverage[i].(float64); ok {
+					sizes[i] = math.Sqrt(c)
+				}
+			}
+		}
+	}
+	setLODGroup(group, levels, sizes)
+	return group
+}
+
+// groupLODSuffixes replaces the children of a node named with a common prefix
+// and _LOD<n> suffixes with a node named by the prefix holding a LODGroup of
+// them, ordered by n.
+func groupLODSuffixes(node *Node) {
+	var bases []string
+	groups := make(map[string][]*Node)
+	for _, c := range node.children {
+		m := lodSuffix.FindStringSubmatch(c.name)
+		if m == nil {
+			continue
+		}
+		if _, ok := groups[m[1]]; !ok {
+			bases = append(bases, m[1])
+		}
+		groups[m[1]] = append(groups[m[1]], c)
+	}
+
+	for _, base := range bases {
+		levels := groups[base]
+		if len(levels) < 2 {
+			continue
+		}
+		sort.SliceStable(levels, func(i, j int) bool {
+			return lodIndex(levels[i].name) < lodIndex(levels[j].name)
+		})
+		group := NewNode(base)
+		for _, l := range levels {
+			node.RemoveChild(l)
+		}
+		setLODGroup(group, levels, defaultLODScreenSizes(len(levels)))
+		node.AddChild(group)
+	}
+}
+
+func lodIndex(name string) int {
+	i, _ := strconv.Atoi(lodSuffix.FindStringSubmatch(name)[2])
+	return i
+}
+
+// defaultLODScreenSizes halves the screen size of every level of detail. The
+// last level is never culled.
+func defaultLODScreenSizes(levels int) []float64 {
+	sizes := make([]float64, levels)
+	for i := 0; i < levels-1; i++ {
+		sizes[i] = 0.5 / float64(int(1)<<i)
+	}
+	return sizes
+}
 
-	return root, nil
+func setLODGroup(group *Node, levels []*Node, sizes []float64) {
+	g := NewLODGroup()
+	for i, l := range levels {
+		group.AddChild(l)
+		g.Levels = append(g.Levels, LODLevel{Node: l, ScreenSize: sizes[i]})
+	}
+	group.SetLODGroup(g)
 }
 
-func loadGLTFNode(doc *gltf.Document, nodeIdx int, prefix string) *Node {
+func loadGLTFNodeContent(e *Engine, doc *gltf.Document, nodeIdx int, prefix string) *Node {
 	gn := doc.Nodes[nodeIdx]
 	name := gn.Name
 	if name == "" {
@@ -82,21 +204,22 @@ func loadGLTFNode(doc *gltf.Document, nodeIdx int, prefix string) *Node {
 				primNode = NewNode(fmt.Sprintf("%s-prim%d", name, pi))
 				node.AddChild(primNode)
 			}
-			loadGLTFPrimitive(doc, gm.Primitives[pi], primNode)
+			loadGLTFPrimitive(e, doc, gm.Primitives[pi], primNode)
 		}
 	}
 
 	// Recurse children
 	for _, childIdx := range gn.Children {
-		child := loadGLTFNode(doc, childIdx, prefix)
+		child := loadGLTFNode(e, doc, childIdx, prefix)
 		node.AddChild(child)
 	}
+	groupLODSuffixes(node)
 
 	return node
 }
 
-func loadGLTFPrimitive(doc *gltf.Document, prim *gltf.Primitive, node *Node) {
-	mesh := NewMesh()
+func loadGLTFPrimitive(e *Engine, doc *gltf.Document, prim *gltf.Primitive, node *Node) {
+	mesh := newMesh(e)
 	mesh.SetName(node.Name())
 	mesh.SetPrimitiveType(PrimitiveTypeTriangles)
 
@@ -173,14 +296,14 @@ func loadGLTFPrimitive(doc *gltf.Document, prim *gltf.Primitive, node *Node) {
 			pbr := mat.PBRMetallicRoughness
 
 			if pbr.BaseColorTexture != nil {
-				tex := loadGLTFTexture(doc, pbr.BaseColorTexture.Index, texDesc)
+				tex := loadGLTFTexture(e, doc, pbr.BaseColorTexture.Index, texDesc)
 				if tex != nil {
 					node.Material().SetTexture("albedoTex", tex)
 				}
 			}
 
 			if pbr.MetallicRoughnessTexture != nil {
-				roughTex, metalTex := splitMetallicRoughnessTexture(doc, pbr.MetallicRoughnessTexture.Index, texDesc)
+				roughTex, metalTex := splitMetallicRoughnessTexture(e, doc, pbr.MetallicRoughnessTexture.Index, texDesc)
 				if roughTex != nil {
 					node.Material().SetTexture("roughTex", roughTex)
 				}
@@ -191,14 +314,14 @@ func loadGLTFPrimitive(doc *gltf.Document, prim *gltf.Primitive, node *Node) {
 		}
 
 		if mat.NormalTexture != nil {
-			tex := loadGLTFTexture(doc, *mat.NormalTexture.Index, texDesc)
+			tex := loadGLTFTexture(e, doc, *mat.NormalTexture.Index, texDesc)
 			if tex != nil {
 				node.Material().SetTexture("normalTex", tex)
 			}
 		}
 	}
 
-	pipeline, err := resourceManager.Pipeline(pipelineName)
+	pipeline, err := e.resourceManager.Pipeline(pipelineName)
 	if err != nil {
 		glog.Warningf("glTF: failed to load pipeline %q: %v", pipelineName, err)
 	} else {
@@ -206,7 +329,7 @@ func loadGLTFPrimitive(doc *gltf.Document, prim *gltf.Primitive, node *Node) {
 	}
 }
 
-func loadGLTFTexture(doc *gltf.Document, texIdx int, desc TextureDescriptor) *Texture {
+func loadGLTFTexture(e *Engine, doc *gltf.Document, texIdx int, desc TextureDescriptor) *Texture {
 	if texIdx >= len(doc.Textures) {
 		return nil
 	}
@@ -235,10 +358,15 @@ func loadGLTFTexture(doc *gltf.Document, texIdx int, desc TextureDescriptor) *Te
 		return nil
 	}
 
-	return renderer.NewTextureFromImageData(data, desc)
+	tex, err := e.renderer.NewTextureFromImageData(data, desc)
+	if err != nil {
+		glog.Warningf("glTF: %v", err)
+		return nil
+	}
+	return tex
 }
 
-func splitMetallicRoughnessTexture(doc *gltf.Document, texIdx int, desc TextureDescriptor) (*Texture, *Texture) {
+func splitMetallicRoughnessTexture(e *Engine, doc *gltf.Document, texIdx int, desc TextureDescriptor) (*Texture, *Texture) {
 	if texIdx >= len(doc.Textures) {
 		return nil, nil
 	}
@@ -299,8 +427,16 @@ func splitMetallicRoughnessTexture(doc *gltf.Document, texIdx int, desc TextureD
 		Mipmaps: desc.Mipmaps, Filter: desc.Filter, WrapMode: desc.WrapMode,
 	}
 
-	roughTex := renderer.NewTexture(texDesc, roughPixels)
-	metalTex := renderer.NewTexture(texDesc, metalPixels)
+	roughTex, err := e.renderer.NewTexture(texDesc, roughPixels)
+	if err != nil {
+		glog.Warningf("glTF: failed to create roughness texture: %v", err)
+		return nil, nil
+	}
+	metalTex, err := e.renderer.NewTexture(texDesc, metalPixels)
+	if err != nil {
+		glog.Warningf("glTF: failed to create metallic texture: %v", err)
+		return roughTex, nil
+	}
 	return roughTex, metalTex
 }
 
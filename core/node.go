@@ -1,14 +1,55 @@
 package core
 
 import (
+	"strings"
+	"sync/atomic"
+
 	"github.com/go-gl/mathgl/mgl64"
 )
 
+// graphVersion changes whenever any node is attached, detached or retagged so
+// scenes know when their lookup indices are stale.
+var graphVersion uint64
+
 // NodeCommand is an interface which wraps logic for running a command against a node.
 type NodeCommand interface {
 	Run(node *Node)
 }
 
+// VisitAction tells Node.Walk how to continue after visiting a node.
+type VisitAction int
+
+const (
+	// VisitContinue continues the walk normally.
+	VisitContinue VisitAction = iota
+
+	// VisitSkipChildren skips the node's children. It is only meaningful when returned from a pre hook.
+	VisitSkipChildren
+
+	// VisitStop ends the walk.
+	VisitStop
+)
+
+// VisitFunc is called for each node visited by Node.Walk.
+type VisitFunc func(n *Node) VisitAction
+
+// LayerMask is a set of up to 32 layers. Nodes are rendered by cameras whose
+// culling mask shares at least one layer with the node's layers.
+type LayerMask uint32
+
+const (
+	// LayerDefault is the layer nodes are created on.
+	LayerDefault LayerMask = 1 << 0
+
+	// LayerAll contains every layer. It is the default camera culling mask.
+	LayerAll LayerMask = ^LayerMask(0)
+)
+
+// Layer returns the mask for a single layer in [0, 32).
+func Layer(i uint) LayerMask {
+	return 1 << i
+}
+
 // BoundsCallbackFn is called if set to allow the user to specify logic to customise
 // a node's bounds. The bounds will have been grown to include any mesh or children. It's/
 // up to the user to decide whether to return the same bounds or a new AABB.
@@ -22,6 +63,9 @@ type Node struct {
 	active   bool
 	children []*Node
 	parent   *Node
+	tags     []string
+	layers   LayerMask
+	occluder bool
 
 	// transform and bounds in object space
 	transform      mgl64.Mat4
@@ -38,6 +82,12 @@ type Node struct {
 	inverseWorldTransform mgl64.Mat4
 	worldBounds           *AABB
 
+	// world transform before the last update, and the transform rendered
+	// between the two when the application runs in fixed steps
+	previousWorldTransform mgl64.Mat4
+	renderTransform        mgl64.Mat4
+	interpolated           bool
+
 	// state management
 	pipeline *Pipeline
 	material *Material
@@ -47,6 +97,11 @@ type Node struct {
 	mesh      *Mesh
 	light     *Light
 	rigidBody RigidBody
+	lodGroup  *LODGroup
+
+	// leaf in the bounding volume hierarchy indexing the node, if any
+	bvh     *bvh
+	bvhLeaf int
 
 	// possibly custom stuff
 	lightExtractor   LightExtractor
@@ -54,6 +109,16 @@ type Node struct {
 	updateComponent  Updater
 	cullComponent    Culler
 	physicsComponent PhysicsComponent
+
+	// model and scene include the node's children were instantiated from, and
+	// whether this node is one of them
+	model     string
+	include   string
+	overrides map[string]NodeOverride
+	instanced bool
+
+	// scene definition of the rigid body, kept so the scene can be saved
+	rigidBodyDef *RigidBodyDef
 }
 
 // NodesByMaterial is used to sort nodes according to material.
@@ -85,19 +150,26 @@ type NodesByCameraDistanceNearToFar struct {
 }
 
 // Len implements the sort.Interface interface.
-func (a NodesByCameraDistanceNearToFar) Len() int {
+func (a *NodesByCameraDistanceNearToFar) Len() int {
 	return len(a.Nodes)
 }
 
 // Swap implements the sort.Interface interface.
-func (a NodesByCameraDistanceNearToFar) Swap(i, j int) {
+func (a *NodesByCameraDistanceNearToFar) Swap(i, j int) {
+	a.compute()
 	a.Nodes[i], a.Nodes[j] = a.Nodes[j], a.Nodes[i]
 	a.nodePos[i], a.nodePos[j] = a.nodePos[j], a.nodePos[i]
 	a.nodeDist[i], a.nodeDist[j] = a.nodeDist[j], a.nodeDist[i]
 }
 
 // Less implements the sort.Interface interface.
-func (a NodesByCameraDistanceNearToFar) Less(i, j int) bool {
+func (a *NodesByCameraDistanceNearToFar) Less(i, j int) bool {
+	a.compute()
+	return a.nodeDist[i] < a.nodeDist[j]
+}
+
+// compute caches the node positions and distances on first use.
+func (a *NodesByCameraDistanceNearToFar) compute() {
 	if !a.computed {
 		a.refPos = a.RefNode.WorldPosition()
 		a.nodePos = make([]mgl64.Vec3, len(a.Nodes))
@@ -108,7 +180,6 @@ func (a NodesByCameraDistanceNearToFar) Less(i, j int) bool {
 		}
 		a.computed = true
 	}
-	return a.nodeDist[i] < a.nodeDist[j]
 }
 
 // NodesByName is used to sort nodes by alphabetic name order.
@@ -135,16 +206,18 @@ func (a NodesByName) Less(i, j int) bool {
 func NewNode(name string) *Node {
 	mat := NewMaterial()
 	n := Node{
-		name:           name,
-		transform:      mgl64.Ident4(),
-		worldTransform: mgl64.Ident4(),
-		active:         true,
-		bounds:         NewAABB(),
-		worldBounds:    NewAABB(),
-		material:       &mat,
-		children:       make([]*Node, 0),
-		dirtyBounds:    true,
-		dirtyTransform: true,
+		name:                   name,
+		transform:              mgl64.Ident4(),
+		worldTransform:         mgl64.Ident4(),
+		previousWorldTransform: mgl64.Ident4(),
+		active:                 true,
+		layers:                 LayerDefault,
+		bounds:                 NewAABB(),
+		worldBounds:            NewAABB(),
+		material:               &mat,
+		children:               make([]*Node, 0),
+		dirtyBounds:            true,
+		dirtyTransform:         true,
 
 		lightExtractor:   new(DefaultLightExtractor),
 		cullComponent:    new(DefaultCuller),
@@ -160,6 +233,42 @@ func (n *Node) Name() string {
 	return n.name
 }
 
+// Layers returns the layers the node is on.
+func (n *Node) Layers() LayerMask {
+	return n.layers
+}
+
+// SetLayers sets the layers the node is on. Layers are not inherited, a node's
+// children keep their own.
+func (n *Node) SetLayers(layers LayerMask) {
+	n.layers = layers
+}
+
+// Occluder returns whether the node hides what is behind it from cameras with
+// occlusion culling.
+func (n *Node) Occluder() bool {
+	return n.occluder
+}
+
+// SetOccluder sets whether the node hides what is behind it from cameras with
+// occlusion culling. Occluders are rasterised as their mesh bounds, so only
+// solid, roughly box shaped meshes such as walls and buildings should be
+// occluders.
+func (n *Node) SetOccluder(occluder bool) {
+	n.occluder = occluder
+}
+
+// LODGroup returns the node's level of detail group, or nil.
+func (n *Node) LODGroup() *LODGroup {
+	return n.lodGroup
+}
+
+// SetLODGroup sets the group selecting which of the node's children are drawn
+// from the node's size on screen.
+func (n *Node) SetLODGroup(g *LODGroup) {
+	n.lodGroup = g
+}
+
 // SetActive marks the node as active.
 func (n *Node) SetActive(active bool) {
 	n.active = active
@@ -233,6 +342,7 @@ func (n *Node) setDirtyBounds() {
 
 func (n *Node) setDirtyTransform() {
 	n.dirtyTransform = true
+	n.dirtyBounds = true
 	if n.parent != nil {
 		n.parent.setDirtyBounds()
 	}
@@ -278,7 +388,28 @@ func (n *Node) WorldTransform() mgl64.Mat4 {
 	return n.worldTransform
 }
 
-func (n *Node) update(s *Scene, dt float64) {
+// InterpolatedWorldTransform returns the node's world transform as it is
+// rendered. When the application runs in fixed steps this is blended between
+// the last two steps by the timer manager's Alpha, otherwise it is the world
+// transform.
+func (n *Node) InterpolatedWorldTransform() mgl64.Mat4 {
+	if n.interpolated {
+		return n.renderTransform
+	}
+	return n.worldTransform
+}
+
+// interpolate blends the node's previous and current world transforms.
+func (n *Node) interpolate(alpha float64) {
+	n.interpolated = n.previousWorldTransform != n.worldTransform
+	if n.interpolated {
+		n.renderTransform = InterpolateTransform(n.previousWorldTransform, n.worldTransform, alpha)
+	}
+}
+
+// processInput runs the input components of the node and its descendants,
+// once per frame.
+func (n *Node) processInput() {
 	// do we have an input component
 	if n.inputComponent != nil {
 		cmds := n.inputComponent.Run(n)
@@ -287,10 +418,26 @@ func (n *Node) update(s *Scene, dt float64) {
 		}
 	}
 
-	if n.updateComponent != nil {
+	// recurse
+	for _, c := range n.children {
+		c.processInput()
+	}
+}
+
+func (n *Node) update(s *Scene, dt float64) {
+	n.previousWorldTransform = n.worldTransform
+	n.interpolated = false
+
+	if tu, ok := n.updateComponent.(TimedUpdater); ok {
+		tu.RunTimed(n, dt)
+	} else if n.updateComponent != nil {
 		n.updateComponent.Run(n)
 	}
 
+	if n.lodGroup != nil && n.lodGroup.FadeDuration > 0 {
+		n.lodGroup.advance(dt)
+	}
+
 	// update our transforms
 	if n.dirtyTransform {
 		n.updateTransforms()
@@ -327,6 +474,21 @@ func (n *Node) SetUpdateComponent(uc Updater) {
 	n.updateComponent = uc
 }
 
+// SetPhysicsComponent sets the node's physics component.
+func (n *Node) SetPhysicsComponent(pc PhysicsComponent) {
+	n.physicsComponent = pc
+}
+
+// SetLightExtractor sets the node's light extractor.
+func (n *Node) SetLightExtractor(le LightExtractor) {
+	n.lightExtractor = le
+}
+
+// LightExtractor returns the node's light extractor.
+func (n *Node) LightExtractor() LightExtractor {
+	return n.lightExtractor
+}
+
 // CullComponent returns the node's culler
 func (n *Node) CullComponent() Culler {
 	return n.cullComponent
@@ -383,6 +545,10 @@ func (n *Node) updateBounds() {
 		n.worldBounds = n.bounds.Transformed(n.worldTransform)
 	}
 	n.dirtyBounds = false
+
+	if n.bvh != nil {
+		n.bvh.move(n)
+	}
 }
 
 func (n *Node) updateTransforms() {
@@ -422,6 +588,7 @@ func (n *Node) AddChild(c *Node) {
 	n.children = append(n.children, c)
 	c.parent = n
 	n.setDirtyBounds()
+	atomic.AddUint64(&graphVersion, 1)
 }
 
 // RemoveChild removes a node's child. Returns true if the child was found and removed.
@@ -431,6 +598,7 @@ func (n *Node) RemoveChild(c *Node) bool {
 			n.setDirtyBounds()
 			n.children = append(n.children[:i], n.children[i+1:]...)
 			c.parent = nil
+			atomic.AddUint64(&graphVersion, 1)
 			return true
 		}
 	}
@@ -448,33 +616,43 @@ func (n *Node) RemoveChildren() {
 		c.RemoveChildren()
 	}
 	n.children = make([]*Node, 0)
+	atomic.AddUint64(&graphVersion, 1)
 }
 
 // Copy deep copies a node.
 func (n *Node) Copy() *Node {
 	mat := NewMaterial()
 	nc := Node{
-		name:           n.name,
-		active:         n.active,
-		transform:      n.transform,
-		worldTransform: n.worldTransform,
-		inverseWorldTransform: n.inverseWorldTransform,
-		pipeline:       n.pipeline,
-		material:       &mat,
-		mesh:           n.mesh,
-		light:          n.light,
-		rigidBody:      n.rigidBody,
-		lightExtractor: n.lightExtractor,
-		updateComponent: n.updateComponent,
-		cullComponent:  n.cullComponent,
-		inputComponent: n.inputComponent,
-		physicsComponent: n.physicsComponent,
-		boundsCallback: n.boundsCallback,
-		bounds:         NewAABB(),
-		worldBounds:    NewAABB(),
-		dirtyTransform: true,
-		dirtyBounds:    true,
-		children:       make([]*Node, 0),
+		name:                   n.name,
+		active:                 n.active,
+		layers:                 n.layers,
+		occluder:               n.occluder,
+		transform:              n.transform,
+		worldTransform:         n.worldTransform,
+		inverseWorldTransform:  n.inverseWorldTransform,
+		previousWorldTransform: n.worldTransform,
+		pipeline:               n.pipeline,
+		material:               &mat,
+		mesh:                   n.mesh,
+		light:                  n.light,
+		rigidBody:              n.rigidBody,
+		lightExtractor:         n.lightExtractor,
+		updateComponent:        n.updateComponent,
+		cullComponent:          n.cullComponent,
+		inputComponent:         n.inputComponent,
+		physicsComponent:       n.physicsComponent,
+		boundsCallback:         n.boundsCallback,
+		model:                  n.model,
+		include:                n.include,
+		overrides:              n.overrides,
+		instanced:              n.instanced,
+		rigidBodyDef:           n.rigidBodyDef,
+		bounds:                 NewAABB(),
+		worldBounds:            NewAABB(),
+		dirtyTransform:         true,
+		dirtyBounds:            true,
+		children:               make([]*Node, 0),
+		tags:                   append([]string(nil), n.tags...),
 	}
 
 	// deep copy material data
@@ -489,6 +667,9 @@ func (n *Node) Copy() *Node {
 	for _, c := range n.children {
 		nc.AddChild(c.Copy())
 	}
+	if n.lodGroup != nil {
+		nc.lodGroup = n.lodGroup.copyFor(n, &nc)
+	}
 
 	return &nc
 }
@@ -513,3 +694,100 @@ func (n *Node) WorldBounds() *AABB {
 func (n *Node) WorldDistance(n2 *Node) float64 {
 	return n2.WorldPosition().Sub(n.WorldPosition()).Len()
 }
+
+// Find returns the descendant at a slash separated path of node names relative
+// to this node, eg: "Lamp/Bulb" for the Bulb child of this node's Lamp child.
+// The first match is used at each level. Returns nil if there is no such node.
+func (n *Node) Find(path string) *Node {
+	node := n
+	for _, name := range strings.Split(path, "/") {
+		var next *Node
+		for _, c := range node.children {
+			if c.name == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// FindAll returns this node and its descendants for which match returns true,
+// in depth first order.
+func (n *Node) FindAll(match func(*Node) bool) []*Node {
+	var nodes []*Node
+	n.Walk(func(c *Node) VisitAction {
+		if match(c) {
+			nodes = append(nodes, c)
+		}
+		return VisitContinue
+	}, nil)
+	return nodes
+}
+
+// Walk visits this node and its descendants depth first. pre is called before
+// a node's children are visited and post after, and either may be nil. Walk
+// returns VisitStop if a hook stopped the walk and VisitContinue otherwise.
+func (n *Node) Walk(pre, post VisitFunc) VisitAction {
+	if pre != nil {
+		switch pre(n) {
+		case VisitStop:
+			return VisitStop
+		case VisitSkipChildren:
+			if post != nil && post(n) == VisitStop {
+				return VisitStop
+			}
+			return VisitContinue
+		}
+	}
+
+	for _, c := range n.children {
+		if c.Walk(pre, post) == VisitStop {
+			return VisitStop
+		}
+	}
+
+	if post != nil && post(n) == VisitStop {
+		return VisitStop
+	}
+	return VisitContinue
+}
+
+// AddTag tags the node. Tagging a node twice has no effect.
+func (n *Node) AddTag(tag string) {
+	if n.HasTag(tag) {
+		return
+	}
+	n.tags = append(n.tags, tag)
+	atomic.AddUint64(&graphVersion, 1)
+}
+
+// RemoveTag removes a tag from the node.
+func (n *Node) RemoveTag(tag string) {
+	for i, t := range n.tags {
+		if t == tag {
+			n.tags = append(n.tags[:i], n.tags[i+1:]...)
+			atomic.AddUint64(&graphVersion, 1)
+			return
+		}
+	}
+}
+
+// HasTag returns whether the node has a tag.
+func (n *Node) HasTag(tag string) bool {
+	for _, t := range n.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns the node's tags.
+func (n *Node) Tags() []string {
+	return n.tags
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newQueryTree() *Node {
+	root := NewNode("ROOT")
+	lamp := NewNode("Lamp")
+	bulb := NewNode("Bulb")
+	bulb.AddTag("emissive")
+	lamp.AddChild(bulb)
+	lamp.AddChild(NewNode("Shade"))
+	root.AddChild(lamp)
+
+	other := NewNode("Lamp")
+	glow := NewNode("Bulb")
+	glow.AddTag("emissive")
+	other.AddChild(glow)
+	root.AddChild(other)
+	return root
+}
+
+func nodeNames(nodes []*Node) string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func TestNodeFind(t *testing.T) {
+	root := newQueryTree()
+	lamp := root.Children()[0]
+
+	tests := []struct {
+		from *Node
+		path string
+		want *Node
+	}{
+		{root, "Lamp", lamp},
+		{root, "Lamp/Bulb", lamp.Children()[0]},
+		{root, "Lamp/Shade", lamp.Children()[1]},
+		{lamp, "Shade", lamp.Children()[1]},
+		{root, "Lamp/Nope", nil},
+		{root, "Bulb", nil},
+		{root, "", nil},
+	}
+	for _, tt := range tests {
+		if got := tt.from.Find(tt.path); got != tt.want {
+			t.Errorf("%s.Find(%q) = %v, want %v", tt.from.Name(), tt.path, got, tt.want)
+		}
+	}
+
+	got := root.FindAll(func(n *Node) bool { return n.HasTag("emissive") })
+	if len(got) != 2 || got[0] != lamp.Children()[0] || got[1] != root.Children()[1].Children()[0] {
+		t.Errorf("FindAll(emissive) = %v", got)
+	}
+}
+
+func TestNodeWalk(t *testing.T) {
+	root := newQueryTree()
+
+	var order []string
+	record := func(prefix string, action VisitAction, on string) VisitFunc {
+		return func(n *Node) VisitAction {
+			order = append(order, prefix+n.Name())
+			if n.Name() == on {
+				return action
+			}
+			return VisitContinue
+		}
+	}
+
+	tests := []struct {
+		pre, post VisitFunc
+		result    VisitAction
+		want      string
+	}{
+		{record("+", VisitContinue, ""), record("-", VisitContinue, ""), VisitContinue,
+			"+ROOT,+Lamp,+Bulb,-Bulb,+Shade,-Shade,-Lamp,+Lamp,+Bulb,-Bulb,-Lamp,-ROOT"},
+		{record("+", VisitSkipChildren, "Lamp"), record("-", VisitContinue, ""), VisitContinue,
+			"+ROOT,+Lamp,-Lamp,+Lamp,-Lamp,-ROOT"},
+		{record("+", VisitStop, "Shade"), record("-", VisitContinue, ""), VisitStop,
+			"+ROOT,+Lamp,+Bulb,-Bulb,+Shade"},
+		{nil, record("-", VisitStop, "Lamp"), VisitStop,
+			"-Bulb,-Shade,-Lamp"},
+	}
+	for i, tt := range tests {
+		order = nil
+		if result := root.Walk(tt.pre, tt.post); result != tt.result {
+			t.Errorf("walk %d result = %v, want %v", i, result, tt.result)
+		}
+		if got := strings.Join(order, ","); got != tt.want {
+			t.Errorf("walk %d order = %s, want %s", i, got, tt.want)
+		}
+	}
+}
+
+func TestNodeTags(t *testing.T) {
+	n := NewNode("n")
+	n.AddTag("a")
+	n.AddTag("b")
+	n.AddTag("a")
+	if strings.Join(n.Tags(), ",") != "a,b" {
+		t.Errorf("tags = %v, want [a b]", n.Tags())
+	}
+	if c := n.Copy(); !c.HasTag("b") {
+		t.Error("copy lost its tags")
+	}
+	n.RemoveTag("a")
+	if n.HasTag("a") || !n.HasTag("b") {
+		t.Errorf("tags after removal = %v, want [b]", n.Tags())
+	}
+}
+
+func TestSceneFindNodes(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	src := `name: Query
+nodes:
+  - name: Lamp
+    tags: [prop]
+    children:
+      - name: Bulb
+        tags: [prop, emissive]
+  - name: Bulb
+`
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	lamp := scene.Root().Find("Lamp")
+	if scene.FindNode("Bulb") != lamp.Children()[0] || len(scene.FindNodes("Bulb")) != 2 {
+		t.Errorf("FindNodes(Bulb) = %v, want both bulbs starting with the lamp's", nodeNames(scene.FindNodes("Bulb")))
+	}
+	if got := nodeNames(scene.FindTagged("prop")); got != "Lamp,Bulb" {
+		t.Errorf("FindTagged(prop) = %s, want Lamp,Bulb", got)
+	}
+	if scene.FindNode("Nope") != nil {
+		t.Error("FindNode found a missing node")
+	}
+
+	// the lookups follow changes to the graph
+	extra := NewNode("Extra")
+	lamp.AddChild(extra)
+	if scene.FindNode("Extra") != extra {
+		t.Error("FindNode did not find an added node")
+	}
+	extra.AddTag("emissive")
+	if len(scene.FindTagged("emissive")) != 2 {
+		t.Errorf("FindTagged(emissive) = %v after tagging", nodeNames(scene.FindTagged("emissive")))
+	}
+	lamp.RemoveChild(extra)
+	if scene.FindNode("Extra") != nil {
+		t.Error("FindNode found a removed node")
+	}
+
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("tags: [prop, emissive]")) {
+		t.Errorf("saved scene lost the tags:\n%s", data)
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// obbEpsilon keeps the separating axis tests robust when edges are nearly
+// parallel and their cross products close to zero.
+const obbEpsilon = 1e-9
+
+// OBB is an oriented bounding box, given by its center, its unit axes and its
+// half extents along them.
+type OBB struct {
+	center      mgl64.Vec3
+	axes        [3]mgl64.Vec3
+	halfExtents mgl64.Vec3
+}
+
+// NewOBB returns an OBB with the given center, half extents and orientation
+func NewOBB(center, halfExtents mgl64.Vec3, orientation mgl64.Quat) *OBB {
+	m := orientation.Normalize().Mat4()
+	return &OBB{
+		center:      center,
+		axes:        [3]mgl64.Vec3{m.Col(0).Vec3(), m.Col(1).Vec3(), m.Col(2).Vec3()},
+		halfExtents: halfExtents,
+	}
+}
+
+// OBBFromAABB returns the OBB an AABB becomes when transformed by m, eg: the
+// world space box of a mesh's bounds. Shears are not supported.
+func OBBFromAABB(a *AABB, m mgl64.Mat4) *OBB {
+	o := &OBB{center: mgl64.TransformCoordinate(a.Center(), m)}
+	extents := a.Size().Mul(0.5)
+	for i := 0; i < 3; i++ {
+		axis := m.Col(i).Vec3()
+		scale := axis.Len()
+		if scale > 0 {
+			axis = axis.Mul(1 / scale)
+		}
+		o.axes[i] = axis
+		o.halfExtents[i] = extents[i] * scale
+	}
+	return o
+}
+
+// Center returns the OBB's center
+func (o *OBB) Center() mgl64.Vec3 {
+	return o.center
+}
+
+// Axes returns the OBB's unit axes
+func (o *OBB) Axes() [3]mgl64.Vec3 {
+	return o.axes
+}
+
+// HalfExtents returns the OBB's half extents along its axes
+func (o *OBB) HalfExtents() mgl64.Vec3 {
+	return o.halfExtents
+}
+
+// String implements the stringer interface
+func (o *OBB) String() string {
+	return fmt.Sprintf("OBB center: %v axes: %v halfExtents: %v", o.center, o.axes, o.halfExtents)
+}
+
+// Corners returns the OBB's eight corners, with bit 0 of the index selecting
+// the positive end of the first axis, bit 1 the second and bit 2 the third.
+func (o *OBB) Corners() [8]mgl64.Vec3 {
+	var corners [8]mgl64.Vec3
+	for i := range corners {
+		c := o.center
+		for j := 0; j < 3; j++ {
+			e := o.halfExtents[j]
+			if i&(1<<j) == 0 {
+				e = -e
+			}
+			c = c.Add(o.axes[j].Mul(e))
+		}
+		corners[i] = c
+	}
+	return corners
+}
+
+// AABB returns the smallest AABB containing the OBB
+func (o *OBB) AABB() *AABB {
+	a := NewAABB()
+	for _, c := range o.Corners() {
+		a.ExtendWithPoint(c)
+	}
+	return a
+}
+
+// local returns a point in the OBB's frame
+func (o *OBB) local(p mgl64.Vec3) mgl64.Vec3 {
+	d := p.Sub(o.center)
+	return mgl64.Vec3{d.Dot(o.axes[0]), d.Dot(o.axes[1]), d.Dot(o.axes[2])}
+}
+
+// ContainsPoint returns whether the OBB contains the given point
+func (o *OBB) ContainsPoint(p mgl64.Vec3) bool {
+	l := o.local(p)
+	for i := 0; i < 3; i++ {
+		if math.Abs(l[i]) > o.halfExtents[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ClosestPoint returns the point in the OBB closest to the given point
+func (o *OBB) ClosestPoint(p mgl64.Vec3) mgl64.Vec3 {
+	l := o.local(p)
+	q := o.center
+	for i := 0; i < 3; i++ {
+		d := math.Max(-o.halfExtents[i], math.Min(l[i], o.halfExtents[i]))
+		q = q.Add(o.axes[i].Mul(d))
+	}
+	return q
+}
+
+// radius returns the OBB's extent projected on an axis
+func (o *OBB) radius(axis mgl64.Vec3) float64 {
+	return o.halfExtents[0]*math.Abs(o.axes[0].Dot(axis)) +
+		o.halfExtents[1]*math.Abs(o.axes[1].Dot(axis)) +
+		o.halfExtents[2]*math.Abs(o.axes[2].Dot(axis))
+}
+
+// ClassifyPlane returns which side of a plane the OBB is on
+func (o *OBB) ClassifyPlane(plane mgl64.Vec4) PlaneSide {
+	n := plane.Vec3()
+	return planeSide(n.Dot(o.center)+plane[3], o.radius(n))
+}
+
+// InFrustum returns whether the OBB is at least partly inside the frustum
+// defined by the given planes
+func (o *OBB) InFrustum(planes [6]mgl64.Vec4) bool {
+	for _, p := range planes {
+		if o.ClassifyPlane(p) == PlaneBack {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsOBB returns whether two OBBs overlap, by separating axis tests on
+// the boxes' axes and the cross products of their axes.
+func (o *OBB) IntersectsOBB(b *OBB) bool {
+	// b's axes and center in o's frame
+	var r, absR mgl64.Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r.Set(i, j, o.axes[i].Dot(b.axes[j]))
+			absR.Set(i, j, math.Abs(r.At(i, j))+obbEpsilon)
+		}
+	}
+	t := o.local(b.center)
+	ea, eb := o.halfExtents, b.halfExtents
+
+	for i := 0; i < 3; i++ {
+		rb := eb[0]*absR.At(i, 0) + eb[1]*absR.At(i, 1) + eb[2]*absR.At(i, 2)
+		if math.Abs(t[i]) > ea[i]+rb {
+			return false
+		}
+	}
+	for j := 0; j < 3; j++ {
+		ra := ea[0]*absR.At(0, j) + ea[1]*absR.At(1, j) + ea[2]*absR.At(2, j)
+		if math.Abs(t[0]*r.At(0, j)+t[1]*r.At(1, j)+t[2]*r.At(2, j)) > ra+eb[j] {
+			return false
+		}
+	}
+
+	// axis o_i x b_j
+	for i := 0; i < 3; i++ {
+		i1, i2 := (i+1)%3, (i+2)%3
+		for j := 0; j < 3; j++ {
+			j1, j2 := (j+1)%3, (j+2)%3
+			ra := ea[i1]*absR.At(i2, j) + ea[i2]*absR.At(i1, j)
+			rb := eb[j1]*absR.At(i, j2) + eb[j2]*absR.At(i, j1)
+			if math.Abs(t[i2]*r.At(i1, j)-t[i1]*r.At(i2, j)) > ra+rb {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IntersectsAABB returns whether the OBB and the AABB overlap
+func (o *OBB) IntersectsAABB(a *AABB) bool {
+	return o.IntersectsOBB(OBBFromAABB(a, mgl64.Ident4()))
+}
+
+// IntersectsSphere returns whether the OBB and the sphere overlap
+func (o *OBB) IntersectsSphere(s *Sphere) bool {
+	return s.IntersectsOBB(o)
+}
+
+// IntersectsTriangle returns whether the OBB and the triangle overlap
+func (o *OBB) IntersectsTriangle(t Triangle) bool {
+	return boxIntersectsTriangle(o.halfExtents, [3]mgl64.Vec3{o.local(t[0]), o.local(t[1]), o.local(t[2])})
+}
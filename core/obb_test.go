@@ -0,0 +1,182 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// newTurnedOBB returns a unit cube centred at center, turned 45 degrees
+// around the z axis.
+func newTurnedOBB(center mgl64.Vec3) *OBB {
+	return NewOBB(center, mgl64.Vec3{1, 1, 1}, mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1}))
+}
+
+func TestOBB_NewOBB(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{1, 2, 3})
+	s := math.Sqrt2 / 2
+	want := [3]mgl64.Vec3{{s, s, 0}, {-s, s, 0}, {0, 0, 1}}
+	for i, axis := range o.Axes() {
+		if !vecNear(axis, want[i]) {
+			t.Errorf("axis %d = %v, want %v", i, axis, want[i])
+		}
+	}
+	if o.Center() != (mgl64.Vec3{1, 2, 3}) || o.HalfExtents() != (mgl64.Vec3{1, 1, 1}) {
+		t.Errorf("center %v half extents %v", o.Center(), o.HalfExtents())
+	}
+}
+
+func TestOBB_OBBFromAABB(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+
+	m := mgl64.Translate3D(5, 0, 0).Mul4(mgl64.HomogRotate3DZ(math.Pi / 4)).Mul4(mgl64.Scale3D(2, 1, 1))
+	o := OBBFromAABB(a, m)
+	if !vecNear(o.Center(), mgl64.Vec3{5, 0, 0}) {
+		t.Errorf("center = %v, want [5 0 0]", o.Center())
+	}
+	if !vecNear(o.HalfExtents(), mgl64.Vec3{2, 1, 1}) {
+		t.Errorf("half extents = %v, want [2 1 1]", o.HalfExtents())
+	}
+
+	// every transformed corner of the box is a corner of the OBB
+	corners := o.Corners()
+	for i, c := range boxCorners(a) {
+		if p := mgl64.TransformCoordinate(c, m); !vecNear(p, corners[i]) {
+			t.Errorf("corner %d = %v, want %v", i, corners[i], p)
+		}
+	}
+
+	// and its AABB is the AABB's transform
+	b, want := o.AABB(), a.Transformed(m)
+	if !vecNear(b.Min(), want.Min()) || !vecNear(b.Max(), want.Max()) {
+		t.Errorf("AABB = %v, want %v", b, want)
+	}
+}
+
+func TestOBB_ContainsPoint(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{})
+
+	tests := []struct {
+		point mgl64.Vec3
+		want  bool
+	}{
+		{mgl64.Vec3{0, 0, 0}, true},
+		{mgl64.Vec3{1.4, 0, 0}, true},
+		{mgl64.Vec3{1.5, 0, 0}, false},
+		{mgl64.Vec3{0.9, 0.9, 0}, false},
+		{mgl64.Vec3{0, 0, 1.1}, false},
+	}
+
+	for _, tt := range tests {
+		if got := o.ContainsPoint(tt.point); got != tt.want {
+			t.Errorf("ContainsPoint(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestOBB_ClosestPoint(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{})
+
+	tests := []struct {
+		point mgl64.Vec3
+		want  mgl64.Vec3
+	}{
+		{mgl64.Vec3{0.5, 0, 0}, mgl64.Vec3{0.5, 0, 0}},
+		// straight out of the corner on the x axis
+		{mgl64.Vec3{3, 0, 0}, mgl64.Vec3{math.Sqrt2, 0, 0}},
+		// out of a face
+		{mgl64.Vec3{2, 2, 0}, mgl64.Vec3{math.Sqrt2 / 2, math.Sqrt2 / 2, 0}},
+		{mgl64.Vec3{0, 0, -4}, mgl64.Vec3{0, 0, -1}},
+	}
+
+	for _, tt := range tests {
+		if got := o.ClosestPoint(tt.point); !vecNear(got, tt.want) {
+			t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestOBB_InFrustum(t *testing.T) {
+	proj := PerspectiveWebGPU(math.Pi/4, 1.0, 0.1, 100)
+	frustum := MakeFrustum(proj, mgl64.Ident4())
+
+	if !newTurnedOBB(mgl64.Vec3{0, 0, -10}).InFrustum(frustum) {
+		t.Error("OBB ahead of the camera should be in frustum")
+	}
+	if newTurnedOBB(mgl64.Vec3{0, 0, -200}).InFrustum(frustum) {
+		t.Error("OBB beyond the far plane should not be in frustum")
+	}
+	if newTurnedOBB(mgl64.Vec3{0, 0, 10}).InFrustum(frustum) {
+		t.Error("OBB behind the camera should not be in frustum")
+	}
+}
+
+func TestOBB_IntersectsOBB(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{})
+
+	tests := []struct {
+		name string
+		b    *OBB
+		want bool
+	}{
+		{"same", newTurnedOBB(mgl64.Vec3{}), true},
+		// the turned corner reaches sqrt(2) along x
+		{"corner", NewOBB(mgl64.Vec3{2.3, 0, 0}, mgl64.Vec3{1, 1, 1}, mgl64.QuatIdent()), true},
+		{"apart", NewOBB(mgl64.Vec3{2.5, 0, 0}, mgl64.Vec3{1, 1, 1}, mgl64.QuatIdent()), false},
+		// overlapping o's AABB but separated across the turned face
+		{"face", NewOBB(mgl64.Vec3{1.3, 1.3, 0}, mgl64.Vec3{0.2, 0.2, 0.2}, mgl64.QuatIdent()), false},
+		// a rod along x, separated only by the cross product of its axis
+		// and o's z axis
+		{"edges", NewOBB(mgl64.Vec3{0, 1.6, 0.9}, mgl64.Vec3{5, 0.1, 0.1}, mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{1, 0, 0})), false},
+		{"edges touching", NewOBB(mgl64.Vec3{0, 1.5, 0.9}, mgl64.Vec3{5, 0.1, 0.1}, mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{1, 0, 0})), true},
+	}
+
+	for _, tt := range tests {
+		if got := o.IntersectsOBB(tt.b); got != tt.want {
+			t.Errorf("%s: IntersectsOBB = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.b.IntersectsOBB(o); got != tt.want {
+			t.Errorf("%s: reversed IntersectsOBB = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOBB_IntersectsAABB(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{2.3, 0, 0})
+
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+	if !o.IntersectsAABB(a) || !a.IntersectsOBB(o) {
+		t.Error("OBB corner inside the AABB should intersect")
+	}
+
+	b := a.Transformed(mgl64.Translate3D(0, 2.5, 0))
+	if o.IntersectsAABB(b) || b.IntersectsOBB(o) {
+		t.Error("OBB and AABB apart should not intersect")
+	}
+}
+
+func TestOBB_IntersectsTriangle(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{})
+
+	tests := []struct {
+		name string
+		tri  Triangle
+		want bool
+	}{
+		{"through", Triangle{{-5, -5, 0}, {5, -5, 0}, {0, 5, 0}}, true},
+		{"corner", Triangle{{1.3, -1, -1}, {1.3, 1, -1}, {1.3, 0, 1}}, true},
+		// would hit the unturned box
+		{"past corner", Triangle{{0.9, 0.9, -1}, {2, 2, -1}, {2, 0.9, 1}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := o.IntersectsTriangle(tt.tri); got != tt.want {
+			t.Errorf("%s: IntersectsTriangle = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
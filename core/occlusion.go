@@ -0,0 +1,268 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// occlusionBufferWidth is the width of a camera's occlusion buffer in texels.
+// Its height follows the camera's aspect ratio.
+const occlusionBufferWidth = 256
+
+// occlusionDepthBias is how much farther than the occluders' depth a box must
+// be to be occluded. It absorbs the rounding of depths to float32, so
+// occluders aren't hidden by their own faces.
+const occlusionDepthBias = 1e-6
+
+// boxTriangles indexes the corners of an AABB, as ordered by boxCorners, into
+// the twelve triangles of its faces.
+var boxTriangles = [36]int{
+	0, 1, 3, 0, 3, 2, // -z
+	4, 6, 7, 4, 7, 5, // +z
+	0, 4, 5, 0, 5, 1, // -y
+	2, 3, 7, 2, 7, 6, // +y
+	0, 2, 6, 0, 6, 4, // -x
+	1, 5, 7, 1, 7, 3, // +x
+}
+
+// occlusionBuffer is a low resolution CPU depth buffer with a hierarchy of
+// max-depth levels, used to cull nodes hidden behind occluders. Depths follow
+// the WebGPU convention of 0 at the near plane and 1 at the far plane.
+type occlusionBuffer struct {
+	levels  [][]float32
+	sizes   [][2]int
+	polygon []mgl64.Vec4
+	clipped []mgl64.Vec4
+	hidden  map[*Node]bool
+}
+
+// reset clears the buffer to the far plane, resizing it if needed.
+func (ob *occlusionBuffer) reset(width, height int) {
+	if len(ob.sizes) == 0 || ob.sizes[0] != [2]int{width, height} {
+		ob.levels, ob.sizes = ob.levels[:0], ob.sizes[:0]
+		for {
+			ob.levels = append(ob.levels, make([]float32, width*height))
+			ob.sizes = append(ob.sizes, [2]int{width, height})
+			if width == 1 && height == 1 {
+				break
+			}
+			width, height = (width+1)/2, (height+1)/2
+		}
+	}
+	depth := ob.levels[0]
+	for i := range depth {
+		depth[i] = 1
+	}
+}
+
+// rasterizeBox renders the faces of a box in object space transformed by mvp.
+func (ob *occlusionBuffer) rasterizeBox(bounds *AABB, mvp mgl64.Mat4) {
+	corners := boxCorners(bounds)
+	var clip [8]mgl64.Vec4
+	for i, c := range corners {
+		clip[i] = mvp.Mul4x1(c.Vec4(1))
+	}
+	for i := 0; i < len(boxTriangles); i += 3 {
+		ob.rasterizeTriangle(clip[boxTriangles[i]], clip[boxTriangles[i+1]], clip[boxTriangles[i+2]])
+	}
+}
+
+// rasterizeTriangle clips a triangle in clip space against the near plane and
+// writes the nearest depth of the pixels whose centres it covers.
+func (ob *occlusionBuffer) rasterizeTriangle(a, b, c mgl64.Vec4) {
+	ob.polygon = append(ob.polygon[:0], a, b, c)
+	ob.clipped = ob.clipped[:0]
+	for i := range ob.polygon {
+		p, q := ob.polygon[i], ob.polygon[(i+1)%len(ob.polygon)]
+		if p[2] >= 0 {
+			ob.clipped = append(ob.clipped, p)
+		}
+		if (p[2] >= 0) != (q[2] >= 0) {
+			t := p[2] / (p[2] - q[2])
+			ob.clipped = append(ob.clipped, p.Add(q.Sub(p).Mul(t)))
+		}
+	}
+	if len(ob.clipped) < 3 {
+		return
+	}
+
+	v0 := ob.toScreen(ob.clipped[0])
+	for i := 1; i+1 < len(ob.clipped); i++ {
+		ob.fillTriangle(v0, ob.toScreen(ob.clipped[i]), ob.toScreen(ob.clipped[i+1]))
+	}
+}
+
+// toScreen maps a clip space position to texel coordinates and depth.
+func (ob *occlusionBuffer) toScreen(p mgl64.Vec4) mgl64.Vec3 {
+	w, h := float64(ob.sizes[0][0]), float64(ob.sizes[0][1])
+	return mgl64.Vec3{
+		(p[0]/p[3]*0.5 + 0.5) * w,
+		(0.5 - p[1]/p[3]*0.5) * h,
+		p[2] / p[3],
+	}
+}
+
+func (ob *occlusionBuffer) fillTriangle(a, b, c mgl64.Vec3) {
+	area := edge(a, b, c)
+	if area == 0 {
+		return
+	}
+
+	width, height := ob.sizes[0][0], ob.sizes[0][1]
+	x0 := max(int(math.Floor(min(a[0], b[0], c[0]))), 0)
+	x1 := min(int(math.Ceil(max(a[0], b[0], c[0]))), width-1)
+	y0 := max(int(math.Floor(min(a[1], b[1], c[1]))), 0)
+	y1 := min(int(math.Ceil(max(a[1], b[1], c[1]))), height-1)
+
+	depth := ob.levels[0]
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			p := mgl64.Vec3{float64(x) + 0.5, float64(y) + 0.5, 0}
+			w0, w1, w2 := edge(b, c, p)/area, edge(c, a, p)/area, edge(a, b, p)/area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			z := float32(w0*a[2] + w1*b[2] + w2*c[2])
+			if i := y*width + x; z < depth[i] {
+				depth[i] = z
+			}
+		}
+	}
+}
+
+func edge(a, b, p mgl64.Vec3) float64 {
+	return (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+}
+
+// buildHierarchy fills every level with the farthest depth of the 2x2 texels
+// below it.
+func (ob *occlusionBuffer) buildHierarchy() {
+	for l := 1; l < len(ob.levels); l++ {
+		src, dst := ob.levels[l-1], ob.levels[l]
+		sw, sh := ob.sizes[l-1][0], ob.sizes[l-1][1]
+		dw, dh := ob.sizes[l][0], ob.sizes[l][1]
+		for y := 0; y < dh; y++ {
+			for x := 0; x < dw; x++ {
+				d := float32(0)
+				for _, o := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+					sx, sy := min(2*x+o[0], sw-1), min(2*y+o[1], sh-1)
+					d = max(d, src[sy*sw+sx])
+				}
+				dst[y*dw+x] = d
+			}
+		}
+	}
+}
+
+// occluded returns whether a box in world space is hidden behind the
+// rasterised occluders. Boxes crossing the near plane or the buffer's edges
+// are never occluded.
+func (ob *occlusionBuffer) occluded(bounds *AABB, viewProjection mgl64.Mat4) bool {
+	minX, minY, minZ := math.Inf(1), math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range boxCorners(bounds) {
+		p := viewProjection.Mul4x1(c.Vec4(1))
+		if p[3] <= 0 || p[2] < 0 {
+			return false
+		}
+		s := ob.toScreen(p)
+		minX, maxX = min(minX, s[0]), max(maxX, s[0])
+		minY, maxY = min(minY, s[1]), max(maxY, s[1])
+		minZ = min(minZ, s[2])
+	}
+
+	width, height := ob.sizes[0][0], ob.sizes[0][1]
+	if minX < 0 || minY < 0 || maxX > float64(width) || maxY > float64(height) {
+		return false
+	}
+
+	z := float32(minZ) - occlusionDepthBias
+
+	// pick the level at which the box covers at most 4x4 texels
+	x0, x1 := int(minX), min(int(maxX), width-1)
+	y0, y1 := int(minY), min(int(maxY), height-1)
+	level := 0
+	for (x1-x0 > 3 || y1-y0 > 3) && level < len(ob.levels)-1 {
+		x0, x1, y0, y1 = x0/2, x1/2, y0/2, y1/2
+		level++
+	}
+
+	depth, w := ob.levels[level], ob.sizes[level][0]
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			if z <= depth[y*w+x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cull rasterises the camera's visible occluders and removes the nodes they
+// hide from its buckets. It returns the number of occluders, tested and culled
+// nodes.
+func (ob *occlusionBuffer) cull(c *Camera) (occluders, tested, culled int) {
+	height := max(int(math.Round(occlusionBufferWidth/c.Aspect())), 1)
+	ob.reset(occlusionBufferWidth, height)
+
+	viewProjection := c.projectionMatrix.Mul4(c.viewMatrix)
+	for _, n := range c.visibleOpaqueNodes {
+		if n.occluder {
+			ob.rasterizeBox(n.mesh.Bounds(), viewProjection.Mul4(n.worldTransform))
+			occluders++
+		}
+	}
+	c.occludedNodes = c.occludedNodes[:0]
+	if occluders == 0 {
+		return
+	}
+	ob.buildHierarchy()
+
+	if ob.hidden == nil {
+		ob.hidden = make(map[*Node]bool)
+	}
+	clear(ob.hidden)
+	for p, bucket := range c.pipelineBuckets {
+		visible := bucket[:0]
+		for _, n := range bucket {
+			tested++
+			if ob.occluded(n.worldBounds, viewProjection) {
+				ob.hidden[n] = true
+				c.occludedNodes = append(c.occludedNodes, n)
+				continue
+			}
+			visible = append(visible, n)
+		}
+		c.pipelineBuckets[p] = visible
+	}
+	culled = len(c.occludedNodes)
+
+	visible := c.visibleOpaqueNodes[:0]
+	for _, n := range c.visibleOpaqueNodes {
+		if !ob.hidden[n] {
+			visible = append(visible, n)
+		}
+	}
+	c.visibleOpaqueNodes = visible
+	return
+}
+
+// boxCorners returns the eight corners of a box, with bit 0 of the index
+// selecting max x, bit 1 max y and bit 2 max z.
+func boxCorners(b *AABB) [8]mgl64.Vec3 {
+	var corners [8]mgl64.Vec3
+	for i := range corners {
+		corners[i] = b.min
+		if i&1 != 0 {
+			corners[i][0] = b.max[0]
+		}
+		if i&2 != 0 {
+			corners[i][1] = b.max[1]
+		}
+		if i&4 != 0 {
+			corners[i][2] = b.max[2]
+		}
+	}
+	return corners
+}
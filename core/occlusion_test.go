@@ -0,0 +1,150 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestOcclusionBuffer(t *testing.T) {
+	viewProjection := PerspectiveWebGPU(mgl64.DegToRad(90), 1, 1, 100)
+	ob := &occlusionBuffer{}
+	ob.reset(64, 64)
+
+	// a wall at z=-10 covering the left half of the view
+	wall := &AABB{min: mgl64.Vec3{-20, -20, -11}, max: mgl64.Vec3{0, 20, -10}}
+	ob.rasterizeBox(wall, viewProjection)
+	ob.buildHierarchy()
+
+	depth := ob.levels[0]
+	if depth[32*64+10] >= 1 || depth[32*64+40] != 1 {
+		t.Errorf("depths = %v (left) %v (right), want the wall on the left only", depth[32*64+10], depth[32*64+40])
+	}
+	if top := ob.levels[len(ob.levels)-1][0]; top != 1 {
+		t.Errorf("top level depth = %v, want the farthest depth", top)
+	}
+
+	tests := []struct {
+		name     string
+		box      AABB
+		occluded bool
+	}{
+		{"behind", AABB{min: mgl64.Vec3{-8, -1, -31}, max: mgl64.Vec3{-6, 1, -30}}, true},
+		{"in front", AABB{min: mgl64.Vec3{-4, -1, -6}, max: mgl64.Vec3{-2, 1, -5}}, false},
+		{"beside", AABB{min: mgl64.Vec3{4, -1, -31}, max: mgl64.Vec3{6, 1, -30}}, false},
+		{"straddling", AABB{min: mgl64.Vec3{-2, -1, -31}, max: mgl64.Vec3{2, 1, -30}}, false},
+		{"crossing near plane", AABB{min: mgl64.Vec3{-8, -1, -31}, max: mgl64.Vec3{-6, 1, 1}}, false},
+	}
+	for _, tt := range tests {
+		if got := ob.occluded(&tt.box, viewProjection); got != tt.occluded {
+			t.Errorf("%s occluded = %v, want %v", tt.name, got, tt.occluded)
+		}
+	}
+}
+
+func TestOcclusionCulling(t *testing.T) {
+	e := newTestEngine(t)
+	backend := useRecordingRenderer(t, e)
+	scene, camera := newRecordingScene(t, e, "unlit")
+	pipeline := scene.Root().Children()[0].Pipeline()
+
+	// the recording scene's node is hidden by a wall filling the view
+	hidden := scene.Root().Children()[0]
+	hidden.Translate(mgl64.Vec3{0, 0, -10})
+	add := func(name string, z, scale float64) *Node {
+		n := NewNode(name)
+		n.SetMesh(newTriangleMesh(e))
+		n.SetPipeline(pipeline)
+		n.Translate(mgl64.Vec3{0, 0, z})
+		n.Scale(mgl64.Vec3{scale, scale, scale})
+		scene.Root().AddChild(n)
+		return n
+	}
+	wall := add("Wall", -5, 20)
+	wall.SetOccluder(true)
+	add("Front", -3, 0.5)
+
+	camera.SetOcclusionCulling(true)
+	scene.update(e, 0)
+	for i := 0; i < 2; i++ {
+		scene.cull(e)
+		nodes := camera.pipelineBuckets[pipeline]
+		if len(nodes) != 2 || len(camera.VisibleOpaqueNodes()) != 2 || nodes[0] == hidden || nodes[1] == hidden {
+			t.Fatalf("cull %d visible nodes = %d, want the wall and the front node", i, len(nodes))
+		}
+	}
+
+	// stats add up over the culls since the last frame
+	e.Renderer().BeginFrame()
+	want := FrameStats{Occluders: 2, OcclusionTested: 6, OcclusionCulled: 2}
+	if got := e.Renderer().Stats(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// hidden nodes still cast shadows
+	light := &Light{Shadower: newShadowMap(e, 256, 1)}
+	light.Block.Position = Vec4DoubleToFloat(mgl64.Vec4{1, 1, 1, 0})
+	light.Shadower.Render(light, camera)
+	e.Renderer().EndFrame()
+	instances := 0
+	for _, d := range backend.LastFrame().DrawCalls() {
+		instances += int(d.InstanceCount)
+	}
+	if instances != 3 {
+		t.Errorf("shadow instances = %d, want 3", instances)
+	}
+
+	if camera.SetOcclusionCulling(false); camera.OcclusionCulling() {
+		t.Error("occlusion culling was not disabled")
+	}
+	scene.cull(e)
+	if got := len(camera.pipelineBuckets[pipeline]); got != 3 {
+		t.Errorf("visible nodes without occlusion culling = %d, want 3", got)
+	}
+}
+
+func TestSceneOcclusion(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	src := `name: Occlusion
+nodes:
+  - name: Building
+    occluder: true
+    camera:
+      name: Street
+      projection: perspective
+      occlusionCulling: true
+`
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !scene.Root().Find("Building").Occluder() || !scene.Camera("Street").OcclusionCulling() {
+		t.Error("occlusion settings were not loaded")
+	}
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("occluder: true\n")) || !bytes.Contains(data, []byte("occlusionCulling: true\n")) {
+		t.Errorf("saved scene lost the occlusion settings:\n%s", data)
+	}
+}
+
+func TestOcclusionSelf(t *testing.T) {
+	viewProjection := PerspectiveWebGPU(mgl64.DegToRad(90), 1, 1, 100)
+	ob := &occlusionBuffer{}
+
+	// an occluder is tested against the buffer it was rasterised into, its
+	// front face must not hide it at any depth
+	for z := 2.0; z < 90; z += 0.37 {
+		box := AABB{min: mgl64.Vec3{-0.5, -0.5, -z - 1}, max: mgl64.Vec3{0.5, 0.5, -z}}
+		ob.reset(64, 64)
+		ob.rasterizeBox(&box, viewProjection)
+		ob.buildHierarchy()
+		if ob.occluded(&box, viewProjection) {
+			t.Errorf("box at z=%v occludes itself", -z)
+		}
+	}
+}
@@ -1,10 +1,6 @@
 package core
 
-import (
-	"log"
-
-	"github.com/go-gl/mathgl/mgl64"
-)
+import "github.com/go-gl/mathgl/mgl64"
 
 // PhysicsSystem is an interface which wraps all physics related logic.
 type PhysicsSystem interface {
@@ -39,16 +35,16 @@ type PhysicsSystem interface {
 	// NewSphereShape returns a collision shape.
 	NewSphereShape(radius float64) CollisionShape
 
-	// NewBoxShape returns a collision shape.
+	// NewBoxShape returns a collision shape with the given half extents.
 	NewBoxShape(mgl64.Vec3) CollisionShape
 
-	// NewCapsuleShape returns a collision shape.
+	// NewCapsuleShape returns a y aligned collision shape. height is the distance between the cap centres.
 	NewCapsuleShape(radius float64, height float64) CollisionShape
 
-	// NewConeShape returns a collision shape.
+	// NewConeShape returns a y aligned collision shape.
 	NewConeShape(radius float64, height float64) CollisionShape
 
-	// NewCylinderShape returns a collision shape.
+	// NewCylinderShape returns a y aligned collision shape. height is the half height.
 	NewCylinderShape(radius float64, height float64) CollisionShape
 
 	// NewCompoundSphereShape returns a collision shape.
@@ -57,7 +53,8 @@ type PhysicsSystem interface {
 	// NewConvexHullShape returns a collision shape.
 	NewConvexHullShape() CollisionShape
 
-	// NewStaticTriangleMeshShape returns a collision shape.
+	// NewStaticTriangleMeshShape returns a collision shape made of the triangles
+	// of the mesh's CPU positions and indices, or nil if it isn't supported.
 	NewStaticTriangleMeshShape(Mesh) CollisionShape
 
 	// DeleteShape deletes a collision shape.
@@ -86,21 +83,15 @@ type RigidBody interface {
 	ApplyImpulse(impulse mgl64.Vec3, localPosition mgl64.Vec3)
 }
 
-var (
-	physicsSystem PhysicsSystem
-)
-
-// SetPhysicsSystem is meant to be called from PhysicsSystem implementations on their init method
-func SetPhysicsSystem(ps PhysicsSystem) {
-	if physicsSystem != nil {
-		log.Fatal("Can't replace previously registered physics system. Please make sure you're not importing twice")
-	}
-	physicsSystem = ps
+// SetPhysicsSystem is meant to be called from PhysicsSystem implementations on their init method.
+// It registers the default engine's physics system and fails if one was already registered.
+func SetPhysicsSystem(ps PhysicsSystem) error {
+	return defaultEngine.SetPhysicsSystem(ps)
 }
 
-// GetPhysicsSystem returns the physics system.
+// GetPhysicsSystem returns the default engine's physics system.
 func GetPhysicsSystem() PhysicsSystem {
-	return physicsSystem
+	return defaultEngine.physicsSystem
 }
 
 // PhysicsComponent is an interface which wraps physics handling logic for a scenegraph node
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/golang/glog"
 )
 
@@ -140,7 +139,7 @@ type Pipeline struct {
 func ParsePipeline(data []byte) (*Pipeline, error) {
 	var s Pipeline
 	if err := json.Unmarshal(data, &s); err != nil {
-		return nil, err
+		return nil, jsonError(data, err)
 	}
 	return &s, nil
 }
@@ -159,18 +158,20 @@ type pipelineKey struct {
 	culling            bool
 	cullFace           CullFace
 	colorWrite         bool
-	colorTargetFormats [4]gpu.TextureFormat // up to 4 MRT targets
+	colorTargetFormats [4]PixelFormat // up to 4 MRT targets
 	numColorTargets    int
-	depthFormat        gpu.TextureFormat
+	depthFormat        PixelFormat
 }
 
 type pipelineCache struct {
-	cache map[pipelineKey]gpu.RenderPipeline
+	backend RenderBackend
+	cache   map[pipelineKey]PipelineHandle
 }
 
-func newPipelineCache() *pipelineCache {
+func newPipelineCache(backend RenderBackend) *pipelineCache {
 	return &pipelineCache{
-		cache: make(map[pipelineKey]gpu.RenderPipeline),
+		backend: backend,
+		cache:   make(map[pipelineKey]PipelineHandle),
 	}
 }
 
@@ -178,11 +179,11 @@ func (pc *pipelineCache) release() {
 	for _, p := range pc.cache {
 		p.Release()
 	}
-	pc.cache = make(map[pipelineKey]gpu.RenderPipeline)
+	pc.cache = make(map[pipelineKey]PipelineHandle)
 }
 
-func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats []gpu.TextureFormat, depthFormat gpu.TextureFormat) gpu.RenderPipeline {
-	var fmtArr [4]gpu.TextureFormat
+func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats []PixelFormat, depthFormat PixelFormat) PipelineHandle {
+	var fmtArr [4]PixelFormat
 	copy(fmtArr[:], colorFormats)
 
 	key := pipelineKey{
@@ -208,14 +209,15 @@ func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats
 	}
 
 	// Build the pipeline
-	desc := gpu.RenderPipelineDescriptor{
+	desc := PipelineDescriptor{
+		Label:        p.Name,
 		Layout:       program.pipelineLayout,
 		VertexModule: program.vertexModule,
 		VertexEntry:  "main",
 		FragmentModule: program.fragmentModule,
 		FragmentEntry:  "main",
 		Primitive:    stateTopology(p.Topology),
-		FrontFace:    gpu.FrontFaceCCW,
+		FrontFace:    FrontFaceCCW,
 	}
 
 	// Vertex buffer layouts — 4 slots matching the mesh
@@ -225,27 +227,27 @@ func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats
 	if p.Culling {
 		switch p.CullFace {
 		case CullBack:
-			desc.CullMode = gpu.CullModeBack
+			desc.CullMode = CullModeBack
 		case CullFront:
-			desc.CullMode = gpu.CullModeFront
+			desc.CullMode = CullModeFront
 		default:
-			desc.CullMode = gpu.CullModeBack
+			desc.CullMode = CullModeBack
 		}
 	} else {
-		desc.CullMode = gpu.CullModeNone
+		desc.CullMode = CullModeNone
 	}
 
 	// Color targets (multiple for MRT)
 	for _, colorFormat := range colorFormats {
-		if colorFormat == gpu.TextureFormatUndefined {
+		if colorFormat == PixelFormatUndefined {
 			continue
 		}
-		writeMask := gpu.ColorWriteMaskAll
+		writeMask := ColorWriteMaskAll
 		if !p.ColorWrite {
-			writeMask = gpu.ColorWriteMaskNone
+			writeMask = ColorWriteMaskNone
 		}
 
-		target := gpu.ColorTargetState{
+		target := ColorTargetState{
 			Format:    colorFormat,
 			WriteMask: writeMask,
 		}
@@ -253,13 +255,13 @@ func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats
 		if p.Blending {
 			srcFactor := mapBlendFactor(p.BlendSrcMode)
 			dstFactor := mapBlendFactor(p.BlendDstMode)
-			blendOp := gpu.BlendOperationAdd
+			blendOp := BlendOperationAdd
 			if p.BlendEquation == BlendFuncMax {
-				blendOp = gpu.BlendOperationMax
+				blendOp = BlendOperationMax
 			}
-			target.Blend = &gpu.BlendState{
-				Color: gpu.BlendComponent{SrcFactor: srcFactor, DstFactor: dstFactor, Operation: blendOp},
-				Alpha: gpu.BlendComponent{SrcFactor: srcFactor, DstFactor: dstFactor, Operation: blendOp},
+			target.Blend = &BlendState{
+				Color: BlendComponent{SrcFactor: srcFactor, DstFactor: dstFactor, Operation: blendOp},
+				Alpha: BlendComponent{SrcFactor: srcFactor, DstFactor: dstFactor, Operation: blendOp},
 			}
 		}
 
@@ -267,103 +269,103 @@ func (pc *pipelineCache) getOrCreate(p *Pipeline, program *Program, colorFormats
 	}
 
 	// Depth stencil
-	if depthFormat != gpu.TextureFormatUndefined {
-		depthCompare := gpu.CompareFunctionAlways
+	if depthFormat != PixelFormatUndefined {
+		depthCompare := CompareFunctionAlways
 		if p.DepthTest {
 			switch p.DepthFunc {
 			case DepthLess:
-				depthCompare = gpu.CompareFunctionLess
+				depthCompare = CompareFunctionLess
 			case DepthLessEqual:
-				depthCompare = gpu.CompareFunctionLessEqual
+				depthCompare = CompareFunctionLessEqual
 			case DepthEqual:
-				depthCompare = gpu.CompareFunctionEqual
+				depthCompare = CompareFunctionEqual
 			}
 		}
-		desc.DepthStencil = &gpu.DepthStencilState{
+		desc.DepthStencil = &DepthStencilState{
 			Format:            depthFormat,
 			DepthWriteEnabled: p.DepthWrite,
 			DepthCompare:      depthCompare,
 		}
 	}
 
-	pipeline := renderer.device.CreateRenderPipeline(desc)
+	pipeline := pc.backend.CreatePipeline(desc)
 	pc.cache[key] = pipeline
 	glog.Infof("Created pipeline: %s (program: %s)", p.Name, p.ProgramName)
 	return pipeline
 }
 
-func mapBlendFactor(mode BlendMode) gpu.BlendFactor {
+func mapBlendFactor(mode BlendMode) BlendFactor {
 	switch mode {
 	case BlendOne:
-		return gpu.BlendFactorOne
+		return BlendFactorOne
 	case BlendSrcAlpha:
-		return gpu.BlendFactorSrcAlpha
+		return BlendFactorSrcAlpha
 	case BlendOneMinusSrcAlpha:
-		return gpu.BlendFactorOneMinusSrcAlpha
+		return BlendFactorOneMinusSrcAlpha
 	default:
-		return gpu.BlendFactorOne
+		return BlendFactorOne
 	}
 }
 
 // standardVertexBufferLayouts returns the 4 vertex buffer slot layouts used by all mesh rendering.
-func standardVertexBufferLayouts() []gpu.VertexBufferLayout {
-	return []gpu.VertexBufferLayout{
+func standardVertexBufferLayouts() []VertexBufferLayout {
+	return []VertexBufferLayout{
 		// Slot 0: positions (float32x3)
 		{
 			ArrayStride: 12,
-			StepMode:    gpu.VertexStepModeVertex,
-			Attributes: []gpu.VertexAttribute{
-				{Format: gpu.VertexFormatFloat32x3, Offset: 0, ShaderLocation: 0},
+			StepMode:    VertexStepModeVertex,
+			Attributes: []VertexAttribute{
+				{Format: VertexFormatFloat32x3, Offset: 0, ShaderLocation: 0},
 			},
 		},
 		// Slot 1: normals (float32x3)
 		{
 			ArrayStride: 12,
-			StepMode:    gpu.VertexStepModeVertex,
-			Attributes: []gpu.VertexAttribute{
-				{Format: gpu.VertexFormatFloat32x3, Offset: 0, ShaderLocation: 1},
+			StepMode:    VertexStepModeVertex,
+			Attributes: []VertexAttribute{
+				{Format: VertexFormatFloat32x3, Offset: 0, ShaderLocation: 1},
 			},
 		},
 		// Slot 2: texcoords (float32x3)
 		{
 			ArrayStride: 12,
-			StepMode:    gpu.VertexStepModeVertex,
-			Attributes: []gpu.VertexAttribute{
-				{Format: gpu.VertexFormatFloat32x3, Offset: 0, ShaderLocation: 2},
+			StepMode:    VertexStepModeVertex,
+			Attributes: []VertexAttribute{
+				{Format: VertexFormatFloat32x3, Offset: 0, ShaderLocation: 2},
 			},
 		},
 		// Slot 3: instance data (InstanceDataLen bytes per instance)
 		{
 			ArrayStride: uint64(InstanceDataLen),
-			StepMode:    gpu.VertexStepModeInstance,
-			Attributes: []gpu.VertexAttribute{
+			StepMode:    VertexStepModeInstance,
+			Attributes: []VertexAttribute{
 				// Model matrix (4 x vec4f)
-				{Format: gpu.VertexFormatFloat32x4, Offset: 0, ShaderLocation: 3},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 16, ShaderLocation: 4},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 32, ShaderLocation: 5},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 48, ShaderLocation: 6},
+				{Format: VertexFormatFloat32x4, Offset: 0, ShaderLocation: 3},
+				{Format: VertexFormatFloat32x4, Offset: 16, ShaderLocation: 4},
+				{Format: VertexFormatFloat32x4, Offset: 32, ShaderLocation: 5},
+				{Format: VertexFormatFloat32x4, Offset: 48, ShaderLocation: 6},
 				// MVP matrix (4 x vec4f)
-				{Format: gpu.VertexFormatFloat32x4, Offset: 64, ShaderLocation: 7},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 80, ShaderLocation: 8},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 96, ShaderLocation: 9},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 112, ShaderLocation: 10},
+				{Format: VertexFormatFloat32x4, Offset: 64, ShaderLocation: 7},
+				{Format: VertexFormatFloat32x4, Offset: 80, ShaderLocation: 8},
+				{Format: VertexFormatFloat32x4, Offset: 96, ShaderLocation: 9},
+				{Format: VertexFormatFloat32x4, Offset: 112, ShaderLocation: 10},
 				// Custom data (4 x vec4f)
-				{Format: gpu.VertexFormatFloat32x4, Offset: 128, ShaderLocation: 11},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 144, ShaderLocation: 12},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 160, ShaderLocation: 13},
-				{Format: gpu.VertexFormatFloat32x4, Offset: 176, ShaderLocation: 14},
+				{Format: VertexFormatFloat32x4, Offset: 128, ShaderLocation: 11},
+				{Format: VertexFormatFloat32x4, Offset: 144, ShaderLocation: 12},
+				{Format: VertexFormatFloat32x4, Offset: 160, ShaderLocation: 13},
+				{Format: VertexFormatFloat32x4, Offset: 176, ShaderLocation: 14},
 			},
 		},
 	}
 }
 
-func stateTopology(t string) gpu.PrimitiveTopology {
+func stateTopology(t string) PrimitiveTopology {
 	switch t {
 	case "lines":
-		return gpu.PrimitiveTopologyLineList
+		return PrimitiveTopologyLineList
 	case "points":
-		return gpu.PrimitiveTopologyPointList
+		return PrimitiveTopologyPointList
 	default:
-		return gpu.PrimitiveTopologyTriangleList
+		return PrimitiveTopologyTriangleList
 	}
 }
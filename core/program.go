@@ -2,18 +2,18 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/golang/glog"
 )
 
 // Program holds a GPU shader program with shader modules and bind group layouts.
 type Program struct {
 	name             string
-	vertexModule     gpu.ShaderModule
-	fragmentModule   gpu.ShaderModule
-	bindGroupLayouts []gpu.BindGroupLayout
-	pipelineLayout   gpu.PipelineLayout
+	vertexModule     ShaderModuleHandle
+	fragmentModule   ShaderModuleHandle
+	bindGroupLayouts []BindGroupLayoutHandle
+	pipelineLayout   PipelineLayoutHandle
 	spec             programSpec
 }
 
@@ -54,23 +54,23 @@ func (p *Program) Name() string {
 	return p.name
 }
 
-func parseVisibility(vis []string) gpu.ShaderStage {
-	var stage gpu.ShaderStage
+func parseVisibility(vis []string) ShaderStage {
+	var stage ShaderStage
 	for _, v := range vis {
 		switch v {
 		case "vertex":
-			stage |= gpu.ShaderStageVertex
+			stage |= ShaderStageVertex
 		case "fragment":
-			stage |= gpu.ShaderStageFragment
+			stage |= ShaderStageFragment
 		}
 	}
 	return stage
 }
 
-func loadProgram(name string, data []byte) *Program {
+func loadProgram(r *Renderer, name string, data []byte) (*Program, error) {
 	var spec programSpec
 	if err := json.Unmarshal(data, &spec); err != nil {
-		glog.Fatalf("Error reading program spec %s: %v", name, err)
+		return nil, jsonError(data, err)
 	}
 
 	p := &Program{
@@ -80,61 +80,68 @@ func loadProgram(name string, data []byte) *Program {
 
 	// Load and compile shader modules
 	if vsFile, ok := spec.Shaders["vertex"]; ok {
-		vsSource := resourceManager.ProgramData(vsFile)
-		p.vertexModule = renderer.device.CreateShaderModuleWGSL(string(vsSource))
+		vsSource, err := r.engine.resourceManager.ProgramData(vsFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load vertex shader: %w", err)
+		}
+		p.vertexModule = r.backend.CreateShaderModule(string(vsSource))
 	}
 	if fsFile, ok := spec.Shaders["fragment"]; ok {
-		fsSource := resourceManager.ProgramData(fsFile)
-		p.fragmentModule = renderer.device.CreateShaderModuleWGSL(string(fsSource))
+		fsSource, err := r.engine.resourceManager.ProgramData(fsFile)
+		if err != nil {
+			releaseHandle(p.vertexModule)
+			return nil, fmt.Errorf("cannot load fragment shader: %w", err)
+		}
+		p.fragmentModule = r.backend.CreateShaderModule(string(fsSource))
 	}
 
 	// Create bind group layouts
-	p.bindGroupLayouts = make([]gpu.BindGroupLayout, len(spec.BindGroupLayouts))
+	p.bindGroupLayouts = make([]BindGroupLayoutHandle, len(spec.BindGroupLayouts))
 	for i, bglSpec := range spec.BindGroupLayouts {
-		entries := make([]gpu.BindGroupLayoutEntry, len(bglSpec.Entries))
+		entries := make([]BindGroupLayoutEntry, len(bglSpec.Entries))
 		for j, e := range bglSpec.Entries {
 			entries[j].Binding = e.Binding
 			entries[j].Visibility = parseVisibility(e.Visibility)
 
 			if e.Buffer != nil {
-				entries[j].Buffer = &gpu.BufferBindingLayout{
-					Type: gpu.BufferBindingTypeUniform,
+				entries[j].Buffer = &BufferBindingLayout{
+					Type: BufferBindingTypeUniform,
 				}
 			}
 			if e.Texture != nil {
-				sampleType := gpu.TextureSampleTypeFloat
+				sampleType := TextureSampleTypeFloat
 				if e.Texture.SampleType == "depth" {
-					sampleType = gpu.TextureSampleTypeDepth
+					sampleType = TextureSampleTypeDepth
 				} else if e.Texture.SampleType == "unfilterable-float" {
-					sampleType = gpu.TextureSampleTypeUnfilterableFloat
+					sampleType = TextureSampleTypeUnfilterableFloat
 				}
-				viewDim := gpu.TextureViewDimension2D
+				viewDim := TextureViewDimension2D
 				if e.Texture.ViewDimension == "2d-array" {
-					viewDim = gpu.TextureViewDimension2DArray
+					viewDim = TextureViewDimension2DArray
 				}
-				entries[j].Texture = &gpu.TextureBindingLayout{
+				entries[j].Texture = &TextureBindingLayout{
 					SampleType:    sampleType,
 					ViewDimension: viewDim,
 				}
 			}
 			if e.Sampler != nil {
-				samplerType := gpu.SamplerBindingTypeFiltering
+				samplerType := SamplerBindingTypeFiltering
 				if e.Sampler.Type == "non-filtering" {
-					samplerType = gpu.SamplerBindingTypeNonFiltering
+					samplerType = SamplerBindingTypeNonFiltering
 				} else if e.Sampler.Type == "comparison" {
-					samplerType = gpu.SamplerBindingTypeComparison
+					samplerType = SamplerBindingTypeComparison
 				}
-				entries[j].Sampler = &gpu.SamplerBindingLayout{
+				entries[j].Sampler = &SamplerBindingLayout{
 					Type: samplerType,
 				}
 			}
 		}
-		p.bindGroupLayouts[i] = renderer.device.CreateBindGroupLayout(entries)
+		p.bindGroupLayouts[i] = r.backend.CreateBindGroupLayout(entries)
 	}
 
 	// Create pipeline layout
-	p.pipelineLayout = renderer.device.CreatePipelineLayout(p.bindGroupLayouts)
+	p.pipelineLayout = r.backend.CreatePipelineLayout(p.bindGroupLayouts)
 
 	glog.Infof("Loaded program: %s", name)
-	return p
+	return p, nil
 }
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Ray is a half line from an origin along a direction. Distances along a ray
+// are in units of its direction's length, which NewRay makes 1.
+type Ray struct {
+	origin    mgl64.Vec3
+	direction mgl64.Vec3
+}
+
+// NewRay returns a ray from origin along the normalised direction
+func NewRay(origin, direction mgl64.Vec3) *Ray {
+	if direction.Len() > 0 {
+		direction = direction.Normalize()
+	}
+	return &Ray{origin: origin, direction: direction}
+}
+
+// Origin returns the ray's origin
+func (r *Ray) Origin() mgl64.Vec3 {
+	return r.origin
+}
+
+// Direction returns the ray's direction
+func (r *Ray) Direction() mgl64.Vec3 {
+	return r.direction
+}
+
+// String implements the stringer interface
+func (r *Ray) String() string {
+	return fmt.Sprintf("Ray origin: %v direction: %v", r.origin, r.direction)
+}
+
+// At returns the point at distance t along the ray
+func (r *Ray) At(t float64) mgl64.Vec3 {
+	return r.origin.Add(r.direction.Mul(t))
+}
+
+// Transformed returns the ray transformed by m. Its direction is not
+// normalised, so distances along it match those along the original ray.
+func (r *Ray) Transformed(m mgl64.Mat4) *Ray {
+	return &Ray{
+		origin:    mgl64.TransformCoordinate(r.origin, m),
+		direction: mgl64.TransformNormal(r.direction, m),
+	}
+}
+
+// ClosestPoint returns the point on the ray closest to the given point and
+// its distance along the ray
+func (r *Ray) ClosestPoint(p mgl64.Vec3) (mgl64.Vec3, float64) {
+	l := r.direction.Dot(r.direction)
+	if l == 0 {
+		return r.origin, 0
+	}
+	t := math.Max(0, p.Sub(r.origin).Dot(r.direction)/l)
+	return r.At(t), t
+}
+
+// IntersectsPlane returns the distance along the ray to a plane, for either
+// side of it. Rays parallel to the plane never hit it.
+func (r *Ray) IntersectsPlane(plane mgl64.Vec4) (float64, bool) {
+	n := plane.Vec3()
+	denom := n.Dot(r.direction)
+	if math.Abs(denom) < 1e-12 {
+		return 0, false
+	}
+	t := -(n.Dot(r.origin) + plane[3]) / denom
+	return t, t >= 0
+}
+
+// IntersectsAABB returns the distance along the ray to where it enters the
+// AABB, or 0 if it starts inside
+func (r *Ray) IntersectsAABB(a *AABB) (float64, bool) {
+	t, _, ok := a.intersectRay(r.origin, r.direction)
+	return t, ok
+}
+
+// IntersectsOBB returns the distance along the ray to where it enters the
+// OBB, or 0 if it starts inside
+func (r *Ray) IntersectsOBB(o *OBB) (float64, bool) {
+	local := &Ray{
+		origin:    o.local(r.origin),
+		direction: mgl64.Vec3{r.direction.Dot(o.axes[0]), r.direction.Dot(o.axes[1]), r.direction.Dot(o.axes[2])},
+	}
+	box := &AABB{min: o.halfExtents.Mul(-1), max: o.halfExtents}
+	return local.IntersectsAABB(box)
+}
+
+// IntersectsSphere returns the distance along the ray to where it enters the
+// sphere, or 0 if it starts inside
+func (r *Ray) IntersectsSphere(s *Sphere) (float64, bool) {
+	m := r.origin.Sub(s.center)
+	c := m.Dot(m) - s.radius*s.radius
+	if c <= 0 {
+		return 0, true
+	}
+
+	a := r.direction.Dot(r.direction)
+	b := m.Dot(r.direction)
+	if b > 0 || a == 0 {
+		return 0, false
+	}
+	disc := b*b - a*c
+	if disc < 0 {
+		return 0, false
+	}
+	return (-b - math.Sqrt(disc)) / a, true
+}
+
+// IntersectsTriangle returns the distance along the ray to a triangle and the
+// barycentric weights of its second and third corners at the hit. Either face
+// of the triangle can be hit.
+func (r *Ray) IntersectsTriangle(tri Triangle) (t, u, v float64, ok bool) {
+	e1, e2 := tri[1].Sub(tri[0]), tri[2].Sub(tri[0])
+	p := r.direction.Cross(e2)
+	det := e1.Dot(p)
+	if math.Abs(det) < 1e-12 {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+
+	s := r.origin.Sub(tri[0])
+	u = s.Dot(p) * inv
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q := s.Cross(e1)
+	v = r.direction.Dot(q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t = e2.Dot(q) * inv
+	return t, u, v, t >= 0
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestRay_NewRay(t *testing.T) {
+	r := NewRay(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{0, 0, -4})
+	if r.Origin() != (mgl64.Vec3{1, 2, 3}) || r.Direction() != (mgl64.Vec3{0, 0, -1}) {
+		t.Errorf("ray = %v, want a normalised direction", r)
+	}
+	if p := r.At(2); p != (mgl64.Vec3{1, 2, 1}) {
+		t.Errorf("At(2) = %v, want [1 2 1]", p)
+	}
+}
+
+func TestRay_Transformed(t *testing.T) {
+	r := NewRay(mgl64.Vec3{}, mgl64.Vec3{1, 0, 0})
+	m := mgl64.Translate3D(0, 1, 0).Mul4(mgl64.Scale3D(2, 2, 2))
+	got := r.Transformed(m)
+
+	// distances along the transformed ray are still the original ones
+	if !vecNear(got.At(3), mgl64.TransformCoordinate(r.At(3), m)) {
+		t.Errorf("transformed At(3) = %v, want %v", got.At(3), mgl64.TransformCoordinate(r.At(3), m))
+	}
+}
+
+func TestRay_ClosestPoint(t *testing.T) {
+	r := NewRay(mgl64.Vec3{}, mgl64.Vec3{1, 0, 0})
+
+	tests := []struct {
+		point mgl64.Vec3
+		want  mgl64.Vec3
+		t     float64
+	}{
+		{mgl64.Vec3{3, 4, 0}, mgl64.Vec3{3, 0, 0}, 3},
+		// behind the origin
+		{mgl64.Vec3{-3, 4, 0}, mgl64.Vec3{0, 0, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		if got, d := r.ClosestPoint(tt.point); got != tt.want || d != tt.t {
+			t.Errorf("ClosestPoint(%v) = %v %v, want %v %v", tt.point, got, d, tt.want, tt.t)
+		}
+	}
+}
+
+func TestRay_IntersectsPlane(t *testing.T) {
+	r := NewRay(mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0, -1, 0})
+
+	if d, ok := r.IntersectsPlane(mgl64.Vec4{0, 1, 0, -1}); !ok || d != 4 {
+		t.Errorf("plane hit = %v %v, want 4", d, ok)
+	}
+	if _, ok := r.IntersectsPlane(mgl64.Vec4{0, 1, 0, -6}); ok {
+		t.Error("plane behind the ray should not be hit")
+	}
+	if _, ok := r.IntersectsPlane(mgl64.Vec4{1, 0, 0, 0}); ok {
+		t.Error("parallel plane should not be hit")
+	}
+}
+
+func TestRay_IntersectsAABB(t *testing.T) {
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+
+	tests := []struct {
+		ray  *Ray
+		want float64
+		ok   bool
+	}{
+		{NewRay(mgl64.Vec3{0, 0, 5}, mgl64.Vec3{0, 0, -1}), 4, true},
+		{NewRay(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, -1}), 0, true},
+		{NewRay(mgl64.Vec3{0, 0, 5}, mgl64.Vec3{0, 0, 1}), 0, false},
+		{NewRay(mgl64.Vec3{2, 0, 5}, mgl64.Vec3{0, 0, -1}), 0, false},
+		{NewRay(mgl64.Vec3{-5, -5, 0}, mgl64.Vec3{1, 1, 0}), 4 * math.Sqrt2, true},
+	}
+
+	for _, tt := range tests {
+		if d, ok := tt.ray.IntersectsAABB(a); ok != tt.ok || math.Abs(d-tt.want) > 1e-9 {
+			t.Errorf("%v: IntersectsAABB = %v %v, want %v %v", tt.ray, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRay_IntersectsOBB(t *testing.T) {
+	o := newTurnedOBB(mgl64.Vec3{0, 0, -10})
+
+	tests := []struct {
+		ray  *Ray
+		want float64
+		ok   bool
+	}{
+		// onto the corner turned towards the ray
+		{NewRay(mgl64.Vec3{5, 0, -10}, mgl64.Vec3{-1, 0, 0}), 5 - math.Sqrt2, true},
+		{NewRay(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, -1}), 9, true},
+		// inside the unturned box, outside the turned one
+		{NewRay(mgl64.Vec3{0.9, 0.9, 0}, mgl64.Vec3{0, 0, -1}), 0, false},
+	}
+
+	for _, tt := range tests {
+		if d, ok := tt.ray.IntersectsOBB(o); ok != tt.ok || math.Abs(d-tt.want) > 1e-9 {
+			t.Errorf("%v: IntersectsOBB = %v %v, want %v %v", tt.ray, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRay_IntersectsSphere(t *testing.T) {
+	s := NewSphere(mgl64.Vec3{0, 0, -10}, 2)
+
+	tests := []struct {
+		ray  *Ray
+		want float64
+		ok   bool
+	}{
+		{NewRay(mgl64.Vec3{}, mgl64.Vec3{0, 0, -1}), 8, true},
+		{NewRay(mgl64.Vec3{0, 0, -9}, mgl64.Vec3{0, 0, -1}), 0, true},
+		{NewRay(mgl64.Vec3{}, mgl64.Vec3{0, 0, 1}), 0, false},
+		{NewRay(mgl64.Vec3{0, 3, 0}, mgl64.Vec3{0, 0, -1}), 0, false},
+		// grazing the top
+		{NewRay(mgl64.Vec3{0, 2, 0}, mgl64.Vec3{0, 0, -1}), 10, true},
+	}
+
+	for _, tt := range tests {
+		if d, ok := tt.ray.IntersectsSphere(s); ok != tt.ok || math.Abs(d-tt.want) > 1e-9 {
+			t.Errorf("%v: IntersectsSphere = %v %v, want %v %v", tt.ray, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRay_IntersectsTriangle(t *testing.T) {
+	tri := Triangle{{-1, -1, -5}, {1, -1, -5}, {0, 1, -5}}
+
+	r := NewRay(mgl64.Vec3{}, mgl64.Vec3{0, 0, -1})
+	d, u, v, ok := r.IntersectsTriangle(tri)
+	if !ok || d != 5 || u != 0.25 || v != 0.5 {
+		t.Errorf("hit = %v (%v, %v) %v, want 5 (0.25, 0.5)", d, u, v, ok)
+	}
+
+	// the back face is hit too
+	r = NewRay(mgl64.Vec3{0, 0, -10}, mgl64.Vec3{0, 0, 1})
+	if d, _, _, ok := r.IntersectsTriangle(tri); !ok || d != 5 {
+		t.Errorf("back face hit = %v %v, want 5", d, ok)
+	}
+
+	if _, _, _, ok := NewRay(mgl64.Vec3{0.9, 0.9, 0}, mgl64.Vec3{0, 0, -1}).IntersectsTriangle(tri); ok {
+		t.Error("ray beside the triangle should miss")
+	}
+	if _, _, _, ok := NewRay(mgl64.Vec3{}, mgl64.Vec3{0, 0, 1}).IntersectsTriangle(tri); ok {
+		t.Error("triangle behind the ray should not be hit")
+	}
+	if _, _, _, ok := NewRay(mgl64.Vec3{0, 0, -5}, mgl64.Vec3{1, 0, 0}).IntersectsTriangle(tri); ok {
+		t.Error("ray in the triangle's plane should not hit it")
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// RaycastHit describes where a ray hit a node's mesh.
+type RaycastHit struct {
+	Node *Node
+
+	// Distance from the ray's origin along its normalised direction.
+	Distance float64
+
+	// Position and Normal are in world space. The normal is the hit
+	// triangle's, following its winding, or the hit face of the mesh bounds.
+	Position mgl64.Vec3
+	Normal   mgl64.Vec3
+
+	// Barycentric holds the weights of the hit triangle's vertices and
+	// Triangle its index in the mesh. Meshes which keep no CPU data are hit
+	// on their bounds and report triangle -1.
+	Barycentric mgl64.Vec3
+	Triangle    int
+}
+
+// Raycast returns the nearest hit of a ray against the scene's active meshes.
+func (s *Scene) Raycast(origin, direction mgl64.Vec3) (RaycastHit, bool) {
+	return s.RaycastLayers(origin, direction, LayerAll)
+}
+
+// RaycastLayers returns the nearest hit of a ray against the scene's active
+// meshes on the given layers.
+func (s *Scene) RaycastLayers(origin, direction mgl64.Vec3, layers LayerMask) (RaycastHit, bool) {
+	var nearest RaycastHit
+	found := false
+	s.raycast(origin, direction, layers, func(h RaycastHit) float64 {
+		if !found || h.Distance < nearest.Distance {
+			nearest, found = h, true
+		}
+		return nearest.Distance
+	})
+	return nearest, found
+}
+
+// RaycastAll returns the hits of a ray against the scene's active meshes on
+// the given layers, nearest first. Each node is hit at most once.
+func (s *Scene) RaycastAll(origin, direction mgl64.Vec3, layers LayerMask) []RaycastHit {
+	var hits []RaycastHit
+	s.raycast(origin, direction, layers, func(h RaycastHit) float64 {
+		hits = append(hits, h)
+		return math.Inf(1)
+	})
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+	return hits
+}
+
+// raycast walks the subtrees whose world bounds the ray crosses within the
+// distance returned by the last hit, and tests their meshes.
+func (s *Scene) raycast(origin, direction mgl64.Vec3, layers LayerMask, hit func(RaycastHit) float64) {
+	if s.root == nil || direction.Len() == 0 {
+		return
+	}
+	direction = direction.Normalize()
+
+	maxDistance := math.Inf(1)
+	s.root.Walk(func(n *Node) VisitAction {
+		if !n.active {
+			return VisitSkipChildren
+		}
+		if t, _, ok := n.worldBounds.intersectRay(origin, direction); !ok || t > maxDistance {
+			return VisitSkipChildren
+		}
+		if n.mesh != nil && n.layers&layers != 0 {
+			if h, ok := raycastNode(n, origin, direction); ok && h.Distance <= maxDistance {
+				maxDistance = hit(h)
+			}
+		}
+		return VisitContinue
+	}, nil)
+}
+
+// raycastNode intersects a ray in world space with a node's mesh. The ray is
+// moved to the mesh's space without normalising it, so distances along it are
+// the same in both.
+func raycastNode(n *Node, origin, direction mgl64.Vec3) (RaycastHit, bool) {
+	ray := (&Ray{origin: origin, direction: direction}).Transformed(n.inverseWorldTransform)
+	m := n.mesh
+
+	h := RaycastHit{Node: n, Triangle: -1}
+	var normal mgl64.Vec3
+	if m.primitiveType != PrimitiveTypeTriangles || len(m.positions) == 0 {
+		t, axis, ok := m.bounds.intersectRay(ray.origin, ray.direction)
+		if !ok {
+			return h, false
+		}
+		h.Distance = t
+		if axis < 0 {
+			// the ray starts inside the bounds
+			normal = ray.direction.Mul(-1)
+		} else {
+			normal[axis] = -math.Copysign(1, ray.direction[axis])
+		}
+	} else {
+		triangles := len(m.indices) / 3
+		if m.indices == nil {
+			triangles = len(m.positions) / 9
+		}
+		for tri := 0; tri < triangles; tri++ {
+			triangle := m.triangle(tri)
+			t, u, v, ok := ray.IntersectsTriangle(triangle)
+			if !ok || (h.Triangle >= 0 && t >= h.Distance) {
+				continue
+			}
+			h.Distance, h.Triangle = t, tri
+			h.Barycentric = mgl64.Vec3{1 - u - v, u, v}
+			normal = triangle[1].Sub(triangle[0]).Cross(triangle[2].Sub(triangle[0]))
+		}
+		if h.Triangle < 0 {
+			return h, false
+		}
+	}
+
+	// normals go to world space with the inverse transpose
+	h.Position = origin.Add(direction.Mul(h.Distance))
+	h.Normal = n.inverseWorldTransform.Transpose().Mul4x1(normal.Vec4(0)).Vec3().Normalize()
+	return h, true
+}
+
+// triangle returns a triangle of a mesh which keeps its data.
+func (m *Mesh) triangle(i int) Triangle {
+	vertex := func(v int) mgl64.Vec3 {
+		if m.indices != nil {
+			v = int(m.indices[v])
+		}
+		p := m.positions[v*3 : v*3+3]
+		return mgl64.Vec3{float64(p[0]), float64(p[1]), float64(p[2])}
+	}
+	return Triangle{vertex(3 * i), vertex(3*i + 1), vertex(3*i + 2)}
+}
+
+// intersectRay returns the distance along a ray to where it enters the box,
+// and the axis of the face it enters through. Rays starting inside the box
+// hit it at distance 0 with axis -1.
+func (a *AABB) intersectRay(origin, direction mgl64.Vec3) (float64, int, bool) {
+	tmin, tmax, axis := 0.0, math.Inf(1), -1
+	for i := 0; i < 3; i++ {
+		if direction[i] == 0 {
+			if origin[i] < a.min[i] || origin[i] > a.max[i] {
+				return 0, -1, false
+			}
+			continue
+		}
+		t0 := (a.min[i] - origin[i]) / direction[i]
+		t1 := (a.max[i] - origin[i]) / direction[i]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > tmin {
+			tmin, axis = t0, i
+		}
+		tmax = min(tmax, t1)
+		if tmin > tmax {
+			return 0, -1, false
+		}
+	}
+	return tmin, axis, true
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func vecNear(a, b mgl64.Vec3) bool {
+	return a.Sub(b).Len() < 1e-9
+}
+
+func TestScreenPointToRay(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	_, camera := newRecordingScene(t, e)
+
+	origin, direction := camera.ScreenPointToRay(32, 32)
+	if !vecNear(origin, mgl64.Vec3{0, 0, -0.1}) || !vecNear(direction, mgl64.Vec3{0, 0, -1}) {
+		t.Errorf("centre ray = %v %v, want down -z from the near plane", origin, direction)
+	}
+
+	// the top right corner is 30 degrees up and right with a square viewport
+	_, direction = camera.ScreenPointToRay(64, 0)
+	tan := math.Tan(mgl64.DegToRad(30))
+	if want := (mgl64.Vec3{tan, tan, -1}).Normalize(); !vecNear(direction, want) {
+		t.Errorf("corner ray direction = %v, want %v", direction, want)
+	}
+
+	// mouse positions are in points, half the viewport's pixels at density 2
+	e.WindowManager().handleResize(32, 32, 64, 64)
+	_, direction = camera.ScreenPointToRay(16, 16)
+	if !vecNear(direction, mgl64.Vec3{0, 0, -1}) {
+		t.Errorf("centre ray direction at density 2 = %v, want -z", direction)
+	}
+	_, direction = camera.ScreenPointToRay(32, 0)
+	if want := (mgl64.Vec3{tan, tan, -1}).Normalize(); !vecNear(direction, want) {
+		t.Errorf("corner ray direction at density 2 = %v, want %v", direction, want)
+	}
+}
+
+func TestSceneRaycast(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	e.SetKeepMeshData(true)
+	scene, _ := newRecordingScene(t, e, "unlit", "unlit", "unlit", "unlit")
+	nodes := append([]*Node(nil), scene.Root().Children()...)
+	near, far, masked, facing := nodes[0], nodes[1], nodes[2], nodes[3]
+	far.Translate(mgl64.Vec3{0, 0, -10})
+	masked.Translate(mgl64.Vec3{0, 0, 5})
+	masked.SetLayers(Layer(2))
+
+	// turned to face +x, 3 units to the left
+	facing.Translate(mgl64.Vec3{-3, 0, 5})
+	facing.Rotate(90, mgl64.Vec3{0, 1, 0})
+
+	// a mesh without cpu data, hit on its bounds
+	boxMesh := newMesh(e)
+	boxMesh.SetKeepData(false)
+	boxMesh.SetPositions([]float32{-1, -1, -1, 1, 1, 1})
+	box := NewNode("Box")
+	box.SetMesh(boxMesh)
+	box.Translate(mgl64.Vec3{5, 0, -10})
+	scene.Root().AddChild(box)
+
+	inactive := near.Copy()
+	inactive.SetActive(false)
+	inactive.Translate(mgl64.Vec3{0, 0, 8})
+	scene.Root().AddChild(inactive)
+	scene.update(e, 0)
+
+	origin, forward := mgl64.Vec3{}, mgl64.Vec3{0, 0, -1}
+	h, ok := scene.RaycastLayers(origin, forward, LayerDefault)
+	if !ok || h.Node != near || math.Abs(h.Distance-10) > 1e-9 || h.Triangle != 0 {
+		t.Fatalf("hit = %+v, want the near triangle at 10", h)
+	}
+	if !vecNear(h.Position, mgl64.Vec3{0, 0, -10}) || !vecNear(h.Normal, mgl64.Vec3{0, 0, 1}) || !vecNear(h.Barycentric, mgl64.Vec3{0.25, 0.25, 0.5}) {
+		t.Errorf("hit position %v, normal %v, barycentric %v", h.Position, h.Normal, h.Barycentric)
+	}
+
+	if h, ok := scene.Raycast(origin, forward); !ok || h.Node != masked {
+		t.Errorf("all layers hit %v, want the layer 2 triangle", h.Node)
+	}
+
+	hits := scene.RaycastAll(origin, forward.Mul(3), LayerAll)
+	if len(hits) != 3 || hits[0].Node != masked || hits[1].Node != near || hits[2].Node != far || math.Abs(hits[2].Distance-20) > 1e-9 {
+		t.Errorf("RaycastAll hit %d nodes, want masked, near and far in order", len(hits))
+	}
+
+	// inside the near triangle's bounds but outside the triangle
+	if h, ok := scene.RaycastLayers(mgl64.Vec3{0.9, 0.9, 0}, forward, LayerDefault); ok {
+		t.Errorf("missed triangle hit %v", h.Node)
+	}
+
+	// the turned triangle, from the right
+	h, ok = scene.Raycast(mgl64.Vec3{0, 0, -5}, mgl64.Vec3{-1, 0, 0})
+	if !ok || h.Node != facing || math.Abs(h.Distance-3) > 1e-9 || !vecNear(h.Normal, mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("turned hit = %+v, want the facing triangle at 3 with a +x normal", h)
+	}
+
+	h, ok = scene.Raycast(mgl64.Vec3{5, 0, 0}, forward)
+	if !ok || h.Node != box || h.Triangle != -1 || math.Abs(h.Distance-9) > 1e-9 || !vecNear(h.Normal, mgl64.Vec3{0, 0, 1}) {
+		t.Errorf("bounds hit = %+v, want the box face at 9", h)
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import "unsafe"
+
+// Backend resource handles. Each backend returns its own implementations and
+// only ever receives back handles it created itself.
+type (
+	// BufferHandle is a backend vertex, index or uniform buffer.
+	BufferHandle interface{ Release() }
+
+	// TextureHandle is a backend texture.
+	TextureHandle interface{ Release() }
+
+	// TextureViewHandle is a backend texture view, used for sampling and as a pass attachment.
+	TextureViewHandle interface{ Release() }
+
+	// SamplerHandle is a backend sampler.
+	SamplerHandle interface{ Release() }
+
+	// ShaderModuleHandle is a compiled backend shader module.
+	ShaderModuleHandle interface{ Release() }
+
+	// BindGroupLayoutHandle is a backend bind group layout.
+	BindGroupLayoutHandle interface{ Release() }
+
+	// PipelineLayoutHandle is a backend pipeline layout.
+	PipelineLayoutHandle interface{ Release() }
+
+	// PipelineHandle is a backend render pipeline.
+	PipelineHandle interface{ Release() }
+
+	// BindGroupHandle is a backend bind group.
+	BindGroupHandle interface{ Release() }
+
+	// SurfaceHandle is a backend presentable surface of a secondary window.
+	SurfaceHandle interface{ Release() }
+)
+
+// TextureViewDescriptor selects which part of a texture a view covers. A zero
+// descriptor views the whole texture with its default dimension.
+type TextureViewDescriptor struct {
+	Dimension       TextureViewDimension
+	BaseArrayLayer  uint32
+	ArrayLayerCount uint32
+}
+
+// BindGroupEntry binds a buffer, texture view or sampler to a binding slot.
+type BindGroupEntry struct {
+	Binding     uint32
+	Buffer      BufferHandle
+	Offset      uint64
+	Size        uint64
+	Sampler     SamplerHandle
+	TextureView TextureViewHandle
+}
+
+// PipelineDescriptor describes a render pipeline. Label is informational and
+// used by backends for debugging and recording.
+type PipelineDescriptor struct {
+	Label          string
+	Layout         PipelineLayoutHandle
+	VertexModule   ShaderModuleHandle
+	VertexEntry    string
+	FragmentModule ShaderModuleHandle
+	FragmentEntry  string
+	Buffers        []VertexBufferLayout
+	Targets        []ColorTargetState
+	Primitive      PrimitiveTopology
+	FrontFace      FrontFace
+	CullMode       CullMode
+	DepthStencil   *DepthStencilState
+}
+
+// RenderPassEncoder records commands for a single render pass.
+type RenderPassEncoder interface {
+	SetPipeline(PipelineHandle)
+	SetVertexBuffer(slot uint32, buf BufferHandle, offset, size uint64)
+	SetIndexBuffer(buf BufferHandle, format IndexFormat, offset, size uint64)
+	SetBindGroup(group uint32, bg BindGroupHandle)
+	SetViewport(x, y, w, h, minDepth, maxDepth float32)
+	SetScissorRect(x, y, w, h uint32)
+	DrawIndexed(indexCount, instanceCount, firstIndex uint32, baseVertex int32, firstInstance uint32)
+	End()
+}
+
+// RenderBackend is the device layer behind Renderer. It owns resource creation,
+// pass encoding and submission. The wgpu backend talks to the GPU, the
+// recording backend captures what would have been submitted.
+type RenderBackend interface {
+	// SurfaceFormat returns the color format of the presentable surface.
+	SurfaceFormat() PixelFormat
+
+	// SurfaceSize returns the size of the presentable surface in pixels.
+	SurfaceSize() (uint32, uint32)
+
+	// ResizeSurface reconfigures a presentable surface for a new size in
+	// pixels. A nil surface is the main window's.
+	ResizeSurface(surface SurfaceHandle, width, height uint32)
+
+	// CreateSurface creates the presentable surface of a secondary window
+	// from its CAMetalLayer.
+	CreateSurface(layer unsafe.Pointer, width, height uint32) (SurfaceHandle, error)
+
+	// AcquireSurface acquires the next image of a secondary surface for the
+	// frame, which EndFrame presents. It returns false if the surface can't
+	// be drawn this frame.
+	AcquireSurface(surface SurfaceHandle) bool
+
+	CreateBuffer(size uint64, usage BufferUsage) BufferHandle
+	WriteBuffer(buf BufferHandle, offset uint64, data unsafe.Pointer, size uint64)
+	CreateTexture(desc TextureStorageDescriptor) TextureHandle
+	WriteTexture(tex TextureHandle, data []byte, bytesPerRow, width, height uint32)
+	CreateTextureView(tex TextureHandle, desc TextureViewDescriptor) TextureViewHandle
+	CreateSampler(desc SamplerDescriptor) SamplerHandle
+	CreateShaderModule(source string) ShaderModuleHandle
+	CreateBindGroupLayout(entries []BindGroupLayoutEntry) BindGroupLayoutHandle
+	CreatePipelineLayout(layouts []BindGroupLayoutHandle) PipelineLayoutHandle
+	CreatePipeline(desc PipelineDescriptor) PipelineHandle
+	CreateBindGroup(layout BindGroupLayoutHandle, entries []BindGroupEntry) BindGroupHandle
+
+	// BeginFrame acquires the next surface image. It returns false if the
+	// frame must be skipped.
+	BeginFrame() bool
+
+	// BeginRenderPass starts a pass. Color attachments with a nil view
+	// target the image of their surface, or of the main surface.
+	BeginRenderPass(desc RenderPassDescriptor) RenderPassEncoder
+
+	// Flush submits the commands recorded so far and continues the frame.
+	Flush()
+
+	// EndFrame submits the remaining commands and presents.
+	EndFrame()
+
+	// Shutdown releases the device.
+	Shutdown()
+}
+
+// releaseHandle releases h if it was ever created.
+func releaseHandle(h interface{ Release() }) {
+	if h != nil {
+		h.Release()
+	}
+}
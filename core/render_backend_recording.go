@@ -0,0 +1,241 @@
+package core
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// RecordedDraw is an indexed draw call captured by the recording backend.
+type RecordedDraw struct {
+	Pipeline      string
+	IndexCount    uint32
+	InstanceCount uint32
+}
+
+// RecordedPass is a render pass captured by the recording backend.
+type RecordedPass struct {
+	Label            string
+	ColorAttachments int
+	DepthAttachment  bool
+	Viewport         mgl32.Vec4
+
+	// Surface is the secondary surface the pass draws into, nil for the
+	// main surface and offscreen targets
+	Surface SurfaceHandle
+
+	// Pipelines lists the pipeline binds in the order they happened
+	Pipelines []string
+	Draws     []RecordedDraw
+}
+
+// RecordedFrame holds everything the recording backend was asked to submit in a frame.
+type RecordedFrame struct {
+	Passes  []RecordedPass
+	Flushes int
+}
+
+// DrawCalls returns all draws in the frame, in submission order.
+func (f RecordedFrame) DrawCalls() []RecordedDraw {
+	var draws []RecordedDraw
+	for _, p := range f.Passes {
+		draws = append(draws, p.Draws...)
+	}
+	return draws
+}
+
+// recordedResource is the handle type handed out by the recording backend.
+type recordedResource struct {
+	label string
+}
+
+func (r *recordedResource) Release() {}
+
+// recordedSurface is the virtual surface of a secondary window.
+type recordedSurface struct {
+	width, height uint32
+}
+
+func (s *recordedSurface) Release() {}
+
+// RecordingBackend is a RenderBackend that never touches a GPU. Resources are
+// placeholders; passes, pipeline binds and draw calls are recorded per frame.
+type RecordingBackend struct {
+	width, height uint32
+	frame         RecordedFrame
+	lastFrame     RecordedFrame
+}
+
+// NewRecordingBackend returns a recording backend with a virtual surface of the given size.
+func NewRecordingBackend(width, height uint32) *RecordingBackend {
+	return &RecordingBackend{width: width, height: height}
+}
+
+// LastFrame returns the last completed frame.
+func (b *RecordingBackend) LastFrame() RecordedFrame {
+	return b.lastFrame
+}
+
+// CurrentFrame returns the frame being recorded.
+func (b *RecordingBackend) CurrentFrame() RecordedFrame {
+	return b.frame
+}
+
+// SurfaceFormat implements the RenderBackend interface
+func (b *RecordingBackend) SurfaceFormat() PixelFormat {
+	return PixelFormatBGRA8Unorm
+}
+
+// SurfaceSize implements the RenderBackend interface
+func (b *RecordingBackend) SurfaceSize() (uint32, uint32) {
+	return b.width, b.height
+}
+
+// ResizeSurface implements the RenderBackend interface
+func (b *RecordingBackend) ResizeSurface(surface SurfaceHandle, width, height uint32) {
+	if s, ok := surface.(*recordedSurface); ok {
+		s.width, s.height = width, height
+		return
+	}
+	b.width, b.height = width, height
+}
+
+// CreateSurface implements the RenderBackend interface
+func (b *RecordingBackend) CreateSurface(layer unsafe.Pointer, width, height uint32) (SurfaceHandle, error) {
+	return &recordedSurface{width: width, height: height}, nil
+}
+
+// AcquireSurface implements the RenderBackend interface
+func (b *RecordingBackend) AcquireSurface(surface SurfaceHandle) bool {
+	return true
+}
+
+// CreateBuffer implements the RenderBackend interface
+func (b *RecordingBackend) CreateBuffer(size uint64, usage BufferUsage) BufferHandle {
+	return &recordedResource{}
+}
+
+// WriteBuffer implements the RenderBackend interface
+func (b *RecordingBackend) WriteBuffer(buf BufferHandle, offset uint64, data unsafe.Pointer, size uint64) {
+}
+
+// CreateTexture implements the RenderBackend interface
+func (b *RecordingBackend) CreateTexture(desc TextureStorageDescriptor) TextureHandle {
+	return &recordedResource{}
+}
+
+// WriteTexture implements the RenderBackend interface
+func (b *RecordingBackend) WriteTexture(tex TextureHandle, data []byte, bytesPerRow, width, height uint32) {
+}
+
+// CreateTextureView implements the RenderBackend interface
+func (b *RecordingBackend) CreateTextureView(tex TextureHandle, desc TextureViewDescriptor) TextureViewHandle {
+	return &recordedResource{}
+}
+
+// CreateSampler implements the RenderBackend interface
+func (b *RecordingBackend) CreateSampler(desc SamplerDescriptor) SamplerHandle {
+	return &recordedResource{}
+}
+
+// CreateShaderModule implements the RenderBackend interface
+func (b *RecordingBackend) CreateShaderModule(source string) ShaderModuleHandle {
+	return &recordedResource{}
+}
+
+// CreateBindGroupLayout implements the RenderBackend interface
+func (b *RecordingBackend) CreateBindGroupLayout(entries []BindGroupLayoutEntry) BindGroupLayoutHandle {
+	return &recordedResource{}
+}
+
+// CreatePipelineLayout implements the RenderBackend interface
+func (b *RecordingBackend) CreatePipelineLayout(layouts []BindGroupLayoutHandle) PipelineLayoutHandle {
+	return &recordedResource{}
+}
+
+// CreatePipeline implements the RenderBackend interface
+func (b *RecordingBackend) CreatePipeline(desc PipelineDescriptor) PipelineHandle {
+	return &recordedResource{label: desc.Label}
+}
+
+// CreateBindGroup implements the RenderBackend interface
+func (b *RecordingBackend) CreateBindGroup(layout BindGroupLayoutHandle, entries []BindGroupEntry) BindGroupHandle {
+	return &recordedResource{}
+}
+
+// BeginFrame implements the RenderBackend interface
+func (b *RecordingBackend) BeginFrame() bool {
+	b.frame = RecordedFrame{}
+	return true
+}
+
+// BeginRenderPass implements the RenderBackend interface
+func (b *RecordingBackend) BeginRenderPass(desc RenderPassDescriptor) RenderPassEncoder {
+	pass := RecordedPass{
+		Label:            desc.Label,
+		ColorAttachments: len(desc.ColorAttachments),
+		DepthAttachment:  desc.DepthAttachment != nil,
+	}
+	for _, ca := range desc.ColorAttachments {
+		if ca.View == nil && ca.Surface != nil {
+			pass.Surface = ca.Surface
+		}
+	}
+	b.frame.Passes = append(b.frame.Passes, pass)
+	return &recordingPassEncoder{backend: b, index: len(b.frame.Passes) - 1}
+}
+
+// Flush implements the RenderBackend interface
+func (b *RecordingBackend) Flush() {
+	b.frame.Flushes++
+}
+
+// EndFrame implements the RenderBackend interface
+func (b *RecordingBackend) EndFrame() {
+	b.lastFrame = b.frame
+}
+
+// Shutdown implements the RenderBackend interface
+func (b *RecordingBackend) Shutdown() {}
+
+// recordingPassEncoder appends commands to a RecordedPass.
+type recordingPassEncoder struct {
+	backend  *RecordingBackend
+	index    int
+	pipeline string
+}
+
+func (e *recordingPassEncoder) pass() *RecordedPass {
+	return &e.backend.frame.Passes[e.index]
+}
+
+func (e *recordingPassEncoder) SetPipeline(p PipelineHandle) {
+	e.pipeline = ""
+	if r, ok := p.(*recordedResource); ok {
+		e.pipeline = r.label
+	}
+	e.pass().Pipelines = append(e.pass().Pipelines, e.pipeline)
+}
+
+func (e *recordingPassEncoder) SetVertexBuffer(slot uint32, buf BufferHandle, offset, size uint64) {}
+
+func (e *recordingPassEncoder) SetIndexBuffer(buf BufferHandle, format IndexFormat, offset, size uint64) {
+}
+
+func (e *recordingPassEncoder) SetBindGroup(group uint32, bg BindGroupHandle) {}
+
+func (e *recordingPassEncoder) SetViewport(x, y, w, h, minDepth, maxDepth float32) {
+	e.pass().Viewport = mgl32.Vec4{x, y, w, h}
+}
+
+func (e *recordingPassEncoder) SetScissorRect(x, y, w, h uint32) {}
+
+func (e *recordingPassEncoder) DrawIndexed(indexCount, instanceCount, firstIndex uint32, baseVertex int32, firstInstance uint32) {
+	e.pass().Draws = append(e.pass().Draws, RecordedDraw{
+		Pipeline:      e.pipeline,
+		IndexCount:    indexCount,
+		InstanceCount: instanceCount,
+	})
+}
+
+func (e *recordingPassEncoder) End() {}
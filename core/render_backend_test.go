@@ -0,0 +1,127 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// useRecordingRenderer gives the engine a renderer on a RecordingBackend.
+func useRecordingRenderer(t testing.TB, e *Engine) *RecordingBackend {
+	t.Helper()
+	backend := NewRecordingBackend(64, 64)
+	if err := e.InitRendererWithBackend(backend); err != nil {
+		t.Fatalf("InitRendererWithBackend failed: %v", err)
+	}
+	return backend
+}
+
+// newRecordingScene builds a scene with one camera looking at the given pipelines.
+func newRecordingScene(t *testing.T, e *Engine, pipelines ...string) (*Scene, *Camera) {
+	t.Helper()
+	root := NewNode("ROOT")
+	for _, name := range pipelines {
+		pipeline, err := e.ResourceManager().Pipeline(name)
+		if err != nil {
+			t.Fatalf("Pipeline(%q) failed: %v", name, err)
+		}
+		n := NewNode(name)
+		n.SetMesh(newTriangleMesh(e))
+		n.SetPipeline(pipeline)
+		n.Translate(mgl64.Vec3{0, 0, -10})
+		root.AddChild(n)
+	}
+
+	camera := NewCamera("Camera", PerspectiveProjection)
+	camera.SetViewport(mgl32.Vec4{0, 0, 64, 64})
+	camera.SetVerticalFieldOfView(60)
+	camera.SetClipDistance(mgl64.Vec2{0.1, 100})
+	camera.SetScene(root)
+
+	scene := NewScene("recording")
+	scene.SetRoot(root)
+	scene.AddCamera(root, camera)
+	scene.update(e, 0)
+	scene.cull(e)
+	return scene, camera
+}
+
+func TestDefaultRenderTechnique(t *testing.T) {
+	e := newTestEngine(t)
+	backend := useRecordingRenderer(t, e)
+	_, camera := newRecordingScene(t, e, "unlit", "glass")
+
+	e.Renderer().BeginFrame()
+	DefaultRenderTechnique(camera, camera.pipelineBuckets)
+	e.Renderer().EndFrame()
+
+	frame := backend.LastFrame()
+	if len(frame.Passes) != 2 {
+		t.Fatalf("passes = %d, want 2", len(frame.Passes))
+	}
+	if got := frame.Passes[0].Pipelines; len(got) != 1 || got[0] != "unlit-z" {
+		t.Errorf("z-prepass pipelines = %v, want [unlit-z]", got)
+	}
+	if got := frame.Passes[1].Pipelines; len(got) != 2 || got[0] != "unlit" || got[1] != "glass" {
+		t.Errorf("main pass pipelines = %v, want [unlit glass]", got)
+	}
+	if frame.Flushes != 1 {
+		t.Errorf("flushes = %d, want 1", frame.Flushes)
+	}
+	if got := len(frame.DrawCalls()); got != 3 {
+		t.Errorf("draws = %d, want 3", got)
+	}
+}
+
+func TestShadowMapRender(t *testing.T) {
+	e := newTestEngine(t)
+	backend := useRecordingRenderer(t, e)
+	_, camera := newRecordingScene(t, e, "unlit", "glass")
+
+	const cascades = 3
+	light := &Light{Shadower: newShadowMap(e, 256, cascades)}
+	light.Block.Position = mgl32.Vec4{1, 1, 1, 0}
+
+	e.Renderer().BeginFrame()
+	light.Shadower.Render(light, camera)
+	e.Renderer().EndFrame()
+
+	frame := backend.LastFrame()
+	if len(frame.Passes) != cascades {
+		t.Fatalf("passes = %d, want %d", len(frame.Passes), cascades)
+	}
+	for i, p := range frame.Passes {
+		if p.Label != "ShadowCamera" {
+			t.Errorf("pass %d label = %q, want %q", i, p.Label, "ShadowCamera")
+		}
+		if p.ColorAttachments != 0 || !p.DepthAttachment {
+			t.Errorf("pass %d attachments = %d color, depth %v, want depth only", i, p.ColorAttachments, p.DepthAttachment)
+		}
+		if p.Viewport != (mgl32.Vec4{0, 0, 256, 256}) {
+			t.Errorf("pass %d viewport = %v, want %v", i, p.Viewport, mgl32.Vec4{0, 0, 256, 256})
+		}
+		// blended nodes do not cast shadows
+		if len(p.Draws) != 1 || p.Draws[0].Pipeline != "shadow" {
+			t.Errorf("pass %d draws = %+v, want one shadow draw", i, p.Draws)
+		}
+	}
+	if frame.Flushes != cascades {
+		t.Errorf("flushes = %d, want %d", frame.Flushes, cascades)
+	}
+	for i := 0; i < cascades; i++ {
+		if light.Block.ZCuts[i][0] <= 0 {
+			t.Errorf("cascade %d z cut = %v, want > 0", i, light.Block.ZCuts[i][0])
+		}
+	}
+}
+
+func TestNewTextureUnsupportedFormat(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+
+	tex, err := e.Renderer().NewTexture(TextureDescriptor{Width: 1, Height: 1, SizedFormat: TextureSizedFormat(-1)}, nil)
+	if err == nil || tex != nil {
+		t.Errorf("NewTexture = %v, %v, want an unsupported format error", tex, err)
+	}
+}
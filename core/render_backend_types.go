@@ -0,0 +1,321 @@
+package core
+
+// Device level enums and descriptors taken by RenderBackend. They follow
+// WebGPU, and backends convert them to their own API. Zero values are the
+// WebGPU defaults, or undefined where WebGPU has no default.
+
+// PixelFormat is the format of a texture's or surface's texels.
+type PixelFormat int
+
+// Pixel formats
+const (
+	PixelFormatUndefined PixelFormat = iota
+	PixelFormatR8Unorm
+	PixelFormatR16Float
+	PixelFormatR32Float
+	PixelFormatRG8Unorm
+	PixelFormatRG16Float
+	PixelFormatRG32Float
+	PixelFormatRGBA8Unorm
+	PixelFormatRGBA16Float
+	PixelFormatRGBA32Float
+	PixelFormatBGRA8Unorm
+	PixelFormatDepth32Float
+)
+
+// BufferUsage is a set of ways a buffer is used.
+type BufferUsage int
+
+// Buffer usages
+const (
+	BufferUsageVertex BufferUsage = 1 << iota
+	BufferUsageIndex
+	BufferUsageUniform
+	BufferUsageCopyDst
+)
+
+// TextureUsage is a set of ways a texture is used.
+type TextureUsage int
+
+// Texture usages
+const (
+	TextureUsageCopyDst TextureUsage = 1 << iota
+	TextureUsageTextureBinding
+	TextureUsageRenderAttachment
+)
+
+// TextureStorageDescriptor describes a 2D texture, or an array of them with
+// more than one layer.
+type TextureStorageDescriptor struct {
+	Width, Height uint32
+	Layers        uint32
+	Format        PixelFormat
+	Usage         TextureUsage
+	MipLevels     uint32
+}
+
+// TextureViewDimension is how a texture view is sampled.
+type TextureViewDimension int
+
+// Texture view dimensions. TextureViewDimensionDefault picks the dimension
+// from the texture.
+const (
+	TextureViewDimensionDefault TextureViewDimension = iota
+	TextureViewDimension2D
+	TextureViewDimension2DArray
+)
+
+// AddressMode is how a sampler handles coordinates outside the texture.
+type AddressMode int
+
+// Address modes
+const (
+	AddressModeClampToEdge AddressMode = iota
+	AddressModeRepeat
+)
+
+// FilterMode is how a sampler filters texels.
+type FilterMode int
+
+// Filter modes
+const (
+	FilterModeNearest FilterMode = iota
+	FilterModeLinear
+)
+
+// MipmapFilterMode is how a sampler filters between mip levels.
+type MipmapFilterMode int
+
+// Mipmap filter modes
+const (
+	MipmapFilterModeNearest MipmapFilterMode = iota
+	MipmapFilterModeLinear
+)
+
+// CompareFunction compares a value against a reference, eg: a fragment's
+// depth against the depth buffer's.
+type CompareFunction int
+
+// Compare functions. CompareFunctionUndefined makes samplers regular, non
+// comparison, samplers.
+const (
+	CompareFunctionUndefined CompareFunction = iota
+	CompareFunctionLess
+	CompareFunctionLessEqual
+	CompareFunctionEqual
+	CompareFunctionAlways
+)
+
+// SamplerDescriptor describes a sampler.
+type SamplerDescriptor struct {
+	AddressModeU  AddressMode
+	AddressModeV  AddressMode
+	AddressModeW  AddressMode
+	MagFilter     FilterMode
+	MinFilter     FilterMode
+	MipmapFilter  MipmapFilterMode
+	MaxAnisotropy uint16
+	Compare       CompareFunction
+}
+
+// ShaderStage is a set of shader stages.
+type ShaderStage int
+
+// Shader stages
+const (
+	ShaderStageVertex ShaderStage = 1 << iota
+	ShaderStageFragment
+)
+
+// BufferBindingType is the type of a buffer binding.
+type BufferBindingType int
+
+// Buffer binding types
+const (
+	BufferBindingTypeUniform BufferBindingType = iota
+)
+
+// SamplerBindingType is the type of a sampler binding.
+type SamplerBindingType int
+
+// Sampler binding types
+const (
+	SamplerBindingTypeFiltering SamplerBindingType = iota
+	SamplerBindingTypeNonFiltering
+	SamplerBindingTypeComparison
+)
+
+// TextureSampleType is the type of the texels a texture binding samples.
+type TextureSampleType int
+
+// Texture sample types
+const (
+	TextureSampleTypeFloat TextureSampleType = iota
+	TextureSampleTypeUnfilterableFloat
+	TextureSampleTypeDepth
+)
+
+// BufferBindingLayout describes a buffer binding.
+type BufferBindingLayout struct {
+	Type BufferBindingType
+}
+
+// SamplerBindingLayout describes a sampler binding.
+type SamplerBindingLayout struct {
+	Type SamplerBindingType
+}
+
+// TextureBindingLayout describes a texture binding.
+type TextureBindingLayout struct {
+	SampleType    TextureSampleType
+	ViewDimension TextureViewDimension
+}
+
+// BindGroupLayoutEntry describes a binding slot of a bind group layout. Only
+// one of Buffer, Sampler and Texture is set.
+type BindGroupLayoutEntry struct {
+	Binding    uint32
+	Visibility ShaderStage
+	Buffer     *BufferBindingLayout
+	Sampler    *SamplerBindingLayout
+	Texture    *TextureBindingLayout
+}
+
+// IndexFormat is the format of an index buffer's indices.
+type IndexFormat int
+
+// Index formats
+const (
+	IndexFormatUint16 IndexFormat = iota
+	IndexFormatUint32
+)
+
+// VertexFormat is the format of a vertex attribute.
+type VertexFormat int
+
+// Vertex formats
+const (
+	VertexFormatFloat32x2 VertexFormat = iota
+	VertexFormatFloat32x3
+	VertexFormatFloat32x4
+	VertexFormatUnorm8x4
+)
+
+// VertexStepMode is whether a vertex buffer advances per vertex or per
+// instance.
+type VertexStepMode int
+
+// Vertex step modes
+const (
+	VertexStepModeVertex VertexStepMode = iota
+	VertexStepModeInstance
+)
+
+// VertexAttribute describes an attribute in a vertex buffer.
+type VertexAttribute struct {
+	Format         VertexFormat
+	Offset         uint64
+	ShaderLocation uint32
+}
+
+// VertexBufferLayout describes the attributes of a vertex buffer.
+type VertexBufferLayout struct {
+	ArrayStride uint64
+	StepMode    VertexStepMode
+	Attributes  []VertexAttribute
+}
+
+// PrimitiveTopology is how vertices are assembled into primitives.
+type PrimitiveTopology int
+
+// Primitive topologies
+const (
+	PrimitiveTopologyTriangleList PrimitiveTopology = iota
+	PrimitiveTopologyLineList
+	PrimitiveTopologyPointList
+)
+
+// FrontFace is the winding of front facing triangles.
+type FrontFace int
+
+// Front faces
+const (
+	FrontFaceCCW FrontFace = iota
+	FrontFaceCW
+)
+
+// CullMode is which triangles are culled.
+type CullMode int
+
+// Cull modes
+const (
+	CullModeNone CullMode = iota
+	CullModeFront
+	CullModeBack
+)
+
+// BlendFactor scales a blended color or alpha.
+type BlendFactor int
+
+// Blend factors
+const (
+	BlendFactorZero BlendFactor = iota
+	BlendFactorOne
+	BlendFactorSrcAlpha
+	BlendFactorOneMinusSrcAlpha
+)
+
+// BlendOperation combines the scaled source and destination.
+type BlendOperation int
+
+// Blend operations
+const (
+	BlendOperationAdd BlendOperation = iota
+	BlendOperationMax
+)
+
+// BlendComponent describes how color or alpha are blended.
+type BlendComponent struct {
+	SrcFactor BlendFactor
+	DstFactor BlendFactor
+	Operation BlendOperation
+}
+
+// BlendState describes how a color target is blended.
+type BlendState struct {
+	Color BlendComponent
+	Alpha BlendComponent
+}
+
+// ColorWriteMask is which color channels are written to a target.
+type ColorWriteMask int
+
+// Color write masks
+const (
+	ColorWriteMaskAll ColorWriteMask = iota
+	ColorWriteMaskNone
+)
+
+// ColorTargetState describes a color target of a pipeline. A nil Blend
+// replaces the target's contents.
+type ColorTargetState struct {
+	Format    PixelFormat
+	Blend     *BlendState
+	WriteMask ColorWriteMask
+}
+
+// DepthStencilState describes the depth target of a pipeline.
+type DepthStencilState struct {
+	Format            PixelFormat
+	DepthWriteEnabled bool
+	DepthCompare      CompareFunction
+}
+
+// LoadOp is what a pass does with an attachment's contents when it begins.
+type LoadOp int
+
+// Load ops
+const (
+	LoadOpLoad LoadOp = iota
+	LoadOpClear
+)
@@ -0,0 +1,647 @@
+package core
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/fcvarela/gosg/gpu"
+	"github.com/golang/glog"
+)
+
+// wgpuBackend implements RenderBackend on top of wgpu-native.
+type wgpuBackend struct {
+	instance      gpu.Instance
+	device        gpu.Device
+	queue         gpu.Queue
+	surface       gpu.Surface
+	surfaceFormat gpu.TextureFormat
+	surfaceWidth  uint32
+	surfaceHeight uint32
+
+	// Per-frame state
+	swapChainTexture gpu.Texture
+	swapChainView    gpu.TextureView
+	encoder          gpu.CommandEncoder
+
+	// secondary surfaces acquired this frame
+	acquired []*wgpuSurface
+}
+
+// wgpuSurface is the surface of a secondary window, with its image for the
+// current frame.
+type wgpuSurface struct {
+	backend *wgpuBackend
+	surface gpu.Surface
+	texture gpu.Texture
+	view    gpu.TextureView
+}
+
+// Release implements the SurfaceHandle interface
+func (s *wgpuSurface) Release() {
+	for i, a := range s.backend.acquired {
+		if a == s {
+			s.backend.acquired = append(s.backend.acquired[:i], s.backend.acquired[i+1:]...)
+			break
+		}
+	}
+	s.releaseImage()
+	s.surface.Release()
+}
+
+// releaseImage releases the surface's image once it was presented.
+func (s *wgpuSurface) releaseImage() {
+	if s.view != (gpu.TextureView{}) {
+		s.view.Release()
+		s.view = gpu.TextureView{}
+	}
+	if s.texture != (gpu.Texture{}) {
+		s.texture.Release()
+		s.texture = gpu.Texture{}
+	}
+}
+
+// newWGPUBackend creates a device and configures a surface for the given CAMetalLayer.
+func newWGPUBackend(metalLayer unsafe.Pointer, width, height uint32) (*wgpuBackend, error) {
+	b := &wgpuBackend{
+		surfaceFormat: gpu.TextureFormatBGRA8Unorm,
+		surfaceWidth:  width,
+		surfaceHeight: height,
+	}
+
+	b.instance = gpu.CreateInstance()
+
+	adapter, err := b.instance.RequestAdapter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wgpu adapter: %w", err)
+	}
+
+	b.device, err = adapter.RequestDevice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wgpu device: %w", err)
+	}
+	adapter.Release()
+
+	b.queue = b.device.GetQueue()
+
+	b.surface, err = b.instance.CreateMetalSurface(metalLayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wgpu surface: %w", err)
+	}
+
+	b.surface.Configure(b.device, b.surfaceFormat, b.surfaceWidth, b.surfaceHeight)
+
+	return b, nil
+}
+
+// handle conversions, nil handles map to zero values
+
+// wgpuHandle returns the wgpu value behind a handle. Handles created by
+// another backend are a programming error.
+func wgpuHandle[T any](kind string, h any) T {
+	var v T
+	if h == nil {
+		return v
+	}
+	v, ok := h.(T)
+	if !ok {
+		panic(fmt.Sprintf("wgpu backend: %s handle is a %T, not a %T", kind, h, v))
+	}
+	return v
+}
+
+func wgpuBuffer(h BufferHandle) gpu.Buffer {
+	return wgpuHandle[gpu.Buffer]("buffer", h)
+}
+
+func wgpuTexture(h TextureHandle) gpu.Texture {
+	return wgpuHandle[gpu.Texture]("texture", h)
+}
+
+func wgpuTextureView(h TextureViewHandle) gpu.TextureView {
+	return wgpuHandle[gpu.TextureView]("texture view", h)
+}
+
+func wgpuSampler(h SamplerHandle) gpu.Sampler {
+	return wgpuHandle[gpu.Sampler]("sampler", h)
+}
+
+func wgpuShaderModule(h ShaderModuleHandle) gpu.ShaderModule {
+	return wgpuHandle[gpu.ShaderModule]("shader module", h)
+}
+
+func wgpuBindGroupLayout(h BindGroupLayoutHandle) gpu.BindGroupLayout {
+	return wgpuHandle[gpu.BindGroupLayout]("bind group layout", h)
+}
+
+// enum and descriptor conversions
+
+var wgpuPixelFormats = map[PixelFormat]gpu.TextureFormat{
+	PixelFormatUndefined:    gpu.TextureFormatUndefined,
+	PixelFormatR8Unorm:      gpu.TextureFormatR8Unorm,
+	PixelFormatR16Float:     gpu.TextureFormatR16Float,
+	PixelFormatR32Float:     gpu.TextureFormatR32Float,
+	PixelFormatRG8Unorm:     gpu.TextureFormatRG8Unorm,
+	PixelFormatRG16Float:    gpu.TextureFormatRG16Float,
+	PixelFormatRG32Float:    gpu.TextureFormatRG32Float,
+	PixelFormatRGBA8Unorm:   gpu.TextureFormatRGBA8Unorm,
+	PixelFormatRGBA16Float:  gpu.TextureFormatRGBA16Float,
+	PixelFormatRGBA32Float:  gpu.TextureFormatRGBA32Float,
+	PixelFormatBGRA8Unorm:   gpu.TextureFormatBGRA8Unorm,
+	PixelFormatDepth32Float: gpu.TextureFormatDepth32Float,
+}
+
+var wgpuTextureViewDimensions = map[TextureViewDimension]gpu.TextureViewDimension{
+	TextureViewDimension2D:      gpu.TextureViewDimension2D,
+	TextureViewDimension2DArray: gpu.TextureViewDimension2DArray,
+}
+
+var wgpuAddressModes = map[AddressMode]gpu.AddressMode{
+	AddressModeClampToEdge: gpu.AddressModeClampToEdge,
+	AddressModeRepeat:      gpu.AddressModeRepeat,
+}
+
+var wgpuFilterModes = map[FilterMode]gpu.FilterMode{
+	FilterModeNearest: gpu.FilterModeNearest,
+	FilterModeLinear:  gpu.FilterModeLinear,
+}
+
+var wgpuMipmapFilterModes = map[MipmapFilterMode]gpu.MipmapFilterMode{
+	MipmapFilterModeNearest: gpu.MipmapFilterModeNearest,
+	MipmapFilterModeLinear:  gpu.MipmapFilterModeLinear,
+}
+
+var wgpuCompareFunctions = map[CompareFunction]gpu.CompareFunction{
+	CompareFunctionUndefined: gpu.CompareFunctionUndefined,
+	CompareFunctionLess:      gpu.CompareFunctionLess,
+	CompareFunctionLessEqual: gpu.CompareFunctionLessEqual,
+	CompareFunctionEqual:     gpu.CompareFunctionEqual,
+	CompareFunctionAlways:    gpu.CompareFunctionAlways,
+}
+
+var wgpuSamplerBindingTypes = map[SamplerBindingType]gpu.SamplerBindingType{
+	SamplerBindingTypeFiltering:    gpu.SamplerBindingTypeFiltering,
+	SamplerBindingTypeNonFiltering: gpu.SamplerBindingTypeNonFiltering,
+	SamplerBindingTypeComparison:   gpu.SamplerBindingTypeComparison,
+}
+
+var wgpuTextureSampleTypes = map[TextureSampleType]gpu.TextureSampleType{
+	TextureSampleTypeFloat:             gpu.TextureSampleTypeFloat,
+	TextureSampleTypeUnfilterableFloat: gpu.TextureSampleTypeUnfilterableFloat,
+	TextureSampleTypeDepth:             gpu.TextureSampleTypeDepth,
+}
+
+var wgpuIndexFormats = map[IndexFormat]gpu.IndexFormat{
+	IndexFormatUint16: gpu.IndexFormatUint16,
+	IndexFormatUint32: gpu.IndexFormatUint32,
+}
+
+var wgpuVertexFormats = map[VertexFormat]gpu.VertexFormat{
+	VertexFormatFloat32x2: gpu.VertexFormatFloat32x2,
+	VertexFormatFloat32x3: gpu.VertexFormatFloat32x3,
+	VertexFormatFloat32x4: gpu.VertexFormatFloat32x4,
+	VertexFormatUnorm8x4:  gpu.VertexFormatUnorm8x4,
+}
+
+var wgpuVertexStepModes = map[VertexStepMode]gpu.VertexStepMode{
+	VertexStepModeVertex:   gpu.VertexStepModeVertex,
+	VertexStepModeInstance: gpu.VertexStepModeInstance,
+}
+
+var wgpuPrimitiveTopologies = map[PrimitiveTopology]gpu.PrimitiveTopology{
+	PrimitiveTopologyTriangleList: gpu.PrimitiveTopologyTriangleList,
+	PrimitiveTopologyLineList:     gpu.PrimitiveTopologyLineList,
+	PrimitiveTopologyPointList:    gpu.PrimitiveTopologyPointList,
+}
+
+var wgpuFrontFaces = map[FrontFace]gpu.FrontFace{
+	FrontFaceCCW: gpu.FrontFaceCCW,
+	FrontFaceCW:  gpu.FrontFaceCW,
+}
+
+var wgpuCullModes = map[CullMode]gpu.CullMode{
+	CullModeNone:  gpu.CullModeNone,
+	CullModeFront: gpu.CullModeFront,
+	CullModeBack:  gpu.CullModeBack,
+}
+
+var wgpuBlendFactors = map[BlendFactor]gpu.BlendFactor{
+	BlendFactorZero:             gpu.BlendFactorZero,
+	BlendFactorOne:              gpu.BlendFactorOne,
+	BlendFactorSrcAlpha:         gpu.BlendFactorSrcAlpha,
+	BlendFactorOneMinusSrcAlpha: gpu.BlendFactorOneMinusSrcAlpha,
+}
+
+var wgpuBlendOperations = map[BlendOperation]gpu.BlendOperation{
+	BlendOperationAdd: gpu.BlendOperationAdd,
+	BlendOperationMax: gpu.BlendOperationMax,
+}
+
+var wgpuColorWriteMasks = map[ColorWriteMask]gpu.ColorWriteMask{
+	ColorWriteMaskAll:  gpu.ColorWriteMaskAll,
+	ColorWriteMaskNone: gpu.ColorWriteMaskNone,
+}
+
+var wgpuLoadOps = map[LoadOp]gpu.LoadOp{
+	LoadOpLoad:  gpu.LoadOpLoad,
+	LoadOpClear: gpu.LoadOpClear,
+}
+
+func wgpuBufferUsage(u BufferUsage) gpu.BufferUsage {
+	var usage gpu.BufferUsage
+	for bit, gpuBit := range map[BufferUsage]gpu.BufferUsage{
+		BufferUsageVertex:  gpu.BufferUsageVertex,
+		BufferUsageIndex:   gpu.BufferUsageIndex,
+		BufferUsageUniform: gpu.BufferUsageUniform,
+		BufferUsageCopyDst: gpu.BufferUsageCopyDst,
+	} {
+		if u&bit != 0 {
+			usage |= gpuBit
+		}
+	}
+	return usage
+}
+
+func wgpuTextureUsage(u TextureUsage) gpu.TextureUsage {
+	var usage gpu.TextureUsage
+	for bit, gpuBit := range map[TextureUsage]gpu.TextureUsage{
+		TextureUsageCopyDst:          gpu.TextureUsageCopyDst,
+		TextureUsageTextureBinding:   gpu.TextureUsageTextureBinding,
+		TextureUsageRenderAttachment: gpu.TextureUsageRenderAttachment,
+	} {
+		if u&bit != 0 {
+			usage |= gpuBit
+		}
+	}
+	return usage
+}
+
+func wgpuShaderStage(s ShaderStage) gpu.ShaderStage {
+	var stage gpu.ShaderStage
+	if s&ShaderStageVertex != 0 {
+		stage |= gpu.ShaderStageVertex
+	}
+	if s&ShaderStageFragment != 0 {
+		stage |= gpu.ShaderStageFragment
+	}
+	return stage
+}
+
+func wgpuBindGroupLayoutEntry(e BindGroupLayoutEntry) gpu.BindGroupLayoutEntry {
+	entry := gpu.BindGroupLayoutEntry{
+		Binding:    e.Binding,
+		Visibility: wgpuShaderStage(e.Visibility),
+	}
+	if e.Buffer != nil {
+		entry.Buffer = &gpu.BufferBindingLayout{Type: gpu.BufferBindingTypeUniform}
+	}
+	if e.Sampler != nil {
+		entry.Sampler = &gpu.SamplerBindingLayout{Type: wgpuSamplerBindingTypes[e.Sampler.Type]}
+	}
+	if e.Texture != nil {
+		entry.Texture = &gpu.TextureBindingLayout{
+			SampleType:    wgpuTextureSampleTypes[e.Texture.SampleType],
+			ViewDimension: wgpuTextureViewDimensions[e.Texture.ViewDimension],
+		}
+	}
+	return entry
+}
+
+func wgpuVertexBufferLayout(l VertexBufferLayout) gpu.VertexBufferLayout {
+	attributes := make([]gpu.VertexAttribute, len(l.Attributes))
+	for i, a := range l.Attributes {
+		attributes[i] = gpu.VertexAttribute{
+			Format:         wgpuVertexFormats[a.Format],
+			Offset:         a.Offset,
+			ShaderLocation: a.ShaderLocation,
+		}
+	}
+	return gpu.VertexBufferLayout{
+		ArrayStride: l.ArrayStride,
+		StepMode:    wgpuVertexStepModes[l.StepMode],
+		Attributes:  attributes,
+	}
+}
+
+func wgpuBlendComponent(c BlendComponent) gpu.BlendComponent {
+	return gpu.BlendComponent{
+		SrcFactor: wgpuBlendFactors[c.SrcFactor],
+		DstFactor: wgpuBlendFactors[c.DstFactor],
+		Operation: wgpuBlendOperations[c.Operation],
+	}
+}
+
+func wgpuColorTargetState(t ColorTargetState) gpu.ColorTargetState {
+	target := gpu.ColorTargetState{
+		Format:    wgpuPixelFormats[t.Format],
+		WriteMask: wgpuColorWriteMasks[t.WriteMask],
+	}
+	if t.Blend != nil {
+		target.Blend = &gpu.BlendState{
+			Color: wgpuBlendComponent(t.Blend.Color),
+			Alpha: wgpuBlendComponent(t.Blend.Alpha),
+		}
+	}
+	return target
+}
+
+func (b *wgpuBackend) SurfaceFormat() PixelFormat {
+	return PixelFormatBGRA8Unorm
+}
+
+func (b *wgpuBackend) SurfaceSize() (uint32, uint32) {
+	return b.surfaceWidth, b.surfaceHeight
+}
+
+func (b *wgpuBackend) ResizeSurface(surface SurfaceHandle, width, height uint32) {
+	if s, ok := surface.(*wgpuSurface); ok {
+		s.surface.Configure(b.device, b.surfaceFormat, width, height)
+		return
+	}
+	b.surfaceWidth = width
+	b.surfaceHeight = height
+	b.surface.Configure(b.device, b.surfaceFormat, b.surfaceWidth, b.surfaceHeight)
+}
+
+func (b *wgpuBackend) CreateSurface(layer unsafe.Pointer, width, height uint32) (SurfaceHandle, error) {
+	surface, err := b.instance.CreateMetalSurface(layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wgpu surface: %w", err)
+	}
+	surface.Configure(b.device, b.surfaceFormat, width, height)
+	return &wgpuSurface{backend: b, surface: surface}, nil
+}
+
+func (b *wgpuBackend) AcquireSurface(surface SurfaceHandle) bool {
+	s, ok := surface.(*wgpuSurface)
+	if !ok {
+		return false
+	}
+	if s.view != (gpu.TextureView{}) {
+		return true
+	}
+	st := s.surface.GetCurrentTexture()
+	if st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessOptimal &&
+		st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessSuboptimal {
+		glog.Warning("Failed to acquire secondary surface texture, skipping its passes")
+		return false
+	}
+	s.texture = st.Texture
+	s.view = s.texture.CreateView()
+	b.acquired = append(b.acquired, s)
+	return true
+}
+
+func (b *wgpuBackend) CreateBuffer(size uint64, usage BufferUsage) BufferHandle {
+	return b.device.CreateBuffer(size, wgpuBufferUsage(usage))
+}
+
+func (b *wgpuBackend) WriteBuffer(buf BufferHandle, offset uint64, data unsafe.Pointer, size uint64) {
+	b.queue.WriteBuffer(wgpuBuffer(buf), offset, data, size)
+}
+
+func (b *wgpuBackend) CreateTexture(desc TextureStorageDescriptor) TextureHandle {
+	return b.device.CreateTexture(gpu.TextureDescriptor{
+		Size:      gpu.Extent3D{Width: desc.Width, Height: desc.Height, DepthOrArrayLayers: desc.Layers},
+		Format:    wgpuPixelFormats[desc.Format],
+		Usage:     wgpuTextureUsage(desc.Usage),
+		Dimension: gpu.TextureDimension2D,
+		MipLevels: desc.MipLevels,
+	})
+}
+
+func (b *wgpuBackend) WriteTexture(tex TextureHandle, data []byte, bytesPerRow, width, height uint32) {
+	b.queue.WriteTexture(
+		gpu.ImageCopyTexture{Texture: wgpuTexture(tex), MipLevel: 0},
+		unsafe.Pointer(&data[0]),
+		uint64(len(data)),
+		gpu.TextureDataLayout{BytesPerRow: bytesPerRow, RowsPerImage: height},
+		gpu.Extent3D{Width: width, Height: height, DepthOrArrayLayers: 1},
+	)
+}
+
+// CreateTextureView only supports depth textures for array and layer views,
+// which is all the shadow maps need.
+func (b *wgpuBackend) CreateTextureView(tex TextureHandle, desc TextureViewDescriptor) TextureViewHandle {
+	t := wgpuTexture(tex)
+	switch {
+	case desc.Dimension == TextureViewDimension2DArray:
+		return t.CreateViewArray(desc.BaseArrayLayer, desc.ArrayLayerCount)
+	case desc.ArrayLayerCount > 0:
+		return t.CreateViewLayer(desc.BaseArrayLayer)
+	default:
+		return t.CreateView()
+	}
+}
+
+func (b *wgpuBackend) CreateSampler(desc SamplerDescriptor) SamplerHandle {
+	return b.device.CreateSampler(gpu.SamplerDescriptor{
+		AddressModeU:  wgpuAddressModes[desc.AddressModeU],
+		AddressModeV:  wgpuAddressModes[desc.AddressModeV],
+		AddressModeW:  wgpuAddressModes[desc.AddressModeW],
+		MagFilter:     wgpuFilterModes[desc.MagFilter],
+		MinFilter:     wgpuFilterModes[desc.MinFilter],
+		MipmapFilter:  wgpuMipmapFilterModes[desc.MipmapFilter],
+		MaxAnisotropy: desc.MaxAnisotropy,
+		Compare:       wgpuCompareFunctions[desc.Compare],
+	})
+}
+
+func (b *wgpuBackend) CreateShaderModule(source string) ShaderModuleHandle {
+	return b.device.CreateShaderModuleWGSL(source)
+}
+
+func (b *wgpuBackend) CreateBindGroupLayout(entries []BindGroupLayoutEntry) BindGroupLayoutHandle {
+	gpuEntries := make([]gpu.BindGroupLayoutEntry, len(entries))
+	for i, e := range entries {
+		gpuEntries[i] = wgpuBindGroupLayoutEntry(e)
+	}
+	return b.device.CreateBindGroupLayout(gpuEntries)
+}
+
+func (b *wgpuBackend) CreatePipelineLayout(layouts []BindGroupLayoutHandle) PipelineLayoutHandle {
+	gpuLayouts := make([]gpu.BindGroupLayout, len(layouts))
+	for i, l := range layouts {
+		gpuLayouts[i] = wgpuBindGroupLayout(l)
+	}
+	return b.device.CreatePipelineLayout(gpuLayouts)
+}
+
+func (b *wgpuBackend) CreatePipeline(desc PipelineDescriptor) PipelineHandle {
+	buffers := make([]gpu.VertexBufferLayout, len(desc.Buffers))
+	for i, l := range desc.Buffers {
+		buffers[i] = wgpuVertexBufferLayout(l)
+	}
+	targets := make([]gpu.ColorTargetState, len(desc.Targets))
+	for i, t := range desc.Targets {
+		targets[i] = wgpuColorTargetState(t)
+	}
+	var depthStencil *gpu.DepthStencilState
+	if ds := desc.DepthStencil; ds != nil {
+		depthStencil = &gpu.DepthStencilState{
+			Format:            wgpuPixelFormats[ds.Format],
+			DepthWriteEnabled: ds.DepthWriteEnabled,
+			DepthCompare:      wgpuCompareFunctions[ds.DepthCompare],
+		}
+	}
+
+	return b.device.CreateRenderPipeline(gpu.RenderPipelineDescriptor{
+		Layout:         wgpuHandle[gpu.PipelineLayout]("pipeline layout", desc.Layout),
+		VertexModule:   wgpuShaderModule(desc.VertexModule),
+		VertexEntry:    desc.VertexEntry,
+		FragmentModule: wgpuShaderModule(desc.FragmentModule),
+		FragmentEntry:  desc.FragmentEntry,
+		Buffers:        buffers,
+		Targets:        targets,
+		Primitive:      wgpuPrimitiveTopologies[desc.Primitive],
+		FrontFace:      wgpuFrontFaces[desc.FrontFace],
+		CullMode:       wgpuCullModes[desc.CullMode],
+		DepthStencil:   depthStencil,
+	})
+}
+
+func (b *wgpuBackend) CreateBindGroup(layout BindGroupLayoutHandle, entries []BindGroupEntry) BindGroupHandle {
+	gpuEntries := make([]gpu.BindGroupEntry, len(entries))
+	for i, e := range entries {
+		gpuEntries[i] = gpu.BindGroupEntry{
+			Binding:     e.Binding,
+			Buffer:      wgpuBuffer(e.Buffer),
+			Offset:      e.Offset,
+			Size:        e.Size,
+			Sampler:     wgpuSampler(e.Sampler),
+			TextureView: wgpuTextureView(e.TextureView),
+		}
+	}
+	return b.device.CreateBindGroup(wgpuBindGroupLayout(layout), gpuEntries)
+}
+
+func (b *wgpuBackend) BeginFrame() bool {
+	st := b.surface.GetCurrentTexture()
+	if st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessOptimal &&
+		st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessSuboptimal {
+		glog.Warning("Failed to acquire swap chain texture, skipping frame")
+		return false
+	}
+	b.swapChainTexture = st.Texture
+	b.swapChainView = b.swapChainTexture.CreateView()
+	b.encoder = b.device.CreateCommandEncoder()
+	return true
+}
+
+func (b *wgpuBackend) BeginRenderPass(desc RenderPassDescriptor) RenderPassEncoder {
+	gpuDesc := gpu.RenderPassDescriptor{}
+
+	for _, ca := range desc.ColorAttachments {
+		colorView := wgpuTextureView(ca.View)
+		if colorView == (gpu.TextureView{}) {
+			colorView = b.swapChainView
+			if surface, ok := ca.Surface.(*wgpuSurface); ok {
+				colorView = surface.view
+			}
+		}
+
+		clearColor := gpu.Color{
+			R: float64(ca.ClearColor[0]),
+			G: float64(ca.ClearColor[1]),
+			B: float64(ca.ClearColor[2]),
+			A: float64(ca.ClearColor[3]),
+		}
+
+		gpuDesc.ColorAttachments = append(gpuDesc.ColorAttachments, gpu.RenderPassColorAttachment{
+			View:       colorView,
+			LoadOp:     wgpuLoadOps[ca.LoadOp],
+			StoreOp:    gpu.StoreOpStore,
+			ClearValue: clearColor,
+		})
+	}
+
+	if desc.DepthAttachment != nil {
+		gpuDesc.DepthStencilAttachment = &gpu.RenderPassDepthStencilAttachment{
+			View:            wgpuTextureView(desc.DepthAttachment.View),
+			DepthLoadOp:     wgpuLoadOps[desc.DepthAttachment.LoadOp],
+			DepthStoreOp:    gpu.StoreOpStore,
+			DepthClearValue: desc.DepthAttachment.ClearDepth,
+		}
+	}
+
+	return &wgpuPassEncoder{encoder: b.encoder.BeginRenderPass(gpuDesc)}
+}
+
+func (b *wgpuBackend) Flush() {
+	cmdBuf := b.encoder.Finish()
+	b.queue.Submit(cmdBuf)
+	cmdBuf.Release()
+	b.encoder.Release()
+	b.encoder = b.device.CreateCommandEncoder()
+}
+
+func (b *wgpuBackend) EndFrame() {
+	cmdBuf := b.encoder.Finish()
+	b.queue.Submit(cmdBuf)
+	cmdBuf.Release()
+	b.encoder.Release()
+	b.surface.Present()
+	for _, s := range b.acquired {
+		s.surface.Present()
+		s.releaseImage()
+	}
+	clear(b.acquired)
+	b.acquired = b.acquired[:0]
+
+	b.swapChainView.Release()
+	b.swapChainView = gpu.TextureView{}
+	b.swapChainTexture.Release()
+	b.swapChainTexture = gpu.Texture{}
+}
+
+func (b *wgpuBackend) Shutdown() {
+	if b.surface != (gpu.Surface{}) {
+		b.surface.Release()
+	}
+	if b.queue != (gpu.Queue{}) {
+		b.queue.Release()
+	}
+	if b.device != (gpu.Device{}) {
+		b.device.Release()
+	}
+	if b.instance != (gpu.Instance{}) {
+		b.instance.Release()
+	}
+}
+
+// wgpuPassEncoder implements RenderPassEncoder with a gpu.RenderPassEncoder.
+type wgpuPassEncoder struct {
+	encoder gpu.RenderPassEncoder
+}
+
+func (e *wgpuPassEncoder) SetPipeline(p PipelineHandle) {
+	e.encoder.SetPipeline(wgpuHandle[gpu.RenderPipeline]("pipeline", p))
+}
+
+func (e *wgpuPassEncoder) SetVertexBuffer(slot uint32, buf BufferHandle, offset, size uint64) {
+	e.encoder.SetVertexBuffer(slot, wgpuBuffer(buf), offset, size)
+}
+
+func (e *wgpuPassEncoder) SetIndexBuffer(buf BufferHandle, format IndexFormat, offset, size uint64) {
+	e.encoder.SetIndexBuffer(wgpuBuffer(buf), wgpuIndexFormats[format], offset, size)
+}
+
+func (e *wgpuPassEncoder) SetBindGroup(group uint32, bg BindGroupHandle) {
+	e.encoder.SetBindGroup(group, wgpuHandle[gpu.BindGroup]("bind group", bg))
+}
+
+func (e *wgpuPassEncoder) SetViewport(x, y, w, h, minDepth, maxDepth float32) {
+	e.encoder.SetViewport(x, y, w, h, minDepth, maxDepth)
+}
+
+func (e *wgpuPassEncoder) SetScissorRect(x, y, w, h uint32) {
+	e.encoder.SetScissorRect(x, y, w, h)
+}
+
+func (e *wgpuPassEncoder) DrawIndexed(indexCount, instanceCount, firstIndex uint32, baseVertex int32, firstInstance uint32) {
+	e.encoder.DrawIndexed(indexCount, instanceCount, firstIndex, baseVertex, firstInstance)
+}
+
+func (e *wgpuPassEncoder) End() {
+	e.encoder.End()
+	e.encoder.Release()
+}
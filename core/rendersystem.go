@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/draw"
@@ -10,7 +11,6 @@ import (
 	"math"
 	"unsafe"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/go-gl/mathgl/mgl64"
 	"github.com/golang/glog"
@@ -34,17 +34,18 @@ const (
 
 // RenderPassColorAttachment describes a color attachment for a render pass.
 type RenderPassColorAttachment struct {
-	View       gpu.TextureView
-	Format     gpu.TextureFormat // gpu.TextureFormatUndefined means use swap chain format
-	LoadOp     gpu.LoadOp
+	View       TextureViewHandle // nil means the swap chain image
+	Surface    SurfaceHandle     // the secondary surface a nil View targets, nil means the main surface
+	Format     PixelFormat       // PixelFormatUndefined means use swap chain format
+	LoadOp     LoadOp
 	ClearColor mgl32.Vec4
 }
 
 // RenderPassDepthAttachment describes a depth attachment for a render pass.
 type RenderPassDepthAttachment struct {
-	View       gpu.TextureView
-	Format     gpu.TextureFormat // gpu.TextureFormatUndefined means Depth32Float
-	LoadOp     gpu.LoadOp
+	View       TextureViewHandle
+	Format     PixelFormat // PixelFormatUndefined means Depth32Float
+	LoadOp     LoadOp
 	ClearDepth float32
 }
 
@@ -53,14 +54,16 @@ type RenderPassDescriptor struct {
 	ColorAttachments []RenderPassColorAttachment
 	DepthAttachment  *RenderPassDepthAttachment
 	Viewport         mgl32.Vec4
+	Label            string
 }
 
-// RenderPass wraps a gpu.RenderPassEncoder with engine-level convenience methods.
+// RenderPass wraps a backend RenderPassEncoder with engine-level convenience methods.
 type RenderPass struct {
-	encoder        gpu.RenderPassEncoder
+	renderer       *Renderer
+	encoder        RenderPassEncoder
 	currentProgram *Program
-	colorFormats   []gpu.TextureFormat
-	depthFormat    gpu.TextureFormat
+	colorFormats   []PixelFormat
+	depthFormat    PixelFormat
 }
 
 // SetPipeline looks up (or creates) the GPU pipeline for the given pipeline config and binds it.
@@ -69,15 +72,16 @@ func (rp *RenderPass) SetPipeline(p *Pipeline) bool {
 	if p.ProgramName == "" {
 		return false
 	}
-	program := resourceManager.Program(p.ProgramName)
-	if program == nil {
+	program, err := rp.renderer.engine.resourceManager.Program(p.ProgramName)
+	if err != nil {
+		glog.Warningf("failed to load program: %v", err)
 		return false
 	}
 	rp.currentProgram = program
 
-	pipeline := renderer.pipelines.getOrCreate(p, program, rp.colorFormats, rp.depthFormat)
+	pipeline := rp.renderer.pipelines.getOrCreate(p, program, rp.colorFormats, rp.depthFormat)
 	rp.encoder.SetPipeline(pipeline)
-	renderer.stats.PipelineSwitches++
+	rp.renderer.stats.PipelineSwitches++
 	return true
 }
 
@@ -89,7 +93,7 @@ func (rp *RenderPass) SetCameraConstants(ubo *UniformBuffer) {
 	if len(rp.currentProgram.bindGroupLayouts) == 0 {
 		return
 	}
-	bg := renderer.device.CreateBindGroup(rp.currentProgram.bindGroupLayouts[0], []gpu.BindGroupEntry{{
+	bg := rp.renderer.backend.CreateBindGroup(rp.currentProgram.bindGroupLayouts[0], []BindGroupEntry{{
 		Binding: 0,
 		Buffer:  ubo.buffer,
 		Offset:  0,
@@ -107,7 +111,7 @@ func (rp *RenderPass) SetMaterial(mat *Material) {
 	if len(rp.currentProgram.bindGroupLayouts) < 2 || len(rp.currentProgram.spec.TextureBindings) == 0 {
 		return
 	}
-	entries := make([]gpu.BindGroupEntry, 0, len(rp.currentProgram.spec.TextureBindings)*2)
+	entries := make([]BindGroupEntry, 0, len(rp.currentProgram.spec.TextureBindings)*2)
 	for texName, binding := range rp.currentProgram.spec.TextureBindings {
 		tex := mat.Texture(texName)
 		if tex == nil {
@@ -121,18 +125,18 @@ func (rp *RenderPass) SetMaterial(mat *Material) {
 				}
 			}
 			if isDepth {
-				tex = renderer.defaultDepthTexture
+				tex = rp.renderer.defaultDepthTexture
 			} else {
-				tex = renderer.defaultTexture
+				tex = rp.renderer.defaultTexture
 			}
 		}
 		entries = append(entries,
-			gpu.BindGroupEntry{Binding: binding.TextureBinding, TextureView: tex.view},
-			gpu.BindGroupEntry{Binding: binding.SamplerBinding, Sampler: tex.sampler},
+			BindGroupEntry{Binding: binding.TextureBinding, TextureView: tex.view},
+			BindGroupEntry{Binding: binding.SamplerBinding, Sampler: tex.sampler},
 		)
 	}
 	if len(entries) > 0 {
-		bg := renderer.device.CreateBindGroup(rp.currentProgram.bindGroupLayouts[1], entries)
+		bg := rp.renderer.backend.CreateBindGroup(rp.currentProgram.bindGroupLayouts[1], entries)
 		rp.encoder.SetBindGroup(1, bg)
 		bg.Release()
 	}
@@ -149,22 +153,22 @@ func (rp *RenderPass) SetScissorRect(x, y, w, h uint32) {
 }
 
 // SetVertexBuffer binds a vertex buffer to a slot.
-func (rp *RenderPass) SetVertexBuffer(slot uint32, buf gpu.Buffer, offset, size uint64) {
+func (rp *RenderPass) SetVertexBuffer(slot uint32, buf BufferHandle, offset, size uint64) {
 	rp.encoder.SetVertexBuffer(slot, buf, offset, size)
 }
 
 // SetIndexBuffer binds an index buffer.
-func (rp *RenderPass) SetIndexBuffer(buf gpu.Buffer, format gpu.IndexFormat, offset, size uint64) {
+func (rp *RenderPass) SetIndexBuffer(buf BufferHandle, format IndexFormat, offset, size uint64) {
 	rp.encoder.SetIndexBuffer(buf, format, offset, size)
 }
 
-// SetGPUPipeline sets a raw GPU pipeline directly.
-func (rp *RenderPass) SetGPUPipeline(pipeline gpu.RenderPipeline) {
+// SetGPUPipeline sets a raw backend pipeline directly.
+func (rp *RenderPass) SetGPUPipeline(pipeline PipelineHandle) {
 	rp.encoder.SetPipeline(pipeline)
 }
 
 // SetBindGroup sets a bind group directly.
-func (rp *RenderPass) SetBindGroup(group uint32, bg gpu.BindGroup) {
+func (rp *RenderPass) SetBindGroup(group uint32, bg BindGroupHandle) {
 	rp.encoder.SetBindGroup(group, bg)
 }
 
@@ -176,7 +180,6 @@ func (rp *RenderPass) DrawIndexed(indexCount, instanceCount, firstIndex uint32,
 // End ends the render pass.
 func (rp *RenderPass) End() {
 	rp.encoder.End()
-	rp.encoder.Release()
 }
 
 // CurrentProgram returns the currently bound program (for ImGui rendering).
@@ -186,146 +189,180 @@ func (rp *RenderPass) CurrentProgram() *Program {
 
 // FrameStats holds per-frame rendering metrics.
 type FrameStats struct {
-	RenderPasses    int
+	RenderPasses     int
 	PipelineSwitches int
-	DrawCalls       int
-	Batches         int
-	InstancesDrawn  int
-	Flushes         int
+	DrawCalls        int
+	Batches          int
+	InstancesDrawn   int
+	Flushes          int
+
+	// occlusion culling of the frame's cull pass
+	Occluders       int
+	OcclusionTested int
+	OcclusionCulled int
 }
 
-// Renderer holds the wgpu device, queue, and rendering state.
+// Renderer holds the render backend and engine-level rendering state.
 type Renderer struct {
-	instance      gpu.Instance
-	device        gpu.Device
-	queue         gpu.Queue
-	surface       gpu.Surface
-	surfaceFormat gpu.TextureFormat
-	surfaceWidth  uint32
-	surfaceHeight uint32
-
-	// Per-frame state
-	swapChainTexture gpu.Texture
-	swapChainView    gpu.TextureView
-	encoder          gpu.CommandEncoder
-	frameActive      bool
+	engine      *Engine
+	backend     RenderBackend
+	frameActive bool
 
 	// Pipeline cache and defaults
 	pipelines           *pipelineCache
 	defaultTexture      *Texture
 	defaultDepthTexture *Texture
+	aabbMesh            *Mesh
+
+	// Render targets which follow the window size
+	windowTargets map[windowTarget]struct{}
 
 	// Per-frame metrics
 	stats FrameStats
+
+	// stats gathered while culling, before the frame begins
+	cullStats FrameStats
 }
 
 // Removed instanceData from Renderer — see package-level var below
 
-var renderer *Renderer
-
 // sharedInstanceData is a package-level buffer reused across all RenderBatch/AABB calls.
 // It must NOT live inside *Renderer — cgo would see an interior pointer into a struct
 // that contains Go pointers and panic.
 var sharedInstanceData [MaxInstances]InstanceData
 
-// InitRenderer creates and initializes the global renderer.
+// InitRenderer creates and initializes the default engine's renderer with the wgpu backend.
 func InitRenderer(metalLayer unsafe.Pointer, width, height uint32) error {
-	r := &Renderer{
-		surfaceFormat: gpu.TextureFormatBGRA8Unorm,
-		surfaceWidth:  width,
-		surfaceHeight: height,
-	}
+	return defaultEngine.InitRenderer(metalLayer, width, height)
+}
 
-	r.instance = gpu.CreateInstance()
+// InitHeadlessRenderer creates the default engine's renderer with a RecordingBackend.
+func InitHeadlessRenderer(width, height uint32) error {
+	return defaultEngine.InitHeadlessRenderer(width, height)
+}
 
-	var err error
-	adapter, err := r.instance.RequestAdapter()
-	if err != nil {
-		return fmt.Errorf("failed to get wgpu adapter: %w", err)
-	}
+// InitRendererWithBackend creates and initializes the default engine's renderer
+// on top of the given backend.
+func InitRendererWithBackend(backend RenderBackend) error {
+	return defaultEngine.InitRendererWithBackend(backend)
+}
 
-	r.device, err = adapter.RequestDevice()
+// InitRenderer creates and initializes the engine's renderer with the wgpu backend.
+func (e *Engine) InitRenderer(metalLayer unsafe.Pointer, width, height uint32) error {
+	backend, err := newWGPUBackend(metalLayer, width, height)
 	if err != nil {
-		return fmt.Errorf("failed to get wgpu device: %w", err)
+		return err
 	}
-	adapter.Release()
-
-	r.queue = r.device.GetQueue()
+	if err := e.InitRendererWithBackend(backend); err != nil {
+		return err
+	}
+	glog.Info("wgpu renderer initialized")
+	return nil
+}
 
-	r.surface, err = r.instance.CreateMetalSurface(metalLayer)
-	if err != nil {
-		return fmt.Errorf("failed to create wgpu surface: %w", err)
+// InitHeadlessRenderer creates the engine's renderer with a RecordingBackend.
+// Passes, pipeline binds and draw calls can be inspected through LastFrame,
+// which makes it suitable for integration tests.
+func (e *Engine) InitHeadlessRenderer(width, height uint32) error {
+	if err := e.InitRendererWithBackend(NewRecordingBackend(width, height)); err != nil {
+		return err
 	}
+	glog.Info("headless renderer initialized")
+	return nil
+}
 
-	r.surface.Configure(r.device, r.surfaceFormat, r.surfaceWidth, r.surfaceHeight)
+// InitRendererWithBackend creates and initializes the engine's renderer on top
+// of the given backend.
+func (e *Engine) InitRendererWithBackend(backend RenderBackend) error {
+	if backend == nil {
+		return errors.New("nil render backend")
+	}
 
-	r.pipelines = newPipelineCache()
+	r := &Renderer{engine: e, backend: backend, windowTargets: make(map[windowTarget]struct{})}
+	r.pipelines = newPipelineCache(backend)
 
 	// Create a default 1x1 white texture for missing texture bindings
-	r.defaultTexture = r.NewTexture(TextureDescriptor{
+	defaultTexture, err := r.NewTexture(TextureDescriptor{
 		Width: 1, Height: 1, Target: TextureTarget2D,
 		Format: TextureFormatRGBA, SizedFormat: TextureSizedFormatRGBA8,
 		ComponentType: TextureComponentTypeUNSIGNEDBYTE,
 		Filter: TextureFilterNearest, WrapMode: TextureWrapModeRepeat,
 	}, []byte{255, 255, 255, 255})
+	if err != nil {
+		return err
+	}
+	r.defaultTexture = defaultTexture
 
 	// Create a default 1x1 depth array texture for missing shadow bindings
-	defaultDepthTex := r.device.CreateTexture(gpu.TextureDescriptor{
-		Size:      gpu.Extent3D{Width: 1, Height: 1, DepthOrArrayLayers: 1},
-		Format:    gpu.TextureFormatDepth32Float,
-		Usage:     gpu.TextureUsageTextureBinding | gpu.TextureUsageRenderAttachment,
-		Dimension: gpu.TextureDimension2D,
+	defaultDepthTex := backend.CreateTexture(TextureStorageDescriptor{
+		Width:     1,
+		Height:    1,
+		Layers:    1,
+		Format:    PixelFormatDepth32Float,
+		Usage:     TextureUsageTextureBinding | TextureUsageRenderAttachment,
 		MipLevels: 1,
 	})
-	defaultDepthView := defaultDepthTex.CreateViewArray(1)
-	defaultDepthSampler := r.device.CreateSampler(gpu.SamplerDescriptor{
-		AddressModeU: gpu.AddressModeClampToEdge,
-		AddressModeV: gpu.AddressModeClampToEdge,
-		Compare:      gpu.CompareFunctionLessEqual,
+	defaultDepthView := backend.CreateTextureView(defaultDepthTex, TextureViewDescriptor{
+		Dimension:       TextureViewDimension2DArray,
+		ArrayLayerCount: 1,
+	})
+	defaultDepthSampler := backend.CreateSampler(SamplerDescriptor{
+		AddressModeU: AddressModeClampToEdge,
+		AddressModeV: AddressModeClampToEdge,
+		Compare:      CompareFunctionLessEqual,
 	})
 	r.defaultDepthTexture = &Texture{
-		id:      allocateTextureID(),
-		texture: defaultDepthTex, view: defaultDepthView, sampler: defaultDepthSampler,
+		renderer: r,
+		id:       allocateTextureID(),
+		texture:  defaultDepthTex, view: defaultDepthView, sampler: defaultDepthSampler,
 		descriptor: TextureDescriptor{Format: TextureFormatDEPTH, SizedFormat: TextureSizedFormatDEPTH32F},
+		format:     PixelFormatDepth32Float,
 	}
 
-	renderer = r
-	glog.Info("wgpu renderer initialized")
+	e.renderer = r
 	return nil
 }
 
 // Shutdown releases all GPU resources held by the renderer.
 func (r *Renderer) Shutdown() {
 	if r.defaultTexture != nil {
-		r.defaultTexture.view.Release()
-		r.defaultTexture.texture.Release()
-		r.defaultTexture.sampler.Release()
+		r.defaultTexture.release()
 	}
 	if r.defaultDepthTexture != nil {
-		r.defaultDepthTexture.view.Release()
-		r.defaultDepthTexture.texture.Release()
-		r.defaultDepthTexture.sampler.Release()
+		r.defaultDepthTexture.release()
 	}
 	if r.pipelines != nil {
 		r.pipelines.release()
 	}
-	if r.surface != (gpu.Surface{}) {
-		r.surface.Release()
-	}
-	if r.queue != (gpu.Queue{}) {
-		r.queue.Release()
-	}
-	if r.device != (gpu.Device{}) {
-		r.device.Release()
-	}
-	if r.instance != (gpu.Instance{}) {
-		r.instance.Release()
+	if r.aabbMesh != nil {
+		r.aabbMesh.Dispose()
 	}
+	r.backend.Shutdown()
 }
 
-// GetRenderer returns the global renderer.
+// GetRenderer returns the default engine's renderer.
 func GetRenderer() *Renderer {
-	return renderer
+	return defaultEngine.renderer
+}
+
+// Backend returns the renderer's backend.
+func (r *Renderer) Backend() RenderBackend {
+	return r.backend
+}
+
+// Headless returns whether the renderer records frames instead of drawing them.
+func (r *Renderer) Headless() bool {
+	_, ok := r.backend.(*RecordingBackend)
+	return ok
+}
+
+// LastFrame returns the last frame captured by a recording backend. It is
+// empty for any other backend.
+func (r *Renderer) LastFrame() RecordedFrame {
+	if b, ok := r.backend.(*RecordingBackend); ok {
+		return b.LastFrame()
+	}
+	return RecordedFrame{}
 }
 
 // Stats returns the frame stats from the last completed frame.
@@ -339,61 +376,62 @@ func (r *Renderer) ProgramExtension() string {
 }
 
 // NewProgram creates a new program from spec data.
-func (r *Renderer) NewProgram(name string, data []byte) *Program {
-	return loadProgram(name, data)
+func (r *Renderer) NewProgram(name string, data []byte) (*Program, error) {
+	return loadProgram(r, name, data)
 }
 
-// NewTexture creates a new texture from raw data.
-func (r *Renderer) NewTexture(d TextureDescriptor, data []byte) *Texture {
-	format := sizedFormatToGPU(d.SizedFormat)
+// NewTexture creates a new texture from raw data. It fails if the
+// descriptor's sized format isn't supported.
+func (r *Renderer) NewTexture(d TextureDescriptor, data []byte) (*Texture, error) {
+	format, err := sizedFormatToGPU(d.SizedFormat)
+	if err != nil {
+		return nil, err
+	}
+	tex := r.createTexture(d, format)
+
+	if data != nil {
+		bytesPerPixel := bytesPerPixelForFormat(d.SizedFormat)
+		r.backend.WriteTexture(tex, data, d.Width*bytesPerPixel, d.Width, d.Height)
+	}
+
+	view := r.backend.CreateTextureView(tex, TextureViewDescriptor{})
+	sampler := r.createSampler(d)
+
+	return &Texture{renderer: r, texture: tex, view: view, sampler: sampler, descriptor: d, format: format, id: allocateTextureID()}, nil
+}
 
+// createTexture creates the backend texture for a descriptor.
+func (r *Renderer) createTexture(d TextureDescriptor, format PixelFormat) TextureHandle {
 	mipLevels := uint32(1)
 	if d.Mipmaps {
 		mipLevels = uint32(math.Log2(float64(min(d.Width, d.Height)))) + 1
 	}
 
-	usage := gpu.TextureUsageTextureBinding | gpu.TextureUsageCopyDst
+	usage := TextureUsageTextureBinding | TextureUsageCopyDst
 	// Textures that will be used as framebuffer attachments need RenderAttachment
 	if d.Format == TextureFormatDEPTH || !d.Mipmaps {
-		usage |= gpu.TextureUsageRenderAttachment
+		usage |= TextureUsageRenderAttachment
 	}
 
-	tex := r.device.CreateTexture(gpu.TextureDescriptor{
-		Size:      gpu.Extent3D{Width: d.Width, Height: d.Height, DepthOrArrayLayers: 1},
+	return r.backend.CreateTexture(TextureStorageDescriptor{
+		Width:     d.Width,
+		Height:    d.Height,
+		Layers:    1,
 		Format:    format,
-		Usage:     gpu.TextureUsage(usage),
-		Dimension: gpu.TextureDimension2D,
+		Usage:     usage,
 		MipLevels: mipLevels,
 	})
-
-	if data != nil {
-		bytesPerPixel := bytesPerPixelForFormat(d.SizedFormat)
-		r.queue.WriteTexture(
-			gpu.ImageCopyTexture{Texture: tex, MipLevel: 0},
-			unsafe.Pointer(&data[0]),
-			uint64(len(data)),
-			gpu.TextureDataLayout{BytesPerRow: d.Width * bytesPerPixel, RowsPerImage: d.Height},
-			gpu.Extent3D{Width: d.Width, Height: d.Height, DepthOrArrayLayers: 1},
-		)
-	}
-
-	view := tex.CreateView()
-	sampler := r.createSampler(d)
-
-	return &Texture{texture: tex, view: view, sampler: sampler, descriptor: d, id: allocateTextureID()}
 }
 
 // NewTextureFromImageData creates a texture from encoded image bytes.
-func (r *Renderer) NewTextureFromImageData(data []byte, d TextureDescriptor) *Texture {
+func (r *Renderer) NewTextureFromImageData(data []byte, d TextureDescriptor) (*Texture, error) {
 	if data == nil {
-		glog.Warning("Cannot read texture: nil data")
-		return nil
+		return nil, errors.New("cannot read texture: nil data")
 	}
 
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		glog.Warning("Cannot decode texture image: ", err)
-		return nil
+		return nil, fmt.Errorf("cannot decode texture image: %w", err)
 	}
 
 	rgba := image.NewRGBA(img.Bounds())
@@ -414,9 +452,40 @@ func (r *Renderer) NewFramebuffer() *Framebuffer {
 	return NewFramebuffer()
 }
 
+// NewWindowFramebuffer creates a framebuffer whose attachments are resized
+// along with the window until it is disposed.
+func (r *Renderer) NewWindowFramebuffer() *Framebuffer {
+	fb := NewFramebuffer()
+	fb.renderer = r
+	r.trackWindowTarget(fb)
+	return fb
+}
+
+// windowTarget is a resource sized to the window.
+type windowTarget interface {
+	resizeToWindow(width, height uint32)
+}
+
+func (r *Renderer) trackWindowTarget(t windowTarget) {
+	r.windowTargets[t] = struct{}{}
+}
+
+func (r *Renderer) untrackWindowTarget(t windowTarget) {
+	delete(r.windowTargets, t)
+}
+
+// resize reconfigures the surface and recreates the window-sized targets for
+// a new window size in pixels.
+func (r *Renderer) resize(width, height uint32) {
+	r.backend.ResizeSurface(nil, width, height)
+	for t := range r.windowTargets {
+		t.resizeToWindow(width, height)
+	}
+}
+
 // NewMesh creates a new mesh.
 func (r *Renderer) NewMesh() *Mesh {
-	return NewMesh()
+	return newMesh(r.engine)
 }
 
 // NewUniform creates a new uniform.
@@ -426,7 +495,7 @@ func (r *Renderer) NewUniform() *Uniform {
 
 // NewUniformBuffer creates a new uniform buffer.
 func (r *Renderer) NewUniformBuffer() *UniformBuffer {
-	return NewUniformBuffer()
+	return &UniformBuffer{engine: r.engine}
 }
 
 // CanBatch returns whether two materials can be batched (same textures).
@@ -436,18 +505,10 @@ func (r *Renderer) CanBatch(a *Material, b *Material) bool {
 
 // BeginFrame acquires the swap chain texture and creates a command encoder.
 func (r *Renderer) BeginFrame() {
-	r.frameActive = false
-	st := r.surface.GetCurrentTexture()
-	if st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessOptimal &&
-		st.Status != gpu.SurfaceGetCurrentTextureStatusSuccessSuboptimal {
-		glog.Warning("Failed to acquire swap chain texture, skipping frame")
-		return
+	r.frameActive = r.backend.BeginFrame()
+	if r.frameActive {
+		r.stats, r.cullStats = r.cullStats, FrameStats{}
 	}
-	r.swapChainTexture = st.Texture
-	r.swapChainView = r.swapChainTexture.CreateView()
-	r.encoder = r.device.CreateCommandEncoder()
-	r.stats = FrameStats{}
-	r.frameActive = true
 }
 
 // BeginRenderPass creates a new render pass from the descriptor.
@@ -455,55 +516,34 @@ func (r *Renderer) BeginRenderPass(desc RenderPassDescriptor) *RenderPass {
 	if !r.frameActive {
 		return nil
 	}
+	for _, ca := range desc.ColorAttachments {
+		if ca.View == nil && ca.Surface != nil && !r.backend.AcquireSurface(ca.Surface) {
+			return nil
+		}
+	}
 	r.stats.RenderPasses++
-	gpuDesc := gpu.RenderPassDescriptor{}
 
-	var colorFormats []gpu.TextureFormat
-	depthFormat := gpu.TextureFormatUndefined
+	var colorFormats []PixelFormat
+	depthFormat := PixelFormatUndefined
 
 	for _, ca := range desc.ColorAttachments {
-		colorView := ca.View
-		if colorView == (gpu.TextureView{}) {
-			colorView = r.swapChainView
-		}
 		colorFmt := ca.Format
-		if colorFmt == gpu.TextureFormatUndefined {
-			colorFmt = r.surfaceFormat
+		if colorFmt == PixelFormatUndefined {
+			colorFmt = r.backend.SurfaceFormat()
 		}
 		colorFormats = append(colorFormats, colorFmt)
-
-		clearColor := gpu.Color{
-			R: float64(ca.ClearColor[0]),
-			G: float64(ca.ClearColor[1]),
-			B: float64(ca.ClearColor[2]),
-			A: float64(ca.ClearColor[3]),
-		}
-
-		gpuDesc.ColorAttachments = append(gpuDesc.ColorAttachments, gpu.RenderPassColorAttachment{
-			View:       colorView,
-			LoadOp:     ca.LoadOp,
-			StoreOp:    gpu.StoreOpStore,
-			ClearValue: clearColor,
-		})
 	}
 
 	if desc.DepthAttachment != nil {
 		depthFormat = desc.DepthAttachment.Format
-		if depthFormat == gpu.TextureFormatUndefined {
-			depthFormat = gpu.TextureFormatDepth32Float
-		}
-		gpuDesc.DepthStencilAttachment = &gpu.RenderPassDepthStencilAttachment{
-			View:            desc.DepthAttachment.View,
-			DepthLoadOp:     desc.DepthAttachment.LoadOp,
-			DepthStoreOp:    gpu.StoreOpStore,
-			DepthClearValue: desc.DepthAttachment.ClearDepth,
+		if depthFormat == PixelFormatUndefined {
+			depthFormat = PixelFormatDepth32Float
 		}
 	}
 
-	enc := r.encoder.BeginRenderPass(gpuDesc)
-
 	rp := &RenderPass{
-		encoder:      enc,
+		renderer:     r,
+		encoder:      r.backend.BeginRenderPass(desc),
 		colorFormats: colorFormats,
 		depthFormat:  depthFormat,
 	}
@@ -522,11 +562,7 @@ func (r *Renderer) Flush() {
 		return
 	}
 	r.stats.Flushes++
-	cmdBuf := r.encoder.Finish()
-	r.queue.Submit(cmdBuf)
-	cmdBuf.Release()
-	r.encoder.Release()
-	r.encoder = r.device.CreateCommandEncoder()
+	r.backend.Flush()
 }
 
 // EndFrame submits the command buffer and presents.
@@ -534,80 +570,70 @@ func (r *Renderer) EndFrame() {
 	if !r.frameActive {
 		return
 	}
-	cmdBuf := r.encoder.Finish()
-	r.queue.Submit(cmdBuf)
-	cmdBuf.Release()
-	r.encoder.Release()
-	r.surface.Present()
-
-	r.swapChainView.Release()
-	r.swapChainView = gpu.TextureView{}
-	r.swapChainTexture.Release()
-	r.swapChainTexture = gpu.Texture{}
+	r.backend.EndFrame()
 }
 
-func (r *Renderer) createSampler(d TextureDescriptor) gpu.Sampler {
-	desc := gpu.SamplerDescriptor{}
+func (r *Renderer) createSampler(d TextureDescriptor) SamplerHandle {
+	desc := SamplerDescriptor{}
 
 	switch d.Filter {
 	case TextureFilterNearest:
-		desc.MinFilter = gpu.FilterModeNearest
-		desc.MagFilter = gpu.FilterModeNearest
-		desc.MipmapFilter = gpu.MipmapFilterModeNearest
+		desc.MinFilter = FilterModeNearest
+		desc.MagFilter = FilterModeNearest
+		desc.MipmapFilter = MipmapFilterModeNearest
 	case TextureFilterLinear:
-		desc.MinFilter = gpu.FilterModeLinear
-		desc.MagFilter = gpu.FilterModeLinear
-		desc.MipmapFilter = gpu.MipmapFilterModeNearest
+		desc.MinFilter = FilterModeLinear
+		desc.MagFilter = FilterModeLinear
+		desc.MipmapFilter = MipmapFilterModeNearest
 	case TextureFilterMipmapLinear:
-		desc.MinFilter = gpu.FilterModeLinear
-		desc.MagFilter = gpu.FilterModeLinear
-		desc.MipmapFilter = gpu.MipmapFilterModeLinear
+		desc.MinFilter = FilterModeLinear
+		desc.MagFilter = FilterModeLinear
+		desc.MipmapFilter = MipmapFilterModeLinear
 	}
 
 	switch d.WrapMode {
 	case TextureWrapModeClampEdge, TextureWrapModeClampBorder:
-		desc.AddressModeU = gpu.AddressModeClampToEdge
-		desc.AddressModeV = gpu.AddressModeClampToEdge
-		desc.AddressModeW = gpu.AddressModeClampToEdge
+		desc.AddressModeU = AddressModeClampToEdge
+		desc.AddressModeV = AddressModeClampToEdge
+		desc.AddressModeW = AddressModeClampToEdge
 	case TextureWrapModeRepeat:
-		desc.AddressModeU = gpu.AddressModeRepeat
-		desc.AddressModeV = gpu.AddressModeRepeat
-		desc.AddressModeW = gpu.AddressModeRepeat
+		desc.AddressModeU = AddressModeRepeat
+		desc.AddressModeV = AddressModeRepeat
+		desc.AddressModeW = AddressModeRepeat
 	}
 
 	// Depth textures get a comparison sampler for hardware shadow mapping
 	if d.Format == TextureFormatDEPTH {
-		desc.Compare = gpu.CompareFunctionLessEqual
+		desc.Compare = CompareFunctionLessEqual
 	}
 
-	return r.device.CreateSampler(desc)
+	return r.backend.CreateSampler(desc)
 }
 
-func sizedFormatToGPU(f TextureSizedFormat) gpu.TextureFormat {
+func sizedFormatToGPU(f TextureSizedFormat) (PixelFormat, error) {
 	switch f {
 	case TextureSizedFormatR8:
-		return gpu.TextureFormatR8Unorm
+		return PixelFormatR8Unorm, nil
 	case TextureSizedFormatR16F:
-		return gpu.TextureFormatR16Float
+		return PixelFormatR16Float, nil
 	case TextureSizedFormatR32F:
-		return gpu.TextureFormatR32Float
+		return PixelFormatR32Float, nil
 	case TextureSizedFormatRG8:
-		return gpu.TextureFormatRG8Unorm
+		return PixelFormatRG8Unorm, nil
 	case TextureSizedFormatRG16F:
-		return gpu.TextureFormatRG16Float
+		return PixelFormatRG16Float, nil
 	case TextureSizedFormatRG32F:
-		return gpu.TextureFormatRG32Float
+		return PixelFormatRG32Float, nil
 	case TextureSizedFormatRGBA8:
-		return gpu.TextureFormatRGBA8Unorm
+		return PixelFormatRGBA8Unorm, nil
 	case TextureSizedFormatRGBA16F:
-		return gpu.TextureFormatRGBA16Float
+		return PixelFormatRGBA16Float, nil
 	case TextureSizedFormatRGBA32F:
-		return gpu.TextureFormatRGBA32Float
+		return PixelFormatRGBA32Float, nil
 	case TextureSizedFormatDEPTH32F:
-		return gpu.TextureFormatDepth32Float
+		return PixelFormatDepth32Float, nil
 	default:
-		glog.Fatalf("Unsupported texture format: %d", f)
-		return gpu.TextureFormatUndefined
+		return PixelFormatUndefined, fmt.Errorf("unsupported texture format %d", f)
 	}
 }
 
@@ -643,8 +669,9 @@ func bytesPerPixelForFormat(f TextureSizedFormat) uint32 {
 // DefaultRenderTechnique does z pre-pass, opaque pass, transparency pass.
 func DefaultRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Node) {
 	// Z-prepass
+	r := camera.engine.renderer
 	zDesc := camera.MakeRenderPassDescriptor(true, true)
-	zPass := renderer.BeginRenderPass(zDesc)
+	zPass := r.BeginRenderPass(zDesc)
 	if zPass == nil {
 		return
 	}
@@ -652,7 +679,7 @@ func DefaultRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Nod
 		if len(nodes) == 0 || m.Blending {
 			continue
 		}
-		zpassState, err := resourceManager.Pipeline(fmt.Sprintf("%s-z", nodes[0].pipeline.Name))
+		zpassState, err := camera.engine.resourceManager.Pipeline(fmt.Sprintf("%s-z", nodes[0].pipeline.Name))
 		if err != nil {
 			glog.Warningf("failed to load z-prepass pipeline: %v", err)
 			continue
@@ -666,11 +693,11 @@ func DefaultRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Nod
 	zPass.End()
 
 	// Flush — opaque pass reuses the same mesh instance buffers
-	renderer.Flush()
+	r.Flush()
 
 	// Opaque pass
 	opaqueDesc := camera.MakeRenderPassDescriptor(false, false)
-	opaquePass := renderer.BeginRenderPass(opaqueDesc)
+	opaquePass := r.BeginRenderPass(opaqueDesc)
 	for m, nodes := range materialBuckets {
 		if len(nodes) == 0 || m.Blending {
 			continue
@@ -699,11 +726,11 @@ func DefaultRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Nod
 // AABBRenderTechnique draws AABBs for all nodes.
 func AABBRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Node) {
 	desc := camera.MakeRenderPassDescriptor(false, false)
-	pass := renderer.BeginRenderPass(desc)
+	pass := camera.engine.renderer.BeginRenderPass(desc)
 	if pass == nil {
 		return
 	}
-	aabbPipeline, err := resourceManager.Pipeline("aabb")
+	aabbPipeline, err := camera.engine.resourceManager.Pipeline("aabb")
 	if err != nil {
 		glog.Warningf("failed to load aabb pipeline: %v", err)
 		return
@@ -726,7 +753,7 @@ func AABBRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]*Node)
 		}
 	}
 	if count > 0 {
-		AABBMesh().DrawInstanced(pass, count, unsafe.Pointer(&sharedInstanceData))
+		pass.renderer.AABBMesh().DrawInstanced(pass, count, unsafe.Pointer(&sharedInstanceData))
 	}
 	pass.End()
 }
@@ -744,7 +771,7 @@ func PostProcessRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]
 		camera.clearMode&ClearColor != 0,
 		camera.clearMode&ClearDepth != 0,
 	)
-	pass := renderer.BeginRenderPass(desc)
+	pass := camera.engine.renderer.BeginRenderPass(desc)
 	if pass == nil {
 		return
 	}
@@ -761,11 +788,12 @@ func PostProcessRenderTechnique(camera *Camera, materialBuckets map[*Pipeline][]
 	pass.End()
 }
 
-// RenderBatchedNodes splits a list of nodes into batched items.
+// RenderBatchedNodes splits a list of nodes into batched items of at most
+// MaxInstances nodes.
 func RenderBatchedNodes(pass *RenderPass, camera *Camera, nodes []*Node) {
 	lastBatchIndex := 0
 	for i := 1; i < len(nodes); i++ {
-		if !renderer.CanBatch(nodes[i].Material(), nodes[i-1].Material()) {
+		if i-lastBatchIndex == MaxInstances || !pass.renderer.CanBatch(nodes[i].Material(), nodes[i-1].Material()) {
 			RenderBatch(pass, camera, nodes[lastBatchIndex:i])
 			lastBatchIndex = i
 		}
@@ -780,16 +808,16 @@ func RenderBatch(pass *RenderPass, camera *Camera, nodes []*Node) {
 	}
 
 	for i, n := range nodes {
-		mMatrix64 := n.WorldTransform()
+		mMatrix64 := n.InterpolatedWorldTransform()
 		mvpMatrix64 := camera.projectionMatrix.Mul4(camera.viewMatrix.Mul4(mMatrix64))
 		sharedInstanceData[i].ModelMatrix = Mat4DoubleToFloat(mMatrix64)
 		sharedInstanceData[i].ModelViewProjectionMatrix = Mat4DoubleToFloat(mvpMatrix64)
-		sharedInstanceData[i].Custom = n.material.instanceData
+		sharedInstanceData[i].Custom = camera.instanceData(n)
 	}
 	pass.SetMaterial(nodes[0].material)
 	nodes[0].mesh.DrawInstanced(pass, len(nodes), unsafe.Pointer(&sharedInstanceData))
 
-	renderer.stats.Batches++
-	renderer.stats.DrawCalls++
-	renderer.stats.InstancesDrawn += len(nodes)
+	pass.renderer.stats.Batches++
+	pass.renderer.stats.DrawCalls++
+	pass.renderer.stats.InstancesDrawn += len(nodes)
 }
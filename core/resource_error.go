@@ -0,0 +1,192 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceKind names a type of resource in errors and path lookups.
+type ResourceKind string
+
+// Resource kinds served by a ResourceSystem.
+const (
+	ResourceModel       ResourceKind = "model"
+	ResourceTexture     ResourceKind = "texture"
+	ResourceProgram     ResourceKind = "program"
+	ResourcePipeline    ResourceKind = "pipeline"
+	ResourceProgramData ResourceKind = "program data"
+	ResourceScene       ResourceKind = "scene"
+)
+
+// ResourceError is returned when a resource cannot be read or parsed. Path,
+// Line and Column are set when known. Line and Column are 1-based.
+type ResourceError struct {
+	Kind   ResourceKind
+	Name   string
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *ResourceError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "cannot load %s", e.Kind)
+	if e.Name != "" {
+		fmt.Fprintf(&buf, " %q", e.Name)
+	}
+
+	loc := e.Path
+	if e.Line > 0 {
+		if loc != "" {
+			loc += ":"
+		}
+		loc += strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			loc += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	if loc != "" {
+		fmt.Fprintf(&buf, " (%s)", loc)
+	}
+
+	fmt.Fprintf(&buf, ": %v", e.Err)
+	return buf.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
+
+// resourceError returns err as a ResourceError for the given resource, keeping
+// any position information already attached to it.
+func resourceError(kind ResourceKind, name, path string, err error) error {
+	var re *ResourceError
+	if errors.As(err, &re) && re.Name == "" {
+		re.Kind, re.Name = kind, name
+		if re.Path == "" {
+			re.Path = path
+		}
+		return re
+	}
+	return &ResourceError{Kind: kind, Name: name, Path: path, Err: err}
+}
+
+// jsonError attaches the line and column of a JSON decoding error to it.
+func jsonError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// the offset is just past the offending byte
+		offset = syntaxErr.Offset - 1
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	line, col := offsetToLineColumn(data, offset)
+	return &ResourceError{Line: line, Column: col, Err: err}
+}
+
+// offsetToLineColumn converts a byte offset into a 1-based line and column.
+func offsetToLineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+var (
+	yamlLineRegexp  = regexp.MustCompile(`line (\d+)`)
+	yamlValueRegexp = regexp.MustCompile("`([^`]*)`")
+)
+
+// yamlError attaches the line and column of a YAML decoding error to it.
+// yaml.v3 only reports lines, so the column is recovered from the document
+// for type errors and left unset for syntax errors.
+func yamlError(data []byte, err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		line, col := yamlPosition(data, typeErr.Errors[0])
+		return &ResourceError{Line: line, Column: col, Err: err}
+	}
+
+	m := yamlLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &ResourceError{Line: line, Err: err}
+}
+
+// yamlPosition finds the node a yaml.v3 type error message refers to.
+func yamlPosition(data []byte, msg string) (int, int) {
+	m := yamlLineRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(m[1])
+
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil {
+		return line, 0
+	}
+
+	value := ""
+	if v := yamlValueRegexp.FindStringSubmatch(msg); v != nil {
+		value = v[1]
+	}
+
+	var first, match *yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Line == line && n.Kind != yaml.DocumentNode {
+			if first == nil {
+				first = n
+			}
+			if match == nil && value != "" && yamlValueMatches(n.Value, value) {
+				match = n
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(&doc)
+
+	switch {
+	case match != nil:
+		return line, match.Column
+	case first != nil:
+		return line, first.Column
+	default:
+		return line, 0
+	}
+}
+
+// yamlValueMatches reports whether a node value is the one quoted in a yaml.v3
+// error. Values longer than 10 bytes are quoted truncated to 7 bytes plus "...".
+func yamlValueMatches(nodeValue, quoted string) bool {
+	if len(nodeValue) > 10 && strings.HasSuffix(quoted, "...") {
+		return strings.HasPrefix(nodeValue, strings.TrimSuffix(quoted, "..."))
+	}
+	return nodeValue == quoted
+}
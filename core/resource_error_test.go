@@ -0,0 +1,88 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestResourceManagerSceneErrors(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	rs := e.ResourceManager().system.(*memResourceSystem)
+	rs.scenes["syntax.yaml"] = []byte("name: broken\nnodes:\n  - name: a\n    model: x: y\n")
+	rs.scenes["type.yaml"] = []byte("name: typed\nnodes:\n  - name: a\n    position: [0, zero, 0]\n")
+
+	tests := []struct {
+		scene        string
+		line, column int
+	}{
+		{"syntax.yaml", 4, 0},
+		{"type.yaml", 4, 19},
+	}
+
+	for _, tt := range tests {
+		_, err := e.ResourceManager().Scene(tt.scene)
+		var re *ResourceError
+		if !errors.As(err, &re) {
+			t.Fatalf("Scene(%q) error = %v, want *ResourceError", tt.scene, err)
+		}
+		if re.Kind != ResourceScene || re.Name != tt.scene {
+			t.Errorf("Scene(%q) error resource = %s %q", tt.scene, re.Kind, re.Name)
+		}
+		if re.Line != tt.line || re.Column != tt.column {
+			t.Errorf("Scene(%q) error position = %d:%d, want %d:%d", tt.scene, re.Line, re.Column, tt.line, tt.column)
+		}
+	}
+
+	_, err := e.ResourceManager().Scene("missing.yaml")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Scene(missing) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestResourceManagerPipelineError(t *testing.T) {
+	e := newTestEngine(t)
+	rs := e.ResourceManager().system.(*memResourceSystem)
+	rs.pipelines["bad"] = []byte("{\n  \"programName\": \"unlit\",\n  \"depthTest\": yes\n}")
+
+	_, err := e.ResourceManager().Pipeline("bad")
+	var re *ResourceError
+	if !errors.As(err, &re) {
+		t.Fatalf("Pipeline error = %v, want *ResourceError", err)
+	}
+	if re.Kind != ResourcePipeline || re.Name != "bad" || re.Line != 3 || re.Column != 16 {
+		t.Errorf("Pipeline error = %+v, want pipeline \"bad\" at 3:16", re)
+	}
+	if _, err := e.ResourceManager().Pipeline("unlit"); err != nil {
+		t.Errorf("Pipeline(unlit) after failure = %v", err)
+	}
+}
+
+func TestResourceManagerProgramError(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	rs := e.ResourceManager().system.(*memResourceSystem)
+	rs.programs["lit"] = []byte(`{"shaders": {"vertex": "lit.vert.wgsl"}}`)
+
+	_, err := e.ResourceManager().Program("lit")
+	var re *ResourceError
+	if !errors.As(err, &re) {
+		t.Fatalf("Program error = %v, want *ResourceError", err)
+	}
+	if re.Kind != ResourceProgram || re.Name != "lit" {
+		t.Errorf("Program error resource = %s %q, want program \"lit\"", re.Kind, re.Name)
+	}
+	var data *ResourceError
+	if !errors.As(re.Err, &data) || data.Kind != ResourceProgramData || data.Name != "lit.vert.wgsl" {
+		t.Errorf("Program error cause = %v, want missing program data", re.Err)
+	}
+}
+
+func TestResourceErrorMessage(t *testing.T) {
+	err := &ResourceError{Kind: ResourceScene, Name: "demo.yaml", Path: "data/scenes/demo.yaml", Line: 3, Column: 7, Err: errors.New("boom")}
+	want := `cannot load scene "demo.yaml" (data/scenes/demo.yaml:3:7): boom`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -2,23 +2,30 @@ package core
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 )
 
-// ResourceSystem is an interface which wraps all resource management logic.
+// ResourceSystem is an interface which wraps all resource management logic. Read
+// failures should be reported as a *ResourceError.
 type ResourceSystem interface {
-	Model(string) []byte
+	Model(string) ([]byte, error)
 	ModelPath(string) string
-	Texture(string) []byte
-	Program(string) []byte
-	Pipeline(string) []byte
-	ProgramData(string) []byte
-	Scene(string) []byte
+	Texture(string) ([]byte, error)
+	Program(string) ([]byte, error)
+	Pipeline(string) ([]byte, error)
+	ProgramData(string) ([]byte, error)
+	Scene(string) ([]byte, error)
+}
+
+// ResourcePather is implemented by resource systems which can tell where a
+// resource is stored. The path is attached to load errors.
+type ResourcePather interface {
+	ResourcePath(kind ResourceKind, name string) string
 }
 
 // ResourceManager wraps a resourcesystem and contains configuration about the location of each resource type.
 type ResourceManager struct {
+	engine    *Engine
 	system    ResourceSystem
 	programs  map[string]*Program
 	pipelines map[string]*Pipeline
@@ -26,12 +33,11 @@ type ResourceManager struct {
 	textures  map[string]*Texture
 }
 
-var (
-	resourceManager *ResourceManager
-)
+var errNoResourceSystem = errors.New("no resource system registered")
 
-func init() {
-	resourceManager = &ResourceManager{
+func newResourceManager(e *Engine) *ResourceManager {
+	return &ResourceManager{
+		engine:    e,
 		programs:  make(map[string]*Program),
 		pipelines: make(map[string]*Pipeline),
 		models:    make(map[string]*Node),
@@ -39,9 +45,9 @@ func init() {
 	}
 }
 
-// GetResourceManager returns the resource manager.
+// GetResourceManager returns the default engine's resource manager.
 func GetResourceManager() *ResourceManager {
-	return resourceManager
+	return defaultEngine.resourceManager
 }
 
 // SetSystem sets the resource manager's resource system
@@ -53,19 +59,46 @@ func (r *ResourceManager) SetSystem(s ResourceSystem) error {
 	return nil
 }
 
+// path returns where a resource lives if the resource system can tell.
+func (r *ResourceManager) path(kind ResourceKind, name string) string {
+	if p, ok := r.system.(ResourcePather); ok {
+		return p.ResourcePath(kind, name)
+	}
+	return ""
+}
+
+// load reads a resource through the resource system.
+func (r *ResourceManager) load(kind ResourceKind, name string, read func(ResourceSystem, string) ([]byte, error)) ([]byte, error) {
+	if r.system == nil {
+		return nil, &ResourceError{Kind: kind, Name: name, Err: errNoResourceSystem}
+	}
+	data, err := read(r.system, name)
+	if err != nil {
+		return nil, resourceError(kind, name, r.path(kind, name), err)
+	}
+	return data, nil
+}
+
 // Model returns a scenegraph node.
 func (r *ResourceManager) Model(name string) (*Node, error) {
 	if r.models[name] == nil {
 		var node *Node
 		var err error
 		if strings.HasSuffix(name, ".gltf") || strings.HasSuffix(name, ".glb") {
-			node, err = LoadGLTF(name, r.system)
+			if r.system == nil {
+				return nil, &ResourceError{Kind: ResourceModel, Name: name, Err: errNoResourceSystem}
+			}
+			node, err = loadGLTF(r.engine, name, r.system)
 		} else {
-			resource := r.system.Model(name)
-			node, err = LoadModel(name, resource)
+			var resource []byte
+			resource, err = r.load(ResourceModel, name, ResourceSystem.Model)
+			if err != nil {
+				return nil, err
+			}
+			node, err = loadModel(r.engine, name, resource)
 		}
 		if err != nil {
-			return nil, err
+			return nil, resourceError(ResourceModel, name, r.path(ResourceModel, name), err)
 		}
 		r.models[name] = node
 	}
@@ -73,21 +106,31 @@ func (r *ResourceManager) Model(name string) (*Node, error) {
 }
 
 // Program returns a GPU program.
-func (r *ResourceManager) Program(name string) *Program {
+func (r *ResourceManager) Program(name string) (*Program, error) {
 	if r.programs[name] == nil {
-		resource := r.system.Program(name)
-		r.programs[name] = renderer.NewProgram(name, resource)
+		resource, err := r.load(ResourceProgram, name, ResourceSystem.Program)
+		if err != nil {
+			return nil, err
+		}
+		program, err := r.engine.renderer.NewProgram(name, resource)
+		if err != nil {
+			return nil, resourceError(ResourceProgram, name, r.path(ResourceProgram, name), err)
+		}
+		r.programs[name] = program
 	}
-	return r.programs[name]
+	return r.programs[name], nil
 }
 
 // Pipeline returns a Pipeline parsed from JSON.
 func (r *ResourceManager) Pipeline(name string) (*Pipeline, error) {
 	if r.pipelines[name] == nil {
-		resource := r.system.Pipeline(name)
+		resource, err := r.load(ResourcePipeline, name, ResourceSystem.Pipeline)
+		if err != nil {
+			return nil, err
+		}
 		pipeline, err := ParsePipeline(resource)
 		if err != nil {
-			return nil, fmt.Errorf("cannot parse pipeline %s: %w", name, err)
+			return nil, resourceError(ResourcePipeline, name, r.path(ResourcePipeline, name), err)
 		}
 		pipeline.Name = name
 		r.pipelines[name] = pipeline
@@ -96,12 +139,24 @@ func (r *ResourceManager) Pipeline(name string) (*Pipeline, error) {
 }
 
 // ProgramData returns source file contents for a given program or subprogram
-func (r *ResourceManager) ProgramData(name string) []byte {
-	return r.system.ProgramData(name)
+func (r *ResourceManager) ProgramData(name string) ([]byte, error) {
+	return r.load(ResourceProgramData, name, ResourceSystem.ProgramData)
 }
 
 // Scene loads and returns a Scene from a YAML file.
-func (r *ResourceManager) Scene(name string) *Scene {
-	data := r.system.Scene(name)
-	return LoadSceneFromYAML(data)
+func (r *ResourceManager) Scene(name string) (*Scene, error) {
+	data, err := r.sceneData(name)
+	if err != nil {
+		return nil, err
+	}
+	scene, err := loadSceneFromYAML(r.engine, name, data)
+	if err != nil {
+		return nil, resourceError(ResourceScene, name, r.path(ResourceScene, name), err)
+	}
+	return scene, nil
+}
+
+// sceneData returns the YAML source of a scene.
+func (r *ResourceManager) sceneData(name string) ([]byte, error) {
+	return r.load(ResourceScene, name, ResourceSystem.Scene)
 }
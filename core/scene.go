@@ -2,7 +2,9 @@ package core
 
 import (
 	"math"
+	"slices"
 	"sort"
+	"sync/atomic"
 
 	"github.com/go-gl/mathgl/mgl64"
 )
@@ -16,11 +18,20 @@ type Scene struct {
 	// should the scenemanager call update and draw this scene
 	active bool
 
+	// devices whose input the scenes below don't get
+	inputCapture InputCapture
+
 	cameraList []*Camera
 	cameraMap  map[string]int
 
 	// per scene lights list
 	lights []*Light
+
+	// node lookup indices, rebuilt when the graph changes
+	nodesByName  map[string][]*Node
+	nodesByTag   map[string][]*Node
+	indexRoot    *Node
+	indexVersion uint64
 }
 
 // NewScene returns a new scene.
@@ -51,6 +62,51 @@ func (s *Scene) Camera(name string) *Camera {
 	return s.cameraList[s.cameraMap[name]]
 }
 
+// FindNode returns the first node named name in depth first order, or nil.
+func (s *Scene) FindNode(name string) *Node {
+	s.index()
+	if nodes := s.nodesByName[name]; len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// FindNodes returns all nodes named name in depth first order.
+func (s *Scene) FindNodes(name string) []*Node {
+	s.index()
+	return s.nodesByName[name]
+}
+
+// FindTagged returns all nodes tagged with tag in depth first order.
+func (s *Scene) FindTagged(tag string) []*Node {
+	s.index()
+	return s.nodesByTag[tag]
+}
+
+// index rebuilds the name and tag lookups if the graph changed since they
+// were built.
+func (s *Scene) index() {
+	version := atomic.LoadUint64(&graphVersion)
+	if s.nodesByName != nil && s.indexRoot == s.root && s.indexVersion == version {
+		return
+	}
+
+	s.nodesByName = make(map[string][]*Node)
+	s.nodesByTag = make(map[string][]*Node)
+	s.indexRoot, s.indexVersion = s.root, version
+	if s.root == nil {
+		return
+	}
+
+	s.root.Walk(func(n *Node) VisitAction {
+		s.nodesByName[n.name] = append(s.nodesByName[n.name], n)
+		for _, t := range n.tags {
+			s.nodesByTag[t] = append(s.nodesByTag[t], n)
+		}
+		return VisitContinue
+	}, nil)
+}
+
 // SetRoot returns the scene's root node
 func (s *Scene) SetRoot(root *Node) {
 	s.root = root
@@ -84,21 +140,62 @@ func (s *Scene) AddCamera(node *Node, camera *Camera) {
 	}
 }
 
-func (s *Scene) update(dt float64) {
+// RemoveCamera removes a camera from the scene and detaches its node.
+func (s *Scene) RemoveCamera(camera *Camera) {
+	i := slices.Index(s.cameraList, camera)
+	if i < 0 {
+		return
+	}
+	s.cameraList = slices.Delete(s.cameraList, i, i+1)
+	clear(s.cameraMap)
+	for i, c := range s.cameraList {
+		s.cameraMap[c.name] = i
+	}
+	if p := camera.node.parent; p != nil {
+		p.RemoveChild(camera.node)
+	}
+
+	// LOD groups keep a selection per camera
+	s.root.Walk(func(n *Node) VisitAction {
+		if n.lodGroup != nil {
+			n.lodGroup.forget(camera)
+		}
+		return VisitContinue
+	}, nil)
+}
+
+// processInput runs the scene's input components and their commands.
+func (s *Scene) processInput() {
+	s.root.processInput()
+}
+
+func (s *Scene) update(e *Engine, dt float64) {
 	// physics update
 	var physicsNodes []*Node
 	if s.root.physicsComponent != nil {
 		s.root.physicsComponent.Run(s.root, &physicsNodes)
 	}
 
-	physicsSystem.Update(dt, physicsNodes)
+	if e.physicsSystem != nil {
+		e.physicsSystem.Update(dt, physicsNodes)
+	}
 
 	// update transforms and bounds
 	s.root.update(s, dt)
 }
 
-func (s *Scene) cull() {
+// interpolate blends the scene's transforms between the last two fixed steps
+// for rendering.
+func (s *Scene) interpolate(alpha float64) {
+	s.root.Walk(func(n *Node) VisitAction {
+		n.interpolate(alpha)
+		return VisitContinue
+	}, nil)
+}
+
+func (s *Scene) cull(e *Engine) {
 	for _, c := range s.cameraList {
+		c.setEngine(e)
 		if c.autoFrustum {
 			// Compute clip distances from scene bounds in view space,
 			// not Euclidean distance, so they stay correct as the camera rotates.
@@ -111,7 +208,7 @@ func (s *Scene) cull() {
 			}
 			c.SetClipDistance(mgl64.Vec2{near, far})
 		}
-		c.Reshape(windowManager.WindowSize())
+		c.Reshape(c.windowSize())
 	}
 
 	s.lights = s.lights[:0]
@@ -119,39 +216,76 @@ func (s *Scene) cull() {
 		s.root.lightExtractor.Run(s.root, &s.lights)
 	}
 
-	for _, c := range s.cameraList {
+	// cameras only write to their own buckets so they are culled in parallel
+	occlusionStats := make([]FrameStats, len(s.cameraList))
+	e.workers.run(len(s.cameraList), func(i int) {
+		c := s.cameraList[i]
 		for bk := range c.pipelineBuckets {
 			c.pipelineBuckets[bk] = c.pipelineBuckets[bk][:0]
-			c.visibleOpaqueNodes = c.visibleOpaqueNodes[:0]
 		}
+		c.visibleOpaqueNodes = c.visibleOpaqueNodes[:0]
+		clear(c.lodFades)
 
 		c.scene.CullComponent().Run(s, c, c.scene)
 
-		for bk := range c.pipelineBuckets {
-			for _, n := range c.pipelineBuckets[bk] {
-				var meshID uint32
-				if n.mesh != nil {
-					meshID = n.mesh.id
-				}
-				n.sortKey = (n.material.sortKey << 32) | uint64(meshID)
-			}
-			sort.Sort(NodesByMaterial(c.pipelineBuckets[bk]))
+		if c.occlusion != nil {
+			stats := &occlusionStats[i]
+			stats.Occluders, stats.OcclusionTested, stats.OcclusionCulled = c.occlusion.cull(c)
 		}
-		sort.Sort(NodesByCameraDistanceNearToFar{Nodes: c.visibleOpaqueNodes, RefNode: c.node})
+	})
+
+	// nodes may be in several cameras' buckets, so their sort keys are set
+	// before the buckets are sorted in parallel
+	var buckets [][]*Node
+	for i, c := range s.cameraList {
+		if e.renderer != nil {
+			e.renderer.cullStats.Occluders += occlusionStats[i].Occluders
+			e.renderer.cullStats.OcclusionTested += occlusionStats[i].OcclusionTested
+			e.renderer.cullStats.OcclusionCulled += occlusionStats[i].OcclusionCulled
+		}
+		for _, bucket := range c.pipelineBuckets {
+			setSortKeys(bucket)
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	cameras := len(s.cameraList)
+	e.workers.run(cameras+len(buckets), func(i int) {
+		if i < cameras {
+			c := s.cameraList[i]
+			sort.Sort(&NodesByCameraDistanceNearToFar{Nodes: c.visibleOpaqueNodes, RefNode: c.node})
+			return
+		}
+		sort.Sort(NodesByMaterial(buckets[i-cameras]))
+	})
+}
+
+// setSortKeys sets the keys NodesByMaterial sorts nodes by.
+func setSortKeys(nodes []*Node) {
+	for _, n := range nodes {
+		var meshID uint32
+		if n.mesh != nil {
+			meshID = n.mesh.id
+		}
+		n.sortKey = (n.material.sortKey << 32) | uint64(meshID)
 	}
 }
 
-func (s *Scene) draw() {
+func (s *Scene) draw(e *Engine) {
 	for _, camera := range s.cameraList {
+		if camera.window != nil && camera.window.closed {
+			continue
+		}
+		lights := camera.visibleLights(s.lights)
 		if camera.projectionType == PerspectiveProjection {
-			for _, light := range s.lights {
+			for _, light := range lights {
 				if light.Shadower != nil {
 					light.Shadower.Render(light, camera)
 				}
 			}
 		}
 
-		camera.constants.SetData(camera.ProjectionMatrix(), camera.ViewMatrix(), s.lights)
+		camera.constants.SetData(camera.ProjectionMatrix(), camera.ViewMatrix(), lights)
 		camera.renderTechnique(camera, camera.pipelineBuckets)
 	}
 }
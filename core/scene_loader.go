@@ -1,6 +1,8 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -9,34 +11,49 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// LoadSceneFromYAML parses YAML scene data and returns a Scene.
-func LoadSceneFromYAML(data []byte) *Scene {
+// LoadSceneFromYAML parses YAML scene data and returns a Scene. Resources are
+// loaded through the default engine. Parse errors, and models, pipelines or
+// components which cannot be loaded, are returned as a *ResourceError carrying
+// the line and column.
+func LoadSceneFromYAML(data []byte) (*Scene, error) {
+	return loadSceneFromYAML(defaultEngine, "", data)
+}
+
+// loadSceneFromYAML builds a scene. The name is the scene resource being
+// loaded, if any, and is used to detect includes of itself.
+func loadSceneFromYAML(e *Engine, name string, data []byte) (*Scene, error) {
 	var sf SceneFile
 	if err := yaml.Unmarshal(data, &sf); err != nil {
-		glog.Fatalf("Failed to parse scene YAML: %v", err)
+		return nil, yamlError(data, err)
 	}
 
 	scene := NewScene(sf.Name)
 	root := NewNode("ROOT")
 	scene.SetRoot(root)
 
-	// Track cameras and framebuffers for deferred texture references
-	cameraMap := make(map[string]*Camera)
-	var deferred []deferredTexRef
+	b := &sceneBuilder{engine: e}
+	if name != "" {
+		b.includes = []string{name}
+	}
 
 	// Pass 1: Build node tree
 	for i := range sf.Nodes {
-		node, cams, refs := buildNode(&sf.Nodes[i])
-		root.AddChild(node)
-		for _, ce := range cams {
-			cameraMap[ce.camera.Name()] = ce.camera
-			scene.AddCamera(ce.parent, ce.camera)
+		node, err := b.buildNode(&sf.Nodes[i])
+		if err != nil {
+			return nil, err
 		}
-		deferred = append(deferred, refs...)
+		root.AddChild(node)
+	}
+
+	// Track cameras and framebuffers for deferred texture references
+	cameraMap := make(map[string]*Camera)
+	for _, ce := range b.cameras {
+		cameraMap[ce.camera.Name()] = ce.camera
+		scene.AddCamera(ce.parent, ce.camera)
 	}
 
 	// Pass 2: Resolve deferred texture references ($CameraName.framebuffer.color0)
-	for _, ref := range deferred {
+	for _, ref := range b.deferred {
 		tex := resolveTextureRef(ref.ref, cameraMap)
 		if tex != nil {
 			ref.node.Material().SetTexture(ref.texName, tex)
@@ -46,9 +63,11 @@ func LoadSceneFromYAML(data []byte) *Scene {
 	}
 
 	scene.SetActive(true)
-	return scene
+	return scene, nil
 }
 
+var errIncludeCycle = errors.New("include cycle")
+
 type deferredTexRef struct {
 	node    *Node
 	texName string
@@ -60,10 +79,24 @@ type cameraEntry struct {
 	camera *Camera
 }
 
-func buildNode(sn *SceneNode) (*Node, []cameraEntry, []deferredTexRef) {
+// sceneBuilder holds the state of a scene being built from YAML.
+type sceneBuilder struct {
+	engine   *Engine
+	includes []string // includes being expanded, outermost first
+	cameras  []cameraEntry
+	deferred []deferredTexRef
+}
+
+func (b *sceneBuilder) buildNode(sn *SceneNode) (*Node, error) {
+	e := b.engine
 	node := NewNode(sn.Name)
-	var deferred []deferredTexRef
-	var cameras []cameraEntry
+	for _, t := range sn.Tags {
+		node.AddTag(t)
+	}
+	if sn.Layers != 0 {
+		node.SetLayers(sn.Layers)
+	}
+	node.SetOccluder(sn.Occluder)
 
 	// Apply transform
 	if sn.Position != [3]float64{} {
@@ -78,37 +111,49 @@ func buildNode(sn *SceneNode) (*Node, []cameraEntry, []deferredTexRef) {
 
 	// Load model
 	if sn.Model != "" {
-		model, err := resourceManager.Model(sn.Model)
+		model, err := e.resourceManager.Model(sn.Model)
 		if err != nil {
-			glog.Warningf("Scene: failed to load model %q: %v", sn.Model, err)
-		} else {
-			for _, c := range model.Children() {
-				node.AddChild(c)
-			}
+			return nil, nodeError(sn, sn.posOf("model"), err)
+		}
+		node.model = sn.Model
+		for _, c := range model.Children() {
+			c.instanced = true
+			node.AddChild(c)
+		}
+	}
+
+	// Included scene or subtree
+	if sn.Include != "" {
+		if err := b.include(node, sn); err != nil {
+			return nil, err
 		}
 	}
 
 	// Pipeline
 	if sn.Pipeline != "" {
-		pipeline, err := resourceManager.Pipeline(sn.Pipeline)
+		pipeline, err := e.resourceManager.Pipeline(sn.Pipeline)
 		if err != nil {
-			glog.Warningf("Scene: failed to load pipeline %q: %v", sn.Pipeline, err)
-		} else {
-			node.SetPipeline(pipeline)
+			return nil, nodeError(sn, sn.posOf("pipeline"), err)
 		}
+		node.SetPipeline(pipeline)
 	}
 
-	// Cull component
-	if sn.Cull != "" {
-		if culler := LookupCullComponent(sn.Cull); culler != nil {
-			node.SetCullComponent(culler)
+	// Components
+	for _, err := range []error{
+		setComponent(sn.Cull, LookupCullComponentWithParams, node.SetCullComponent),
+		setComponent(sn.Input, LookupInputComponentWithParams, node.SetInputComponent),
+		setComponent(sn.Update, LookupUpdater, node.SetUpdateComponent),
+		setComponent(sn.Physics, LookupPhysicsComponent, node.SetPhysicsComponent),
+		setComponent(sn.LightExtractor, LookupLightExtractor, node.SetLightExtractor),
+	} {
+		if err != nil {
+			return nil, nodeError(sn, sn.pos, err)
 		}
 	}
 
 	// Screen quad
 	if sn.ScreenQuad {
-		windowSize := GetWindowManager().WindowSize()
-		node.SetMesh(NewScreenQuadMesh(windowSize.X(), windowSize.Y()))
+		node.SetMesh(newWindowQuadMesh(e))
 	}
 
 	// Light
@@ -121,7 +166,7 @@ func buildNode(sn *SceneNode) (*Node, []cameraEntry, []deferredTexRef) {
 			ShadowBias: sn.Light.ShadowBias,
 		}
 		if sn.Light.Shadow != nil {
-			light.Shadower = NewShadowMap(sn.Light.Shadow.Size, sn.Light.Shadow.Cascades)
+			light.Shadower = newShadowMap(e, sn.Light.Shadow.Size, sn.Light.Shadow.Cascades)
 		}
 		node.SetLight(light)
 	}
@@ -129,34 +174,206 @@ func buildNode(sn *SceneNode) (*Node, []cameraEntry, []deferredTexRef) {
 	// Textures (may contain deferred references)
 	for texName, texRef := range sn.Textures {
 		if strings.HasPrefix(texRef, "$") {
-			deferred = append(deferred, deferredTexRef{node: node, texName: texName, ref: texRef})
+			b.deferred = append(b.deferred, deferredTexRef{node: node, texName: texName, ref: texRef})
 		}
 	}
 
 	// Build children
 	for i := range sn.Children {
-		child, childCams, childRefs := buildNode(&sn.Children[i])
+		child, err := b.buildNode(&sn.Children[i])
+		if err != nil {
+			return nil, err
+		}
 		node.AddChild(child)
-		deferred = append(deferred, childRefs...)
-		cameras = append(cameras, childCams...)
+	}
+
+	// Level of detail, once the levels' nodes are in place
+	if sn.LOD != nil {
+		node.SetLODGroup(buildLODGroup(node, sn.LOD))
+	}
+
+	// Rigid body, built once meshes and children are in place
+	if sn.RigidBody != nil {
+		if e.physicsSystem == nil {
+			return nil, nodeError(sn, sn.posOf("rigidBody"), errors.New("rigid body needs a physics system"))
+		}
+		body, err := buildRigidBody(e.physicsSystem, node, sn.RigidBody)
+		if err != nil {
+			return nil, nodeError(sn, sn.posOf("rigidBody"), fmt.Errorf("rigid body: %w", err))
+		}
+		node.SetRigidBody(body)
+		node.rigidBodyDef = sn.RigidBody
 	}
 
 	// Camera (defined on the node that owns it)
 	if sn.Camera != nil {
-		cam := buildCamera(sn.Camera, node)
-		cameras = append(cameras, cameraEntry{parent: node, camera: cam})
+		cam, err := buildCamera(e, sn.Camera, node)
+		if err != nil {
+			return nil, nodeError(sn, sn.posOf("camera"), err)
+		}
+		b.cameras = append(b.cameras, cameraEntry{parent: node, camera: cam})
 	}
 
-	return node, cameras, deferred
+	return node, nil
 }
 
-func buildCamera(cd *CameraDef, sceneNode *Node) *Camera {
+// buildLODGroup creates the level of detail group described for a node. Levels
+// which don't name one of the node's children are skipped.
+func buildLODGroup(node *Node, def *LODDef) *LODGroup {
+	g := &LODGroup{Hysteresis: def.Hysteresis, FadeDuration: def.FadeDuration}
+	for _, ld := range def.Levels {
+		level := node.Find(ld.Node)
+		if level == nil || level.parent != node {
+			glog.Warningf("Scene: node %q: LOD level %q is not a child", node.name, ld.Node)
+			continue
+		}
+		g.Levels = append(g.Levels, LODLevel{Node: level, ScreenSize: ld.ScreenSize})
+	}
+	return g
+}
+
+// include instantiates the nodes of another scene file, or a subtree of it, as
+// children of node. The included nodes are marked as instanced so saving the
+// scene writes the include rather than its contents.
+func (b *sceneBuilder) include(node *Node, sn *SceneNode) error {
+	for _, inc := range b.includes {
+		if inc == sn.Include {
+			chain := strings.Join(append(b.includes, sn.Include), " -> ")
+			return fmt.Errorf("%w: %s", errIncludeCycle, chain)
+		}
+	}
+
+	name, subtree, _ := strings.Cut(sn.Include, "#")
+	data, err := b.engine.resourceManager.sceneData(name)
+	if err != nil {
+		return err
+	}
+
+	path := b.engine.resourceManager.path(ResourceScene, name)
+	var sf SceneFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return resourceError(ResourceScene, name, path, yamlError(data, err))
+	}
+
+	nodes := sf.Nodes
+	if subtree != "" {
+		sub := findSceneNode(nodes, subtree)
+		if sub == nil {
+			return &ResourceError{Kind: ResourceScene, Name: name, Err: fmt.Errorf("no node at %q", subtree)}
+		}
+		nodes = []SceneNode{*sub}
+	}
+
+	for target, o := range sn.Overrides {
+		t := findSceneNode(nodes, target)
+		if t == nil {
+			glog.Warningf("Scene: node %q overrides %q which is not in %q", sn.Name, target, sn.Include)
+			continue
+		}
+		o.apply(t)
+	}
+
+	b.includes = append(b.includes, sn.Include)
+	defer func() { b.includes = b.includes[:len(b.includes)-1] }()
+
+	for i := range nodes {
+		child, err := b.buildNode(&nodes[i])
+		if err != nil {
+			// positions are in the included scene
+			return resourceError(ResourceScene, name, path, err)
+		}
+		child.instanced = true
+		node.AddChild(child)
+	}
+
+	node.include = sn.Include
+	node.overrides = sn.Overrides
+	return nil
+}
+
+// findSceneNode returns the node at a slash separated path of node names.
+func findSceneNode(nodes []SceneNode, path string) *SceneNode {
+	name, rest, nested := strings.Cut(path, "/")
+	for i := range nodes {
+		if nodes[i].Name != name {
+			continue
+		}
+		if !nested {
+			return &nodes[i]
+		}
+		return findSceneNode(nodes[i].Children, rest)
+	}
+	return nil
+}
+
+// setComponent looks up a component from its scene definition and sets it on a
+// node. Errors for unknown components carry the definition's position.
+func setComponent[T any](def ComponentDef, lookup func(string, ComponentParams) (T, error), set func(T)) error {
+	if def.Type == "" {
+		return nil
+	}
+	c, err := lookup(def.Type, def.Params)
+	if err != nil {
+		return &ResourceError{Kind: ResourceScene, Line: def.pos.line, Column: def.pos.column, Err: err}
+	}
+	set(c)
+	return nil
+}
+
+// nodeError returns an error building a node as a ResourceError at a position
+// in the scene, keeping the position of errors which already have one. The
+// scene's name and path are filled in by the resource manager.
+func nodeError(sn *SceneNode, pos sourcePos, err error) error {
+	var re *ResourceError
+	if errors.As(err, &re) && re.Kind == ResourceScene && re.Name == "" {
+		re.Err = fmt.Errorf("node %q: %w", sn.Name, re.Err)
+		return re
+	}
+	return &ResourceError{Kind: ResourceScene, Line: pos.line, Column: pos.column, Err: fmt.Errorf("node %q: %w", sn.Name, err)}
+}
+
+// apply copies the set fields of an override onto a node definition.
+func (o *NodeOverride) apply(sn *SceneNode) {
+	if o.Position != [3]float64{} {
+		sn.Position = o.Position
+	}
+	if o.Rotation != [4]float64{} {
+		sn.Rotation = o.Rotation
+	}
+	if o.Scale != [3]float64{} {
+		sn.Scale = o.Scale
+	}
+	if o.Pipeline != "" {
+		sn.Pipeline = o.Pipeline
+	}
+	if o.Cull.Type != "" {
+		sn.Cull = o.Cull
+	}
+	if o.Input.Type != "" {
+		sn.Input = o.Input
+	}
+	if o.Update.Type != "" {
+		sn.Update = o.Update
+	}
+	if len(o.Textures) > 0 {
+		textures := make(map[string]string, len(sn.Textures)+len(o.Textures))
+		for k, v := range sn.Textures {
+			textures[k] = v
+		}
+		for k, v := range o.Textures {
+			textures[k] = v
+		}
+		sn.Textures = textures
+	}
+}
+
+func buildCamera(e *Engine, cd *CameraDef, sceneNode *Node) (*Camera, error) {
 	projType := PerspectiveProjection
 	if cd.Projection == "orthographic" {
 		projType = OrthographicProjection
 	}
 
-	cam := NewCamera(cd.Name, projType)
+	cam := newCamera(e, cd.Name, projType)
 
 	if cd.AutoReshape != nil {
 		cam.SetAutoReshape(*cd.AutoReshape)
@@ -187,16 +404,21 @@ func buildCamera(cd *CameraDef, sceneNode *Node) *Camera {
 	}
 
 	cam.SetRenderOrder(cd.RenderOrder)
+	if cd.CullingMask != 0 {
+		cam.SetCullingMask(cd.CullingMask)
+	}
+	cam.SetOcclusionCulling(cd.OcclusionCulling)
 
 	if cd.Position != [3]float64{} {
 		cam.Node().Translate(mgl64.Vec3(cd.Position))
 	}
+	if cd.Rotation != [4]float64{} {
+		cam.Node().Rotate(cd.Rotation[0], mgl64.Vec3{cd.Rotation[1], cd.Rotation[2], cd.Rotation[3]})
+	}
 
 	// Input component
-	if cd.Input != "" {
-		if ic := LookupInputComponent(cd.Input); ic != nil {
-			cam.Node().SetInputComponent(ic)
-		}
+	if err := setComponent(cd.Input, LookupInputComponentWithParams, cam.Node().SetInputComponent); err != nil {
+		return nil, err
 	}
 
 	// Render technique
@@ -208,35 +430,41 @@ func buildCamera(cd *CameraDef, sceneNode *Node) *Camera {
 
 	// Framebuffer
 	if cd.Framebuffer != nil {
-		fb := GetRenderer().NewFramebuffer()
+		fb := e.renderer.NewWindowFramebuffer()
 		cam.SetFramebuffer(fb)
 
-		windowSize := GetWindowManager().WindowSize()
+		windowSize := e.windowManager.WindowSize()
 		w := uint32(windowSize.X())
 		h := uint32(windowSize.Y())
 
 		if cd.Framebuffer.Color0 != nil {
-			tex := createAttachmentTexture(cd.Framebuffer.Color0, w, h)
+			tex, err := createAttachmentTexture(e, cd.Framebuffer.Color0, w, h)
+			if err != nil {
+				return nil, fmt.Errorf("color0 attachment: %w", err)
+			}
 			fb.SetColorAttachment(0, tex)
 		}
 		if cd.Framebuffer.Depth != nil {
-			tex := createAttachmentTexture(cd.Framebuffer.Depth, w, h)
+			tex, err := createAttachmentTexture(e, cd.Framebuffer.Depth, w, h)
+			if err != nil {
+				return nil, fmt.Errorf("depth attachment: %w", err)
+			}
 			fb.SetDepthAttachment(tex)
 		}
 	}
 
-	// For orthographic cameras without autoReshape, set viewport explicitly
+	// Orthographic cameras without autoReshape still cover the window, the
+	// cull reshapes them when it is resized
 	if projType == OrthographicProjection && (cd.AutoReshape == nil || !*cd.AutoReshape) {
-		windowSize := GetWindowManager().WindowSize()
-		cam.SetViewport(mgl32.Vec4{0, 0, windowSize.X(), windowSize.Y()})
-		cam.Reshape(windowSize)
+		cam.fitWindow = true
+		cam.Reshape(e.windowManager.WindowSize())
 	}
 
 	cam.SetScene(sceneNode)
-	return cam
+	return cam, nil
 }
 
-func createAttachmentTexture(ad *AttachmentDef, width, height uint32) *Texture {
+func createAttachmentTexture(e *Engine, ad *AttachmentDef, width, height uint32) (*Texture, error) {
 	desc := TextureDescriptor{
 		Width:  width,
 		Height: height,
@@ -284,7 +512,7 @@ func createAttachmentTexture(ad *AttachmentDef, width, height uint32) *Texture {
 		desc.WrapMode = TextureWrapModeClampEdge
 	}
 
-	return GetRenderer().NewTexture(desc, nil)
+	return e.renderer.NewTexture(desc, nil)
 }
 
 // resolveTextureRef resolves a "$CameraName.framebuffer.color0" style reference.
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const lampRig = `name: LampRig
+nodes:
+  - name: Lamp
+    pipeline: unlit
+    children:
+      - name: Bulb
+        pipeline: unlit
+        position: [0, 2, 0]
+      - name: Light
+        position: [0, 3, 0]
+        light:
+          color: [1, 1, 0.8]
+`
+
+const rigLibrary = `name: Rigs
+nodes:
+  - name: Rigs
+    children:
+      - name: Post
+        pipeline: unlit
+      - name: Spot
+        cull: alwaysPass
+`
+
+func newIncludeEngine(t *testing.T, scenes map[string]string) *Engine {
+	t.Helper()
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	rs := e.ResourceManager().system.(*memResourceSystem)
+	for name, data := range scenes {
+		rs.scenes[name] = []byte(data)
+	}
+	return e
+}
+
+func TestSceneInclude(t *testing.T) {
+	e := newIncludeEngine(t, map[string]string{
+		"lamp.yaml": lampRig,
+		"rigs.yaml": rigLibrary,
+		"street.yaml": `name: Street
+nodes:
+  - name: Lamp1
+    include: lamp.yaml
+    position: [10, 0, 0]
+  - name: Lamp2
+    include: lamp.yaml
+    position: [20, 0, 0]
+    overrides:
+      Lamp/Bulb:
+        pipeline: glass
+        position: [0, 4, 0]
+        textures:
+          glow: "$Missing.framebuffer.color0"
+  - name: Spot1
+    include: rigs.yaml#Rigs/Spot
+`,
+	})
+
+	scene, err := e.ResourceManager().Scene("street.yaml")
+	if err != nil {
+		t.Fatalf("Scene failed: %v", err)
+	}
+
+	lamps := scene.Root().Children()
+	if len(lamps) != 3 {
+		t.Fatalf("top level nodes = %d, want 3", len(lamps))
+	}
+	if lamps[0].Transform().Col(3) != lamps[1].Transform().Col(3).Sub(mgl64.Vec4{10, 0, 0, 0}) {
+		t.Errorf("instance transforms = %v, %v", lamps[0].Transform(), lamps[1].Transform())
+	}
+
+	for i, want := range []struct {
+		pipeline string
+		y        float64
+	}{{"unlit", 2}, {"glass", 4}} {
+		children := lamps[i].Children()
+		if len(children) != 1 || children[0].Name() != "Lamp" {
+			t.Fatalf("%s children = %d, want the Lamp node", lamps[i].Name(), len(children))
+		}
+		bulb := children[0].Children()[0]
+		if bulb.Pipeline().Name != want.pipeline {
+			t.Errorf("%s bulb pipeline = %q, want %q", lamps[i].Name(), bulb.Pipeline().Name, want.pipeline)
+		}
+		if bulb.Transform().Col(3).Y() != want.y {
+			t.Errorf("%s bulb y = %v, want %v", lamps[i].Name(), bulb.Transform().Col(3).Y(), want.y)
+		}
+		if children[0].Children()[1].Light() == nil {
+			t.Errorf("%s has no light", lamps[i].Name())
+		}
+	}
+
+	spot := lamps[2].Children()
+	if len(spot) != 1 || spot[0].Name() != "Spot" {
+		t.Fatalf("subtree include children = %v, want [Spot]", spot)
+	}
+	if _, ok := spot[0].CullComponent().(*AlwaysPassCuller); !ok {
+		t.Errorf("subtree cull component = %T, want *AlwaysPassCuller", spot[0].CullComponent())
+	}
+}
+
+func TestSceneIncludeCycle(t *testing.T) {
+	e := newIncludeEngine(t, map[string]string{
+		"a.yaml":    "name: A\nnodes:\n  - name: B\n    include: b.yaml\n",
+		"b.yaml":    "name: B\nnodes:\n  - name: Inner\n    children:\n      - name: A\n        include: a.yaml\n",
+		"self.yaml": "name: Self\nnodes:\n  - name: Loop\n    include: self.yaml#Loop\n",
+	})
+
+	for _, name := range []string{"a.yaml", "self.yaml"} {
+		_, err := e.ResourceManager().Scene(name)
+		if !errors.Is(err, errIncludeCycle) {
+			t.Errorf("Scene(%q) error = %v, want include cycle", name, err)
+		}
+	}
+}
+
+func TestSceneIncludeErrors(t *testing.T) {
+	e := newIncludeEngine(t, map[string]string{
+		"missing.yaml": "name: M\nnodes:\n  - name: X\n    include: nowhere.yaml\n",
+		"badpath.yaml": "name: P\nnodes:\n  - name: X\n    include: lamp.yaml#Lamp/Nope\n",
+		"broken.yaml":  "name: B\nnodes:\n  - name: X\n    include: syntax.yaml\n",
+		"syntax.yaml":  "name: S\nnodes:\n  - name: Y\n    model: a: b\n",
+		"lamp.yaml":    lampRig,
+	})
+
+	tests := []struct {
+		scene, include string
+		line           int
+	}{
+		{"missing.yaml", "nowhere.yaml", 0},
+		{"badpath.yaml", "lamp.yaml", 0},
+		{"broken.yaml", "syntax.yaml", 4},
+	}
+	for _, tt := range tests {
+		_, err := e.ResourceManager().Scene(tt.scene)
+		var outer *ResourceError
+		if !errors.As(err, &outer) || outer.Name != tt.scene {
+			t.Fatalf("Scene(%q) error = %v, want error for %q", tt.scene, err, tt.scene)
+		}
+		var inner *ResourceError
+		if !errors.As(outer.Err, &inner) || inner.Name != tt.include || inner.Line != tt.line {
+			t.Errorf("Scene(%q) cause = %v, want %q at line %d", tt.scene, outer.Err, tt.include, tt.line)
+		}
+	}
+}
+
+func TestSceneBuildErrors(t *testing.T) {
+	e := newIncludeEngine(t, map[string]string{
+		"model.yaml":     "name: M\nnodes:\n  - name: Crate\n    model: crate.model\n",
+		"pipeline.yaml":  "name: P\nnodes:\n  - name: Root\n    children:\n      - name: Glass\n        pipeline: nope\n",
+		"component.yaml": "name: C\nnodes:\n  - name: Spinner\n    update: {type: warp, speed: 2}\n",
+		"params.yaml":    "name: U\nnodes:\n  - name: Spinner\n    update: {type: rotator, sped: 2}\n",
+		"camera.yaml":    "name: K\nnodes:\n  - name: Eye\n    camera:\n      name: Main\n      projection: perspective\n      input: nope\n",
+		"outer.yaml":     "name: O\nnodes:\n  - name: Inner\n    include: pipeline.yaml\n",
+	})
+
+	tests := []struct {
+		scene        string
+		line, column int
+		cause        ResourceKind
+	}{
+		{"model.yaml", 4, 12, ResourceModel},
+		{"pipeline.yaml", 6, 19, ResourcePipeline},
+		{"component.yaml", 4, 13, ""},
+		{"params.yaml", 4, 13, ""},
+		{"camera.yaml", 7, 14, ""},
+	}
+	for _, tt := range tests {
+		_, err := e.ResourceManager().Scene(tt.scene)
+		var re *ResourceError
+		if !errors.As(err, &re) {
+			t.Fatalf("Scene(%q) error = %v, want *ResourceError", tt.scene, err)
+		}
+		if re.Kind != ResourceScene || re.Name != tt.scene || re.Line != tt.line || re.Column != tt.column {
+			t.Errorf("Scene(%q) error = %v, want %q at %d:%d", tt.scene, err, tt.scene, tt.line, tt.column)
+		}
+		var cause *ResourceError
+		if tt.cause != "" && (!errors.As(re.Err, &cause) || cause.Kind != tt.cause) {
+			t.Errorf("Scene(%q) cause = %v, want a %s error", tt.scene, re.Err, tt.cause)
+		}
+	}
+
+	// errors in included scenes are positioned in them
+	_, err := e.ResourceManager().Scene("outer.yaml")
+	var outer, inner *ResourceError
+	if !errors.As(err, &outer) || outer.Name != "outer.yaml" || !errors.As(outer.Err, &inner) {
+		t.Fatalf("Scene(outer) error = %v, want the include's error", err)
+	}
+	if inner.Name != "pipeline.yaml" || inner.Line != 6 || inner.Column != 19 {
+		t.Errorf("Scene(outer) cause = %v, want pipeline.yaml at 6:19", inner)
+	}
+}
+
+func TestSaveSceneWritesIncludes(t *testing.T) {
+	e := newIncludeEngine(t, map[string]string{"lamp.yaml": lampRig})
+	src := `name: Street
+nodes:
+  - name: Lamp1
+    include: lamp.yaml
+    position: [10, 0, 0]
+    overrides:
+      Lamp/Bulb:
+        pipeline: glass
+`
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if bytes.Contains(data, []byte("Bulb\n")) || !bytes.Contains(data, []byte("include: lamp.yaml")) ||
+		!bytes.Contains(data, []byte("Lamp/Bulb:")) {
+		t.Errorf("saved scene does not reference the include:\n%s", data)
+	}
+
+	again, err := loadSceneFromYAML(e, "", data)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	compareSceneNodes(t, scene.Root(), again.Root())
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// buildRigidBody creates the rigid body described by a scene node and adds it
+// to the physics world. It is called once the node's meshes and children are
+// in place so shapes can be derived from them.
+func buildRigidBody(ps PhysicsSystem, node *Node, def *RigidBodyDef) (RigidBody, error) {
+	shape, err := buildShape(ps, node, &def.Shape)
+	if err != nil {
+		return nil, err
+	}
+	body := ps.CreateRigidBody(def.Mass, shape)
+	ps.AddRigidBody(body)
+	return body, nil
+}
+
+func buildShape(ps PhysicsSystem, node *Node, def *ShapeDef) (CollisionShape, error) {
+	var bounds *AABB
+	if def.FromMesh {
+		bounds = meshBounds(node)
+		if bounds == nil && def.Type != "triangleMesh" {
+			return nil, fmt.Errorf("%s shape derives from mesh but node has no mesh", def.Type)
+		}
+	}
+
+	var shape CollisionShape
+	switch def.Type {
+	case "plane":
+		if def.Normal == [3]float64{} {
+			return nil, errors.New("plane shape needs a normal")
+		}
+		shape = ps.NewStaticPlaneShape(mgl64.Vec3(def.Normal), def.Constant)
+		bounds = nil
+
+	case "sphere":
+		radius := def.Radius
+		if radius == 0 && bounds != nil {
+			size := bounds.Size()
+			radius = 0.5 * max(size[0], size[1], size[2])
+		}
+		if radius <= 0 {
+			return nil, errors.New("sphere shape needs a radius")
+		}
+		shape = ps.NewSphereShape(radius)
+
+	case "box":
+		halfExtents := mgl64.Vec3(def.HalfExtents)
+		if halfExtents == (mgl64.Vec3{}) && bounds != nil {
+			halfExtents = bounds.Size().Mul(0.5)
+		}
+		if halfExtents == (mgl64.Vec3{}) {
+			return nil, errors.New("box shape needs half extents")
+		}
+		shape = ps.NewBoxShape(halfExtents)
+
+	case "capsule", "cone", "cylinder":
+		radius, height := def.Radius, def.Height
+		if bounds != nil {
+			// shapes are aligned on the y axis
+			size := bounds.Size()
+			if radius == 0 {
+				radius = 0.5 * max(size[0], size[2])
+			}
+			if height == 0 {
+				height = size[1]
+				switch def.Type {
+				case "capsule":
+					height = max(height-2*radius, 0)
+				case "cylinder":
+					height *= 0.5
+				}
+			}
+		}
+		if radius <= 0 {
+			return nil, fmt.Errorf("%s shape needs a radius", def.Type)
+		}
+		switch def.Type {
+		case "capsule":
+			shape = ps.NewCapsuleShape(radius, height)
+		case "cone":
+			shape = ps.NewConeShape(radius, height)
+		case "cylinder":
+			shape = ps.NewCylinderShape(radius, height)
+		}
+
+	case "compound":
+		if len(def.Children) == 0 {
+			return nil, errors.New("compound shape has no children")
+		}
+		shape = ps.NewCompoundShape()
+		if shape == nil {
+			return nil, unsupportedShape(def)
+		}
+		for i := range def.Children {
+			cd := &def.Children[i]
+			child, err := buildShape(ps, node, &cd.ShapeDef)
+			if err != nil {
+				return nil, fmt.Errorf("compound child %d: %w", i, err)
+			}
+			orientation := mgl64.QuatIdent()
+			if cd.Rotation != [4]float64{} {
+				orientation = mgl64.QuatRotate(mgl64.DegToRad(cd.Rotation[0]), mgl64.Vec3{cd.Rotation[1], cd.Rotation[2], cd.Rotation[3]}).Normalize()
+			}
+			shape.AddChildShape(child, mgl64.Vec3(cd.Position), orientation)
+		}
+		return shape, nil
+
+	case "convexHull":
+		points := def.Points
+		if len(points) == 0 && bounds != nil {
+			// the hull of the node's mesh vertices if it keeps them, and of its
+			// mesh bounds otherwise
+			if node.mesh != nil && len(node.mesh.positions) > 0 && len(node.children) == 0 {
+				ps := node.mesh.positions
+				for i := 0; i+2 < len(ps); i += 3 {
+					points = append(points, [3]float64{float64(ps[i]), float64(ps[i+1]), float64(ps[i+2])})
+				}
+			} else {
+				for _, p := range boxCorners(bounds) {
+					points = append(points, p)
+				}
+			}
+			bounds = nil
+		}
+		if len(points) == 0 {
+			return nil, errors.New("convexHull shape needs points")
+		}
+		shape = ps.NewConvexHullShape()
+		if shape == nil {
+			return nil, unsupportedShape(def)
+		}
+		for _, p := range points {
+			shape.AddVertex(mgl64.Vec3(p))
+		}
+		return shape, nil
+
+	case "triangleMesh":
+		mesh := triangleMeshSource(node)
+		if mesh == nil {
+			return nil, errors.New("triangleMesh shape needs a mesh on the node or a model with a single untransformed mesh")
+		}
+		// the triangles come from the mesh's cpu copy of its geometry
+		if len(mesh.positions) == 0 || len(mesh.indices) == 0 {
+			return nil, errors.New("triangleMesh shape needs a mesh which keeps its positions and indices")
+		}
+		shape = ps.NewStaticTriangleMeshShape(*mesh)
+		bounds = nil
+
+	default:
+		return nil, fmt.Errorf("unknown shape type %q", def.Type)
+	}
+	if shape == nil {
+		return nil, unsupportedShape(def)
+	}
+
+	// shapes derived from a mesh are centred on its bounds
+	if bounds != nil && bounds.Center().Len() > 1e-9 {
+		compound := ps.NewCompoundShape()
+		compound.AddChildShape(shape, bounds.Center(), mgl64.QuatIdent())
+		return compound, nil
+	}
+	return shape, nil
+}
+
+// triangleMeshSource returns the node's mesh, or the mesh of its only child
+// if that has no transform of its own as is the case for most models.
+func triangleMeshSource(n *Node) *Mesh {
+	if n.mesh != nil {
+		return n.mesh
+	}
+	if len(n.children) != 1 {
+		return nil
+	}
+	c := n.children[0]
+	if c.mesh == nil || len(c.children) != 0 || c.transform != mgl64.Ident4() {
+		return nil
+	}
+	return c.mesh
+}
+
+// unsupportedShape is the error for shapes the physics system can't create.
+func unsupportedShape(def *ShapeDef) error {
+	return fmt.Errorf("physics system does not support %s shapes", def.Type)
+}
+
+// meshBounds returns the bounds of a node's mesh and its children's meshes in
+// the node's space, or nil if there are none.
+func meshBounds(n *Node) *AABB {
+	var bounds *AABB
+	if n.mesh != nil && n.mesh.bounds.min[0] <= n.mesh.bounds.max[0] {
+		bounds = NewAABB()
+		bounds.ExtendWithBox(n.mesh.bounds)
+	}
+	for _, c := range n.children {
+		cb := meshBounds(c)
+		if cb == nil {
+			continue
+		}
+		if bounds == nil {
+			bounds = NewAABB()
+		}
+		bounds.ExtendWithBox(cb.Transformed(c.transform))
+	}
+	return bounds
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// recordingPhysicsSystem records the shapes and bodies created through it.
+type recordingPhysicsSystem struct {
+	shapes []*recordingShape
+	world  []RigidBody
+}
+
+type recordingShape struct {
+	desc     string
+	children []string
+	vertices int
+}
+
+func (s *recordingShape) AddChildShape(child CollisionShape, position mgl64.Vec3, orientation mgl64.Quat) {
+	s.children = append(s.children, fmt.Sprintf("%s@%v", child.(*recordingShape).desc, position))
+}
+
+func (s *recordingShape) AddVertex(mgl64.Vec3) { s.vertices++ }
+
+type recordingBody struct {
+	mass  float32
+	shape *recordingShape
+}
+
+func (b *recordingBody) GetTransform() mgl64.Mat4                  { return mgl64.Ident4() }
+func (b *recordingBody) SetTransform(mgl64.Mat4)                   {}
+func (b *recordingBody) ApplyImpulse(impulse, position mgl64.Vec3) {}
+
+func (p *recordingPhysicsSystem) shape(format string, args ...interface{}) CollisionShape {
+	s := &recordingShape{desc: fmt.Sprintf(format, args...)}
+	p.shapes = append(p.shapes, s)
+	return s
+}
+
+func (p *recordingPhysicsSystem) Start()                     {}
+func (p *recordingPhysicsSystem) Stop()                      {}
+func (p *recordingPhysicsSystem) Update(float64, []*Node)    {}
+func (p *recordingPhysicsSystem) SetGravity(mgl64.Vec3)      {}
+func (p *recordingPhysicsSystem) AddRigidBody(b RigidBody)   { p.world = append(p.world, b) }
+func (p *recordingPhysicsSystem) RemoveRigidBody(RigidBody)  {}
+func (p *recordingPhysicsSystem) DeleteRigidBody(RigidBody)  {}
+func (p *recordingPhysicsSystem) DeleteShape(CollisionShape) {}
+
+func (p *recordingPhysicsSystem) CreateRigidBody(mass float32, shape CollisionShape) RigidBody {
+	return &recordingBody{mass: mass, shape: shape.(*recordingShape)}
+}
+
+func (p *recordingPhysicsSystem) NewStaticPlaneShape(normal mgl64.Vec3, constant float64) CollisionShape {
+	return p.shape("plane%v/%v", normal, constant)
+}
+
+func (p *recordingPhysicsSystem) NewSphereShape(radius float64) CollisionShape {
+	return p.shape("sphere%v", radius)
+}
+
+func (p *recordingPhysicsSystem) NewBoxShape(halfExtents mgl64.Vec3) CollisionShape {
+	return p.shape("box%v", halfExtents)
+}
+
+func (p *recordingPhysicsSystem) NewCapsuleShape(radius, height float64) CollisionShape {
+	return p.shape("capsule%v/%v", radius, height)
+}
+
+func (p *recordingPhysicsSystem) NewConeShape(radius, height float64) CollisionShape {
+	return p.shape("cone%v/%v", radius, height)
+}
+
+func (p *recordingPhysicsSystem) NewCylinderShape(radius, height float64) CollisionShape {
+	return p.shape("cylinder%v/%v", radius, height)
+}
+
+func (p *recordingPhysicsSystem) NewCompoundShape() CollisionShape {
+	return p.shape("compound")
+}
+
+func (p *recordingPhysicsSystem) NewConvexHullShape() CollisionShape {
+	return p.shape("hull")
+}
+
+func (p *recordingPhysicsSystem) NewStaticTriangleMeshShape(Mesh) CollisionShape {
+	return p.shape("trimesh")
+}
+
+const physicsScene = `name: Physics
+nodes:
+  - name: Ground
+    rigidBody:
+      shape: {type: plane, normal: [0, 1, 0]}
+  - name: Ball
+    rigidBody:
+      mass: 2
+      shape: {type: sphere, radius: 0.5}
+  - name: Crate
+    model: crate.model
+    rigidBody:
+      mass: 5
+      shape: {type: box, fromMesh: true}
+  - name: Pill
+    model: crate.model
+    rigidBody:
+      mass: 1
+      shape: {type: capsule, fromMesh: true}
+  - name: Table
+    rigidBody:
+      mass: 10
+      shape:
+        type: compound
+        children:
+          - {type: box, halfExtents: [1, 0.1, 1], position: [0, 1, 0]}
+          - {type: cylinder, radius: 0.1, height: 0.5, position: [0.9, 0.5, 0.9], rotation: [90, 0, 1, 0]}
+  - name: Rock
+    model: crate.model
+    rigidBody:
+      shape: {type: convexHull, fromMesh: true}
+`
+
+func newPhysicsTestEngine(t *testing.T) (*Engine, *recordingPhysicsSystem) {
+	t.Helper()
+	e := newCrateEngine(t)
+	ps := &recordingPhysicsSystem{}
+	if err := e.SetPhysicsSystem(ps); err != nil {
+		t.Fatalf("SetPhysicsSystem failed: %v", err)
+	}
+	return e, ps
+}
+
+// newCrateEngine returns an engine with the crate model used by physicsScene.
+func newCrateEngine(t *testing.T) *Engine {
+	t.Helper()
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+
+	// a 2x4x2 crate offset one unit up
+	model := NewNode("crate.model")
+	mesh := NewNode("CrateMesh")
+	m := e.Renderer().NewMesh()
+	m.SetPositions([]float32{-1, 0, -1, 1, 4, 1})
+	mesh.SetMesh(m)
+	mesh.Translate(mgl64.Vec3{0, -1, 0})
+	model.AddChild(mesh)
+	e.ResourceManager().models["crate.model"] = model
+	return e
+}
+
+func TestSceneRigidBodies(t *testing.T) {
+	e, ps := newPhysicsTestEngine(t)
+	scene, err := loadSceneFromYAML(e, "", []byte(physicsScene))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	tests := []struct {
+		node     string
+		mass     float32
+		shape    string
+		children []string
+	}{
+		{"Ground", 0, "plane[0 1 0]/0", nil},
+		{"Ball", 2, "sphere0.5", nil},
+		{"Crate", 5, "compound", []string{"box[1 2 1]@[0 1 0]"}},
+		{"Pill", 1, "compound", []string{"capsule1/2@[0 1 0]"}},
+		{"Table", 10, "compound", []string{"box[1 0.1 1]@[0 1 0]", "cylinder0.1/0.5@[0.9 0.5 0.9]"}},
+		{"Rock", 0, "hull", nil},
+	}
+
+	nodes := scene.Root().Children()
+	for i, tt := range tests {
+		n := nodes[i]
+		body, ok := n.RigidBody().(*recordingBody)
+		if !ok {
+			t.Errorf("%s has no rigid body", tt.node)
+			continue
+		}
+		if body.mass != tt.mass || body.shape.desc != tt.shape || strings.Join(body.shape.children, ",") != strings.Join(tt.children, ",") {
+			t.Errorf("%s body = %v %s %v, want %v %s %v", tt.node, body.mass, body.shape.desc, body.shape.children, tt.mass, tt.shape, tt.children)
+		}
+	}
+	if hull := nodes[5].RigidBody().(*recordingBody).shape; hull.vertices != 8 {
+		t.Errorf("hull vertices = %d, want the 8 bounds corners", hull.vertices)
+	}
+	if len(ps.world) != len(tests) {
+		t.Errorf("bodies in world = %d, want %d", len(ps.world), len(tests))
+	}
+
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("rigidBody:\n      mass: 5\n      shape:\n        type: box\n        fromMesh: true\n")) {
+		t.Errorf("saved scene lost the rigid bodies:\n%s", data)
+	}
+}
+
+// unsupportedTriangleMeshes is a physics system without triangle meshes, like
+// the bullet one.
+type unsupportedTriangleMeshes struct {
+	recordingPhysicsSystem
+}
+
+func (p *unsupportedTriangleMeshes) NewStaticTriangleMeshShape(Mesh) CollisionShape {
+	return nil
+}
+
+// addSlabModel registers a model with a single untransformed triangle.
+func addSlabModel(e *Engine) {
+	model := NewNode("slab.model")
+	mesh := NewNode("SlabMesh")
+	mesh.SetMesh(newTriangleMesh(e))
+	model.AddChild(mesh)
+	e.ResourceManager().models["slab.model"] = model
+}
+
+func TestSceneRigidBodyTriangleMesh(t *testing.T) {
+	e, ps := newPhysicsTestEngine(t)
+	e.SetKeepMeshData(true)
+	addSlabModel(e)
+
+	src := "name: T\nnodes:\n  - name: Slab\n    model: slab.model\n    rigidBody:\n      shape: {type: triangleMesh}\n"
+	scene, err := loadSceneFromYAML(e, "", []byte(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	body, ok := scene.Root().Children()[0].RigidBody().(*recordingBody)
+	if !ok || body.shape.desc != "trimesh" || len(ps.world) != 1 {
+		t.Errorf("Slab body = %v, want a triangle mesh in the world", body)
+	}
+
+	e = newCrateEngine(t)
+	e.SetKeepMeshData(true)
+	addSlabModel(e)
+	if err := e.SetPhysicsSystem(&unsupportedTriangleMeshes{}); err != nil {
+		t.Fatalf("SetPhysicsSystem failed: %v", err)
+	}
+	_, err = loadSceneFromYAML(e, "", []byte(src))
+	var re *ResourceError
+	if !errors.As(err, &re) || re.Line != 6 || re.Column != 7 || !strings.Contains(err.Error(), "does not support triangleMesh") {
+		t.Errorf("load error = %v, want unsupported triangleMesh at 6:7", err)
+	}
+}
+
+func TestSceneRigidBodyErrors(t *testing.T) {
+	e, _ := newPhysicsTestEngine(t)
+	addSlabModel(e)
+	tests := []struct {
+		model, shape string
+		want         string
+	}{
+		{"crate.model", "{type: teapot}", "unknown shape type"},
+		{"slab.model", "{type: sphere}", "needs a radius"},
+		{"crate.model", "{type: triangleMesh}", "single untransformed mesh"},
+		{"slab.model", "{type: triangleMesh}", "keeps its positions and indices"},
+	}
+	for _, tt := range tests {
+		src := "name: E\nnodes:\n  - name: Ok\n  - name: Bad\n    model: " + tt.model + "\n    rigidBody:\n      shape: " + tt.shape + "\n"
+		_, err := loadSceneFromYAML(e, "", []byte(src))
+		var re *ResourceError
+		if !errors.As(err, &re) || re.Line != 7 || re.Column != 7 || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("shape %s: load error = %v, want %q at 7:7", tt.shape, err, tt.want)
+		}
+	}
+}
+
+func TestSceneRigidBodyWithoutPhysics(t *testing.T) {
+	e := newCrateEngine(t)
+	_, err := loadSceneFromYAML(e, "", []byte(physicsScene))
+	var re *ResourceError
+	if !errors.As(err, &re) || re.Line != 5 || re.Column != 7 || !strings.Contains(err.Error(), `node "Ground"`) {
+		t.Errorf("load error = %v, want Ground's rigid body at 5:7", err)
+	}
+}
@@ -0,0 +1,352 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/golang/glog"
+	"gopkg.in/yaml.v3"
+)
+
+// SaveSceneToYAML serializes a scene into the YAML format read by
+// LoadSceneFromYAML. Nodes loaded from a model or include are written as the
+// reference along with the include's overrides, and textures are only written when they are a camera framebuffer
+// attachment. Components which aren't registered by name are skipped.
+func SaveSceneToYAML(s *Scene) ([]byte, error) {
+	if s.root == nil {
+		return nil, errors.New("scene has no root node")
+	}
+
+	w := sceneWriter{
+		cameras:     make(map[*Node]*Camera),
+		attachments: make(map[*Texture]string),
+	}
+	for _, c := range s.cameraList {
+		w.cameras[c.node] = c
+		if c.framebuffer == nil {
+			continue
+		}
+		if t := c.framebuffer.ColorAttachment(0); t != nil {
+			w.attachments[t] = "$" + c.name + ".framebuffer.color0"
+		}
+		if t := c.framebuffer.DepthAttachment(); t != nil {
+			w.attachments[t] = "$" + c.name + ".framebuffer.depth"
+		}
+	}
+
+	sf := SceneFile{Name: s.name}
+	for _, c := range s.root.children {
+		if cam, ok := w.cameras[c]; ok {
+			glog.Warningf("Scene: camera %q is attached to the root node and cannot be saved", cam.name)
+		}
+		if w.skip(c) {
+			continue
+		}
+		sf.Nodes = append(sf.Nodes, w.node(c))
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(&sf); err != nil {
+		return nil, fmt.Errorf("cannot marshal scene %q: %w", s.name, err)
+	}
+	compactYAML(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("cannot marshal scene %q: %w", s.name, err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("cannot marshal scene %q: %w", s.name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// compactYAML drops all-zero vectors, which yaml.v3 doesn't treat as empty and
+// which decode to the same value as a missing key, and writes vectors inline.
+func compactYAML(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if isZeroVector(n.Content[i+1]) {
+				continue
+			}
+			content = append(content, n.Content[i], n.Content[i+1])
+		}
+		n.Content = content
+	}
+
+	if n.Kind == yaml.SequenceNode && isScalarSequence(n) {
+		n.Style = yaml.FlowStyle
+	}
+
+	for _, c := range n.Content {
+		compactYAML(c)
+	}
+}
+
+func isScalarSequence(n *yaml.Node) bool {
+	for _, c := range n.Content {
+		if c.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeroVector(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		return false
+	}
+	for _, c := range n.Content {
+		if c.Kind != yaml.ScalarNode || (c.Tag != "!!int" && c.Tag != "!!float") {
+			return false
+		}
+		if v, err := strconv.ParseFloat(c.Value, 64); err != nil || v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type sceneWriter struct {
+	cameras     map[*Node]*Camera
+	attachments map[*Texture]string
+}
+
+// skip reports whether a child node is written as part of its parent.
+func (w *sceneWriter) skip(n *Node) bool {
+	if n.instanced {
+		return true
+	}
+	_, ok := w.cameras[n]
+	return ok
+}
+
+func (w *sceneWriter) node(n *Node) SceneNode {
+	sn := SceneNode{Name: n.name, Tags: n.tags, Model: n.model, Include: n.include, Overrides: n.overrides}
+	if n.layers != LayerDefault {
+		sn.Layers = n.layers
+	}
+	sn.Occluder = n.occluder
+	sn.Position, sn.Rotation, sn.Scale = decomposeTransform(n.transform)
+
+	if n.pipeline != nil {
+		sn.Pipeline = n.pipeline.Name
+	}
+
+	sn.Cull = componentDef(n.name, cullComponents, n.cullComponent)
+	sn.Input = componentDef(n.name, inputComponents, n.inputComponent)
+	sn.Update = componentDef(n.name, updateComponents, n.updateComponent)
+	sn.Physics = componentDef(n.name, physicsComponents, n.physicsComponent)
+	sn.LightExtractor = componentDef(n.name, lightExtractors, n.lightExtractor)
+
+	if n.mesh != nil && n.mesh.name == "ScreenQuadMesh" {
+		sn.ScreenQuad = true
+	}
+
+	if n.light != nil {
+		sn.Light = lightDef(n.light)
+	}
+
+	if n.lodGroup != nil {
+		sn.LOD = lodDef(n.lodGroup)
+	}
+
+	if n.rigidBody != nil {
+		sn.RigidBody = n.rigidBodyDef
+		if sn.RigidBody == nil {
+			glog.Warningf("Scene: node %q has a rigid body which wasn't loaded from a scene and cannot be saved", n.name)
+		}
+	}
+
+	for name, t := range n.material.textures {
+		if ref, ok := w.attachments[t]; ok {
+			if sn.Textures == nil {
+				sn.Textures = make(map[string]string)
+			}
+			sn.Textures[name] = ref
+		}
+	}
+
+	for _, c := range n.children {
+		if cam, ok := w.cameras[c]; ok {
+			if sn.Camera != nil {
+				glog.Warningf("Scene: node %q has more than one camera, skipping %q", n.name, cam.name)
+				continue
+			}
+			sn.Camera = cameraDef(cam)
+		}
+		if w.skip(c) {
+			continue
+		}
+		sn.Children = append(sn.Children, w.node(c))
+	}
+
+	return sn
+}
+
+// componentDef returns the scene definition of a node's component. Components
+// of the type registered as "default" are what NewNode sets and are omitted.
+func componentDef[T any](node string, r *componentRegistry[T], c T) ComponentDef {
+	if reflect.TypeOf(c) == nil {
+		return ComponentDef{}
+	}
+	def := r.def(c)
+	switch {
+	case def.Type == "":
+		glog.Warningf("Scene: node %q has unregistered %s %T", node, r.kind, c)
+	case def.Type == "default" && len(def.Params) == 0:
+		return ComponentDef{}
+	}
+	return def
+}
+
+func lodDef(g *LODGroup) *LODDef {
+	ld := &LODDef{Hysteresis: g.Hysteresis, FadeDuration: g.FadeDuration}
+	for _, l := range g.Levels {
+		ld.Levels = append(ld.Levels, LODLevelDef{Node: l.Node.name, ScreenSize: l.ScreenSize})
+	}
+	return ld
+}
+
+func lightDef(l *Light) *LightDef {
+	ld := &LightDef{
+		Color:      [3]float32{l.Block.Color[0], l.Block.Color[1], l.Block.Color[2]},
+		ShadowBias: l.ShadowBias,
+	}
+	if sm, ok := l.Shadower.(*ShadowMap); ok {
+		ld.Shadow = &ShadowDef{Size: sm.size, Cascades: sm.numCascades}
+	}
+	return ld
+}
+
+func cameraDef(c *Camera) *CameraDef {
+	cd := &CameraDef{
+		Name:         c.name,
+		Projection:   "perspective",
+		FOV:          c.vertFOV,
+		ClearColor:   [4]float32(c.clearColor),
+		ClipDistance: [2]float64(c.clipDistance),
+		RenderOrder:  c.renderOrder,
+	}
+	if c.projectionType == OrthographicProjection {
+		cd.Projection = "orthographic"
+	}
+	if c.cullingMask != LayerAll {
+		cd.CullingMask = c.cullingMask
+	}
+	cd.OcclusionCulling = c.OcclusionCulling()
+	if c.autoReshape {
+		autoReshape := true
+		cd.AutoReshape = &autoReshape
+	}
+	if c.autoFrustum {
+		autoFrustum := true
+		cd.AutoFrustum = &autoFrustum
+	}
+	if c.clearMode != ClearColor|ClearDepth {
+		if c.clearMode&ClearColor != 0 {
+			cd.ClearMode = append(cd.ClearMode, "color")
+		}
+		if c.clearMode&ClearDepth != 0 {
+			cd.ClearMode = append(cd.ClearMode, "depth")
+		}
+	}
+
+	var scale [3]float64
+	cd.Position, cd.Rotation, scale = decomposeTransform(c.node.transform)
+	if scale != [3]float64{} {
+		glog.Warningf("Scene: camera %q is scaled, its scale will not be saved", c.name)
+	}
+
+	cd.Input = componentDef(c.name, inputComponents, c.node.inputComponent)
+
+	if tech := renderTechniqueName(c.renderTechnique); tech != "default" {
+		cd.Technique = tech
+	}
+
+	if c.framebuffer != nil {
+		cd.Framebuffer = &FramebufferDef{}
+		if t := c.framebuffer.ColorAttachment(0); t != nil {
+			cd.Framebuffer.Color0 = attachmentDef(t.descriptor)
+		}
+		if t := c.framebuffer.DepthAttachment(); t != nil {
+			cd.Framebuffer.Depth = attachmentDef(t.descriptor)
+		}
+	}
+
+	return cd
+}
+
+func attachmentDef(desc TextureDescriptor) *AttachmentDef {
+	ad := &AttachmentDef{Format: "rgba8", Filter: "nearest", Wrap: "clampEdge"}
+
+	switch desc.SizedFormat {
+	case TextureSizedFormatRGBA16F:
+		ad.Format = "rgba16f"
+	case TextureSizedFormatDEPTH32F:
+		ad.Format = "depth32f"
+	}
+
+	switch desc.Filter {
+	case TextureFilterLinear:
+		ad.Filter = "linear"
+	case TextureFilterMipmapLinear:
+		ad.Filter = "mipmapLinear"
+	}
+
+	if desc.WrapMode == TextureWrapModeRepeat {
+		ad.Wrap = "repeat"
+	}
+
+	return ad
+}
+
+// decomposeTransform splits a transform into the translation, rotation
+// ([angle, axisX, axisY, axisZ] in degrees) and scale applied by the scene
+// loader. Identity components are returned as zero values so they are omitted.
+func decomposeTransform(m mgl64.Mat4) (position [3]float64, rotation [4]float64, scale [3]float64) {
+	position = [3]float64{roundTransform(m[12]), roundTransform(m[13]), roundTransform(m[14])}
+
+	s := mgl64.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+	for i := range s {
+		if s[i] == 0 {
+			return position, rotation, [3]float64{}
+		}
+	}
+	if roundTransform(s[0]) != 1 || roundTransform(s[1]) != 1 || roundTransform(s[2]) != 1 {
+		scale = [3]float64{roundTransform(s[0]), roundTransform(s[1]), roundTransform(s[2])}
+	}
+
+	r := mgl64.Mat4FromCols(m.Col(0).Mul(1/s[0]), m.Col(1).Mul(1/s[1]), m.Col(2).Mul(1/s[2]), mgl64.Vec4{0, 0, 0, 1})
+	q := mgl64.Mat4ToQuat(r).Normalize()
+	if q.W < 0 {
+		q = q.Scale(-1)
+	}
+	angle := 2 * math.Acos(math.Min(q.W, 1))
+	if roundTransform(mgl64.RadToDeg(angle)) != 0 {
+		axis := q.V.Normalize()
+		rotation = [4]float64{
+			roundTransform(mgl64.RadToDeg(angle)),
+			roundTransform(axis[0]), roundTransform(axis[1]), roundTransform(axis[2]),
+		}
+	}
+
+	return position, rotation, scale
+}
+
+// roundTransform drops the noise left by decomposing a transform.
+func roundTransform(v float64) float64 {
+	r := math.Round(v*1e9) / 1e9
+	if r == 0 {
+		return 0 // no negative zeros
+	}
+	return r
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const roundTripScene = `name: RoundTrip
+nodes:
+  - name: GeometryRoot
+    children:
+      - name: Cube
+        model: cube.model
+        pipeline: unlit
+        position: [1, 2, 3]
+        rotation: [30, 0, 1, 0]
+        scale: [2, 2, 2]
+        children:
+          - name: Glass
+            pipeline: glass
+            cull: alwaysPass
+      - name: Light1
+        position: [100, 0, 100]
+        light:
+          color: [1.0, 0.5, 0.25]
+          shadowBias: 0.001
+          shadow:
+            size: 256
+            cascades: 2
+    camera:
+      name: GeometryPassCamera
+      projection: perspective
+      fov: 60
+      autoReshape: true
+      autoFrustum: true
+      clearColor: [0.4, 0.6, 0.9, 1.0]
+      clipDistance: [1.0, 2.0]
+      position: [0, 0, 50]
+      rotation: [-45, 1, 0, 0]
+      input: mouseCameraInput
+      framebuffer:
+        color0:
+          format: rgba16f
+          filter: linear
+          wrap: clampEdge
+        depth:
+          format: depth32f
+          filter: nearest
+          wrap: clampEdge
+  - name: ScreenQuad
+    cull: alwaysPass
+    pipeline: unlit
+    screenQuad: true
+    textures:
+      colorTexture: "$GeometryPassCamera.framebuffer.color0"
+      depthTexture: "$GeometryPassCamera.framebuffer.depth"
+    camera:
+      name: PostCamera
+      projection: orthographic
+      clipDistance: [0, 1]
+      clearMode: [color]
+      renderOrder: 1
+      technique: postprocess
+`
+
+func newRoundTripEngine(t *testing.T) *Engine {
+	t.Helper()
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+
+	model := NewNode("cube.model")
+	for _, name := range []string{"CubeMesh0", "CubeMesh1"} {
+		n := NewNode(name)
+		n.SetMesh(newTriangleMesh(e))
+		model.AddChild(n)
+	}
+	e.ResourceManager().models["cube.model"] = model
+	return e
+}
+
+func TestSaveSceneRoundTrip(t *testing.T) {
+	e := newRoundTripEngine(t)
+
+	first, err := loadSceneFromYAML(e, "", []byte(roundTripScene))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	data, err := SaveSceneToYAML(first)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	second, err := loadSceneFromYAML(e, "", data)
+	if err != nil {
+		t.Fatalf("reload failed: %v\n%s", err, data)
+	}
+	again, err := SaveSceneToYAML(second)
+	if err != nil {
+		t.Fatalf("second save failed: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("saves differ:\n%s\n---\n%s", data, again)
+	}
+
+	if first.Name() != second.Name() {
+		t.Errorf("name = %q, want %q", second.Name(), first.Name())
+	}
+	compareSceneNodes(t, first.Root(), second.Root())
+
+	if len(first.Cameras()) != len(second.Cameras()) {
+		t.Fatalf("cameras = %d, want %d", len(second.Cameras()), len(first.Cameras()))
+	}
+	for i, a := range first.Cameras() {
+		compareCameras(t, a, second.Cameras()[i])
+	}
+
+	quad := second.Root().Children()[1]
+	camera := second.Camera("GeometryPassCamera")
+	if quad.Material().Texture("colorTexture") != camera.Framebuffer().ColorAttachment(0) {
+		t.Error("color texture reference was not restored")
+	}
+	if quad.Material().Texture("depthTexture") != camera.Framebuffer().DepthAttachment() {
+		t.Error("depth texture reference was not restored")
+	}
+}
+
+func TestSaveSceneSkipsModelChildren(t *testing.T) {
+	e := newRoundTripEngine(t)
+	scene, err := loadSceneFromYAML(e, "", []byte(roundTripScene))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	data, err := SaveSceneToYAML(scene)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	for _, name := range []string{"CubeMesh0", "GeometryPassCamera\n    children", "technique: default"} {
+		if bytes.Contains(data, []byte(name)) {
+			t.Errorf("saved scene contains %q:\n%s", name, data)
+		}
+	}
+}
+
+func compareSceneNodes(t *testing.T, a, b *Node) {
+	t.Helper()
+	if a.Name() != b.Name() {
+		t.Errorf("node name = %q, want %q", b.Name(), a.Name())
+		return
+	}
+	if !a.Transform().ApproxEqualThreshold(b.Transform(), 1e-6) {
+		t.Errorf("node %q transform = %v, want %v", a.Name(), b.Transform(), a.Transform())
+	}
+	if (a.Pipeline() == nil) != (b.Pipeline() == nil) || a.Pipeline() != nil && a.Pipeline().Name != b.Pipeline().Name {
+		t.Errorf("node %q pipeline differs", a.Name())
+	}
+	if !reflect.DeepEqual(cullComponents.def(a.CullComponent()), cullComponents.def(b.CullComponent())) {
+		t.Errorf("node %q cull component = %T, want %T", a.Name(), b.CullComponent(), a.CullComponent())
+	}
+	if !reflect.DeepEqual(inputComponents.def(a.InputComponent()), inputComponents.def(b.InputComponent())) {
+		t.Errorf("node %q input component = %T, want %T", a.Name(), b.InputComponent(), a.InputComponent())
+	}
+	if !reflect.DeepEqual(updateComponents.def(a.UpdateComponent()), updateComponents.def(b.UpdateComponent())) {
+		t.Errorf("node %q update component = %+v, want %+v", a.Name(), b.UpdateComponent(), a.UpdateComponent())
+	}
+	if a.Layers() != b.Layers() {
+		t.Errorf("node %q layers = %#x, want %#x", a.Name(), b.Layers(), a.Layers())
+	}
+	if (a.Mesh() == nil) != (b.Mesh() == nil) {
+		t.Errorf("node %q mesh differs", a.Name())
+	}
+	if (a.Light() == nil) != (b.Light() == nil) {
+		t.Errorf("node %q light differs", a.Name())
+	} else if a.Light() != nil && !reflect.DeepEqual(lightDef(a.Light()), lightDef(b.Light())) {
+		t.Errorf("node %q light = %+v, want %+v", a.Name(), lightDef(b.Light()), lightDef(a.Light()))
+	}
+	if len(a.Material().Textures()) != len(b.Material().Textures()) {
+		t.Errorf("node %q textures = %d, want %d", a.Name(), len(b.Material().Textures()), len(a.Material().Textures()))
+	}
+	if len(a.Children()) != len(b.Children()) {
+		t.Errorf("node %q children = %d, want %d", a.Name(), len(b.Children()), len(a.Children()))
+		return
+	}
+	for i := range a.Children() {
+		compareSceneNodes(t, a.Children()[i], b.Children()[i])
+	}
+}
+
+func compareCameras(t *testing.T, a, b *Camera) {
+	t.Helper()
+	if a.name != b.name || a.projectionType != b.projectionType || a.vertFOV != b.vertFOV ||
+		a.autoReshape != b.autoReshape || a.autoFrustum != b.autoFrustum ||
+		a.clearColor != b.clearColor || a.clearMode != b.clearMode ||
+		a.clipDistance != b.clipDistance || a.renderOrder != b.renderOrder || a.cullingMask != b.cullingMask {
+		t.Errorf("camera %q differs after round trip", a.name)
+	}
+	if renderTechniqueName(a.renderTechnique) != renderTechniqueName(b.renderTechnique) {
+		t.Errorf("camera %q technique = %q, want %q", a.name, renderTechniqueName(b.renderTechnique), renderTechniqueName(a.renderTechnique))
+	}
+	if a.scene.Name() != b.scene.Name() {
+		t.Errorf("camera %q scene = %q, want %q", a.name, b.scene.Name(), a.scene.Name())
+	}
+	if (a.framebuffer == nil) != (b.framebuffer == nil) {
+		t.Errorf("camera %q framebuffer differs", a.name)
+	} else if a.framebuffer != nil {
+		if a.framebuffer.ColorAttachment(0).Descriptor() != b.framebuffer.ColorAttachment(0).Descriptor() ||
+			a.framebuffer.DepthAttachment().Descriptor() != b.framebuffer.DepthAttachment().Descriptor() {
+			t.Errorf("camera %q framebuffer attachments differ", a.name)
+		}
+	}
+}
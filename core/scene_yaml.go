@@ -1,5 +1,12 @@
 package core
 
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
 // SceneFile is the top-level YAML structure for a scene file.
 type SceneFile struct {
 	Name  string      `yaml:"name"`
@@ -8,35 +15,161 @@ type SceneFile struct {
 
 // SceneNode describes a node in the scene YAML.
 type SceneNode struct {
-	Name       string      `yaml:"name"`
-	Model      string      `yaml:"model,omitempty"`
-	Position   [3]float64  `yaml:"position,omitempty"`
-	Rotation   [4]float64  `yaml:"rotation,omitempty"`   // [angle, axisX, axisY, axisZ]
-	Scale      [3]float64  `yaml:"scale,omitempty"`
-	Pipeline   string      `yaml:"pipeline,omitempty"`
-	Cull       string      `yaml:"cull,omitempty"`
-	ScreenQuad bool        `yaml:"screenQuad,omitempty"`
-	Light      *LightDef   `yaml:"light,omitempty"`
-	Camera     *CameraDef  `yaml:"camera,omitempty"`
-	Textures   map[string]string `yaml:"textures,omitempty"`
-	Children   []SceneNode `yaml:"children,omitempty"`
+	Name           string                  `yaml:"name"`
+	Tags           []string                `yaml:"tags,omitempty"`
+	Layers         LayerMask               `yaml:"layers,omitempty"` // bitmask, eg: 0x5 for layers 0 and 2. Defaults to layer 0
+	Model          string                  `yaml:"model,omitempty"`
+	Include        string                  `yaml:"include,omitempty"`   // scene name, or name#path/to/node for a subtree
+	Overrides      map[string]NodeOverride `yaml:"overrides,omitempty"` // keyed by node path within the include
+	Position       [3]float64              `yaml:"position,omitempty"`
+	Rotation       [4]float64              `yaml:"rotation,omitempty"` // [angle, axisX, axisY, axisZ]
+	Scale          [3]float64              `yaml:"scale,omitempty"`
+	Pipeline       string                  `yaml:"pipeline,omitempty"`
+	Cull           ComponentDef            `yaml:"cull,omitempty"`
+	Input          ComponentDef            `yaml:"input,omitempty"`
+	Update         ComponentDef            `yaml:"update,omitempty"`
+	Physics        ComponentDef            `yaml:"physics,omitempty"`
+	LightExtractor ComponentDef            `yaml:"lightExtractor,omitempty"`
+	ScreenQuad     bool                    `yaml:"screenQuad,omitempty"`
+	Occluder       bool                    `yaml:"occluder,omitempty"`
+	LOD            *LODDef                 `yaml:"lod,omitempty"`
+	Light          *LightDef               `yaml:"light,omitempty"`
+	RigidBody      *RigidBodyDef           `yaml:"rigidBody,omitempty"`
+	Camera         *CameraDef              `yaml:"camera,omitempty"`
+	Textures       map[string]string       `yaml:"textures,omitempty"`
+	Children       []SceneNode             `yaml:"children,omitempty"`
+
+	pos      sourcePos
+	valuePos map[string]sourcePos
+}
+
+// sourcePos is a 1-based line and column in a YAML document, kept for the
+// errors of definitions which only fail once the scene is built.
+type sourcePos struct {
+	line, column int
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (sn *SceneNode) UnmarshalYAML(value *yaml.Node) error {
+	type plain SceneNode
+	if err := value.Decode((*plain)(sn)); err != nil {
+		return err
+	}
+
+	sn.pos = sourcePos{value.Line, value.Column}
+	sn.valuePos = make(map[string]sourcePos, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		v := value.Content[i+1]
+		sn.valuePos[value.Content[i].Value] = sourcePos{v.Line, v.Column}
+	}
+	return nil
+}
+
+// posOf returns the position of a key's value, or of the node if the key
+// isn't set in the document.
+func (sn *SceneNode) posOf(key string) sourcePos {
+	if p, ok := sn.valuePos[key]; ok {
+		return p
+	}
+	return sn.pos
+}
+
+// NodeOverride changes a node instantiated by an include. Zero fields leave the
+// included value in place; textures are merged by name.
+type NodeOverride struct {
+	Position [3]float64        `yaml:"position,omitempty"`
+	Rotation [4]float64        `yaml:"rotation,omitempty"`
+	Scale    [3]float64        `yaml:"scale,omitempty"`
+	Pipeline string            `yaml:"pipeline,omitempty"`
+	Cull     ComponentDef      `yaml:"cull,omitempty"`
+	Input    ComponentDef      `yaml:"input,omitempty"`
+	Update   ComponentDef      `yaml:"update,omitempty"`
+	Textures map[string]string `yaml:"textures,omitempty"`
+}
+
+// ComponentDef names a registered component and its parameters. In YAML it is
+// either the name alone (cull: alwaysPass) or a mapping with a type key and
+// the parameters (update: {type: rotator, speed: 1.5}).
+type ComponentDef struct {
+	Type   string
+	Params ComponentParams
+
+	pos sourcePos
+}
+
+// IsZero reports whether no component is set, so the key can be omitted.
+func (d ComponentDef) IsZero() bool {
+	return d.Type == ""
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (d *ComponentDef) UnmarshalYAML(value *yaml.Node) error {
+	d.pos = sourcePos{value.Line, value.Column}
+	if value.Kind == yaml.ScalarNode {
+		d.Type, d.Params = value.Value, nil
+		return nil
+	}
+
+	var params ComponentParams
+	if err := value.Decode(&params); err != nil {
+		return err
+	}
+	name, ok := params["type"].(string)
+	if !ok {
+		return fmt.Errorf("line %d: component has no type", value.Line)
+	}
+	delete(params, "type")
+	if len(params) == 0 {
+		params = nil
+	}
+	d.Type, d.Params = name, params
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (d ComponentDef) MarshalYAML() (interface{}, error) {
+	if len(d.Params) == 0 {
+		return d.Type, nil
+	}
+
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	n.Content = append(n.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "type"},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: d.Type})
+
+	keys := make([]string, 0, len(d.Params))
+	for k := range d.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var v yaml.Node
+		if err := v.Encode(d.Params[k]); err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, &v)
+	}
+	return n, nil
 }
 
 // CameraDef describes a camera in the scene YAML.
 type CameraDef struct {
-	Name         string         `yaml:"name"`
-	Projection   string         `yaml:"projection"`          // "perspective" or "orthographic"
-	FOV          float64        `yaml:"fov,omitempty"`
-	AutoReshape  *bool          `yaml:"autoReshape,omitempty"`
-	AutoFrustum  *bool          `yaml:"autoFrustum,omitempty"`
-	ClearColor   [4]float32     `yaml:"clearColor,omitempty"`
-	ClearMode    []string       `yaml:"clearMode,omitempty"` // ["color", "depth"]
-	ClipDistance  [2]float64     `yaml:"clipDistance,omitempty"`
-	RenderOrder  uint8          `yaml:"renderOrder,omitempty"`
-	Position     [3]float64     `yaml:"position,omitempty"`
-	Input        string         `yaml:"input,omitempty"`
-	Technique    string         `yaml:"technique,omitempty"`
-	Framebuffer  *FramebufferDef `yaml:"framebuffer,omitempty"`
+	Name             string          `yaml:"name"`
+	Projection       string          `yaml:"projection"` // "perspective" or "orthographic"
+	FOV              float64         `yaml:"fov,omitempty"`
+	AutoReshape      *bool           `yaml:"autoReshape,omitempty"`
+	AutoFrustum      *bool           `yaml:"autoFrustum,omitempty"`
+	ClearColor       [4]float32      `yaml:"clearColor,omitempty"`
+	ClearMode        []string        `yaml:"clearMode,omitempty"` // ["color", "depth"]
+	ClipDistance     [2]float64      `yaml:"clipDistance,omitempty"`
+	RenderOrder      uint8           `yaml:"renderOrder,omitempty"`
+	CullingMask      LayerMask       `yaml:"cullingMask,omitempty"` // bitmask of rendered layers. Defaults to all layers
+	OcclusionCulling bool            `yaml:"occlusionCulling,omitempty"`
+	Position         [3]float64      `yaml:"position,omitempty"`
+	Rotation         [4]float64      `yaml:"rotation,omitempty"` // [angle, axisX, axisY, axisZ]
+	Input            ComponentDef    `yaml:"input,omitempty"`
+	Technique        string          `yaml:"technique,omitempty"`
+	Framebuffer      *FramebufferDef `yaml:"framebuffer,omitempty"`
 }
 
 // LightDef describes a light in the scene YAML.
@@ -46,6 +179,53 @@ type LightDef struct {
 	Shadow     *ShadowDef `yaml:"shadow,omitempty"`
 }
 
+// RigidBodyDef describes a node's rigid body in the scene YAML. A mass of 0
+// makes the body static.
+type RigidBodyDef struct {
+	Mass  float32  `yaml:"mass,omitempty"`
+	Shape ShapeDef `yaml:"shape"`
+}
+
+// ShapeDef describes a collision shape. Type is one of plane, sphere, box,
+// capsule, cone, cylinder, compound, convexHull or triangleMesh. With FromMesh,
+// dimensions that aren't given are derived from the bounds of the node's
+// meshes. Triangle meshes use the node's mesh, or its model's only mesh, which
+// must keep its positions and indices (see Engine.SetKeepMeshData).
+type ShapeDef struct {
+	Type        string          `yaml:"type"`
+	Radius      float64         `yaml:"radius,omitempty"`
+	Height      float64         `yaml:"height,omitempty"`
+	HalfExtents [3]float64      `yaml:"halfExtents,omitempty"`
+	Normal      [3]float64      `yaml:"normal,omitempty"`   // plane
+	Constant    float64         `yaml:"constant,omitempty"` // plane
+	Points      [][3]float64    `yaml:"points,omitempty"`   // convexHull
+	FromMesh    bool            `yaml:"fromMesh,omitempty"`
+	Children    []ChildShapeDef `yaml:"children,omitempty"` // compound
+}
+
+// ChildShapeDef places a shape inside a compound shape.
+type ChildShapeDef struct {
+	ShapeDef `yaml:",inline"`
+	Position [3]float64 `yaml:"position,omitempty"`
+	Rotation [4]float64 `yaml:"rotation,omitempty"` // [angle, axisX, axisY, axisZ]
+}
+
+// LODDef describes a node's level of detail group. Levels name the node's
+// children, including those instantiated from its model, from the most
+// detailed to the least.
+type LODDef struct {
+	Hysteresis   float64       `yaml:"hysteresis,omitempty"`
+	FadeDuration float64       `yaml:"fadeDuration,omitempty"` // seconds
+	Levels       []LODLevelDef `yaml:"levels"`
+}
+
+// LODLevelDef describes a level of detail. ScreenSize is a fraction of the
+// viewport height.
+type LODLevelDef struct {
+	Node       string  `yaml:"node"` // path relative to the group's node
+	ScreenSize float64 `yaml:"screenSize"`
+}
+
 // ShadowDef describes shadow map parameters.
 type ShadowDef struct {
 	Size     uint32 `yaml:"size"`
@@ -2,22 +2,20 @@ package core
 
 // SceneManager manages a stack of scenes
 type SceneManager struct {
+	engine        *Engine
 	managedScenes []*Scene
 }
 
-var (
-	sceneManager *SceneManager
-)
-
-func init() {
-	sceneManager = &SceneManager{
-		make([]*Scene, 0),
+func newSceneManager(e *Engine) *SceneManager {
+	return &SceneManager{
+		engine:        e,
+		managedScenes: make([]*Scene, 0),
 	}
 }
 
-// GetSceneManager returns the scene manager.
+// GetSceneManager returns the default engine's scene manager.
 func GetSceneManager() *SceneManager {
-	return sceneManager
+	return defaultEngine.sceneManager
 }
 
 // PushScene pushes a scene to the stack.
@@ -46,12 +44,53 @@ func (sm *SceneManager) FrontScene() *Scene {
 	return sm.managedScenes[len(sm.managedScenes)-1]
 }
 
+// advance updates the scenes for a frame of dt seconds, in fixed steps if the
+// timer manager has them. Input is processed once per frame before the steps,
+// so presses are neither dropped by frames without steps nor repeated by
+// catch-up frames.
+func (sm *SceneManager) advance(dt float64) {
+	sm.processInput()
+
+	tm := sm.engine.timerManager
+	steps, fixed := tm.steps(dt)
+	if !fixed {
+		sm.update(dt)
+		return
+	}
+
+	for i := 0; i < steps; i++ {
+		sm.update(tm.fixedDt)
+	}
+	sm.interpolate(tm.alpha)
+}
+
+func (sm *SceneManager) processInput() {
+	// we process input in reverse order, frontmost first, and it hides the
+	// devices it captures from the scenes below
+	im := sm.engine.inputManager
+	for i := range sm.managedScenes {
+		var currentScene = sm.managedScenes[len(sm.managedScenes)-1-i]
+		if currentScene.active {
+			currentScene.processInput()
+			im.Capture(currentScene.inputCapture)
+		}
+	}
+	im.releaseCapture()
+}
+
 func (sm *SceneManager) update(dt float64) {
-	// we update scenes in reverse order, frontmost processes input first
 	for i := range sm.managedScenes {
 		var currentScene = sm.managedScenes[len(sm.managedScenes)-1-i]
 		if currentScene.active {
-			currentScene.update(dt)
+			currentScene.update(sm.engine, dt)
+		}
+	}
+}
+
+func (sm *SceneManager) interpolate(alpha float64) {
+	for _, s := range sm.managedScenes {
+		if s.active {
+			s.interpolate(alpha)
 		}
 	}
 }
@@ -59,7 +98,7 @@ func (sm *SceneManager) update(dt float64) {
 func (sm *SceneManager) cull() {
 	for _, s := range sm.managedScenes {
 		if s.active {
-			s.cull()
+			s.cull(sm.engine)
 		}
 	}
 }
@@ -67,7 +106,7 @@ func (sm *SceneManager) cull() {
 func (sm *SceneManager) draw() {
 	for _, s := range sm.managedScenes {
 		if s.active {
-			s.draw()
+			s.draw(sm.engine)
 		}
 	}
 }
@@ -2,8 +2,8 @@ package core
 
 import (
 	"math"
+	"sort"
 
-	"github.com/fcvarela/gosg/gpu"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/go-gl/mathgl/mgl64"
 	"github.com/golang/glog"
@@ -18,78 +18,99 @@ type Shadower interface {
 
 // ShadowMap implements depth-only shadow mapping with PCF and configurable cascades.
 type ShadowMap struct {
-	size         uint32
-	numCascades  int
-	lambda       float64
-	cameras      []*Camera
-	depthTexture gpu.Texture     // single 2D array texture with N layers
-	arrayView    gpu.TextureView // view as 2d_array for shader sampling
-	layerViews   []gpu.TextureView // per-layer views for framebuffer attachments
-	texture      *Texture       // wrapper for bind group creation
+	engine         *Engine
+	size           uint32
+	numCascades    int
+	lambda         float64
+	cameras        []*Camera
+	depthTexture   TextureHandle       // single 2D array texture with N layers
+	arrayView      TextureViewHandle   // view as 2d_array for shader sampling
+	layerViews     []TextureViewHandle // per-layer views for framebuffer attachments
+	texture        *Texture            // wrapper for bind group creation
 	cascadeCenters [maxCascades]mgl64.Vec3
 	cascadeRadii   [maxCascades]float64
 	cascadeZCuts   [maxCascades]float64
+	casters        [maxCascades][]*Node
 }
 
 const maxCascades = 10
 
-// NewShadowMap returns a new ShadowMap with the given number of cascades.
+// NewShadowMap returns a new ShadowMap with the given number of cascades on the
+// default engine.
 func NewShadowMap(size uint32, cascades int) *ShadowMap {
+	return newShadowMap(defaultEngine, size, cascades)
+}
+
+func newShadowMap(e *Engine, size uint32, cascades int) *ShadowMap {
+	r := e.renderer
 	sm := &ShadowMap{
+		engine:      e,
 		size:        size,
 		numCascades: cascades,
 		lambda:      0.5,
 		cameras:     make([]*Camera, cascades),
-		layerViews:  make([]gpu.TextureView, cascades),
+		layerViews:  make([]TextureViewHandle, cascades),
 	}
 
 	// Create a single 2D array depth texture with N layers
-	sm.depthTexture = renderer.device.CreateTexture(gpu.TextureDescriptor{
-		Size:      gpu.Extent3D{Width: size, Height: size, DepthOrArrayLayers: uint32(cascades)},
-		Format:    gpu.TextureFormatDepth32Float,
-		Usage:     gpu.TextureUsageTextureBinding | gpu.TextureUsageRenderAttachment,
-		Dimension: gpu.TextureDimension2D,
+	sm.depthTexture = r.backend.CreateTexture(TextureStorageDescriptor{
+		Width:     size,
+		Height:    size,
+		Layers:    uint32(cascades),
+		Format:    PixelFormatDepth32Float,
+		Usage:     TextureUsageTextureBinding | TextureUsageRenderAttachment,
 		MipLevels: 1,
 	})
 
 	// Create array view for shader sampling
-	sm.arrayView = sm.depthTexture.CreateViewArray(uint32(cascades))
+	sm.arrayView = r.backend.CreateTextureView(sm.depthTexture, TextureViewDescriptor{
+		Dimension:       TextureViewDimension2DArray,
+		ArrayLayerCount: uint32(cascades),
+	})
 
 	// Create per-layer views for framebuffer attachments + comparison sampler
-	compSampler := renderer.device.CreateSampler(gpu.SamplerDescriptor{
-		AddressModeU: gpu.AddressModeClampToEdge,
-		AddressModeV: gpu.AddressModeClampToEdge,
-		AddressModeW: gpu.AddressModeClampToEdge,
-		MinFilter:    gpu.FilterModeNearest,
-		MagFilter:    gpu.FilterModeNearest,
-		Compare:      gpu.CompareFunctionLessEqual,
+	compSampler := r.backend.CreateSampler(SamplerDescriptor{
+		AddressModeU: AddressModeClampToEdge,
+		AddressModeV: AddressModeClampToEdge,
+		AddressModeW: AddressModeClampToEdge,
+		MinFilter:    FilterModeNearest,
+		MagFilter:    FilterModeNearest,
+		Compare:      CompareFunctionLessEqual,
 	})
 
 	sm.texture = &Texture{
-		id:      allocateTextureID(),
-		texture: sm.depthTexture,
-		view:    sm.arrayView,
-		sampler: compSampler,
+		renderer: r,
+		id:       allocateTextureID(),
+		texture:  sm.depthTexture,
+		view:     sm.arrayView,
+		sampler:  compSampler,
 		descriptor: TextureDescriptor{
 			Width: size, Height: size,
 			Format: TextureFormatDEPTH, SizedFormat: TextureSizedFormatDEPTH32F,
 		},
+		format: PixelFormatDepth32Float,
 	}
 
 	for i := 0; i < cascades; i++ {
-		sm.layerViews[i] = sm.depthTexture.CreateViewLayer(uint32(i))
+		sm.layerViews[i] = r.backend.CreateTextureView(sm.depthTexture, TextureViewDescriptor{
+			Dimension:       TextureViewDimension2D,
+			BaseArrayLayer:  uint32(i),
+			ArrayLayerCount: 1,
+		})
 
 		fb := &Framebuffer{colorAttachments: make(map[int]*Texture)}
 		fb.depthAttachment = &Texture{
-			id:   allocateTextureID(),
-			view: sm.layerViews[i],
+			renderer: r,
+			id:       allocateTextureID(),
+			view:     sm.layerViews[i],
 			descriptor: TextureDescriptor{
 				Width: size, Height: size,
 				Format: TextureFormatDEPTH, SizedFormat: TextureSizedFormatDEPTH32F,
 			},
+			format: PixelFormatDepth32Float,
 		}
 
-		c := NewCamera("ShadowCamera", OrthographicProjection)
+		c := newCamera(e, "ShadowCamera", OrthographicProjection)
 		c.SetFramebuffer(fb)
 		c.SetViewport(mgl32.Vec4{0, 0, float32(size), float32(size)})
 		c.SetAutoReshape(false)
@@ -171,7 +192,8 @@ func (s *ShadowMap) computeCascades(camera *Camera) {
 	}
 }
 
-func (s *ShadowMap) renderCascade(cascade int, light *Light, camera *Camera) {
+// cullCascade fits a cascade's camera to the light and gathers its casters.
+func (s *ShadowMap) cullCascade(cascade int, light *Light, camera *Camera) {
 	shadowCam := s.cameras[cascade]
 	center := s.cascadeCenters[cascade]
 	radius := s.cascadeRadii[cascade]
@@ -240,41 +262,118 @@ func (s *ShadowMap) renderCascade(cascade int, light *Light, camera *Camera) {
 	light.Block.ZCuts[cascade] = mgl32.Vec4{float32(s.cascadeZCuts[cascade]), 0, 0, 0}
 	light.Block.VPMatrix[cascade] = Mat4DoubleToFloat(biasvpmatrix)
 
+	// casters are culled against the cascade's frustum so those outside the
+	// camera's view still cast shadows, through the root's bounding volume
+	// hierarchy if it has one. Subtrees culled by their own components, such
+	// as levels of detail, cast shadows when the camera draws them. Nodes
+	// hidden by occluders are not drawn but still cast shadows.
+	casters := s.casters[cascade][:0]
+	root := camera.scene
+	frustum := MakeFrustum(shadowCam.projectionMatrix, shadowCam.viewMatrix)
+	addCaster := func(n *Node) {
+		if n.pipeline != nil && !n.pipeline.Blending && camera.Sees(n) {
+			casters = append(casters, n)
+		}
+	}
+	if bc, ok := root.cullComponent.(*BVHCuller); ok {
+		bc.Query(frustum, addCaster)
+	} else {
+		root.Walk(func(n *Node) VisitAction {
+			if !n.active || !n.worldBounds.InFrustum(frustum) || (n != root && !castsFromWalk(n)) {
+				return VisitSkipChildren
+			}
+			if n.mesh != nil {
+				addCaster(n)
+			}
+			return VisitContinue
+		}, nil)
+	}
+	for _, nodes := range [][]*Node{camera.visibleOpaqueNodes, camera.occludedNodes} {
+		for _, n := range nodes {
+			if !n.pipeline.Blending && !castsFromRoot(root, n) {
+				casters = append(casters, n)
+			}
+		}
+	}
+	s.casters[cascade] = casters
+}
+
+// castsFromWalk returns whether a node is culled as a caster by the cascades'
+// frustum, rather than by its own cull component.
+func castsFromWalk(n *Node) bool {
+	_, ok := n.cullComponent.(*DefaultCuller)
+	return ok && n.lodGroup == nil
+}
+
+// castsFromRoot returns whether the cascades' frustum cull reaches a node
+// below root.
+func castsFromRoot(root, n *Node) bool {
+	for ; n != root; n = n.parent {
+		if n == nil || !castsFromWalk(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// bindCasters binds the shadow array texture to a cascade's casters and drops
+// those which are not on the light's layers.
+func (s *ShadowMap) bindCasters(cascade int, light *Light) {
+	casters := s.casters[cascade][:0]
+	for _, n := range s.casters[cascade] {
+		n.material.SetTexture("shadowTex", s.texture)
+		if light.layers != 0 && light.layers&n.layers == 0 {
+			continue
+		}
+		casters = append(casters, n)
+	}
+	setSortKeys(casters)
+	s.casters[cascade] = casters
+}
+
+func (s *ShadowMap) renderCascade(cascade int) {
+	shadowCam := s.cameras[cascade]
 	shadowCam.constants.SetData(shadowCam.projectionMatrix, shadowCam.viewMatrix, nil)
 
 	desc := shadowCam.MakeRenderPassDescriptor(false, true)
-	pass := renderer.BeginRenderPass(desc)
+	pass := s.engine.renderer.BeginRenderPass(desc)
 	if pass == nil {
 		return
 	}
+	defer pass.End()
 
-	shadowPipeline, err := resourceManager.Pipeline("shadow")
+	shadowPipeline, err := s.engine.resourceManager.Pipeline("shadow")
 	if err != nil {
 		glog.Warningf("failed to load shadow pipeline: %v", err)
-		pass.End()
 		return
 	}
 
-	for pipeline, nodeBucket := range camera.pipelineBuckets {
-		if pipeline.Blending {
-			continue
-		}
-		// Bind the shadow array texture for all nodes
-		for _, n := range nodeBucket {
-			n.material.SetTexture("shadowTex", s.texture)
-		}
+	if casters := s.casters[cascade]; len(casters) > 0 {
 		pass.SetPipeline(shadowPipeline)
 		pass.SetCameraConstants(shadowCam.constants.buffer)
-		RenderBatchedNodes(pass, shadowCam, nodeBucket)
+		RenderBatchedNodes(pass, shadowCam, casters)
 	}
-	pass.End()
 }
 
 // Render implements the Shadower interface
 func (s *ShadowMap) Render(light *Light, cam *Camera) {
 	s.computeCascades(cam)
+
+	// cascades are culled and sorted in parallel, casters are shared between
+	// cascades so they are bound to the shadow texture in between
+	workers := s.engine.workers
+	workers.run(s.numCascades, func(c int) {
+		s.cullCascade(c, light, cam)
+	})
+	for c := 0; c < s.numCascades; c++ {
+		s.bindCasters(c, light)
+	}
+	workers.run(s.numCascades, func(c int) {
+		sort.Sort(NodesByMaterial(s.casters[c]))
+	})
+
 	for c := 0; c < s.numCascades; c++ {
-		s.renderCascade(c, light, cam)
-		renderer.Flush()
+		s.renderCascade(c)
+		s.engine.renderer.Flush()
 	}
 }
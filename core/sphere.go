@@ -0,0 +1,202 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// sphereEpsilon is the relative tolerance points are considered inside a
+// sphere with while it is being fitted to them.
+const sphereEpsilon = 1e-9
+
+// Sphere is a bounding sphere
+type Sphere struct {
+	center mgl64.Vec3
+	radius float64
+}
+
+// NewSphere returns a sphere with the given center and radius
+func NewSphere(center mgl64.Vec3, radius float64) *Sphere {
+	return &Sphere{center: center, radius: radius}
+}
+
+// SphereFromPoints returns the smallest sphere containing the given points,
+// found with Welzl's algorithm in expected linear time. It returns nil
+// without points.
+func SphereFromPoints(points []mgl64.Vec3) *Sphere {
+	if len(points) == 0 {
+		return nil
+	}
+
+	// the expected running time relies on a random order, seeded so the
+	// result doesn't change between runs
+	p := append([]mgl64.Vec3(nil), points...)
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(p), func(i, j int) { p[i], p[j] = p[j], p[i] })
+
+	// each level fixes one more point on the sphere's surface
+	s := Sphere{center: p[0]}
+	for i := 1; i < len(p); i++ {
+		if s.fits(p[i]) {
+			continue
+		}
+		s = Sphere{center: p[i]}
+		for j := 0; j < i; j++ {
+			if s.fits(p[j]) {
+				continue
+			}
+			s = sphereThrough(p[i], p[j])
+			for k := 0; k < j; k++ {
+				if s.fits(p[k]) {
+					continue
+				}
+				s = sphereThrough(p[i], p[j], p[k])
+				for l := 0; l < k; l++ {
+					if !s.fits(p[l]) {
+						s = sphereThrough(p[i], p[j], p[k], p[l])
+					}
+				}
+			}
+		}
+	}
+	return &s
+}
+
+// fits returns whether a point is inside the sphere, with some tolerance
+func (s *Sphere) fits(p mgl64.Vec3) bool {
+	return p.Sub(s.center).Len() <= s.radius*(1+sphereEpsilon)+sphereEpsilon
+}
+
+// sphereThrough returns the smallest sphere with two to four points on its
+// surface. Degenerate sets fall back to the sphere through the points
+// farthest apart.
+func sphereThrough(p ...mgl64.Vec3) Sphere {
+	a := p[0]
+	switch len(p) {
+	case 2:
+		center := a.Add(p[1]).Mul(0.5)
+		return Sphere{center: center, radius: center.Sub(a).Len()}
+
+	case 3:
+		ab, ac := p[1].Sub(a), p[2].Sub(a)
+		n := ab.Cross(ac)
+		if d := 2 * n.Dot(n); d > 1e-18 {
+			offset := n.Cross(ab).Mul(ac.Dot(ac)).Add(ac.Cross(n).Mul(ab.Dot(ab))).Mul(1 / d)
+			return Sphere{center: a.Add(offset), radius: offset.Len()}
+		}
+
+	case 4:
+		ab, ac, ad := p[1].Sub(a), p[2].Sub(a), p[3].Sub(a)
+		m := mgl64.Mat3FromRows(ab, ac, ad)
+		if det := m.Det(); math.Abs(det) > 1e-18 {
+			rhs := mgl64.Vec3{ab.Dot(ab), ac.Dot(ac), ad.Dot(ad)}.Mul(0.5)
+			offset := m.Inv().Mul3x1(rhs)
+			return Sphere{center: a.Add(offset), radius: offset.Len()}
+		}
+	}
+
+	var best Sphere
+	for i := range p {
+		for j := i + 1; j < len(p); j++ {
+			if s := sphereThrough(p[i], p[j]); s.radius > best.radius {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// Center returns the sphere's center
+func (s *Sphere) Center() mgl64.Vec3 {
+	return s.center
+}
+
+// Radius returns the sphere's radius
+func (s *Sphere) Radius() float64 {
+	return s.radius
+}
+
+// String implements the stringer interface
+func (s *Sphere) String() string {
+	return fmt.Sprintf("Sphere center: %v radius: %v", s.center, s.radius)
+}
+
+// ContainsPoint returns whether the sphere contains the given point
+func (s *Sphere) ContainsPoint(p mgl64.Vec3) bool {
+	d := p.Sub(s.center)
+	return d.Dot(d) <= s.radius*s.radius
+}
+
+// ClosestPoint returns the point in the sphere closest to the given point
+func (s *Sphere) ClosestPoint(p mgl64.Vec3) mgl64.Vec3 {
+	d := p.Sub(s.center)
+	if l := d.Len(); l > s.radius {
+		return s.center.Add(d.Mul(s.radius / l))
+	}
+	return p
+}
+
+// Transformed returns a sphere containing the sphere transformed by m. Under
+// non-uniform scales the radius grows with the largest scale.
+func (s *Sphere) Transformed(m mgl64.Mat4) *Sphere {
+	scale := math.Max(m.Col(0).Vec3().Len(), math.Max(m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()))
+	return &Sphere{center: mgl64.TransformCoordinate(s.center, m), radius: s.radius * scale}
+}
+
+// ClassifyPlane returns which side of a plane the sphere is on
+func (s *Sphere) ClassifyPlane(plane mgl64.Vec4) PlaneSide {
+	return planeSide(plane.Vec3().Dot(s.center)+plane[3], s.radius)
+}
+
+// InFrustum returns whether the sphere is at least partly inside the frustum
+// defined by the given planes
+func (s *Sphere) InFrustum(planes [6]mgl64.Vec4) bool {
+	for _, p := range planes {
+		if s.ClassifyPlane(p) == PlaneBack {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsSphere returns whether two spheres overlap
+func (s *Sphere) IntersectsSphere(o *Sphere) bool {
+	r := s.radius + o.radius
+	d := o.center.Sub(s.center)
+	return d.Dot(d) <= r*r
+}
+
+// IntersectsAABB returns whether the sphere and the AABB overlap
+func (s *Sphere) IntersectsAABB(a *AABB) bool {
+	return s.ContainsPoint(a.ClosestPoint(s.center))
+}
+
+// IntersectsOBB returns whether the sphere and the OBB overlap
+func (s *Sphere) IntersectsOBB(o *OBB) bool {
+	return s.ContainsPoint(o.ClosestPoint(s.center))
+}
+
+// IntersectsTriangle returns whether the sphere and the triangle overlap
+func (s *Sphere) IntersectsTriangle(t Triangle) bool {
+	p, _ := t.ClosestPoint(s.center)
+	return s.ContainsPoint(p)
+}
+
+// BoundingSphere returns the smallest sphere containing the mesh's vertices
+// if it keeps them, and its bounds otherwise. It returns nil for empty meshes.
+func (m *Mesh) BoundingSphere() *Sphere {
+	var points []mgl64.Vec3
+	if len(m.positions) > 0 {
+		points = make([]mgl64.Vec3, 0, len(m.positions)/3)
+		for i := 0; i+2 < len(m.positions); i += 3 {
+			points = append(points, mgl64.Vec3{float64(m.positions[i]), float64(m.positions[i+1]), float64(m.positions[i+2])})
+		}
+	} else if validBounds(m.bounds) {
+		corners := boxCorners(m.bounds)
+		points = corners[:]
+	}
+	return SphereFromPoints(points)
+}
@@ -0,0 +1,213 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSphere_SphereFromPoints(t *testing.T) {
+	if s := SphereFromPoints(nil); s != nil {
+		t.Errorf("sphere without points = %v, want nil", s)
+	}
+
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{-1, -1, -1})
+	a.ExtendWithPoint(mgl64.Vec3{1, 1, 1})
+	corners := boxCorners(a)
+
+	s := math.Sqrt(3) / 2
+	tests := []struct {
+		name   string
+		points []mgl64.Vec3
+		center mgl64.Vec3
+		radius float64
+	}{
+		{"point", []mgl64.Vec3{{1, 2, 3}}, mgl64.Vec3{1, 2, 3}, 0},
+		{"cube", corners[:], mgl64.Vec3{}, math.Sqrt(3)},
+		// the longest edge of an obtuse triangle is a diameter
+		{"obtuse", []mgl64.Vec3{{0, 0, 0}, {4, 0, 0}, {1, 1, 0}}, mgl64.Vec3{2, 0, 0}, 2},
+		{"equilateral", []mgl64.Vec3{{1, 0, 0}, {-0.5, s, 0}, {-0.5, -s, 0}}, mgl64.Vec3{}, 1},
+		{"collinear", []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {3, 0, 0}, {2, 0, 0}}, mgl64.Vec3{1.5, 0, 0}, 1.5},
+		{"duplicates", []mgl64.Vec3{{0, 0, 0}, {0, 0, 0}, {0, 2, 0}, {0, 2, 0}}, mgl64.Vec3{0, 1, 0}, 1},
+	}
+
+	for _, tt := range tests {
+		got := SphereFromPoints(tt.points)
+		if !vecNear(got.Center(), tt.center) || math.Abs(got.Radius()-tt.radius) > 1e-9 {
+			t.Errorf("%s: sphere = %v, want center %v radius %v", tt.name, got, tt.center, tt.radius)
+		}
+	}
+}
+
+func TestSphere_SphereFromPointsMinimal(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for run := 0; run < 20; run++ {
+		points := make([]mgl64.Vec3, 10)
+		for i := range points {
+			points[i] = mgl64.Vec3{r.Float64()*10 - 5, r.Float64()*4 - 2, r.Float64() * 3}
+		}
+		s := SphereFromPoints(points)
+
+		for _, p := range points {
+			if d := p.Sub(s.Center()).Len(); d > s.Radius()+1e-9 {
+				t.Fatalf("run %d: point %v outside %v by %v", run, p, s, d-s.Radius())
+			}
+		}
+
+		// the smallest sphere is the smallest one through up to four of the
+		// points which contains them all
+		best := math.Inf(1)
+		var subsets func(from int, chosen []mgl64.Vec3)
+		subsets = func(from int, chosen []mgl64.Vec3) {
+			if len(chosen) >= 2 {
+				c := sphereThrough(chosen...)
+				if c.radius < best {
+					inside := true
+					for _, p := range points {
+						inside = inside && c.fits(p)
+					}
+					if inside {
+						best = c.radius
+					}
+				}
+			}
+			if len(chosen) == 4 {
+				return
+			}
+			for i := from; i < len(points); i++ {
+				subsets(i+1, append(chosen, points[i]))
+			}
+		}
+		subsets(0, nil)
+
+		if math.Abs(s.Radius()-best) > 1e-6 {
+			t.Errorf("run %d: radius = %v, want %v", run, s.Radius(), best)
+		}
+	}
+}
+
+func TestSphere_MeshBoundingSphere(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+
+	m := newMesh(e)
+	m.SetKeepData(true)
+	m.SetPositions([]float32{-1, 0, 0, 1, 0, 0, 0, 0.5, 0})
+	if s := m.BoundingSphere(); !vecNear(s.Center(), mgl64.Vec3{}) || math.Abs(s.Radius()-1) > 1e-9 {
+		t.Errorf("sphere from vertices = %v, want the unit sphere", s)
+	}
+
+	// without vertices the sphere bounds the mesh's box
+	m = newMesh(e)
+	m.SetKeepData(false)
+	m.SetPositions([]float32{-1, 0, 0, 1, 0, 0, 0, 0.5, 0})
+	want := math.Sqrt(1 + 0.25*0.25)
+	if s := m.BoundingSphere(); !vecNear(s.Center(), mgl64.Vec3{0, 0.25, 0}) || math.Abs(s.Radius()-want) > 1e-9 {
+		t.Errorf("sphere from bounds = %v, want radius %v", s, want)
+	}
+}
+
+func TestSphere_Transformed(t *testing.T) {
+	s := NewSphere(mgl64.Vec3{1, 0, 0}, 1)
+	got := s.Transformed(mgl64.Translate3D(0, 5, 0).Mul4(mgl64.Scale3D(1, 3, 2)))
+	if !vecNear(got.Center(), mgl64.Vec3{1, 5, 0}) || got.Radius() != 3 {
+		t.Errorf("transformed = %v, want center [1 5 0] radius 3", got)
+	}
+}
+
+func TestSphere_ClosestPoint(t *testing.T) {
+	s := NewSphere(mgl64.Vec3{1, 1, 1}, 2)
+
+	tests := []struct {
+		point mgl64.Vec3
+		want  mgl64.Vec3
+	}{
+		{mgl64.Vec3{1, 2, 1}, mgl64.Vec3{1, 2, 1}},
+		{mgl64.Vec3{1, 1, 7}, mgl64.Vec3{1, 1, 3}},
+		{mgl64.Vec3{-3, 1, 1}, mgl64.Vec3{-1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		if got := s.ClosestPoint(tt.point); !vecNear(got, tt.want) {
+			t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestSphere_ClassifyPlane(t *testing.T) {
+	s := NewSphere(mgl64.Vec3{0, 1, 0}, 1)
+
+	tests := []struct {
+		plane mgl64.Vec4
+		want  PlaneSide
+	}{
+		{mgl64.Vec4{0, 1, 0, 0.5}, PlaneFront},
+		{mgl64.Vec4{0, 1, 0, -2.5}, PlaneBack},
+		{mgl64.Vec4{0, 1, 0, -1}, PlaneIntersecting},
+		{mgl64.Vec4{0, -1, 0, 0}, PlaneIntersecting},
+	}
+
+	for _, tt := range tests {
+		if got := s.ClassifyPlane(tt.plane); got != tt.want {
+			t.Errorf("ClassifyPlane(%v) = %v, want %v", tt.plane, got, tt.want)
+		}
+	}
+}
+
+func TestSphere_InFrustum(t *testing.T) {
+	proj := PerspectiveWebGPU(math.Pi/4, 1.0, 0.1, 100)
+	frustum := MakeFrustum(proj, mgl64.Ident4())
+
+	if !NewSphere(mgl64.Vec3{0, 0, -10}, 1).InFrustum(frustum) {
+		t.Error("sphere ahead of the camera should be in frustum")
+	}
+	if NewSphere(mgl64.Vec3{0, 0, -200}, 1).InFrustum(frustum) {
+		t.Error("sphere beyond the far plane should not be in frustum")
+	}
+	if NewSphere(mgl64.Vec3{0, 0, 5}, 1).InFrustum(frustum) {
+		t.Error("sphere behind the camera should not be in frustum")
+	}
+}
+
+func TestSphere_Intersects(t *testing.T) {
+	s := NewSphere(mgl64.Vec3{}, 1)
+
+	a := NewAABB()
+	a.ExtendWithPoint(mgl64.Vec3{0.5, 0.5, 0.5})
+	a.ExtendWithPoint(mgl64.Vec3{2, 2, 2})
+	if !s.IntersectsAABB(a) || !a.IntersectsSphere(s) {
+		t.Error("sphere should intersect the box at its corner")
+	}
+
+	// the box's corner is sqrt(3)*0.7 away
+	b := a.Transformed(mgl64.Translate3D(0.2, 0.2, 0.2))
+	if s.IntersectsAABB(b) || b.IntersectsSphere(s) {
+		t.Error("sphere should miss the box past its corner")
+	}
+
+	o := newTurnedOBB(mgl64.Vec3{2.3, 0, 0})
+	if !s.IntersectsOBB(o) || !o.IntersectsSphere(s) {
+		t.Error("sphere should intersect the turned box's corner")
+	}
+	o = newTurnedOBB(mgl64.Vec3{1.6, 1.6, 0})
+	if s.IntersectsOBB(o) || o.IntersectsSphere(s) {
+		t.Error("sphere should miss the turned box's face")
+	}
+
+	if !s.IntersectsSphere(NewSphere(mgl64.Vec3{0, 1.9, 0}, 1)) {
+		t.Error("overlapping spheres should intersect")
+	}
+	if s.IntersectsSphere(NewSphere(mgl64.Vec3{0, 2.1, 0}, 1)) {
+		t.Error("separated spheres should not intersect")
+	}
+
+	if !s.IntersectsTriangle(Triangle{{-5, -5, 0.9}, {5, -5, 0.9}, {0, 5, 0.9}}) {
+		t.Error("sphere should intersect the triangle above its centre")
+	}
+	if s.IntersectsTriangle(Triangle{{1, 1, -1}, {3, 1, -1}, {1, 1, 1}}) {
+		t.Error("sphere should miss the triangle beside it")
+	}
+}
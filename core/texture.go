@@ -3,19 +3,19 @@ package core
 import (
 	"sync/atomic"
 	"unsafe"
-
-	"github.com/fcvarela/gosg/gpu"
 )
 
 var nextTextureID uint32
 
 // Texture holds a GPU texture, its view, sampler, and descriptor.
 type Texture struct {
+	renderer   *Renderer
 	id         uint32
-	texture    gpu.Texture
-	view       gpu.TextureView
-	sampler    gpu.Sampler
+	texture    TextureHandle
+	view       TextureViewHandle
+	sampler    SamplerHandle
 	descriptor TextureDescriptor
+	format     PixelFormat
 }
 
 // Descriptor returns the descriptor used to create this texture.
@@ -33,6 +33,28 @@ func (t *Texture) ID() uint32 {
 	return t.id
 }
 
+// release releases the texture's backend resources.
+func (t *Texture) release() {
+	releaseHandle(t.view)
+	releaseHandle(t.texture)
+	releaseHandle(t.sampler)
+}
+
+// resize recreates the texture's storage at a new size. The texture keeps its
+// ID and sampler, its contents are lost.
+func (t *Texture) resize(width, height uint32) {
+	if t.descriptor.Width == width && t.descriptor.Height == height {
+		return
+	}
+	releaseHandle(t.view)
+	releaseHandle(t.texture)
+
+	t.descriptor.Width = width
+	t.descriptor.Height = height
+	t.texture = t.renderer.createTexture(t.descriptor, t.format)
+	t.view = t.renderer.backend.CreateTextureView(t.texture, TextureViewDescriptor{})
+}
+
 func allocateTextureID() uint32 {
 	return atomic.AddUint32(&nextTextureID, 1)
 }
@@ -40,13 +62,13 @@ func allocateTextureID() uint32 {
 // SetFilter recreates the sampler with the given filter mode.
 func (t *Texture) SetFilter(f TextureFilter) {
 	t.descriptor.Filter = f
-	t.sampler = renderer.createSampler(t.descriptor)
+	t.sampler = t.renderer.createSampler(t.descriptor)
 }
 
 // SetWrapMode recreates the sampler with the given wrap mode.
 func (t *Texture) SetWrapMode(wm TextureWrapMode) {
 	t.descriptor.WrapMode = wm
-	t.sampler = renderer.createSampler(t.descriptor)
+	t.sampler = t.renderer.createSampler(t.descriptor)
 }
 
 // TextureTarget specifies a texture target type
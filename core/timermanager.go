@@ -11,6 +11,7 @@ type TimerHistogram struct {
 
 // TimerManager wraps the system's high resolution timer
 type TimerManager struct {
+	engine            *Engine
 	frameCounterMod10 int
 	dt                float64
 	frameStartTime    float64
@@ -18,16 +19,21 @@ type TimerManager struct {
 	avgFps            float64
 	paused            bool
 	histogram         TimerHistogram
-}
 
-var (
-	timerManager *TimerManager
-)
+	// fixed step length and catch-up cap, time not simulated yet and where
+	// the frame falls between the last two steps
+	fixedDt     float64
+	maxSteps    int
+	accumulator float64
+	alpha       float64
+}
 
-func init() {
-	timerManager = &TimerManager{
+func newTimerManager(e *Engine) *TimerManager {
+	return &TimerManager{
+		engine: e,
 		dt:     0.0,
 		paused: true,
+		alpha:  1.0,
 		histogram: TimerHistogram{
 			Values: make([]float32, 60),
 			Min:    float32(math.Inf(+1)),
@@ -36,9 +42,9 @@ func init() {
 	}
 }
 
-// GetTimerManager returns the timer manager.
+// GetTimerManager returns the default engine's timer manager.
 func GetTimerManager() *TimerManager {
-	return timerManager
+	return defaultEngine.timerManager
 }
 
 // Start starts/resumes the system timer.
@@ -57,8 +63,17 @@ func (ts *TimerManager) Paused() bool {
 }
 
 // Time returns the system time in number of seconds since application startup.
+// While a recording is played back it is the replayed frame's start time.
 func (ts *TimerManager) Time() float64 {
-	return sdlGetTimeSeconds()
+	if ts.engine.inputManager.Replaying() {
+		return ts.frameStartTime
+	}
+	return ts.clock()
+}
+
+// clock returns the window system's time, even during a replay.
+func (ts *TimerManager) clock() float64 {
+	return ts.engine.windowManager.time()
 }
 
 // FrameStartTime returns the time at which the current frame started in number of seconds since application startup.
@@ -125,3 +140,50 @@ func (ts *TimerManager) AvgFPS() float64 {
 func (ts *TimerManager) Histogram() TimerHistogram {
 	return ts.histogram
 }
+
+// SetFixedTimestep makes the application update scenes and physics in steps
+// of dt seconds, as many times per frame as the elapsed time needs and at most
+// maxSteps. Time beyond the cap is dropped, so a slow frame slows the
+// simulation down instead of stalling the next ones. A dt of 0 goes back to
+// one update per frame with the frame's duration.
+func (ts *TimerManager) SetFixedTimestep(dt float64, maxSteps int) {
+	ts.fixedDt = math.Max(dt, 0)
+	ts.maxSteps = max(maxSteps, 1)
+	ts.accumulator = 0
+	ts.alpha = 1
+}
+
+// FixedTimestep returns the fixed step length and catch-up cap, or 0 when
+// updates run once per frame.
+func (ts *TimerManager) FixedTimestep() (float64, int) {
+	return ts.fixedDt, ts.maxSteps
+}
+
+// Alpha returns how far the current frame is between the last two fixed
+// steps, from 0 to 1. Nodes and cameras are rendered blended between their
+// transforms at those steps by it. It is always 1 without a fixed timestep.
+func (ts *TimerManager) Alpha() float64 {
+	return ts.alpha
+}
+
+// steps adds a frame's duration to the time to simulate and returns how many
+// fixed steps to run for it. It returns false without a fixed timestep.
+func (ts *TimerManager) steps(dt float64) (int, bool) {
+	if ts.fixedDt <= 0 {
+		return 0, false
+	}
+
+	ts.accumulator += dt
+	steps := 0
+	for ts.accumulator >= ts.fixedDt && steps < ts.maxSteps {
+		ts.accumulator -= ts.fixedDt
+		steps++
+	}
+	if ts.accumulator >= ts.fixedDt {
+		// too far behind to catch up
+		ts.accumulator = math.Mod(ts.accumulator, ts.fixedDt)
+	}
+
+	ts.alpha = ts.accumulator / ts.fixedDt
+	return steps, true
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestTimerManagerFixedTimestep(t *testing.T) {
+	tm := newTimerManager(NewEngine())
+	if _, fixed := tm.steps(0.5); fixed || tm.Alpha() != 1 {
+		t.Fatalf("variable timestep ran fixed steps, alpha %v", tm.Alpha())
+	}
+
+	tm.SetFixedTimestep(0.25, 4)
+	tests := []struct {
+		dt    float64
+		steps int
+		alpha float64
+	}{
+		{0.5, 2, 0},
+		{0.125, 0, 0.5},
+		{0.25, 1, 0.5},
+		{0.125, 1, 0},
+		// capped, with the rest of the time dropped
+		{2.125, 4, 0.5},
+	}
+
+	for i, tt := range tests {
+		steps, fixed := tm.steps(tt.dt)
+		if !fixed || steps != tt.steps || math.Abs(tm.Alpha()-tt.alpha) > 1e-9 {
+			t.Errorf("frame %d: steps = %d alpha = %v, want %d and %v", i, steps, tm.Alpha(), tt.steps, tt.alpha)
+		}
+	}
+
+	tm.SetFixedTimestep(0, 4)
+	if _, fixed := tm.steps(0.5); fixed || tm.Alpha() != 1 {
+		t.Errorf("steps ran after the fixed timestep was cleared")
+	}
+}
+
+type testMover struct {
+	velocity mgl64.Vec3
+	updates  int
+}
+
+func (m *testMover) Run(n *Node) {}
+
+func (m *testMover) RunTimed(n *Node, dt float64) {
+	n.Translate(m.velocity.Mul(dt))
+	m.updates++
+}
+
+func TestFixedTimestepInterpolation(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	scene, camera := newRecordingScene(t, e, "unlit")
+	e.SceneManager().PushScene(scene)
+
+	node := scene.Root().Children()[0]
+	mover := &testMover{velocity: mgl64.Vec3{4, 0, 0}}
+	node.SetUpdateComponent(mover)
+
+	camera.Node().SetUpdateComponent(&testMover{velocity: mgl64.Vec3{0, 4, 0}})
+
+	e.TimerManager().SetFixedTimestep(0.25, 8)
+
+	// two steps move the node 2 units, and the frame ends half way into the
+	// third
+	e.SceneManager().advance(0.625)
+	if mover.updates != 2 {
+		t.Fatalf("updates = %d, want 2", mover.updates)
+	}
+	if p := node.WorldPosition(); !vecNear(p, mgl64.Vec3{2, 0, -10}) {
+		t.Errorf("position = %v, want [2 0 -10]", p)
+	}
+	rendered := node.InterpolatedWorldTransform().Col(3).Vec3()
+	if !vecNear(rendered, mgl64.Vec3{1.5, 0, -10}) {
+		t.Errorf("rendered position = %v, want [1.5 0 -10]", rendered)
+	}
+
+	// cameras render from their interpolated position
+	camera.Reshape(e.WindowManager().WindowSize())
+	if eye := camera.ViewMatrix().Inv().Col(3).Vec3(); !vecNear(eye, mgl64.Vec3{0, 1.5, 0}) {
+		t.Errorf("camera position = %v, want [0 1.5 0]", eye)
+	}
+
+	// no step this frame, only the blend moves on
+	e.SceneManager().advance(0.0625)
+	rendered = node.InterpolatedWorldTransform().Col(3).Vec3()
+	if mover.updates != 2 || !vecNear(rendered, mgl64.Vec3{1.75, 0, -10}) {
+		t.Errorf("rendered position = %v after %d updates, want [1.75 0 -10] after 2", rendered, mover.updates)
+	}
+
+	// nodes which didn't move in the last step render where they are
+	node.SetUpdateComponent(nil)
+	e.SceneManager().advance(0.25)
+	if got := node.InterpolatedWorldTransform(); got != node.WorldTransform() {
+		t.Errorf("still node rendered at %v, want %v", got.Col(3), node.WorldTransform().Col(3))
+	}
+
+	// back to variable steps
+	e.TimerManager().SetFixedTimestep(0, 0)
+	node.SetUpdateComponent(mover)
+	e.SceneManager().advance(0.1)
+	if mover.updates != 3 || node.InterpolatedWorldTransform() != node.WorldTransform() {
+		t.Errorf("variable step ran %d updates and interpolated", mover.updates)
+	}
+}
+
+// toggleInput flips a node's toggle with a command when "toggle" is pressed.
+type toggleInput struct {
+	im      *InputManager
+	toggled bool
+	presses int
+}
+
+func (c *toggleInput) Run(node *Node) []NodeCommand {
+	if !c.im.ActionPressed("toggle") {
+		return nil
+	}
+	return []NodeCommand{toggleCommand{c}}
+}
+
+type toggleCommand struct{ c *toggleInput }
+
+func (t toggleCommand) Run(node *Node) {
+	t.c.toggled = !t.c.toggled
+	t.c.presses++
+}
+
+func TestFixedTimestepInputOncePerFrame(t *testing.T) {
+	e := newTestEngine(t)
+	im := e.InputManager()
+	im.InputMap().Bind("toggle", InputBinding{Key: KeyT})
+
+	scene := NewScene("input")
+	scene.SetRoot(NewNode("root"))
+	toggle := &toggleInput{im: im}
+	scene.Root().SetInputComponent(toggle)
+	mover := &testMover{}
+	scene.Root().SetUpdateComponent(mover)
+	e.SceneManager().PushScene(scene)
+
+	e.TimerManager().SetFixedTimestep(0.25, 8)
+
+	for _, tt := range []struct {
+		dt    float64
+		steps int
+	}{
+		{0.125, 0},
+		{0.125, 1},
+		{0.75, 3},
+	} {
+		toggle.presses, mover.updates = 0, 0
+		im.reset()
+		im.HandleKeyEvent(KeyT, true)
+		e.SceneManager().advance(tt.dt)
+		im.reset()
+		im.HandleKeyEvent(KeyT, false)
+
+		if toggle.presses != 1 || mover.updates != tt.steps {
+			t.Errorf("%d steps: press handled %d times with %d updates, want once", tt.steps, toggle.presses, mover.updates)
+		}
+	}
+	if !toggle.toggled {
+		t.Error("three presses left the toggle off")
+	}
+}
+
+func TestInterpolateTransform(t *testing.T) {
+	from := mgl64.Translate3D(0, 0, 0)
+	to := mgl64.Translate3D(2, 4, 0).Mul4(mgl64.HomogRotate3DY(math.Pi / 2)).Mul4(mgl64.Scale3D(3, 3, 3))
+
+	got := InterpolateTransform(from, to, 0.5)
+	want := mgl64.Translate3D(1, 2, 0).Mul4(mgl64.HomogRotate3DY(math.Pi / 4)).Mul4(mgl64.Scale3D(2, 2, 2))
+	if !got.ApproxEqualThreshold(want, 1e-9) {
+		t.Errorf("half way = %v, want %v", got, want)
+	}
+
+	// mirrored transforms keep their handedness
+	mirror := mgl64.Scale3D(-1, 1, 1)
+	if got := InterpolateTransform(mirror, mirror, 0.3); !got.ApproxEqualThreshold(mirror, 1e-9) {
+		t.Errorf("mirror = %v, want %v", got, mirror)
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Triangle is a triangle given by its three corners. Its front face is the
+// one its corners are seen counter-clockwise from.
+type Triangle [3]mgl64.Vec3
+
+// Normal returns the triangle's unit normal, or the zero vector if the
+// triangle is degenerate.
+func (t Triangle) Normal() mgl64.Vec3 {
+	n := t[1].Sub(t[0]).Cross(t[2].Sub(t[0]))
+	if l := n.Len(); l > 0 {
+		return n.Mul(1 / l)
+	}
+	return n
+}
+
+// Plane returns the plane the triangle lies on, with its front face in front.
+func (t Triangle) Plane() mgl64.Vec4 {
+	n := t.Normal()
+	return n.Vec4(-n.Dot(t[0]))
+}
+
+// ClosestPoint returns the point on the triangle closest to the given point
+// and its barycentric weights.
+func (t Triangle) ClosestPoint(p mgl64.Vec3) (mgl64.Vec3, mgl64.Vec3) {
+	a, b, c := t[0], t[1], t[2]
+	ab, ac, ap := b.Sub(a), c.Sub(a), p.Sub(a)
+
+	// vertex and edge regions, in turn
+	d1, d2 := ab.Dot(ap), ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a, mgl64.Vec3{1, 0, 0}
+	}
+
+	bp := p.Sub(b)
+	d3, d4 := ab.Dot(bp), ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b, mgl64.Vec3{0, 1, 0}
+	}
+
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		return a.Add(ab.Mul(v)), mgl64.Vec3{1 - v, v, 0}
+	}
+
+	cp := p.Sub(c)
+	d5, d6 := ab.Dot(cp), ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c, mgl64.Vec3{0, 0, 1}
+	}
+
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		return a.Add(ac.Mul(w)), mgl64.Vec3{1 - w, 0, w}
+	}
+
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		return b.Add(c.Sub(b).Mul(w)), mgl64.Vec3{0, 1 - w, w}
+	}
+
+	// inside the face
+	denom := 1 / (va + vb + vc)
+	v, w := vb*denom, vc*denom
+	return a.Add(ab.Mul(v)).Add(ac.Mul(w)), mgl64.Vec3{1 - v - w, v, w}
+}
+
+// ClassifyPlane returns which side of a plane the triangle is on
+func (t Triangle) ClassifyPlane(plane mgl64.Vec4) PlaneSide {
+	front, back := false, false
+	for _, p := range t {
+		d := plane.Vec3().Dot(p) + plane[3]
+		front, back = front || d > 0, back || d < 0
+	}
+	switch {
+	case front && !back:
+		return PlaneFront
+	case back && !front:
+		return PlaneBack
+	default:
+		return PlaneIntersecting
+	}
+}
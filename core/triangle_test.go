@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestTriangle_Plane(t *testing.T) {
+	tri := Triangle{{0, 2, 0}, {0, 2, 1}, {1, 2, 0}}
+	if n := tri.Normal(); n != (mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("normal = %v, want [0 1 0]", n)
+	}
+	if p := tri.Plane(); p != (mgl64.Vec4{0, 1, 0, -2}) {
+		t.Errorf("plane = %v, want [0 1 0 -2]", p)
+	}
+	if n := (Triangle{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}}).Normal(); n != (mgl64.Vec3{}) {
+		t.Errorf("degenerate normal = %v, want zero", n)
+	}
+}
+
+func TestTriangle_ClosestPoint(t *testing.T) {
+	tri := Triangle{{0, 0, 0}, {4, 0, 0}, {0, 4, 0}}
+
+	tests := []struct {
+		name        string
+		point       mgl64.Vec3
+		want        mgl64.Vec3
+		barycentric mgl64.Vec3
+	}{
+		{"face", mgl64.Vec3{1, 1, 5}, mgl64.Vec3{1, 1, 0}, mgl64.Vec3{0.5, 0.25, 0.25}},
+		{"vertex a", mgl64.Vec3{-1, -1, 0}, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0}},
+		{"vertex b", mgl64.Vec3{6, -1, 0}, mgl64.Vec3{4, 0, 0}, mgl64.Vec3{0, 1, 0}},
+		{"vertex c", mgl64.Vec3{-1, 6, 2}, mgl64.Vec3{0, 4, 0}, mgl64.Vec3{0, 0, 1}},
+		{"edge ab", mgl64.Vec3{1, -3, 0}, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0.75, 0.25, 0}},
+		{"edge ac", mgl64.Vec3{-2, 3, 0}, mgl64.Vec3{0, 3, 0}, mgl64.Vec3{0.25, 0, 0.75}},
+		{"edge bc", mgl64.Vec3{3, 3, 0}, mgl64.Vec3{2, 2, 0}, mgl64.Vec3{0, 0.5, 0.5}},
+	}
+
+	for _, tt := range tests {
+		got, barycentric := tri.ClosestPoint(tt.point)
+		if !vecNear(got, tt.want) || !vecNear(barycentric, tt.barycentric) {
+			t.Errorf("%s: ClosestPoint = %v %v, want %v %v", tt.name, got, barycentric, tt.want, tt.barycentric)
+		}
+	}
+}
+
+func TestTriangle_ClassifyPlane(t *testing.T) {
+	tri := Triangle{{0, 0, 0}, {4, 0, 0}, {0, 4, 0}}
+
+	tests := []struct {
+		plane mgl64.Vec4
+		want  PlaneSide
+	}{
+		{mgl64.Vec4{0, 0, 1, 1}, PlaneFront},
+		{mgl64.Vec4{1, 0, 0, 1}, PlaneFront},
+		{mgl64.Vec4{1, 0, 0, -5}, PlaneBack},
+		{mgl64.Vec4{1, 0, 0, -2}, PlaneIntersecting},
+	}
+
+	for _, tt := range tests {
+		if got := tri.ClassifyPlane(tt.plane); got != tt.want {
+			t.Errorf("ClassifyPlane(%v) = %v, want %v", tt.plane, got, tt.want)
+		}
+	}
+}
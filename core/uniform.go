@@ -1,10 +1,6 @@
 package core
 
-import (
-	"unsafe"
-
-	"github.com/fcvarela/gosg/gpu"
-)
+import "unsafe"
 
 // Uniform holds a shader uniform value.
 type Uniform struct {
@@ -33,24 +29,37 @@ func (u *Uniform) Copy() *Uniform {
 
 // UniformBuffer holds a GPU uniform buffer.
 type UniformBuffer struct {
-	buffer gpu.Buffer
+	engine *Engine
+	buffer BufferHandle
 	size   uint64
 }
 
-// NewUniformBuffer creates a new empty uniform buffer.
+// NewUniformBuffer creates a new empty uniform buffer on the default engine.
 func NewUniformBuffer() *UniformBuffer {
-	return &UniformBuffer{}
+	return &UniformBuffer{engine: defaultEngine}
+}
+
+// setEngine moves the buffer to another engine. The backend buffer belongs to
+// the previous engine's renderer and is recreated on the next Set.
+func (ub *UniformBuffer) setEngine(e *Engine) {
+	if ub.engine == e {
+		return
+	}
+	releaseHandle(ub.buffer)
+	ub.buffer = nil
+	ub.size = 0
+	ub.engine = e
 }
 
 // Set uploads data to the uniform buffer, creating/resizing as needed.
 func (ub *UniformBuffer) Set(data unsafe.Pointer, dataLen int) {
 	size := uint64(dataLen)
 	if ub.size < size {
-		ub.buffer.Release()
-		ub.buffer = renderer.device.CreateBuffer(size, gpu.BufferUsageUniform|gpu.BufferUsageCopyDst)
+		releaseHandle(ub.buffer)
+		ub.buffer = ub.engine.renderer.backend.CreateBuffer(size, BufferUsageUniform|BufferUsageCopyDst)
 		ub.size = size
 	}
-	renderer.queue.WriteBuffer(ub.buffer, 0, data, size)
+	ub.engine.renderer.backend.WriteBuffer(ub.buffer, 0, data, size)
 }
 
 // Lt is used for sorting.
@@ -1,7 +1,41 @@
 package core
 
+import "github.com/go-gl/mathgl/mgl64"
+
 // Updater is an interface that wraps updating a node.
 type Updater interface {
 	// Run updates a scenegraph node.
 	Run(*Node)
 }
+
+// TimedUpdater is an Updater which needs the time since the last update.
+// Nodes call RunTimed instead of Run on update components implementing it.
+type TimedUpdater interface {
+	Updater
+
+	// RunTimed updates a scenegraph node. dt is the time since the last
+	// update in seconds.
+	RunTimed(node *Node, dt float64)
+}
+
+// Rotator is a TimedUpdater which spins a node around an axis.
+type Rotator struct {
+	Speed float64    `yaml:"speed"` // degrees per second
+	Axis  [3]float64 `yaml:"axis"`
+}
+
+// Run implements the Updater interface, turning the node by the default
+// engine's last frame time. Nodes call RunTimed with their own instead.
+func (r *Rotator) Run(node *Node) {
+	r.RunTimed(node, GetTimerManager().Dt())
+}
+
+// RunTimed implements the TimedUpdater interface.
+func (r *Rotator) RunTimed(node *Node, dt float64) {
+	node.Rotate(r.Speed*dt, mgl64.Vec3(r.Axis))
+}
+
+// Params implements the ParameterizedComponent interface.
+func (r *Rotator) Params() ComponentParams {
+	return ComponentParams{"speed": r.Speed, "axis": r.Axis}
+}
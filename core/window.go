@@ -9,6 +9,7 @@ import "C"
 
 import (
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -21,28 +22,58 @@ type WindowConfig struct {
 	Name              string
 	MonitorIndex      int
 	Width, Height, Hz int
-	Fullscreen        bool
 	Vsync             int
+
+	// Fullscreen switches the monitor to the video mode closest to Width,
+	// Height and Hz. Borderless instead covers the monitor with a borderless
+	// window at its desktop mode.
+	Fullscreen bool
+	Borderless bool
+
+	// Resizable lets the user resize the window.
+	Resizable bool
+
+	// Headless opens no window and uses a recording renderer instead of the GPU.
+	// Width and Height are still used as the virtual framebuffer size.
+	Headless bool
 }
 
-// WindowManager exposes windowing to client applications.
+// windowBackend is the platform layer behind WindowManager.
+type windowBackend interface {
+	open(w *WindowManager) error
+	openWindow(win *Window) error
+	destroyWindow(win *Window)
+	setWindowMode(win *Window, cfg WindowConfig) error
+	monitors() []Monitor
+	pollEvents(w *WindowManager)
+	close()
+	time() float64
+	setTextInput(active bool)
+	keyLabel(key Key) string
+	rumbleGamepad(id GamepadID, low, high float64, duration time.Duration) bool
+}
+
+// WindowManager exposes windowing to client applications. It embeds the main
+// window.
 type WindowManager struct {
-	window         *C.SDL_Window
-	metalView      C.SDL_MetalView
-	cfg            WindowConfig
-	pixelWidth     int
-	pixelHeight    int
+	Window
+	engine         *Engine
+	backend        windowBackend
 	cursorPosition mgl64.Vec2
-	shouldClose    bool
+	textInput      bool
+
+	// secondary windows
+	windows []*Window
 }
 
 var (
-	windowManager *WindowManager
 	windowInitErr error
 )
 
-func init() {
-	windowManager = &WindowManager{}
+func newWindowManager(e *Engine) *WindowManager {
+	w := &WindowManager{engine: e, backend: &sdlWindow{}}
+	w.manager = w
+	return w
 }
 
 // InitWindowManager initializes SDL. Call before using WindowManager.
@@ -50,16 +81,16 @@ func InitWindowManager() error {
 	if windowInitErr != nil {
 		return windowInitErr
 	}
-	if C.SDL_Init(C.SDL_INIT_VIDEO|C.SDL_INIT_EVENTS) == false {
+	if C.SDL_Init(C.SDL_INIT_VIDEO|C.SDL_INIT_EVENTS|C.SDL_INIT_GAMEPAD) == false {
 		windowInitErr = fmt.Errorf("SDL_Init failed: %s", C.GoString(C.SDL_GetError()))
 		return windowInitErr
 	}
 	return nil
 }
 
-// GetWindowManager returns the global WindowManager.
+// GetWindowManager returns the default engine's WindowManager.
 func GetWindowManager() *WindowManager {
-	return windowManager
+	return defaultEngine.windowManager
 }
 
 // SetWindowConfig sets the config for WindowManager created windows.
@@ -67,142 +98,254 @@ func (w *WindowManager) SetWindowConfig(cfg WindowConfig) {
 	w.cfg = cfg
 }
 
+// Headless returns whether the window manager runs without a window.
+func (w *WindowManager) Headless() bool {
+	return w.cfg.Headless
+}
+
 // MakeWindow creates the window using the previously passed config.
 func (w *WindowManager) MakeWindow() error {
-	glog.Info("Creating SDL3 window")
-
-	flags := C.SDL_WINDOW_METAL | C.SDL_WINDOW_HIGH_PIXEL_DENSITY
-	if w.cfg.Fullscreen {
-		flags |= C.SDL_WINDOW_FULLSCREEN
-
-		// Use native display resolution
-		displayID := C.SDL_GetPrimaryDisplay()
-		mode := C.SDL_GetDesktopDisplayMode(displayID)
-		if mode != nil {
-			w.cfg.Width = int(mode.w)
-			w.cfg.Height = int(mode.h)
-		}
+	if w.cfg.Headless {
+		w.backend = &headlessWindow{}
 	}
+	return w.backend.open(w)
+}
 
-	w.window = C.SDL_CreateWindow(
-		C.CString(w.cfg.Name),
-		C.int(w.cfg.Width), C.int(w.cfg.Height),
-		C.Uint64(flags),
-	)
-	if w.window == nil {
-		return fmt.Errorf("SDL_CreateWindow failed: %s", C.GoString(C.SDL_GetError()))
-	}
+// PollEvents processes all pending window events and dispatches to InputManager.
+func (w *WindowManager) PollEvents() {
+	w.backend.pollEvents(w)
+}
 
-	// Hide cursor and enable relative mouse mode
-	C.SDL_SetWindowRelativeMouseMode(w.window, true)
+// CursorPosition reports the current cursor position in window coordinates.
+func (w *WindowManager) CursorPosition() (float64, float64) {
+	return w.cursorPosition.X(), w.cursorPosition.Y()
+}
 
-	// Create Metal view for wgpu surface
-	w.metalView = C.SDL_Metal_CreateView(w.window)
-	if w.metalView == nil {
-		return fmt.Errorf("SDL_Metal_CreateView failed: %s", C.GoString(C.SDL_GetError()))
+// StartTextInput starts delivering typed text to the InputManager, eg: while a
+// text field has focus. On some platforms this shows an on-screen keyboard.
+func (w *WindowManager) StartTextInput() {
+	if !w.textInput {
+		w.textInput = true
+		w.backend.setTextInput(true)
 	}
+}
 
-	// In fullscreen mode, pump events to let macOS finish the fullscreen
-	// transition (notch animation, etc.) before querying the final size.
-	if w.cfg.Fullscreen {
-		C.SDL_SyncWindow(w.window)
+// StopTextInput stops delivering typed text to the InputManager.
+func (w *WindowManager) StopTextInput() {
+	if w.textInput {
+		w.textInput = false
+		w.backend.setTextInput(false)
 	}
+}
 
-	// Query the actual pixel dimensions after the window is fully set up
-	var pw, ph C.int
-	C.SDL_GetWindowSizeInPixels(w.window, &pw, &ph)
-	w.pixelWidth = int(pw)
-	w.pixelHeight = int(ph)
+// TextInputActive returns whether typed text is delivered to the InputManager.
+func (w *WindowManager) TextInputActive() bool {
+	return w.textInput
+}
 
-	// Update point-space dimensions from actual window size
-	var pointW, pointH C.int
-	C.SDL_GetWindowSize(w.window, &pointW, &pointH)
-	if pointW > 0 && pointH > 0 {
-		w.cfg.Width = int(pointW)
-		w.cfg.Height = int(pointH)
+// KeyLabel returns the label of the key at a scancode's position in the
+// current keyboard layout, eg: "Z" for KeyY on a German keyboard.
+func (w *WindowManager) KeyLabel(key Key) string {
+	if label := w.backend.keyLabel(key); label != "" {
+		return label
 	}
+	return key.String()
+}
 
-	return InitRenderer(w.GetMetalLayer(), uint32(w.pixelWidth), uint32(w.pixelHeight))
+func (w *WindowManager) closeWindow() {
+	glog.Info("Stopping")
+	for len(w.windows) > 0 {
+		w.windows[0].Close()
+	}
+	if w.engine.renderer != nil {
+		w.engine.renderer.Shutdown()
+	}
+	w.backend.close()
 }
 
-// GetMetalLayer returns the CAMetalLayer pointer for wgpu surface creation.
+// GetMetalLayer returns the CAMetalLayer pointer for wgpu surface creation,
+// or nil when running headless.
 func (w *WindowManager) GetMetalLayer() unsafe.Pointer {
-	return C.SDL_Metal_GetLayer(w.metalView)
+	if s, ok := w.backend.(*sdlWindow); ok && s.metalView != nil {
+		return C.SDL_Metal_GetLayer(s.metalView)
+	}
+	return nil
+}
+
+// time returns the backend clock in seconds (used by TimerManager).
+func (w *WindowManager) time() float64 {
+	return w.backend.time()
 }
 
 // WindowSize returns the window size in pixels (for framebuffers/viewports).
-func (w *WindowManager) WindowSize() mgl32.Vec2 {
+func (w *Window) WindowSize() mgl32.Vec2 {
 	return mgl32.Vec2{float32(w.pixelWidth), float32(w.pixelHeight)}
 }
 
 // WindowSizePoints returns the window size in points (for UI layout).
-func (w *WindowManager) WindowSizePoints() mgl32.Vec2 {
+func (w *Window) WindowSizePoints() mgl32.Vec2 {
 	return mgl32.Vec2{float32(w.cfg.Width), float32(w.cfg.Height)}
 }
 
 // PixelDensity returns the ratio of pixels to points (e.g., 2.0 on Retina).
-func (w *WindowManager) PixelDensity() float32 {
+func (w *Window) PixelDensity() float32 {
 	if w.cfg.Width == 0 {
 		return 1.0
 	}
 	return float32(w.pixelWidth) / float32(w.cfg.Width)
 }
-func (w *WindowManager) ShouldClose() bool {
+
+// ShouldClose returns whether the user requested the window to close.
+// Secondary windows stay open until they are closed with Close.
+func (w *Window) ShouldClose() bool {
 	return w.shouldClose
 }
 
-// PollEvents processes all pending SDL events and dispatches to InputManager.
-func (w *WindowManager) PollEvents() {
+// sdlWindow is the SDL3/Metal window backend.
+type sdlWindow struct {
+	window    *C.SDL_Window
+	metalView C.SDL_MetalView
+	gamepads  map[GamepadID]*C.SDL_Gamepad
+	secondary map[*Window]*sdlSecondaryWindow
+}
+
+func (s *sdlWindow) open(w *WindowManager) error {
+	glog.Info("Creating SDL3 window")
+
+	width, height := sdlWindowSize(w.cfg)
+	s.window = C.SDL_CreateWindow(
+		C.CString(w.cfg.Name),
+		width, height,
+		sdlWindowFlags(w.cfg),
+	)
+	if s.window == nil {
+		return fmt.Errorf("SDL_CreateWindow failed: %s", C.GoString(C.SDL_GetError()))
+	}
+
+	// Hide cursor and enable relative mouse mode
+	C.SDL_SetWindowRelativeMouseMode(s.window, true)
+
+	// Create Metal view for wgpu surface
+	s.metalView = C.SDL_Metal_CreateView(s.window)
+	if s.metalView == nil {
+		return fmt.Errorf("SDL_Metal_CreateView failed: %s", C.GoString(C.SDL_GetError()))
+	}
+
+	// Move to the configured monitor and switch to fullscreen modes, this
+	// waits for the transition (notch animation, etc.) to finish.
+	if w.cfg.mode() != WindowModeWindowed || w.cfg.MonitorIndex != 0 {
+		if err := s.setWindowMode(&w.Window, w.cfg); err != nil {
+			return err
+		}
+	}
+
+	// Query the actual pixel dimensions after the window is fully set up
+	var pw, ph C.int
+	C.SDL_GetWindowSizeInPixels(s.window, &pw, &ph)
+	w.pixelWidth = int(pw)
+	w.pixelHeight = int(ph)
+
+	// Update point-space dimensions from actual window size
+	var pointW, pointH C.int
+	C.SDL_GetWindowSize(s.window, &pointW, &pointH)
+	if pointW > 0 && pointH > 0 {
+		w.cfg.Width = int(pointW)
+		w.cfg.Height = int(pointH)
+	}
+
+	return w.engine.InitRenderer(C.SDL_Metal_GetLayer(s.metalView), uint32(w.pixelWidth), uint32(w.pixelHeight))
+}
+
+func (s *sdlWindow) pollEvents(w *WindowManager) {
 	var event C.SDL_Event
 	for C.SDL_PollEvent(&event) != false {
 		eventType := *(*C.Uint32)(unsafe.Pointer(&event))
+		timestamp := (*C.SDL_CommonEvent)(unsafe.Pointer(&event)).timestamp
+		w.engine.inputManager.eventTime = float64(timestamp) / 1e9
 		switch eventType {
 		case C.SDL_EVENT_QUIT:
 			w.shouldClose = true
-		case C.SDL_EVENT_WINDOW_CLOSE_REQUESTED:
-			w.shouldClose = true
+		case C.SDL_EVENT_WINDOW_CLOSE_REQUESTED,
+			C.SDL_EVENT_WINDOW_RESIZED, C.SDL_EVENT_WINDOW_PIXEL_SIZE_CHANGED, C.SDL_EVENT_WINDOW_DISPLAY_SCALE_CHANGED,
+			C.SDL_EVENT_WINDOW_FOCUS_GAINED, C.SDL_EVENT_WINDOW_FOCUS_LOST,
+			C.SDL_EVENT_WINDOW_MINIMIZED, C.SDL_EVENT_WINDOW_RESTORED, C.SDL_EVENT_WINDOW_MAXIMIZED,
+			C.SDL_EVENT_DROP_FILE:
+			s.windowEvent(w, &event, eventType)
 		case C.SDL_EVENT_KEY_DOWN:
 			ke := (*C.SDL_KeyboardEvent)(unsafe.Pointer(&event))
-			inputManager.HandleKeyEvent(Key(ke.scancode), true)
+			w.engine.inputManager.HandleKeyMods(KeyMod(ke.mod))
+			w.engine.inputManager.HandleKeyEvent(Key(ke.scancode), true)
 		case C.SDL_EVENT_KEY_UP:
 			ke := (*C.SDL_KeyboardEvent)(unsafe.Pointer(&event))
-			inputManager.HandleKeyEvent(Key(ke.scancode), false)
+			w.engine.inputManager.HandleKeyMods(KeyMod(ke.mod))
+			w.engine.inputManager.HandleKeyEvent(Key(ke.scancode), false)
+		case C.SDL_EVENT_TEXT_INPUT:
+			te := (*C.SDL_TextInputEvent)(unsafe.Pointer(&event))
+			w.engine.inputManager.HandleTextInput(C.GoString(te.text))
 		case C.SDL_EVENT_MOUSE_BUTTON_DOWN:
 			me := (*C.SDL_MouseButtonEvent)(unsafe.Pointer(&event))
-			inputManager.HandleMouseButton(MouseButton(me.button), true)
+			w.engine.inputManager.HandleMouseButton(MouseButton(me.button), true)
 		case C.SDL_EVENT_MOUSE_BUTTON_UP:
 			me := (*C.SDL_MouseButtonEvent)(unsafe.Pointer(&event))
-			inputManager.HandleMouseButton(MouseButton(me.button), false)
+			w.engine.inputManager.HandleMouseButton(MouseButton(me.button), false)
 		case C.SDL_EVENT_MOUSE_MOTION:
 			me := (*C.SDL_MouseMotionEvent)(unsafe.Pointer(&event))
-			inputManager.HandleMouseMove(float64(me.x), float64(me.y), float64(me.xrel), float64(me.yrel))
+			w.engine.inputManager.HandleMouseMove(float64(me.x), float64(me.y), float64(me.xrel), float64(me.yrel))
 		case C.SDL_EVENT_MOUSE_WHEEL:
 			me := (*C.SDL_MouseWheelEvent)(unsafe.Pointer(&event))
-			inputManager.HandleMouseScroll(float64(me.x), float64(me.y))
+			w.engine.inputManager.HandleMouseScroll(float64(me.x), float64(me.y))
+		case C.SDL_EVENT_GAMEPAD_ADDED:
+			ge := (*C.SDL_GamepadDeviceEvent)(unsafe.Pointer(&event))
+			s.gamepadAdded(w, ge.which)
+		case C.SDL_EVENT_GAMEPAD_REMOVED:
+			ge := (*C.SDL_GamepadDeviceEvent)(unsafe.Pointer(&event))
+			s.gamepadRemoved(w, ge.which)
+		case C.SDL_EVENT_GAMEPAD_BUTTON_DOWN:
+			ge := (*C.SDL_GamepadButtonEvent)(unsafe.Pointer(&event))
+			w.engine.inputManager.HandleGamepadButton(GamepadID(ge.which), gamepadButtonFromSDL(ge.button), true)
+		case C.SDL_EVENT_GAMEPAD_BUTTON_UP:
+			ge := (*C.SDL_GamepadButtonEvent)(unsafe.Pointer(&event))
+			w.engine.inputManager.HandleGamepadButton(GamepadID(ge.which), gamepadButtonFromSDL(ge.button), false)
+		case C.SDL_EVENT_GAMEPAD_AXIS_MOTION:
+			ge := (*C.SDL_GamepadAxisEvent)(unsafe.Pointer(&event))
+			w.engine.inputManager.HandleGamepadAxis(GamepadID(ge.which), GamepadAxis(ge.axis), gamepadAxisFromSDL(int16(ge.value)))
 		}
 	}
 }
 
-// CursorPosition reports the current cursor position in window coordinates.
-func (w *WindowManager) CursorPosition() (float64, float64) {
-	return w.cursorPosition.X(), w.cursorPosition.Y()
-}
-
-func (w *WindowManager) closeWindow() {
-	glog.Info("Stopping")
-	if renderer != nil {
-		renderer.Shutdown()
+func (s *sdlWindow) close() {
+	for id, g := range s.gamepads {
+		C.SDL_CloseGamepad(g)
+		delete(s.gamepads, id)
 	}
-	if w.metalView != nil {
-		C.SDL_Metal_DestroyView(w.metalView)
+	if s.metalView != nil {
+		C.SDL_Metal_DestroyView(s.metalView)
 	}
-	if w.window != nil {
-		C.SDL_DestroyWindow(w.window)
+	if s.window != nil {
+		C.SDL_DestroyWindow(s.window)
 	}
 	C.SDL_Quit()
 }
 
-// sdlGetTimeSeconds returns SDL time in seconds (used by TimerManager).
-func sdlGetTimeSeconds() float64 {
+func (s *sdlWindow) setTextInput(active bool) {
+	if s.window == nil {
+		return
+	}
+	if active {
+		C.SDL_StartTextInput(s.window)
+	} else {
+		C.SDL_StopTextInput(s.window)
+	}
+}
+
+// keyLabel returns the name of the keycode a scancode produces in the
+// current layout.
+func (s *sdlWindow) keyLabel(key Key) string {
+	keycode := C.SDL_GetKeyFromScancode(C.SDL_Scancode(key), 0, false)
+	return C.GoString(C.SDL_GetKeyName(keycode))
+}
+
+// time returns SDL time in seconds.
+func (s *sdlWindow) time() float64 {
 	return float64(C.SDL_GetTicks()) / 1000.0
 }
@@ -0,0 +1,143 @@
+package core
+
+// WindowEventType is the kind of a window event.
+type WindowEventType int
+
+// Window event types
+const (
+	// WindowEventResized is sent when the window's size in pixels changes.
+	// Its surface, and for the main window the window-sized render targets,
+	// were already resized.
+	WindowEventResized WindowEventType = iota
+
+	// WindowEventPixelDensityChanged is sent when the window's pixel
+	// density changes, eg: when it moves to a display with another scale.
+	WindowEventPixelDensityChanged
+
+	// WindowEventFocusGained is sent when the window gains keyboard focus.
+	WindowEventFocusGained
+
+	// WindowEventFocusLost is sent when the window loses keyboard focus.
+	WindowEventFocusLost
+
+	// WindowEventMinimized is sent when the window is minimized. While the
+	// main window is minimized frames are updated but not drawn.
+	WindowEventMinimized
+
+	// WindowEventRestored is sent when the window is restored after being
+	// minimized.
+	WindowEventRestored
+
+	// WindowEventFileDropped is sent for each file dropped on the window.
+	WindowEventFileDropped
+)
+
+var windowEventTypeNames = map[WindowEventType]string{
+	WindowEventResized:             "resized",
+	WindowEventPixelDensityChanged: "pixelDensityChanged",
+	WindowEventFocusGained:         "focusGained",
+	WindowEventFocusLost:           "focusLost",
+	WindowEventMinimized:           "minimized",
+	WindowEventRestored:            "restored",
+	WindowEventFileDropped:         "fileDropped",
+}
+
+func (t WindowEventType) String() string {
+	return windowEventTypeNames[t]
+}
+
+// WindowEvent is a change to the window's state.
+type WindowEvent struct {
+	Type WindowEventType
+
+	// Width and Height are the window size in pixels.
+	Width, Height int
+
+	// PixelDensity is the ratio of pixels to points.
+	PixelDensity float32
+
+	// Path is the dropped file's path, for WindowEventFileDropped.
+	Path string
+}
+
+// WindowEventHandler is called with the window events handled by PollEvents.
+type WindowEventHandler func(WindowEvent)
+
+// AddEventHandler adds a handler called for every window event. Handlers run
+// during PollEvents, in the order they were added.
+func (w *Window) AddEventHandler(h WindowEventHandler) {
+	w.eventHandlers = append(w.eventHandlers, h)
+}
+
+// Focused returns whether the window has keyboard focus.
+func (w *Window) Focused() bool {
+	return !w.unfocused
+}
+
+// Minimized returns whether the window is minimized.
+func (w *Window) Minimized() bool {
+	return w.minimized
+}
+
+// handleResize updates the window size, resizes its surface, and notifies
+// the handlers. Sizes of zero, sent
+// by some platforms while minimizing, are ignored.
+func (w *Window) handleResize(width, height, pixelWidth, pixelHeight int) {
+	if width <= 0 || height <= 0 || pixelWidth <= 0 || pixelHeight <= 0 {
+		return
+	}
+
+	density := w.PixelDensity()
+	resized := pixelWidth != w.pixelWidth || pixelHeight != w.pixelHeight
+
+	w.cfg.Width, w.cfg.Height = width, height
+	w.pixelWidth, w.pixelHeight = pixelWidth, pixelHeight
+
+	if resized {
+		w.resizeSurface(uint32(pixelWidth), uint32(pixelHeight))
+		w.dispatch(WindowEvent{Type: WindowEventResized})
+	}
+	if w.PixelDensity() != density {
+		w.dispatch(WindowEvent{Type: WindowEventPixelDensityChanged})
+	}
+}
+
+// handleFocus records focus changes and notifies the handlers.
+func (w *Window) handleFocus(focused bool) {
+	if focused == w.Focused() {
+		return
+	}
+	w.unfocused = !focused
+	if focused {
+		w.dispatch(WindowEvent{Type: WindowEventFocusGained})
+	} else {
+		w.dispatch(WindowEvent{Type: WindowEventFocusLost})
+	}
+}
+
+// handleMinimized records minimizing and restoring and notifies the handlers.
+func (w *Window) handleMinimized(minimized bool) {
+	if minimized == w.minimized {
+		return
+	}
+	w.minimized = minimized
+	if minimized {
+		w.dispatch(WindowEvent{Type: WindowEventMinimized})
+	} else {
+		w.dispatch(WindowEvent{Type: WindowEventRestored})
+	}
+}
+
+// handleFileDrop notifies the handlers of a file dropped on the window.
+func (w *Window) handleFileDrop(path string) {
+	w.dispatch(WindowEvent{Type: WindowEventFileDropped, Path: path})
+}
+
+// dispatch fills in the window's size and calls the handlers.
+func (w *Window) dispatch(e WindowEvent) {
+	e.Width, e.Height = w.pixelWidth, w.pixelHeight
+	e.PixelDensity = w.PixelDensity()
+	for _, h := range w.eventHandlers {
+		h(e)
+	}
+}
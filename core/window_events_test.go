@@ -0,0 +1,142 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// windowEventClient records the window events passed to its hooks.
+type windowEventClient struct {
+	headlessTestApp
+	sizes   [][2]int
+	focused []bool
+	dropped []string
+	events  func()
+}
+
+func (c *windowEventClient) InputComponent() ClientApplicationInputComponent { return c }
+
+func (c *windowEventClient) Run() []ClientApplicationCommand {
+	if c.frames == 0 {
+		c.events()
+	}
+	return c.headlessTestApp.Run()
+}
+
+func (c *windowEventClient) OnResize(width, height int) {
+	c.sizes = append(c.sizes, [2]int{width, height})
+}
+func (c *windowEventClient) OnFocusChanged(focused bool) { c.focused = append(c.focused, focused) }
+func (c *windowEventClient) OnFileDropped(path string)   { c.dropped = append(c.dropped, path) }
+
+func TestWindowResize(t *testing.T) {
+	e := newRoundTripEngine(t)
+	wm := e.WindowManager()
+	wm.handleResize(32, 32, 64, 64)
+
+	handlers := len(wm.eventHandlers)
+	scene, err := loadSceneFromYAML(e, "", []byte(roundTripScene))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(wm.eventHandlers) != handlers {
+		t.Errorf("loading a scene added %d window event handlers, want none", len(wm.eventHandlers)-handlers)
+	}
+	color := scene.Camera("GeometryPassCamera").Framebuffer().ColorAttachment(0)
+	quad := scene.Root().Children()[1].Mesh()
+	post := scene.Camera("PostCamera")
+	id := color.ID()
+
+	var events []WindowEventType
+	wm.AddEventHandler(func(ev WindowEvent) { events = append(events, ev.Type) })
+
+	wm.handleResize(100, 50, 300, 150)
+	if w, h := e.Renderer().backend.SurfaceSize(); w != 300 || h != 150 {
+		t.Errorf("surface = %dx%d, want 300x150", w, h)
+	}
+	if d := color.Descriptor(); d.Width != 300 || d.Height != 150 || color.ID() != id {
+		t.Errorf("color attachment = %dx%d id %d, want 300x150 id %d", d.Width, d.Height, color.ID(), id)
+	}
+	if max := quad.Bounds().Max(); max.X() != 300 || max.Y() != 150 {
+		t.Errorf("screen quad extends to %v, want the new window size", max)
+	}
+	post.Reshape(post.windowSize()) // as the cull does
+	if vp := post.Viewport(); vp[2] != 300 || vp[3] != 150 {
+		t.Errorf("orthographic camera viewport = %v, want the new window size", vp)
+	}
+	want := []WindowEventType{WindowEventResized, WindowEventPixelDensityChanged}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+
+	// the same size, or a zero size while minimizing, changes nothing
+	events = nil
+	wm.handleResize(100, 50, 300, 150)
+	wm.handleResize(0, 0, 0, 0)
+	if len(events) != 0 || wm.WindowSize().X() != 300 {
+		t.Errorf("events = %v, size = %v, want no change", events, wm.WindowSize())
+	}
+
+	// disposed framebuffers are no longer resized
+	fb := scene.Camera("GeometryPassCamera").Framebuffer()
+	fb.Dispose()
+	if _, ok := e.Renderer().windowTargets[fb]; ok {
+		t.Error("disposed framebuffer is still resized with the window")
+	}
+}
+
+func TestIMGUISceneFitsWindow(t *testing.T) {
+	e := newTestEngine(t)
+	useRecordingRenderer(t, e)
+	wm := e.WindowManager()
+	wm.handleResize(32, 32, 64, 64)
+
+	handlers := len(wm.eventHandlers)
+	scene := e.NewIMGUIScene("ui", nil)
+	if len(wm.eventHandlers) != handlers {
+		t.Errorf("IMGUI scene added %d window event handlers, want none", len(wm.eventHandlers)-handlers)
+	}
+
+	// the viewport covers the window's pixels and the projection its points
+	wm.handleResize(100, 50, 300, 150)
+	scene.cull(e)
+	camera := scene.cameraList[0]
+	if vp := camera.Viewport(); vp[2] != 300 || vp[3] != 150 {
+		t.Errorf("viewport = %v, want the window's pixels", vp)
+	}
+	if p := mgl64.TransformCoordinate(mgl64.Vec3{100, 50, 0}, camera.ProjectionMatrix()); !vecNear(p, mgl64.Vec3{1, -1, 0}) {
+		t.Errorf("bottom right point projects to %v, want the clip space corner", p)
+	}
+}
+
+func TestWindowEventHooks(t *testing.T) {
+	e := newTestEngine(t)
+	e.WindowManager().SetWindowConfig(WindowConfig{Name: "test", Width: 320, Height: 240, Headless: true})
+
+	client := &windowEventClient{headlessTestApp: headlessTestApp{maxFrames: 2}}
+	client.events = func() {
+		wm := e.WindowManager()
+		wm.handleResize(640, 480, 640, 480)
+		wm.handleFocus(false)
+		wm.handleFocus(false)
+		wm.handleFocus(true)
+		wm.handleFileDrop("/tmp/level.yaml")
+		wm.handleMinimized(true)
+	}
+	NewApplication(e).Start(func() ClientApplication { return client })
+
+	if !reflect.DeepEqual(client.sizes, [][2]int{{640, 480}}) {
+		t.Errorf("resizes = %v, want [[640 480]]", client.sizes)
+	}
+	if !reflect.DeepEqual(client.focused, []bool{false, true}) {
+		t.Errorf("focus changes = %v, want [false true]", client.focused)
+	}
+	if !reflect.DeepEqual(client.dropped, []string{"/tmp/level.yaml"}) {
+		t.Errorf("dropped files = %v", client.dropped)
+	}
+	if frames := e.Renderer().backend.(*RecordingBackend).LastFrame().Passes; len(frames) != 0 {
+		t.Errorf("drew %d passes while minimized, want none", len(frames))
+	}
+}
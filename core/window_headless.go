@@ -0,0 +1,110 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// headlessWindow is a window backend that opens nothing. It is used for
+// integration tests and tooling that need the runloop without a display or GPU.
+type headlessWindow struct {
+	start time.Time
+}
+
+func (h *headlessWindow) open(w *WindowManager) error {
+	glog.Info("Creating headless window")
+
+	h.start = time.Now()
+	if w.cfg.mode() != WindowModeWindowed {
+		if err := h.setWindowMode(&w.Window, w.cfg); err != nil {
+			return err
+		}
+	}
+	w.pixelWidth = w.cfg.Width
+	w.pixelHeight = w.cfg.Height
+
+	return w.engine.InitHeadlessRenderer(uint32(w.pixelWidth), uint32(w.pixelHeight))
+}
+
+// openWindow opens a virtual window with a recorded surface.
+func (h *headlessWindow) openWindow(win *Window) error {
+	if win.cfg.mode() != WindowModeWindowed {
+		if err := h.setWindowMode(win, win.cfg); err != nil {
+			return err
+		}
+	}
+	win.pixelWidth = win.cfg.Width
+	win.pixelHeight = win.cfg.Height
+
+	surface, err := win.manager.engine.renderer.backend.CreateSurface(nil, uint32(win.pixelWidth), uint32(win.pixelHeight))
+	if err != nil {
+		return err
+	}
+	win.surface = surface
+	return nil
+}
+
+func (h *headlessWindow) destroyWindow(win *Window) {}
+
+// setWindowMode resizes the virtual window as its mode would on the virtual
+// monitor.
+func (h *headlessWindow) setWindowMode(win *Window, cfg WindowConfig) error {
+	width, height := cfg.Width, cfg.Height
+	switch cfg.mode() {
+	case WindowModeFullscreen:
+		mode := headlessMonitor.ClosestMode(cfg.Width, cfg.Height, float32(cfg.Hz))
+		width, height = mode.Width, mode.Height
+	case WindowModeBorderless:
+		width, height = headlessMonitor.Width, headlessMonitor.Height
+	}
+	if win.pixelWidth == 0 {
+		win.cfg.Width, win.cfg.Height = width, height
+		return nil
+	}
+	win.handleResize(width, height, width, height)
+	return nil
+}
+
+// headlessMonitor is the single monitor of headless window managers.
+var headlessMonitor = Monitor{
+	Name:         "headless",
+	Primary:      true,
+	Width:        1920,
+	Height:       1080,
+	ContentScale: 1,
+	Desktop:      VideoMode{Width: 1920, Height: 1080, PixelDensity: 1, Hz: 60},
+	Modes: []VideoMode{
+		{Width: 1920, Height: 1080, PixelDensity: 1, Hz: 60},
+		{Width: 1280, Height: 720, PixelDensity: 1, Hz: 60},
+		{Width: 1280, Height: 720, PixelDensity: 1, Hz: 30},
+	},
+}
+
+func (h *headlessWindow) monitors() []Monitor {
+	m := headlessMonitor
+	m.Modes = slices.Clone(m.Modes)
+	return []Monitor{m}
+}
+
+func (h *headlessWindow) pollEvents(w *WindowManager) {}
+
+func (h *headlessWindow) close() {}
+
+func (h *headlessWindow) setTextInput(active bool) {}
+
+func (h *headlessWindow) keyLabel(key Key) string {
+	return ""
+}
+
+func (h *headlessWindow) rumbleGamepad(id GamepadID, low, high float64, duration time.Duration) bool {
+	return false
+}
+
+func (h *headlessWindow) time() float64 {
+	if h.start.IsZero() {
+		return 0.0
+	}
+	return time.Since(h.start).Seconds()
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Window is an operating system window with its own surface. The main window
+// is embedded in the WindowManager. Secondary windows, eg: a tools inspector
+// next to the game, are opened with OpenWindow and cameras draw into them
+// with Camera.SetWindow.
+type Window struct {
+	manager       *WindowManager
+	cfg           WindowConfig
+	pixelWidth    int
+	pixelHeight   int
+	unfocused     bool
+	minimized     bool
+	shouldClose   bool
+	closed        bool
+	eventHandlers []WindowEventHandler
+
+	// surface is nil for the main window, which uses the renderer's
+	surface SurfaceHandle
+}
+
+// WindowMode is how a window covers its monitor.
+type WindowMode int
+
+// Window modes
+const (
+	WindowModeWindowed WindowMode = iota
+	WindowModeFullscreen
+	WindowModeBorderless
+)
+
+var windowModeNames = map[WindowMode]string{
+	WindowModeWindowed:   "windowed",
+	WindowModeFullscreen: "fullscreen",
+	WindowModeBorderless: "borderless",
+}
+
+func (m WindowMode) String() string {
+	return windowModeNames[m]
+}
+
+// mode returns the window mode the config requests.
+func (c WindowConfig) mode() WindowMode {
+	switch {
+	case c.Fullscreen:
+		return WindowModeFullscreen
+	case c.Borderless:
+		return WindowModeBorderless
+	}
+	return WindowModeWindowed
+}
+
+// VideoMode is a monitor resolution and refresh rate.
+type VideoMode struct {
+	// Width and Height are in points, PixelDensity is the ratio of pixels
+	// to points.
+	Width, Height int
+	PixelDensity  float32
+	Hz            float32
+}
+
+// Monitor is a display connected to the system.
+type Monitor struct {
+	// Index is the monitor's position in Monitors, as used by
+	// WindowConfig.MonitorIndex.
+	Index   int
+	Name    string
+	Primary bool
+
+	// X, Y, Width and Height are the monitor's bounds on the desktop, in
+	// points.
+	X, Y, Width, Height int
+
+	// ContentScale is the scale the desktop uses for content on the
+	// monitor, eg: 1.5 for 150%.
+	ContentScale float32
+
+	// Desktop is the monitor's desktop video mode, Modes lists the video
+	// modes usable in fullscreen, largest first.
+	Desktop VideoMode
+	Modes   []VideoMode
+}
+
+// ClosestMode returns the monitor's fullscreen video mode closest to a size
+// and refresh rate. Zero values stand for the desktop mode's.
+func (m Monitor) ClosestMode(width, height int, hz float32) VideoMode {
+	if width == 0 || height == 0 {
+		width, height = m.Desktop.Width, m.Desktop.Height
+	}
+	if hz == 0 {
+		hz = m.Desktop.Hz
+	}
+
+	best := m.Desktop
+	bestSize, bestHz := math.MaxInt, float32(math.MaxFloat32)
+	for _, mode := range m.Modes {
+		dw, dh := mode.Width-width, mode.Height-height
+		size := dw*dw + dh*dh
+		dhz := float32(math.Abs(float64(mode.Hz - hz)))
+		if size < bestSize || size == bestSize && dhz < bestHz {
+			best, bestSize, bestHz = mode, size, dhz
+		}
+	}
+	return best
+}
+
+// Monitors returns the connected monitors, in the order WindowConfig's
+// MonitorIndex uses.
+func (w *WindowManager) Monitors() []Monitor {
+	return w.backend.monitors()
+}
+
+// MainWindow returns the main window.
+func (w *WindowManager) MainWindow() *Window {
+	return &w.Window
+}
+
+// OpenWindow opens a secondary window with its own surface. The main window
+// must be open; headless window managers open virtual windows.
+func (w *WindowManager) OpenWindow(cfg WindowConfig) (*Window, error) {
+	if w.engine.renderer == nil {
+		return nil, errors.New("cannot open a window before the main window")
+	}
+	cfg.Headless = w.cfg.Headless
+
+	win := &Window{manager: w, cfg: cfg}
+	if err := w.backend.openWindow(win); err != nil {
+		return nil, fmt.Errorf("cannot open window %q: %w", cfg.Name, err)
+	}
+	w.windows = append(w.windows, win)
+	return win, nil
+}
+
+// Windows returns the open secondary windows.
+func (w *WindowManager) Windows() []*Window {
+	return w.windows
+}
+
+// Name returns the window's name.
+func (w *Window) Name() string {
+	return w.cfg.Name
+}
+
+// Mode returns how the window covers its monitor.
+func (w *Window) Mode() WindowMode {
+	return w.cfg.mode()
+}
+
+// Monitor returns the index of the monitor the window was last placed on.
+func (w *Window) Monitor() int {
+	return w.cfg.MonitorIndex
+}
+
+// SetWindowed makes the window a regular window of a size in points.
+func (w *Window) SetWindowed(width, height int) error {
+	cfg := w.cfg
+	cfg.Fullscreen, cfg.Borderless = false, false
+	cfg.Width, cfg.Height = width, height
+	return w.setMode(cfg)
+}
+
+// SetFullscreen makes the window fullscreen on a monitor, switching it to its
+// video mode closest to the given one. A zero mode keeps the desktop mode.
+func (w *Window) SetFullscreen(monitor int, mode VideoMode) error {
+	cfg := w.cfg
+	cfg.Fullscreen, cfg.Borderless = true, false
+	cfg.MonitorIndex = monitor
+	cfg.Width, cfg.Height, cfg.Hz = mode.Width, mode.Height, int(math.Round(float64(mode.Hz)))
+	return w.setMode(cfg)
+}
+
+// SetBorderless makes the window a borderless window covering a monitor at
+// its desktop mode.
+func (w *Window) SetBorderless(monitor int) error {
+	cfg := w.cfg
+	cfg.Fullscreen, cfg.Borderless = false, true
+	cfg.MonitorIndex = monitor
+	return w.setMode(cfg)
+}
+
+// setMode applies a config's window mode. The backend passes the resulting
+// size on to handleResize.
+func (w *Window) setMode(cfg WindowConfig) error {
+	if w.closed {
+		return fmt.Errorf("window %q is closed", w.cfg.Name)
+	}
+	if monitors := w.manager.Monitors(); cfg.MonitorIndex < 0 || cfg.MonitorIndex >= len(monitors) {
+		return fmt.Errorf("no monitor %d, %d connected", cfg.MonitorIndex, len(monitors))
+	}
+	if err := w.manager.backend.setWindowMode(w, cfg); err != nil {
+		return fmt.Errorf("cannot make window %q %v: %w", w.cfg.Name, cfg.mode(), err)
+	}
+	w.cfg.Fullscreen, w.cfg.Borderless = cfg.Fullscreen, cfg.Borderless
+	w.cfg.MonitorIndex, w.cfg.Hz = cfg.MonitorIndex, cfg.Hz
+	return nil
+}
+
+// Close closes a secondary window and releases its surface. Closing the main
+// window stops the application.
+func (w *Window) Close() {
+	if w.isMain() {
+		w.shouldClose = true
+		return
+	}
+	if w.closed {
+		return
+	}
+	w.closed = true
+
+	m := w.manager
+	releaseHandle(w.surface)
+	w.surface = nil
+	m.backend.destroyWindow(w)
+	m.windows = slices.DeleteFunc(m.windows, func(o *Window) bool { return o == w })
+}
+
+// Closed returns whether a secondary window was closed.
+func (w *Window) Closed() bool {
+	return w.closed
+}
+
+// isMain returns whether this is the window manager's main window.
+func (w *Window) isMain() bool {
+	return w == &w.manager.Window
+}
+
+// resizeSurface resizes the window's surface. The main window's also resizes
+// the renderer's window-sized targets.
+func (w *Window) resizeSurface(width, height uint32) {
+	r := w.manager.engine.renderer
+	switch {
+	case r == nil:
+	case w.isMain():
+		r.resize(width, height)
+	case w.surface != nil:
+		r.backend.ResizeSurface(w.surface, width, height)
+	}
+}
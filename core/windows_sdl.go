@@ -0,0 +1,271 @@
+package core
+
+/*
+#cgo pkg-config: sdl3
+#include <stdlib.h>
+#include <SDL3/SDL.h>
+#include <SDL3/SDL_metal.h>
+
+static int gosg_centered_on(SDL_DisplayID display) {
+	return SDL_WINDOWPOS_CENTERED_DISPLAY(display);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/golang/glog"
+)
+
+// sdlSecondaryWindow is the SDL window and Metal view of a secondary window.
+type sdlSecondaryWindow struct {
+	window    *C.SDL_Window
+	metalView C.SDL_MetalView
+}
+
+// sdlWindowFlags returns the SDL window flags for a config. Fullscreen modes
+// are applied once the window exists.
+func sdlWindowFlags(cfg WindowConfig) C.Uint64 {
+	flags := C.Uint64(C.SDL_WINDOW_METAL | C.SDL_WINDOW_HIGH_PIXEL_DENSITY)
+	if cfg.Resizable {
+		flags |= C.SDL_WINDOW_RESIZABLE
+	}
+	return flags
+}
+
+// sdlWindowSize returns the size a window is created with, which defaults
+// to its monitor's desktop size.
+func sdlWindowSize(cfg WindowConfig) (C.int, C.int) {
+	if cfg.Width > 0 && cfg.Height > 0 {
+		return C.int(cfg.Width), C.int(cfg.Height)
+	}
+	if displays := sdlDisplays(); cfg.MonitorIndex >= 0 && cfg.MonitorIndex < len(displays) {
+		if mode := C.SDL_GetDesktopDisplayMode(displays[cfg.MonitorIndex]); mode != nil {
+			return mode.w, mode.h
+		}
+	}
+	return 1280, 720
+}
+
+// handle returns the SDL window of a window, nil if it isn't open.
+func (s *sdlWindow) handle(win *Window) *C.SDL_Window {
+	if win.isMain() {
+		return s.window
+	}
+	if sec := s.secondary[win]; sec != nil {
+		return sec.window
+	}
+	return nil
+}
+
+// windowFor returns the window with an SDL window ID, nil if it isn't ours.
+func (s *sdlWindow) windowFor(w *WindowManager, id C.SDL_WindowID) *Window {
+	if s.window != nil && C.SDL_GetWindowID(s.window) == id {
+		return &w.Window
+	}
+	for win, sec := range s.secondary {
+		if C.SDL_GetWindowID(sec.window) == id {
+			return win
+		}
+	}
+	return nil
+}
+
+// windowEvent passes a window or drop event to the window it is for.
+func (s *sdlWindow) windowEvent(w *WindowManager, event *C.SDL_Event, eventType C.Uint32) {
+	we := (*C.SDL_WindowEvent)(unsafe.Pointer(event))
+	win := s.windowFor(w, we.windowID)
+	if win == nil {
+		return
+	}
+
+	switch eventType {
+	case C.SDL_EVENT_WINDOW_CLOSE_REQUESTED:
+		win.shouldClose = true
+	case C.SDL_EVENT_WINDOW_RESIZED, C.SDL_EVENT_WINDOW_PIXEL_SIZE_CHANGED, C.SDL_EVENT_WINDOW_DISPLAY_SCALE_CHANGED:
+		s.syncSize(win)
+	case C.SDL_EVENT_WINDOW_FOCUS_GAINED:
+		win.handleFocus(true)
+	case C.SDL_EVENT_WINDOW_FOCUS_LOST:
+		win.handleFocus(false)
+	case C.SDL_EVENT_WINDOW_MINIMIZED:
+		win.handleMinimized(true)
+	case C.SDL_EVENT_WINDOW_RESTORED, C.SDL_EVENT_WINDOW_MAXIMIZED:
+		win.handleMinimized(false)
+		s.syncSize(win)
+	case C.SDL_EVENT_DROP_FILE:
+		de := (*C.SDL_DropEvent)(unsafe.Pointer(event))
+		win.handleFileDrop(C.GoString(de.data))
+	}
+}
+
+// syncSize passes a window's current size in points and pixels to it.
+func (s *sdlWindow) syncSize(win *Window) {
+	window := s.handle(win)
+	if window == nil {
+		return
+	}
+	var pointW, pointH, pw, ph C.int
+	C.SDL_GetWindowSize(window, &pointW, &pointH)
+	C.SDL_GetWindowSizeInPixels(window, &pw, &ph)
+	win.handleResize(int(pointW), int(pointH), int(pw), int(ph))
+}
+
+func (s *sdlWindow) openWindow(win *Window) error {
+	name := C.CString(win.cfg.Name)
+	defer C.free(unsafe.Pointer(name))
+
+	width, height := sdlWindowSize(win.cfg)
+	window := C.SDL_CreateWindow(name, width, height, sdlWindowFlags(win.cfg))
+	if window == nil {
+		return fmt.Errorf("SDL_CreateWindow failed: %s", C.GoString(C.SDL_GetError()))
+	}
+	metalView := C.SDL_Metal_CreateView(window)
+	if metalView == nil {
+		err := fmt.Errorf("SDL_Metal_CreateView failed: %s", C.GoString(C.SDL_GetError()))
+		C.SDL_DestroyWindow(window)
+		return err
+	}
+
+	if s.secondary == nil {
+		s.secondary = make(map[*Window]*sdlSecondaryWindow)
+	}
+	s.secondary[win] = &sdlSecondaryWindow{window: window, metalView: metalView}
+
+	if win.cfg.mode() != WindowModeWindowed || win.cfg.MonitorIndex != 0 {
+		if err := s.setWindowMode(win, win.cfg); err != nil {
+			s.destroyWindow(win)
+			return err
+		}
+	}
+	s.syncSize(win)
+
+	surface, err := win.manager.engine.renderer.backend.CreateSurface(
+		C.SDL_Metal_GetLayer(metalView), uint32(win.pixelWidth), uint32(win.pixelHeight))
+	if err != nil {
+		s.destroyWindow(win)
+		return err
+	}
+	win.surface = surface
+	return nil
+}
+
+func (s *sdlWindow) destroyWindow(win *Window) {
+	sec := s.secondary[win]
+	if sec == nil {
+		return
+	}
+	C.SDL_Metal_DestroyView(sec.metalView)
+	C.SDL_DestroyWindow(sec.window)
+	delete(s.secondary, win)
+}
+
+func (s *sdlWindow) setWindowMode(win *Window, cfg WindowConfig) error {
+	window := s.handle(win)
+	if window == nil {
+		return errors.New("window isn't open")
+	}
+	displays := sdlDisplays()
+	if cfg.MonitorIndex < 0 || cfg.MonitorIndex >= len(displays) {
+		return fmt.Errorf("no monitor %d", cfg.MonitorIndex)
+	}
+	display := displays[cfg.MonitorIndex]
+	centered := C.gosg_centered_on(display)
+
+	switch cfg.mode() {
+	case WindowModeFullscreen:
+		want := sdlMonitor(display, cfg.MonitorIndex).ClosestMode(cfg.Width, cfg.Height, float32(cfg.Hz))
+		var mode C.SDL_DisplayMode
+		if C.SDL_GetClosestFullscreenDisplayMode(display, C.int(want.Width), C.int(want.Height), C.float(want.Hz), true, &mode) == false {
+			return fmt.Errorf("SDL_GetClosestFullscreenDisplayMode failed: %s", C.GoString(C.SDL_GetError()))
+		}
+		if C.SDL_SetWindowFullscreenMode(window, &mode) == false {
+			return fmt.Errorf("SDL_SetWindowFullscreenMode failed: %s", C.GoString(C.SDL_GetError()))
+		}
+		if C.SDL_SetWindowFullscreen(window, true) == false {
+			return fmt.Errorf("SDL_SetWindowFullscreen failed: %s", C.GoString(C.SDL_GetError()))
+		}
+	case WindowModeBorderless:
+		C.SDL_SetWindowPosition(window, centered, centered)
+		if C.SDL_SetWindowFullscreenMode(window, nil) == false {
+			return fmt.Errorf("SDL_SetWindowFullscreenMode failed: %s", C.GoString(C.SDL_GetError()))
+		}
+		if C.SDL_SetWindowFullscreen(window, true) == false {
+			return fmt.Errorf("SDL_SetWindowFullscreen failed: %s", C.GoString(C.SDL_GetError()))
+		}
+	default:
+		if C.SDL_SetWindowFullscreen(window, false) == false {
+			return fmt.Errorf("SDL_SetWindowFullscreen failed: %s", C.GoString(C.SDL_GetError()))
+		}
+		if cfg.Width > 0 && cfg.Height > 0 {
+			C.SDL_SetWindowSize(window, C.int(cfg.Width), C.int(cfg.Height))
+		}
+		C.SDL_SetWindowPosition(window, centered, centered)
+	}
+
+	// let the platform finish the transition (eg: the macOS fullscreen
+	// animation) before querying the final size
+	C.SDL_SyncWindow(window)
+	s.syncSize(win)
+	return nil
+}
+
+func (s *sdlWindow) monitors() []Monitor {
+	displays := sdlDisplays()
+	monitors := make([]Monitor, 0, len(displays))
+	for i, display := range displays {
+		monitors = append(monitors, sdlMonitor(display, i))
+	}
+	return monitors
+}
+
+// sdlDisplays returns the IDs of the connected displays.
+func sdlDisplays() []C.SDL_DisplayID {
+	var count C.int
+	ids := C.SDL_GetDisplays(&count)
+	if ids == nil {
+		glog.Warningf("Cannot list displays: %s", C.GoString(C.SDL_GetError()))
+		return nil
+	}
+	defer C.SDL_free(unsafe.Pointer(ids))
+	return append([]C.SDL_DisplayID(nil), unsafe.Slice(ids, int(count))...)
+}
+
+// sdlMonitor describes a display.
+func sdlMonitor(display C.SDL_DisplayID, index int) Monitor {
+	m := Monitor{
+		Index:        index,
+		Name:         C.GoString(C.SDL_GetDisplayName(display)),
+		Primary:      display == C.SDL_GetPrimaryDisplay(),
+		ContentScale: float32(C.SDL_GetDisplayContentScale(display)),
+	}
+
+	var bounds C.SDL_Rect
+	if C.SDL_GetDisplayBounds(display, &bounds) != false {
+		m.X, m.Y, m.Width, m.Height = int(bounds.x), int(bounds.y), int(bounds.w), int(bounds.h)
+	}
+	if mode := C.SDL_GetDesktopDisplayMode(display); mode != nil {
+		m.Desktop = videoModeFromSDL(mode)
+	}
+
+	var count C.int
+	if modes := C.SDL_GetFullscreenDisplayModes(display, &count); modes != nil {
+		for _, mode := range unsafe.Slice(modes, int(count)) {
+			m.Modes = append(m.Modes, videoModeFromSDL(mode))
+		}
+		C.SDL_free(unsafe.Pointer(modes))
+	}
+	return m
+}
+
+func videoModeFromSDL(mode *C.SDL_DisplayMode) VideoMode {
+	return VideoMode{
+		Width:        int(mode.w),
+		Height:       int(mode.h),
+		PixelDensity: float32(mode.pixel_density),
+		Hz:           float32(mode.refresh_rate),
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"testing"
+)
+
+// newHeadlessWindowEngine returns an engine with an open headless main window.
+func newHeadlessWindowEngine(t *testing.T) *Engine {
+	t.Helper()
+	e := newTestEngine(t)
+	wm := e.WindowManager()
+	wm.SetWindowConfig(WindowConfig{Name: "main", Width: 320, Height: 240, Headless: true})
+	if err := wm.MakeWindow(); err != nil {
+		t.Fatalf("MakeWindow failed: %v", err)
+	}
+	return e
+}
+
+func TestMonitors(t *testing.T) {
+	e := newHeadlessWindowEngine(t)
+	monitors := e.WindowManager().Monitors()
+	if len(monitors) != 1 || !monitors[0].Primary || len(monitors[0].Modes) != 3 {
+		t.Fatalf("monitors = %+v, want the headless monitor", monitors)
+	}
+
+	m := monitors[0]
+	tests := []struct {
+		width, height int
+		hz            float32
+		want          VideoMode
+	}{
+		{0, 0, 0, m.Desktop},
+		{1280, 720, 30, m.Modes[2]},
+		{1000, 700, 0, m.Modes[1]},
+		{4000, 3000, 144, m.Modes[0]},
+	}
+	for _, tt := range tests {
+		if got := m.ClosestMode(tt.width, tt.height, tt.hz); got != tt.want {
+			t.Errorf("ClosestMode(%d, %d, %v) = %+v, want %+v", tt.width, tt.height, tt.hz, got, tt.want)
+		}
+	}
+}
+
+func TestWindowModes(t *testing.T) {
+	e := newHeadlessWindowEngine(t)
+	wm := e.WindowManager()
+
+	size := func() [2]float32 {
+		w, h := e.Renderer().backend.SurfaceSize()
+		if s := wm.WindowSize(); s.X() != float32(w) || s.Y() != float32(h) {
+			t.Errorf("window size %v and surface size %dx%d differ", s, w, h)
+		}
+		return [2]float32{wm.WindowSize().X(), wm.WindowSize().Y()}
+	}
+
+	if err := wm.SetFullscreen(0, VideoMode{Width: 1280, Height: 720, Hz: 60}); err != nil {
+		t.Fatal(err)
+	}
+	if got := size(); wm.Mode() != WindowModeFullscreen || got != [2]float32{1280, 720} {
+		t.Errorf("fullscreen window = %v %v, want fullscreen 1280x720", wm.Mode(), got)
+	}
+
+	if err := wm.SetBorderless(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := size(); wm.Mode() != WindowModeBorderless || got != [2]float32{1920, 1080} {
+		t.Errorf("borderless window = %v %v, want borderless 1920x1080", wm.Mode(), got)
+	}
+
+	if err := wm.SetWindowed(800, 600); err != nil {
+		t.Fatal(err)
+	}
+	if got := size(); wm.Mode() != WindowModeWindowed || got != [2]float32{800, 600} {
+		t.Errorf("window = %v %v, want windowed 800x600", wm.Mode(), got)
+	}
+
+	if err := wm.SetBorderless(1); err == nil || wm.Mode() != WindowModeWindowed {
+		t.Errorf("switching to a missing monitor = %v, mode %v, want an error and no change", err, wm.Mode())
+	}
+}
+
+func TestSecondaryWindow(t *testing.T) {
+	e := newHeadlessWindowEngine(t)
+	wm := e.WindowManager()
+
+	win, err := wm.OpenWindow(WindowConfig{Name: "inspector", Width: 400, Height: 300})
+	if err != nil {
+		t.Fatalf("OpenWindow failed: %v", err)
+	}
+	if len(wm.Windows()) != 1 || win.Name() != "inspector" || win.surface == nil {
+		t.Fatalf("windows = %v, want the inspector with a surface", wm.Windows())
+	}
+
+	game, gameCamera := newRecordingScene(t, e, "unlit")
+	inspector, inspectorCamera := newRecordingScene(t, e, "unlit")
+	gameCamera.SetAutoReshape(true)
+	inspectorCamera.SetAutoReshape(true)
+	inspectorCamera.SetWindow(win)
+
+	frame := func() RecordedFrame {
+		for _, s := range []*Scene{game, inspector} {
+			s.cull(e)
+		}
+		e.Renderer().BeginFrame()
+		game.draw(e)
+		inspector.draw(e)
+		e.Renderer().EndFrame()
+		return e.Renderer().LastFrame()
+	}
+
+	passes := frame().Passes
+	if len(passes) != 4 {
+		t.Fatalf("passes = %d, want the z and opaque passes of both cameras", len(passes))
+	}
+	for i, p := range passes {
+		want, size := SurfaceHandle(nil), [2]float32{320, 240}
+		if i >= 2 {
+			want, size = win.surface, [2]float32{400, 300}
+		}
+		if p.Surface != want || p.Viewport[2] != size[0] || p.Viewport[3] != size[1] {
+			t.Errorf("pass %d drew into %v at %v, want %v at %v", i, p.Surface, p.Viewport, want, size)
+		}
+	}
+
+	// resizing the inspector leaves the main window alone
+	win.handleResize(500, 400, 1000, 800)
+	if s := win.surface.(*recordedSurface); s.width != 1000 || s.height != 800 {
+		t.Errorf("inspector surface = %dx%d, want 1000x800", s.width, s.height)
+	}
+	if w, h := e.Renderer().backend.SurfaceSize(); w != 320 || h != 240 {
+		t.Errorf("main surface = %dx%d, want 320x240", w, h)
+	}
+	if vp := frame().Passes[3].Viewport; vp[2] != 1000 || vp[3] != 800 {
+		t.Errorf("inspector viewport = %v, want the new size", vp)
+	}
+
+	win.Close()
+	if len(wm.Windows()) != 0 || !win.Closed() || win.surface != nil {
+		t.Error("window not closed")
+	}
+	if passes := frame().Passes; len(passes) != 2 {
+		t.Errorf("passes = %d after closing the inspector, want the game's only", len(passes))
+	}
+	if err := win.SetWindowed(100, 100); err == nil {
+		t.Error("closed window changed mode")
+	}
+
+	// closing the main window stops the application
+	wm.Close()
+	if !wm.ShouldClose() {
+		t.Error("main window didn't request the application to stop")
+	}
+}
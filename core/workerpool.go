@@ -0,0 +1,45 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// workerPool runs batches of independent tasks on a bounded number of
+// goroutines. Tasks are handed out by index, so callers that write each
+// task's results to its own slot get the same output as a serial run.
+type workerPool struct {
+	workers int
+}
+
+func newWorkerPool() *workerPool {
+	return &workerPool{workers: runtime.GOMAXPROCS(0)}
+}
+
+// run calls task for every index in [0, count) and returns once all calls
+// returned. With a single worker or task, tasks run on the calling goroutine.
+func (p *workerPool) run(count int, task func(i int)) {
+	workers := min(p.workers, count)
+	if workers <= 1 {
+		for i := 0; i < count; i++ {
+			task(i)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Go(func() {
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= count {
+					return
+				}
+				task(i)
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorkerPool(t *testing.T) {
+	for _, workers := range []int{1, 3, 16} {
+		p := &workerPool{workers: workers}
+		results := make([]int, 100)
+		var calls atomic.Int64
+		p.run(len(results), func(i int) {
+			calls.Add(1)
+			results[i] = i * i
+		})
+		if calls.Load() != 100 {
+			t.Errorf("%d workers: %d calls, want 100", workers, calls.Load())
+		}
+		for i, r := range results {
+			if r != i*i {
+				t.Fatalf("%d workers: task %d result = %d", workers, i, r)
+			}
+		}
+	}
+}
+
+// cullSnapshot is what a cull produced for a camera, in order.
+type cullSnapshot struct {
+	buckets map[string][]*Node
+	opaque  []*Node
+	casters [][]*Node
+}
+
+func TestParallelCullMatchesSerial(t *testing.T) {
+	e, scene, camera := newCullScene(t, 5000, 50)
+	glass, _ := e.ResourceManager().Pipeline("glass")
+	for i, g := range scene.Root().Children() {
+		if i%3 == 0 {
+			g.Children()[0].SetPipeline(glass)
+		}
+	}
+
+	// cameras looking along each axis
+	cameras := []*Camera{camera}
+	for _, axis := range []mgl64.Vec3{{0, 1, 0}, {1, 0, 0}, {0, -1, 0}} {
+		c := NewCamera("Camera", PerspectiveProjection)
+		c.SetViewport(mgl32.Vec4{0, 0, 64, 64})
+		c.SetVerticalFieldOfView(60)
+		c.SetClipDistance(mgl64.Vec2{0.1, 1000})
+		c.SetScene(scene.Root())
+		c.SetOcclusionCulling(true)
+		scene.AddCamera(scene.Root(), c)
+		c.node.Rotate(90, axis)
+		cameras = append(cameras, c)
+	}
+	scene.update(e, 0)
+
+	light := &Light{Shadower: newShadowMap(e, 256, 3)}
+	light.Block.Position = mgl32.Vec4{1, 1, 1, 0}
+	shadowMap := light.Shadower.(*ShadowMap)
+
+	cull := func(workers int) []cullSnapshot {
+		e.SetWorkers(workers)
+		scene.cull(e)
+		var snapshots []cullSnapshot
+		for _, c := range cameras {
+			s := cullSnapshot{buckets: make(map[string][]*Node), opaque: append([]*Node(nil), c.visibleOpaqueNodes...)}
+			for p, nodes := range c.pipelineBuckets {
+				s.buckets[p.Name] = append([]*Node(nil), nodes...)
+			}
+			e.Renderer().BeginFrame()
+			light.Shadower.Render(light, c)
+			e.Renderer().EndFrame()
+			for i := 0; i < shadowMap.numCascades; i++ {
+				s.casters = append(s.casters, append([]*Node(nil), shadowMap.casters[i]...))
+			}
+			snapshots = append(snapshots, s)
+		}
+		return snapshots
+	}
+
+	for _, culler := range []Culler{new(DefaultCuller), NewBVHCuller()} {
+		scene.Root().SetCullComponent(culler)
+		serial := cull(1)
+		if len(serial[0].opaque) == 0 || len(serial[0].casters[2]) == 0 {
+			t.Fatalf("%T: nothing was culled", culler)
+		}
+		for i := 0; i < 3; i++ {
+			if parallel := cull(8); !reflect.DeepEqual(parallel, serial) {
+				t.Errorf("%T: parallel cull %d differs from the serial cull", culler, i)
+			}
+		}
+	}
+}
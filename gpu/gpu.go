@@ -487,12 +487,12 @@ func (t Texture) CreateView() TextureView {
 	return TextureView{C.wgpuTextureCreateView(t.ref, nil)}
 }
 
-// CreateViewArray creates a view of the full texture as a 2D array.
-func (t Texture) CreateViewArray(layers uint32) TextureView {
+// CreateViewArray creates a view of layers starting at baseLayer as a 2D array.
+func (t Texture) CreateViewArray(baseLayer, layers uint32) TextureView {
 	desc := (*C.WGPUTextureViewDescriptor)(C.calloc(1, C.size_t(unsafe.Sizeof(C.WGPUTextureViewDescriptor{}))))
 	defer C.free(unsafe.Pointer(desc))
 	desc.dimension = C.WGPUTextureViewDimension_2DArray
-	desc.baseArrayLayer = 0
+	desc.baseArrayLayer = C.uint32_t(baseLayer)
 	desc.arrayLayerCount = C.uint32_t(layers)
 	desc.baseMipLevel = 0
 	desc.mipLevelCount = 1
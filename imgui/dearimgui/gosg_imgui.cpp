@@ -30,6 +30,11 @@ int wants_capture_keyboard() {
     return io.WantCaptureKeyboard;
 }
 
+int wants_text_input() {
+    ImGuiIO &io = ImGui::GetIO();
+    return io.WantTextInput;
+}
+
 void set_display_size(float x, float y) {
     ImGuiIO &io = ImGui::GetIO();
     io.DisplaySize.x = x;
@@ -62,6 +67,32 @@ void set_mouse_buttons(int b0, int b1, int b2) {
     io.MouseDown[2] = b2;
 }
 
+void set_key_map(const int *keys) {
+    ImGuiIO &io = ImGui::GetIO();
+    for (int i = 0; i < ImGuiKey_COUNT; i++) {
+        io.KeyMap[i] = keys[i];
+    }
+}
+
+void set_key(int key, int down) {
+    ImGuiIO &io = ImGui::GetIO();
+    if (key >= 0 && key < (int)(sizeof(io.KeysDown) / sizeof(*io.KeysDown))) {
+        io.KeysDown[key] = down;
+    }
+}
+
+void set_key_mods(int ctrl, int shift, int alt) {
+    ImGuiIO &io = ImGui::GetIO();
+    io.KeyCtrl = ctrl;
+    io.KeyShift = shift;
+    io.KeyAlt = alt;
+}
+
+void add_input_characters(const char *utf8) {
+    ImGuiIO &io = ImGui::GetIO();
+    io.AddInputCharactersUTF8(utf8);
+}
+
 unsigned char *get_texture_data(int *width, int *height) {
     ImGuiStyle& style = ImGui::GetStyle();
     style.WindowRounding = 0.0;
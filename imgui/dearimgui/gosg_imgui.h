@@ -17,9 +17,14 @@ void set_texture_id(void *texture);
 void set_mouse_position(double x, double y);
 void set_mouse_buttons(int b0, int b1, int b2);
 void set_mouse_scroll_position(double xoffset, double yoffset);
+void set_key_map(const int *keys);
+void set_key(int key, int down);
+void set_key_mods(int ctrl, int shift, int alt);
+void add_input_characters(const char *utf8);
 
 int wants_capture_mouse();
 int wants_capture_keyboard();
+int wants_text_input();
 
 // draw loop
 void frame_new();
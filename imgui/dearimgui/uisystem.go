@@ -1,6 +1,7 @@
 // Package dearimgui provides an implementation of core.IMGUISystem by wrapping the C++ library DearIMGUI
 package dearimgui
 
+// #include <stdlib.h>
 // #include "gosg_imgui.h"
 // #cgo windows LDFLAGS: -Wl,--allow-multiple-definition -limm32
 import "C"
@@ -20,6 +21,9 @@ func init() {
 // IMGUISystem provides an implementation of the core.IMGUISystem interface by wrapping the C++ library DearIMGUI.
 type IMGUISystem struct {
 	texture *core.Texture
+
+	// whether text input was started for an active ImGui text widget
+	textInput bool
 }
 
 type textureData struct {
@@ -30,6 +34,14 @@ type textureData struct {
 
 var (
 	displaySize mgl32.Vec2
+
+	// keyMap holds the keys for ImGuiKey_Tab to ImGuiKey_Z, in order
+	keyMap = [...]core.Key{
+		core.KeyTab, core.KeyLeft, core.KeyRight, core.KeyUp, core.KeyDown,
+		core.KeyPageUp, core.KeyPageDown, core.KeyHome, core.KeyEnd, core.KeyDelete,
+		core.KeyBackspace, core.KeyReturn, core.KeyEscape,
+		core.KeyA, core.KeyC, core.KeyV, core.KeyX, core.KeyY, core.KeyZ,
+	}
 )
 
 func (i *IMGUISystem) getTextureData() textureData {
@@ -54,11 +66,18 @@ func (i *IMGUISystem) Start() {
 		Filter:        core.TextureFilterLinear,
 		WrapMode:      core.TextureWrapModeClampEdge,
 	}
-	i.texture = core.GetRenderer().NewTexture(textureDescriptor, tdata.payload)
-	if i.texture == nil {
-		glog.Fatal("Cannot set nil texture")
+	texture, err := core.GetRenderer().NewTexture(textureDescriptor, tdata.payload)
+	if err != nil {
+		glog.Fatal("Cannot create font texture: ", err)
 	}
+	i.texture = texture
 	C.set_texture_id(i.texture.Handle())
+
+	var keys [len(keyMap)]C.int
+	for j, k := range keyMap {
+		keys[j] = C.int(k)
+	}
+	C.set_key_map(&keys[0])
 }
 
 // Stop implements the core.IMGUISystem interface
@@ -127,6 +146,10 @@ func (i *IMGUISystem) StartFrame(dt float64) {
 		state.Mouse.Buttons.Active[core.MouseButton2],
 		state.Mouse.Buttons.Active[core.MouseButton3])
 	i.SetMouseScrollPosition(state.Mouse.Scroll.X, state.Mouse.Scroll.Y)
+	i.SetKeys(&state.Keys)
+	if state.Text != "" {
+		i.AddInputCharacters(state.Text)
+	}
 
 	C.set_dt(C.double(dt))
 	C.frame_new()
@@ -172,18 +195,48 @@ func (i *IMGUISystem) SetMouseScrollPosition(xoffset, yoffset float64) {
 	C.set_mouse_scroll_position(C.double(xoffset), C.double(yoffset))
 }
 
+// SetKeys passes the held keys and modifiers to ImGui.
+func (i *IMGUISystem) SetKeys(keys *core.KeyState) {
+	for k, down := range keys.Active {
+		C.set_key(C.int(k), cBool(down))
+	}
+	C.set_key_mods(cBool(keys.Control()), cBool(keys.Shift()), cBool(keys.Alt()))
+}
+
+// AddInputCharacters passes typed UTF-8 text to ImGui's text widgets.
+func (i *IMGUISystem) AddInputCharacters(text string) {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+	C.add_input_characters(cText)
+}
+
 // EndFrame implements the core.IMGUISystem interface
 func (i *IMGUISystem) EndFrame() {
 	C.render()
 
-	// Only consume input that ImGui actually used
-	state := core.GetInputManager().State()
+	// Text widgets need the window system's text input while they are active
+	wm := core.GetWindowManager()
+	if wantsText := i.WantsTextInput(); wantsText && !wm.TextInputActive() {
+		wm.StartTextInput()
+		i.textInput = true
+	} else if !wantsText && i.textInput {
+		wm.StopTextInput()
+		i.textInput = false
+	}
+
+	// Only capture input that ImGui actually used, the scenes below don't get it
+	im := core.GetInputManager()
+	state := im.State()
+	capture := core.InputCaptureNone
 	if i.WantsCaptureMouse() {
 		state.SetMouseValid(false)
+		capture |= core.InputCaptureMouse
 	}
 	if i.WantsCaptureKeyboard() {
 		state.SetKeysValid(false)
+		capture |= core.InputCaptureKeyboard
 	}
+	im.Capture(capture)
 }
 
 // WantsCaptureMouse implements the core.IMGUISystem interface
@@ -196,6 +249,18 @@ func (i *IMGUISystem) WantsCaptureKeyboard() bool {
 	return int(C.wants_capture_keyboard()) == 1
 }
 
+// WantsTextInput returns whether an ImGui text widget is active.
+func (i *IMGUISystem) WantsTextInput() bool {
+	return int(C.wants_text_input()) == 1
+}
+
+func cBool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // DrawData implements the core.DrawData interface
 type DrawData struct {
 	drawData unsafe.Pointer
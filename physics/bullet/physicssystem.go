@@ -12,7 +12,9 @@ import (
 )
 
 func init() {
-	core.SetPhysicsSystem(&PhysicsSystem{})
+	if err := core.SetPhysicsSystem(&PhysicsSystem{}); err != nil {
+		glog.Error(err)
+	}
 }
 
 // convenience
@@ -155,7 +157,8 @@ func (p *PhysicsSystem) NewConvexHullShape() core.CollisionShape {
 	return CollisionShape{C.plNewConvexHullShape()}
 }
 
-// NewStaticTriangleMeshShape implements the core.PhysicsSystem interface
+// NewStaticTriangleMeshShape implements the core.PhysicsSystem interface. The
+// C API doesn't implement triangle meshes yet, so it returns nil.
 func (p *PhysicsSystem) NewStaticTriangleMeshShape(mesh core.Mesh) core.CollisionShape {
 	/*
 		bulletMeshInterface := C.plNewMeshInterface()
@@ -25,30 +25,38 @@ var (
 
 func init() {
 	flag.Parse()
-	core.GetResourceManager().SetSystem(New())
+	r, err := New()
+	if err != nil {
+		glog.Error(err)
+		return
+	}
+	if err := core.GetResourceManager().SetSystem(r); err != nil {
+		glog.Error(err)
+	}
 }
 
-// New returns a new ResourceSystem
-func New() *ResourceSystem {
+// New returns a new ResourceSystem. It fails if the data directories cannot be resolved
+// or a required one is missing.
+func New() (*ResourceSystem, error) {
 	var bp, ubp string
 
 	if runtime.GOOS == "darwin" && strings.HasSuffix(filepath.Dir(os.Args[0]), "MacOS") {
 		glog.Info("Looking for data directory in same folder")
 		path, err := filepath.Abs(filepath.Dir(os.Args[0]) + "/../Resources")
 		if err != nil {
-			glog.Fatalf("Could not create data path from provided: %s\n", *basePath)
+			return nil, fmt.Errorf("could not create data path from provided %s: %w", *basePath, err)
 		}
 		bp = filepath.Join(path, *basePath)
 		ubp = filepath.Join(path, *userBasePath)
 	} else {
 		path, err := filepath.Abs(*basePath)
 		if err != nil {
-			glog.Fatalf("Could not create data path from provided: %s\n", *basePath)
+			return nil, fmt.Errorf("could not create data path from provided %s: %w", *basePath, err)
 		}
 
 		userPath, err := filepath.Abs(*userBasePath)
 		if err != nil {
-			glog.Fatalf("Could not create data path from provided: %s\n", *basePath)
+			return nil, fmt.Errorf("could not create data path from provided %s: %w", *userBasePath, err)
 		}
 
 		bp = path
@@ -69,73 +77,86 @@ func New() *ResourceSystem {
 	for _, name := range requiredPaths {
 		p := paths[name]
 		if _, err := os.Stat(p[0]); os.IsNotExist(err) {
-			glog.Fatalf("No such file or directory: %v\n", p[0])
+			return nil, fmt.Errorf("no such file or directory: %v", p[0])
 		}
 	}
 
-	return &r
+	return &r, nil
 }
 
-func (r *ResourceSystem) resourceWithFullpath(fullpath string) ([]byte, error) {
-	data, e := os.ReadFile(fullpath)
-	if e != nil {
-		return nil, fmt.Errorf("could not read file: %v", e)
+// resolve returns the user path of a resource if it exists, otherwise its base path.
+func (r *ResourceSystem) resolve(name, rtype string) string {
+	fullpath := filepath.Join(r.paths[rtype][1], name)
+	if _, err := os.Stat(fullpath); err == nil {
+		return fullpath
 	}
-	return data, nil
+	return filepath.Join(r.paths[rtype][0], name)
 }
 
-func (r *ResourceSystem) loadResource(name, rtype string) []byte {
-	fullpath := filepath.Join(r.paths[rtype][1], name)
-	res, err := r.resourceWithFullpath(fullpath)
+func (r *ResourceSystem) loadResource(kind core.ResourceKind, name, filename, rtype string) ([]byte, error) {
+	fullpath := r.resolve(filename, rtype)
+	data, err := os.ReadFile(fullpath)
 	if err != nil {
-		fullpath = filepath.Join(r.paths[rtype][0], name)
-		res, err = r.resourceWithFullpath(fullpath)
-		if err != nil {
-			glog.Fatal(err)
-		}
-		return res
+		return nil, &core.ResourceError{Kind: kind, Name: name, Path: fullpath, Err: err}
 	}
-	return res
+	return data, nil
+}
+
+// ResourcePath implements the core.ResourcePather interface
+func (r *ResourceSystem) ResourcePath(kind core.ResourceKind, name string) string {
+	switch kind {
+	case core.ResourceModel:
+		return r.resolve(name, "models")
+	case core.ResourceTexture:
+		return r.resolve(name, "textures")
+	case core.ResourceProgram:
+		return r.resolve(r.programFilename(name), "programs")
+	case core.ResourceProgramData:
+		return r.resolve(name, "programs")
+	case core.ResourcePipeline:
+		return r.resolve(name+".json", "pipelines")
+	case core.ResourceScene:
+		return r.resolve(name, "scenes")
+	default:
+		return ""
+	}
+}
+
+func (r *ResourceSystem) programFilename(name string) string {
+	return name + "." + core.GetRenderer().ProgramExtension()
 }
 
 // Model implements the core.ResourceSystem interface
-func (r *ResourceSystem) Model(filename string) []byte {
-	return r.loadResource(filename, "models")
+func (r *ResourceSystem) Model(filename string) ([]byte, error) {
+	return r.loadResource(core.ResourceModel, filename, filename, "models")
 }
 
 // ModelPath returns the resolved filesystem path to a model file.
 func (r *ResourceSystem) ModelPath(filename string) string {
-	// Try user path first, then base path
-	fullpath := filepath.Join(r.paths["models"][1], filename)
-	if _, err := os.Stat(fullpath); err == nil {
-		return fullpath
-	}
-	return filepath.Join(r.paths["models"][0], filename)
+	return r.resolve(filename, "models")
 }
 
 // Texture implements the core.ResourceSystem interface
-func (r *ResourceSystem) Texture(filename string) []byte {
-	return r.loadResource(filename, "textures")
+func (r *ResourceSystem) Texture(filename string) ([]byte, error) {
+	return r.loadResource(core.ResourceTexture, filename, filename, "textures")
 }
 
 // Pipeline implements the core.ResourceSystem interface
-func (r *ResourceSystem) Pipeline(name string) []byte {
-	var filename = name + ".json"
-	return r.loadResource(filename, "pipelines")
+func (r *ResourceSystem) Pipeline(name string) ([]byte, error) {
+	return r.loadResource(core.ResourcePipeline, name, name+".json", "pipelines")
 }
 
 // Program implements the core.ResourceSystem interface
-func (r *ResourceSystem) Program(name string) []byte {
-	var filename = name + "." + core.GetRenderer().ProgramExtension()
-	return r.loadResource(filename, "programs")
+func (r *ResourceSystem) Program(name string) ([]byte, error) {
+	return r.loadResource(core.ResourceProgram, name, r.programFilename(name), "programs")
 }
 
 // ProgramData implements the core.ResourceSystem interface
-func (r *ResourceSystem) ProgramData(name string) []byte {
-	return r.loadResource(name, "programs")
+func (r *ResourceSystem) ProgramData(name string) ([]byte, error) {
+	return r.loadResource(core.ResourceProgramData, name, name, "programs")
 }
 
 // Scene implements the core.ResourceSystem interface
-func (r *ResourceSystem) Scene(name string) []byte {
-	return r.loadResource(name, "scenes")
+func (r *ResourceSystem) Scene(name string) ([]byte, error) {
+	return r.loadResource(core.ResourceScene, name, name, "scenes")
 }